@@ -181,6 +181,10 @@ func setupServer(staticConfiguration *static.Configuration) (*server.Server, err
 	// ACME
 
 	tlsManager := traefiktls.NewManager()
+	if staticConfiguration.OCSP != nil {
+		tlsManager.EnableOCSPStapling(staticConfiguration.OCSP, routinesPool)
+	}
+
 	httpChallengeProvider := acme.NewChallengeHTTP()
 
 	// we need to wait at least 2 times the ProvidersThrottleDuration to be sure to handle the challenge.
@@ -243,7 +247,7 @@ func setupServer(staticConfiguration *static.Configuration) (*server.Server, err
 
 	accessLog := setupAccessLog(staticConfiguration.AccessLog)
 	chainBuilder := middleware.NewChainBuilder(*staticConfiguration, metricsRegistry, accessLog)
-	routerFactory := server.NewRouterFactory(*staticConfiguration, managerFactory, tlsManager, chainBuilder, pluginBuilder)
+	routerFactory := server.NewRouterFactory(*staticConfiguration, managerFactory, metricsRegistry, tlsManager, chainBuilder, pluginBuilder)
 
 	// Watcher
 
@@ -367,13 +371,24 @@ func initACMEProvider(c *static.Configuration, providerAggregator *aggregator.Pr
 	var resolvers []*acme.Provider
 	for name, resolver := range c.CertificatesResolvers {
 		if resolver.ACME != nil {
-			if localStores[resolver.ACME.Storage] == nil {
-				localStores[resolver.ACME.Storage] = acme.NewLocalStore(resolver.ACME.Storage)
+			var store acme.Store
+			if resolver.ACME.KVStorage != nil {
+				kvStore, err := acme.NewKVStore(context.Background(), resolver.ACME.KVStorage)
+				if err != nil {
+					log.WithoutContext().Errorf("The ACME resolver %q is skipped from the resolvers list because: %v", name, err)
+					continue
+				}
+				store = kvStore
+			} else {
+				if localStores[resolver.ACME.Storage] == nil {
+					localStores[resolver.ACME.Storage] = acme.NewLocalStore(resolver.ACME.Storage)
+				}
+				store = localStores[resolver.ACME.Storage]
 			}
 
 			p := &acme.Provider{
 				Configuration:         resolver.ACME,
-				Store:                 localStores[resolver.ACME.Storage],
+				Store:                 store,
 				ResolverName:          name,
 				HTTPChallengeProvider: httpChallengeProvider,
 				TLSChallengeProvider:  tlsChallengeProvider,
@@ -775,9 +775,10 @@ func TestDo_staticConfiguration(t *testing.T) {
 	}
 
 	config.API = &static.API{
-		Insecure:  true,
-		Dashboard: true,
-		Debug:     true,
+		Insecure:   true,
+		Dashboard:  true,
+		Debug:      true,
+		CachePurge: true,
 		DashboardAssets: &assetfs.AssetFS{
 			Asset: func(path string) ([]byte, error) {
 				return nil, nil
@@ -11,6 +11,8 @@ import (
 	"github.com/gorilla/mux"
 	"github.com/traefik/traefik/v2/pkg/config/runtime"
 	"github.com/traefik/traefik/v2/pkg/log"
+	"github.com/traefik/traefik/v2/pkg/middlewares/cache"
+	"github.com/traefik/traefik/v2/pkg/server/service/loadbalancer/mirror"
 )
 
 type routerRepresentation struct {
@@ -165,6 +167,36 @@ func (h Handler) getService(rw http.ResponseWriter, request *http.Request) {
 	}
 }
 
+// getServiceMirroringMismatches returns the logged mismatches between the responses of the mirrors
+// and the response of the main service of a mirroring service, the most recent first.
+func (h Handler) getServiceMirroringMismatches(rw http.ResponseWriter, request *http.Request) {
+	serviceID := mux.Vars(request)["serviceID"]
+
+	rw.Header().Set("Content-Type", "application/json")
+
+	service, ok := h.runtimeConfiguration.Services[serviceID]
+	if !ok {
+		writeError(rw, fmt.Sprintf("service not found: %s", serviceID), http.StatusNotFound)
+		return
+	}
+
+	if service.Mirroring == nil || service.Mirroring.Comparison == nil {
+		writeError(rw, fmt.Sprintf("service does not compare the mirrored responses: %s", serviceID), http.StatusBadRequest)
+		return
+	}
+
+	mismatches, _ := mirror.Mismatches(serviceID)
+	if mismatches == nil {
+		mismatches = []mirror.Mismatch{}
+	}
+
+	err := json.NewEncoder(rw).Encode(mismatches)
+	if err != nil {
+		log.FromContext(request.Context()).Error(err)
+		writeError(rw, err.Error(), http.StatusInternalServerError)
+	}
+}
+
 func (h Handler) getMiddlewares(rw http.ResponseWriter, request *http.Request) {
 	results := make([]middlewareRepresentation, 0, len(h.runtimeConfiguration.Middlewares))
 
@@ -217,6 +249,39 @@ func (h Handler) getMiddleware(rw http.ResponseWriter, request *http.Request) {
 	}
 }
 
+type cachePurgeRepresentation struct {
+	Purged int `json:"purged"`
+}
+
+// purgeMiddlewareCache removes the responses cached by a cache middleware.
+// The optional path query parameter restricts the purge to the responses to the requests with this path.
+func (h Handler) purgeMiddlewareCache(rw http.ResponseWriter, request *http.Request) {
+	middlewareID := mux.Vars(request)["middlewareID"]
+
+	rw.Header().Set("Content-Type", "application/json")
+
+	middleware, ok := h.runtimeConfiguration.Middlewares[middlewareID]
+	if !ok {
+		writeError(rw, fmt.Sprintf("middleware not found: %s", middlewareID), http.StatusNotFound)
+		return
+	}
+
+	if middleware.Cache == nil {
+		writeError(rw, fmt.Sprintf("middleware is not a cache middleware: %s", middlewareID), http.StatusBadRequest)
+		return
+	}
+
+	result := cachePurgeRepresentation{
+		Purged: cache.Purge(middlewareID, request.URL.Query().Get("path")),
+	}
+
+	err := json.NewEncoder(rw).Encode(result)
+	if err != nil {
+		log.FromContext(request.Context()).Error(err)
+		writeError(rw, err.Error(), http.StatusInternalServerError)
+	}
+}
+
 func keepRouter(name string, item *runtime.RouterInfo, criterion *searchCriterion) bool {
 	if criterion == nil {
 		return true
@@ -1,6 +1,7 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
 	"flag"
 	"io/ioutil"
@@ -13,6 +14,9 @@ import (
 	"github.com/traefik/traefik/v2/pkg/config/dynamic"
 	"github.com/traefik/traefik/v2/pkg/config/runtime"
 	"github.com/traefik/traefik/v2/pkg/config/static"
+	"github.com/traefik/traefik/v2/pkg/middlewares/cache"
+	"github.com/traefik/traefik/v2/pkg/safe"
+	"github.com/traefik/traefik/v2/pkg/server/service/loadbalancer/mirror"
 )
 
 var updateExpected = flag.Bool("update_expected", false, "Update expected files in testdata")
@@ -280,3 +284,191 @@ func TestHandler_GetMiddleware(t *testing.T) {
 		})
 	}
 }
+
+func TestHandler_PurgeMiddlewareCache(t *testing.T) {
+	next := http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+		rw.Header().Set("Cache-Control", "max-age=60")
+		_, _ = rw.Write([]byte("foo"))
+	})
+
+	cacheHandler, err := cache.New(context.Background(), next, dynamic.Cache{MaxEntries: 10, MaxResponseBodyBytes: 1024}, "cache@myprovider")
+	require.NoError(t, err)
+
+	for _, path := range []string{"/foo", "/bar"} {
+		cacheHandler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "http://localhost"+path, nil))
+	}
+
+	conf := runtime.Configuration{
+		Middlewares: map[string]*runtime.MiddlewareInfo{
+			"cache@myprovider": {
+				Middleware: &dynamic.Middleware{Cache: &dynamic.Cache{MaxEntries: 10, MaxResponseBodyBytes: 1024}},
+			},
+			"auth@myprovider": {
+				Middleware: &dynamic.Middleware{BasicAuth: &dynamic.BasicAuth{Users: []string{"admin:admin"}}},
+			},
+		},
+	}
+
+	testCases := []struct {
+		desc           string
+		disabled       bool
+		path           string
+		expectedStatus int
+		expected       string
+	}{
+		{
+			desc:           "Endpoint disabled",
+			disabled:       true,
+			path:           "/api/http/middlewares/cache@myprovider/cache",
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			desc:           "Middleware not found",
+			path:           "/api/http/middlewares/unknown@myprovider/cache",
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			desc:           "Not a cache middleware",
+			path:           "/api/http/middlewares/auth@myprovider/cache",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			desc:           "Purge path",
+			path:           "/api/http/middlewares/cache@myprovider/cache?path=/foo",
+			expectedStatus: http.StatusOK,
+			expected:       `{"purged":1}`,
+		},
+		{
+			desc:           "Purge all",
+			path:           "/api/http/middlewares/cache@myprovider/cache",
+			expectedStatus: http.StatusOK,
+			expected:       `{"purged":1}`,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			handler := New(static.Configuration{API: &static.API{CachePurge: !test.disabled}, Global: &static.Global{}}, &conf)
+			server := httptest.NewServer(handler.createRouter())
+			defer server.Close()
+
+			req, err := http.NewRequest(http.MethodDelete, server.URL+test.path, nil)
+			require.NoError(t, err)
+
+			resp, err := http.DefaultClient.Do(req)
+			require.NoError(t, err)
+
+			assert.Equal(t, test.expectedStatus, resp.StatusCode)
+
+			data, err := ioutil.ReadAll(resp.Body)
+			require.NoError(t, err)
+
+			err = resp.Body.Close()
+			require.NoError(t, err)
+
+			if test.expected != "" {
+				assert.JSONEq(t, test.expected, string(data))
+			}
+		})
+	}
+}
+
+func TestHandler_GetServiceMirroringMismatches(t *testing.T) {
+	respond := func(body string) http.Handler {
+		return http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+			_, _ = rw.Write([]byte(body))
+		})
+	}
+
+	pool := safe.NewPool(context.Background())
+
+	mirroring := mirror.New(respond("foo"), pool, -1)
+	require.NoError(t, mirroring.EnableComparison("mirrored@myprovider", dynamic.MirrorComparison{LogSize: 10, LogPercent: 100}, nil))
+	require.NoError(t, mirroring.AddMirror(respond("bar"), 100, mirror.WithName("mirror@myprovider")))
+
+	mirroring.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "http://localhost/foo", nil))
+	pool.Stop()
+
+	conf := runtime.Configuration{
+		Services: map[string]*runtime.ServiceInfo{
+			"mirrored@myprovider": {
+				Service: &dynamic.Service{Mirroring: &dynamic.Mirroring{
+					Service:    "main@myprovider",
+					Mirrors:    []dynamic.MirrorService{{Name: "mirror@myprovider", Percent: 100}},
+					Comparison: &dynamic.MirrorComparison{LogSize: 10, LogPercent: 100},
+				}},
+			},
+			"notcompared@myprovider": {
+				Service: &dynamic.Service{Mirroring: &dynamic.Mirroring{Service: "main@myprovider"}},
+			},
+			"pending@myprovider": {
+				Service: &dynamic.Service{Mirroring: &dynamic.Mirroring{
+					Service:    "main@myprovider",
+					Comparison: &dynamic.MirrorComparison{LogSize: 10, LogPercent: 100},
+				}},
+			},
+		},
+	}
+
+	handler := New(static.Configuration{API: &static.API{}, Global: &static.Global{}}, &conf)
+	server := httptest.NewServer(handler.createRouter())
+	defer server.Close()
+
+	testCases := []struct {
+		desc            string
+		path            string
+		expectedStatus  int
+		expectedReasons [][]string
+	}{
+		{
+			desc:           "Service not found",
+			path:           "/api/http/services/unknown@myprovider/mirroring/mismatches",
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			desc:           "Responses not compared",
+			path:           "/api/http/services/notcompared@myprovider/mirroring/mismatches",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			desc:            "No mismatch logged yet",
+			path:            "/api/http/services/pending@myprovider/mirroring/mismatches",
+			expectedStatus:  http.StatusOK,
+			expectedReasons: [][]string{},
+		},
+		{
+			desc:            "Mismatches",
+			path:            "/api/http/services/mirrored@myprovider/mirroring/mismatches",
+			expectedStatus:  http.StatusOK,
+			expectedReasons: [][]string{{"body"}},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			resp, err := http.DefaultClient.Get(server.URL + test.path)
+			require.NoError(t, err)
+
+			assert.Equal(t, test.expectedStatus, resp.StatusCode)
+
+			if test.expectedReasons == nil {
+				require.NoError(t, resp.Body.Close())
+				return
+			}
+
+			var mismatches []mirror.Mismatch
+			err = json.NewDecoder(resp.Body).Decode(&mismatches)
+			require.NoError(t, err)
+
+			require.NoError(t, resp.Body.Close())
+
+			reasons := [][]string{}
+			for _, mismatch := range mismatches {
+				assert.Equal(t, "mirror@myprovider", mismatch.Mirror)
+				assert.Equal(t, "/foo", mismatch.URL)
+				reasons = append(reasons, mismatch.Reasons)
+			}
+			assert.Equal(t, test.expectedReasons, reasons)
+		})
+	}
+}
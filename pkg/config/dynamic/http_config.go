@@ -47,6 +47,8 @@ type Router struct {
 	Rule        string           `json:"rule,omitempty" toml:"rule,omitempty" yaml:"rule,omitempty"`
 	Priority    int              `json:"priority,omitempty" toml:"priority,omitempty,omitzero" yaml:"priority,omitempty" export:"true"`
 	TLS         *RouterTLSConfig `json:"tls,omitempty" toml:"tls,omitempty" yaml:"tls,omitempty" label:"allowEmpty" file:"allowEmpty" export:"true"`
+	// AccessLog overrides the access log configuration for the requests handled by the router.
+	AccessLog *RouterAccessLog `json:"accessLog,omitempty" toml:"accessLog,omitempty" yaml:"accessLog,omitempty" export:"true"`
 }
 
 // +k8s:deepcopy-gen=true
@@ -60,11 +62,34 @@ type RouterTLSConfig struct {
 
 // +k8s:deepcopy-gen=true
 
+// RouterAccessLog holds the access log configuration of a router.
+type RouterAccessLog struct {
+	Disabled bool               `json:"disabled,omitempty" toml:"disabled,omitempty" yaml:"disabled,omitempty" export:"true"`
+	Sampling *AccessLogSampling `json:"sampling,omitempty" toml:"sampling,omitempty" yaml:"sampling,omitempty" label:"allowEmpty" file:"allowEmpty" export:"true"`
+}
+
+// +k8s:deepcopy-gen=true
+
+// AccessLogSampling holds the sampling configuration of the access logs of a router.
+type AccessLogSampling struct {
+	Rate       float64 `json:"rate,omitempty" toml:"rate,omitempty" yaml:"rate,omitempty" export:"true"`
+	KeepErrors bool    `json:"keepErrors,omitempty" toml:"keepErrors,omitempty" yaml:"keepErrors,omitempty" export:"true"`
+}
+
+// SetDefaults sets the default values.
+func (a *AccessLogSampling) SetDefaults() {
+	a.Rate = 1
+}
+
+// +k8s:deepcopy-gen=true
+
 // Mirroring holds the Mirroring configuration.
 type Mirroring struct {
 	Service     string          `json:"service,omitempty" toml:"service,omitempty" yaml:"service,omitempty" export:"true"`
 	MaxBodySize *int64          `json:"maxBodySize,omitempty" toml:"maxBodySize,omitempty" yaml:"maxBodySize,omitempty" export:"true"`
 	Mirrors     []MirrorService `json:"mirrors,omitempty" toml:"mirrors,omitempty" yaml:"mirrors,omitempty" export:"true"`
+	// Comparison enables the comparison of the responses of the mirrors with the response of the main service.
+	Comparison *MirrorComparison `json:"comparison,omitempty" toml:"comparison,omitempty" yaml:"comparison,omitempty" label:"allowEmpty" file:"allowEmpty" export:"true"`
 }
 
 // SetDefaults Default values for a WRRService.
@@ -75,10 +100,32 @@ func (m *Mirroring) SetDefaults() {
 
 // +k8s:deepcopy-gen=true
 
+// MirrorComparison holds the configuration of the comparison of the mirrors responses with the main response.
+// The responses are compared on their status code, the given headers, and a hash of their body.
+type MirrorComparison struct {
+	Headers []string `json:"headers,omitempty" toml:"headers,omitempty" yaml:"headers,omitempty" export:"true"`
+	// LogSize is the number of most recent mismatches kept in the mismatch log.
+	LogSize int `json:"logSize,omitempty" toml:"logSize,omitempty" yaml:"logSize,omitempty" export:"true"`
+	// LogPercent is the percentage of the mismatches recorded in the mismatch log.
+	LogPercent int `json:"logPercent,omitempty" toml:"logPercent,omitempty" yaml:"logPercent,omitempty" export:"true"`
+}
+
+// SetDefaults Default values for a MirrorComparison.
+func (m *MirrorComparison) SetDefaults() {
+	m.LogSize = 100
+	m.LogPercent = 100
+}
+
+// +k8s:deepcopy-gen=true
+
 // MirrorService holds the MirrorService configuration.
 type MirrorService struct {
 	Name    string `json:"name,omitempty" toml:"name,omitempty" yaml:"name,omitempty" export:"true"`
 	Percent int    `json:"percent,omitempty" toml:"percent,omitempty" yaml:"percent,omitempty" export:"true"`
+	// Rule restricts the mirroring to the requests matching it, and uses the same matchers as the router rules.
+	Rule string `json:"rule,omitempty" toml:"rule,omitempty" yaml:"rule,omitempty"`
+	// Headers are added to the mirrored requests.
+	Headers map[string]string `json:"headers,omitempty" toml:"headers,omitempty" yaml:"headers,omitempty" export:"true"`
 }
 
 // +k8s:deepcopy-gen=true
@@ -105,6 +152,18 @@ func (w *WRRService) SetDefaults() {
 
 // +k8s:deepcopy-gen=true
 
+// HashPolicy holds the configuration of the key used by the hash load-balancing strategy.
+// The first non-empty value among the header, the cookie, and the query parameter is used as the key,
+// and the client IP is used if none of them is present.
+type HashPolicy struct {
+	Header     string      `json:"header,omitempty" toml:"header,omitempty" yaml:"header,omitempty" export:"true"`
+	Cookie     string      `json:"cookie,omitempty" toml:"cookie,omitempty" yaml:"cookie,omitempty" export:"true"`
+	Query      string      `json:"query,omitempty" toml:"query,omitempty" yaml:"query,omitempty" export:"true"`
+	IPStrategy *IPStrategy `json:"ipStrategy,omitempty" toml:"ipStrategy,omitempty" yaml:"ipStrategy,omitempty" label:"allowEmpty" file:"allowEmpty" export:"true"`
+}
+
+// +k8s:deepcopy-gen=true
+
 // Sticky holds the sticky configuration.
 type Sticky struct {
 	Cookie *Cookie `json:"cookie,omitempty" toml:"cookie,omitempty" yaml:"cookie,omitempty" label:"allowEmpty" file:"allowEmpty" export:"true"`
@@ -120,13 +179,30 @@ type Cookie struct {
 	SameSite string `json:"sameSite,omitempty" toml:"sameSite,omitempty" yaml:"sameSite,omitempty" export:"true"`
 }
 
+// Load-balancing strategies of a ServersLoadBalancer.
+const (
+	// BalancerStrategyWRR is the weighted round robin strategy, and the default one.
+	BalancerStrategyWRR = "wrr"
+	// BalancerStrategyLeastConn selects the server with the fewest in-flight requests.
+	BalancerStrategyLeastConn = "leastConn"
+	// BalancerStrategyP2C selects the least loaded of two servers chosen at random (power of two random choices).
+	BalancerStrategyP2C = "p2c"
+	// BalancerStrategyRandom selects a server at random.
+	BalancerStrategyRandom = "random"
+	// BalancerStrategyHash selects the server from a consistent hash of a request key.
+	BalancerStrategyHash = "hash"
+)
+
 // +k8s:deepcopy-gen=true
 
 // ServersLoadBalancer holds the ServersLoadBalancer configuration.
 type ServersLoadBalancer struct {
+	Strategy           string              `json:"strategy,omitempty" toml:"strategy,omitempty" yaml:"strategy,omitempty" export:"true"`
+	Hash               *HashPolicy         `json:"hash,omitempty" toml:"hash,omitempty" yaml:"hash,omitempty" label:"allowEmpty" file:"allowEmpty" export:"true"`
 	Sticky             *Sticky             `json:"sticky,omitempty" toml:"sticky,omitempty" yaml:"sticky,omitempty" label:"allowEmpty" file:"allowEmpty" export:"true"`
 	Servers            []Server            `json:"servers,omitempty" toml:"servers,omitempty" yaml:"servers,omitempty" label-slice-as-struct:"server" export:"true"`
 	HealthCheck        *HealthCheck        `json:"healthCheck,omitempty" toml:"healthCheck,omitempty" yaml:"healthCheck,omitempty" export:"true"`
+	PassiveHealthCheck *PassiveHealthCheck `json:"passiveHealthCheck,omitempty" toml:"passiveHealthCheck,omitempty" yaml:"passiveHealthCheck,omitempty" label:"allowEmpty" file:"allowEmpty" export:"true"`
 	PassHostHeader     *bool               `json:"passHostHeader" toml:"passHostHeader" yaml:"passHostHeader" export:"true"`
 	ResponseForwarding *ResponseForwarding `json:"responseForwarding,omitempty" toml:"responseForwarding,omitempty" yaml:"responseForwarding,omitempty" export:"true"`
 	ServersTransport   string              `json:"serversTransport,omitempty" toml:"serversTransport,omitempty" yaml:"serversTransport,omitempty" export:"true"`
@@ -167,6 +243,7 @@ type ResponseForwarding struct {
 // Server holds the server configuration.
 type Server struct {
 	URL    string `json:"url,omitempty" toml:"url,omitempty" yaml:"url,omitempty" label:"-"`
+	Weight *int   `json:"weight,omitempty" toml:"weight,omitempty" yaml:"weight,omitempty" export:"true"`
 	Scheme string `toml:"-" json:"-" yaml:"-" file:"-"`
 	Port   string `toml:"-" json:"-" yaml:"-" file:"-"`
 }
@@ -200,6 +277,24 @@ func (h *HealthCheck) SetDefaults() {
 
 // +k8s:deepcopy-gen=true
 
+// PassiveHealthCheck holds the passive health check configuration.
+// A server is ejected from the load-balancer after MaxFailures consecutive failed requests (502, 503 and 504 responses, or connection errors),
+// for EjectionTime, which doubles every time the server is ejected again without having recovered, up to MaxEjectionTime.
+type PassiveHealthCheck struct {
+	MaxFailures     int             `json:"maxFailures,omitempty" toml:"maxFailures,omitempty" yaml:"maxFailures,omitempty" export:"true"`
+	EjectionTime    ptypes.Duration `json:"ejectionTime,omitempty" toml:"ejectionTime,omitempty" yaml:"ejectionTime,omitempty" export:"true"`
+	MaxEjectionTime ptypes.Duration `json:"maxEjectionTime,omitempty" toml:"maxEjectionTime,omitempty" yaml:"maxEjectionTime,omitempty" export:"true"`
+}
+
+// SetDefaults Default values for a PassiveHealthCheck.
+func (p *PassiveHealthCheck) SetDefaults() {
+	p.MaxFailures = 5
+	p.EjectionTime = ptypes.Duration(30 * time.Second)
+	p.MaxEjectionTime = ptypes.Duration(5 * time.Minute)
+}
+
+// +k8s:deepcopy-gen=true
+
 // ServersTransport options to configure communication between Traefik and the servers.
 type ServersTransport struct {
 	ServerName          string              `description:"ServerName used to contact the server" json:"serverName,omitempty" toml:"serverName,omitempty" yaml:"serverName,omitempty"`
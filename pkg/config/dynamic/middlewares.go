@@ -32,9 +32,14 @@ type Middleware struct {
 	DigestAuth        *DigestAuth        `json:"digestAuth,omitempty" toml:"digestAuth,omitempty" yaml:"digestAuth,omitempty" export:"true"`
 	ForwardAuth       *ForwardAuth       `json:"forwardAuth,omitempty" toml:"forwardAuth,omitempty" yaml:"forwardAuth,omitempty" export:"true"`
 	InFlightReq       *InFlightReq       `json:"inFlightReq,omitempty" toml:"inFlightReq,omitempty" yaml:"inFlightReq,omitempty" export:"true"`
+	Limits            *Limits            `json:"limits,omitempty" toml:"limits,omitempty" yaml:"limits,omitempty" export:"true"`
+	JWT               *JWT               `json:"jwt,omitempty" toml:"jwt,omitempty" yaml:"jwt,omitempty" export:"true"`
+	OIDC              *OIDC              `json:"oidc,omitempty" toml:"oidc,omitempty" yaml:"oidc,omitempty" export:"true"`
+	ClientCertAuth    *ClientCertAuth    `json:"clientCertAuth,omitempty" toml:"clientCertAuth,omitempty" yaml:"clientCertAuth,omitempty" export:"true"`
 	Buffering         *Buffering         `json:"buffering,omitempty" toml:"buffering,omitempty" yaml:"buffering,omitempty" export:"true"`
 	CircuitBreaker    *CircuitBreaker    `json:"circuitBreaker,omitempty" toml:"circuitBreaker,omitempty" yaml:"circuitBreaker,omitempty" export:"true"`
 	Compress          *Compress          `json:"compress,omitempty" toml:"compress,omitempty" yaml:"compress,omitempty" label:"allowEmpty" file:"allowEmpty" export:"true"`
+	Cache             *Cache             `json:"cache,omitempty" toml:"cache,omitempty" yaml:"cache,omitempty" label:"allowEmpty" file:"allowEmpty" export:"true"`
 	PassTLSClientCert *PassTLSClientCert `json:"passTLSClientCert,omitempty" toml:"passTLSClientCert,omitempty" yaml:"passTLSClientCert,omitempty" export:"true"`
 	Retry             *Retry             `json:"retry,omitempty" toml:"retry,omitempty" yaml:"retry,omitempty" export:"true"`
 	ContentType       *ContentType       `json:"contentType,omitempty" toml:"contentType,omitempty" yaml:"contentType,omitempty" export:"true"`
@@ -88,6 +93,29 @@ type Buffering struct {
 
 // +k8s:deepcopy-gen=true
 
+// Cache holds the HTTP response cache configuration.
+type Cache struct {
+	// MaxEntries is the maximum number of responses kept in the cache,
+	// the least recently used ones being evicted first.
+	MaxEntries int `json:"maxEntries,omitempty" toml:"maxEntries,omitempty" yaml:"maxEntries,omitempty" export:"true"`
+	// MaxResponseBodyBytes is the size above which a response is not cached.
+	MaxResponseBodyBytes int64 `json:"maxResponseBodyBytes,omitempty" toml:"maxResponseBodyBytes,omitempty" yaml:"maxResponseBodyBytes,omitempty" export:"true"`
+	// DefaultTTL is the freshness lifetime of the responses without explicit expiration time.
+	// It defaults to 0, which means these responses are not cached.
+	DefaultTTL ptypes.Duration `json:"defaultTTL,omitempty" toml:"defaultTTL,omitempty" yaml:"defaultTTL,omitempty" export:"true"`
+	// DiskPath is the directory in which the responses are stored.
+	// By default, they are kept in memory.
+	DiskPath string `json:"diskPath,omitempty" toml:"diskPath,omitempty" yaml:"diskPath,omitempty" export:"true"`
+}
+
+// SetDefaults sets the default values on a Cache.
+func (c *Cache) SetDefaults() {
+	c.MaxEntries = 1000
+	c.MaxResponseBodyBytes = 1024 * 1024
+}
+
+// +k8s:deepcopy-gen=true
+
 // Chain holds a chain of middlewares.
 type Chain struct {
 	Middlewares []string `json:"middlewares,omitempty" toml:"middlewares,omitempty" yaml:"middlewares,omitempty" export:"true"`
@@ -105,6 +133,10 @@ type CircuitBreaker struct {
 // Compress holds the compress configuration.
 type Compress struct {
 	ExcludedContentTypes []string `json:"excludedContentTypes,omitempty" toml:"excludedContentTypes,omitempty" yaml:"excludedContentTypes,omitempty" export:"true"`
+	MinResponseBodyBytes int      `json:"minResponseBodyBytes,omitempty" toml:"minResponseBodyBytes,omitempty" yaml:"minResponseBodyBytes,omitempty" export:"true"`
+	GzipLevel            int      `json:"gzipLevel,omitempty" toml:"gzipLevel,omitempty" yaml:"gzipLevel,omitempty" export:"true"`
+	BrotliLevel          int      `json:"brotliLevel,omitempty" toml:"brotliLevel,omitempty" yaml:"brotliLevel,omitempty" export:"true"`
+	ZstdLevel            int      `json:"zstdLevel,omitempty" toml:"zstdLevel,omitempty" yaml:"zstdLevel,omitempty" export:"true"`
 }
 
 // +k8s:deepcopy-gen=true
@@ -125,6 +157,16 @@ type ErrorPage struct {
 	Status  []string `json:"status,omitempty" toml:"status,omitempty" yaml:"status,omitempty" export:"true"`
 	Service string   `json:"service,omitempty" toml:"service,omitempty" yaml:"service,omitempty" export:"true"`
 	Query   string   `json:"query,omitempty" toml:"query,omitempty" yaml:"query,omitempty" export:"true"`
+	// Directory is a local directory containing the error page templates, named after the status code (502.html),
+	// the status code class (5xx.json), or error.html and error.json for the other status codes.
+	Directory string `json:"directory,omitempty" toml:"directory,omitempty" yaml:"directory,omitempty" export:"true"`
+	// HTMLTemplate is an inline template of the HTML error pages.
+	HTMLTemplate string `json:"htmlTemplate,omitempty" toml:"htmlTemplate,omitempty" yaml:"htmlTemplate,omitempty" export:"true"`
+	// JSONTemplate is an inline template of the JSON error pages.
+	JSONTemplate string `json:"jsonTemplate,omitempty" toml:"jsonTemplate,omitempty" yaml:"jsonTemplate,omitempty" export:"true"`
+	// TraefikErrorsOnly restricts the error pages to the errors generated by Traefik,
+	// e.g. the 502 responses when the servers cannot be reached, leaving the error responses of the servers untouched.
+	TraefikErrorsOnly bool `json:"traefikErrorsOnly,omitempty" toml:"traefikErrorsOnly,omitempty" yaml:"traefikErrorsOnly,omitempty" export:"true"`
 }
 
 // +k8s:deepcopy-gen=true
@@ -286,6 +328,104 @@ type InFlightReq struct {
 
 // +k8s:deepcopy-gen=true
 
+// JWT holds the JWT authentication configuration.
+type JWT struct {
+	// Keys are the PEM encoded public keys, or certificates, used to verify the RS, PS and ES signatures.
+	// Each key can be given as a file path or as the content of the file.
+	Keys []string `json:"keys,omitempty" toml:"keys,omitempty" yaml:"keys,omitempty"`
+	// Secret is the key used to verify the HS signatures.
+	Secret string `json:"secret,omitempty" toml:"secret,omitempty" yaml:"secret,omitempty"`
+	// JWKSURL is the URL of a JSON Web Key Set, from which the keys are periodically fetched.
+	JWKSURL string `json:"jwksURL,omitempty" toml:"jwksURL,omitempty" yaml:"jwksURL,omitempty"`
+	// JWKSRefreshInterval is the interval between two fetches of the JSON Web Key Set.
+	JWKSRefreshInterval ptypes.Duration `json:"jwksRefreshInterval,omitempty" toml:"jwksRefreshInterval,omitempty" yaml:"jwksRefreshInterval,omitempty" export:"true"`
+	// Issuer, when set, must be the value of the iss claim of the tokens.
+	Issuer string `json:"issuer,omitempty" toml:"issuer,omitempty" yaml:"issuer,omitempty" export:"true"`
+	// Audience, when set, must be one of the values of the aud claim of the tokens.
+	Audience string `json:"audience,omitempty" toml:"audience,omitempty" yaml:"audience,omitempty" export:"true"`
+	// ClaimsHeaders maps the names of the request headers to the names of the claims copied into them.
+	ClaimsHeaders map[string]string `json:"claimsHeaders,omitempty" toml:"claimsHeaders,omitempty" yaml:"claimsHeaders,omitempty" export:"true"`
+	// RemoveHeader removes the Authorization header from the request forwarded to the service.
+	RemoveHeader bool `json:"removeHeader,omitempty" toml:"removeHeader,omitempty" yaml:"removeHeader,omitempty" export:"true"`
+}
+
+// SetDefaults sets the default values on a JWT.
+func (j *JWT) SetDefaults() {
+	j.JWKSRefreshInterval = ptypes.Duration(15 * time.Minute)
+}
+
+// +k8s:deepcopy-gen=true
+
+// Limits holds the request size limits configuration.
+// A zero value means no limit.
+type Limits struct {
+	// MaxRequestBodyBytes is the maximum size of the request body.
+	// The requests with a larger body are rejected with a 413 status code, without buffering the body.
+	MaxRequestBodyBytes int64 `json:"maxRequestBodyBytes,omitempty" toml:"maxRequestBodyBytes,omitempty" yaml:"maxRequestBodyBytes,omitempty" export:"true"`
+	// MaxHeaderCount is the maximum number of request headers.
+	MaxHeaderCount int `json:"maxHeaderCount,omitempty" toml:"maxHeaderCount,omitempty" yaml:"maxHeaderCount,omitempty" export:"true"`
+	// MaxHeaderBytes is the maximum size of the request headers, i.e. of their names and values.
+	MaxHeaderBytes int `json:"maxHeaderBytes,omitempty" toml:"maxHeaderBytes,omitempty" yaml:"maxHeaderBytes,omitempty" export:"true"`
+	// MaxURILength is the maximum length of the request URI, i.e. of its path and query.
+	MaxURILength int `json:"maxURILength,omitempty" toml:"maxURILength,omitempty" yaml:"maxURILength,omitempty" export:"true"`
+}
+
+// +k8s:deepcopy-gen=true
+
+// OIDC holds the OpenID Connect authentication configuration.
+type OIDC struct {
+	// Issuer is the URL of the OpenID Provider, from which its configuration is discovered.
+	Issuer       string   `json:"issuer,omitempty" toml:"issuer,omitempty" yaml:"issuer,omitempty" export:"true"`
+	ClientID     string   `json:"clientID,omitempty" toml:"clientID,omitempty" yaml:"clientID,omitempty"`
+	ClientSecret string   `json:"clientSecret,omitempty" toml:"clientSecret,omitempty" yaml:"clientSecret,omitempty"`
+	Scopes       []string `json:"scopes,omitempty" toml:"scopes,omitempty" yaml:"scopes,omitempty" export:"true"`
+	// CallbackPath is the path of the redirection URI, to which the OpenID Provider sends back the users.
+	CallbackPath string `json:"callbackPath,omitempty" toml:"callbackPath,omitempty" yaml:"callbackPath,omitempty" export:"true"`
+	// LogoutPath is the path on which the session is closed.
+	LogoutPath string `json:"logoutPath,omitempty" toml:"logoutPath,omitempty" yaml:"logoutPath,omitempty" export:"true"`
+	// PostLogoutRedirectURL is the URL to which the users are redirected after the logout.
+	PostLogoutRedirectURL string `json:"postLogoutRedirectURL,omitempty" toml:"postLogoutRedirectURL,omitempty" yaml:"postLogoutRedirectURL,omitempty" export:"true"`
+	// SessionSecret is the secret from which the key encrypting the session cookie is derived.
+	SessionSecret string `json:"sessionSecret,omitempty" toml:"sessionSecret,omitempty" yaml:"sessionSecret,omitempty"`
+	// SessionCookieName is the name of the session cookie.
+	SessionCookieName string `json:"sessionCookieName,omitempty" toml:"sessionCookieName,omitempty" yaml:"sessionCookieName,omitempty" export:"true"`
+	// SessionMaxAge is the maximum duration of a session, after which the users must log in again.
+	SessionMaxAge ptypes.Duration `json:"sessionMaxAge,omitempty" toml:"sessionMaxAge,omitempty" yaml:"sessionMaxAge,omitempty" export:"true"`
+	// ClaimsHeaders maps the names of the request headers to the names of the ID token claims copied into them.
+	ClaimsHeaders map[string]string `json:"claimsHeaders,omitempty" toml:"claimsHeaders,omitempty" yaml:"claimsHeaders,omitempty" export:"true"`
+	// ForwardAccessToken sends the access token to the service in the Authorization header.
+	ForwardAccessToken bool `json:"forwardAccessToken,omitempty" toml:"forwardAccessToken,omitempty" yaml:"forwardAccessToken,omitempty" export:"true"`
+}
+
+// SetDefaults sets the default values on an OIDC.
+func (o *OIDC) SetDefaults() {
+	o.Scopes = []string{"openid", "profile", "email"}
+	o.CallbackPath = "/oauth2/callback"
+	o.LogoutPath = "/oauth2/logout"
+	o.SessionCookieName = "_traefik_oidc"
+	o.SessionMaxAge = ptypes.Duration(24 * time.Hour)
+}
+
+// +k8s:deepcopy-gen=true
+
+// ClientCertAuth holds the client certificate authorization configuration.
+// A request is authorized when its client certificate matches at least one entry of the allow lists.
+type ClientCertAuth struct {
+	// AllowedCommonNames are the allowed common names (CN) of the certificate subject.
+	AllowedCommonNames []string `json:"allowedCommonNames,omitempty" toml:"allowedCommonNames,omitempty" yaml:"allowedCommonNames,omitempty"`
+	// AllowedOrganizations are the allowed organizations (O) of the certificate subject.
+	AllowedOrganizations []string `json:"allowedOrganizations,omitempty" toml:"allowedOrganizations,omitempty" yaml:"allowedOrganizations,omitempty"`
+	// AllowedDNSNames are the allowed DNS names of the certificate subject alternative names.
+	AllowedDNSNames []string `json:"allowedDNSNames,omitempty" toml:"allowedDNSNames,omitempty" yaml:"allowedDNSNames,omitempty"`
+	// AllowedURIs are the allowed URIs of the certificate subject alternative names, such as SPIFFE IDs.
+	AllowedURIs []string `json:"allowedURIs,omitempty" toml:"allowedURIs,omitempty" yaml:"allowedURIs,omitempty"`
+	// AllowedFingerprints are the allowed SHA-256 fingerprints of the certificate, hex encoded.
+	// They are the only entries matched on certificates not verified against the CA files of the TLS options.
+	AllowedFingerprints []string `json:"allowedFingerprints,omitempty" toml:"allowedFingerprints,omitempty" yaml:"allowedFingerprints,omitempty"`
+}
+
+// +k8s:deepcopy-gen=true
+
 // PassTLSClientCert holds the TLS client cert headers configuration.
 type PassTLSClientCert struct {
 	PEM  bool                      `json:"pem,omitempty" toml:"pem,omitempty" yaml:"pem,omitempty" export:"true"`
@@ -322,6 +462,10 @@ type RateLimit struct {
 	Burst int64 `json:"burst,omitempty" toml:"burst,omitempty" yaml:"burst,omitempty" export:"true"`
 
 	SourceCriterion *SourceCriterion `json:"sourceCriterion,omitempty" toml:"sourceCriterion,omitempty" yaml:"sourceCriterion,omitempty" export:"true"`
+
+	// Storage defines where the token buckets are kept.
+	// By default, they are kept in memory, so each Traefik instance applies the limit on its own.
+	Storage *RateLimitStorage `json:"storage,omitempty" toml:"storage,omitempty" yaml:"storage,omitempty" export:"true"`
 }
 
 // SetDefaults sets the default values on a RateLimit.
@@ -330,6 +474,46 @@ func (r *RateLimit) SetDefaults() {
 	r.Period = ptypes.Duration(time.Second)
 }
 
+// Rate limiter fallback behaviors, applied when the storage is unreachable.
+const (
+	RateLimitFallbackLocal = "local"
+	RateLimitFallbackAllow = "allow"
+	RateLimitFallbackDeny  = "deny"
+)
+
+// +k8s:deepcopy-gen=true
+
+// RateLimitStorage holds the configuration of a shared storage for the rate limiter token buckets.
+type RateLimitStorage struct {
+	Redis *RedisStorage `json:"redis,omitempty" toml:"redis,omitempty" yaml:"redis,omitempty" label:"allowEmpty" file:"allowEmpty" export:"true"`
+
+	// Fallback defines what happens to a request when the storage is unreachable:
+	// "local" applies the limit with in-memory token buckets, "allow" lets the request through,
+	// and "deny" rejects it. It defaults to "local".
+	Fallback string `json:"fallback,omitempty" toml:"fallback,omitempty" yaml:"fallback,omitempty" export:"true"`
+}
+
+// SetDefaults sets the default values on a RateLimitStorage.
+func (s *RateLimitStorage) SetDefaults() {
+	s.Fallback = RateLimitFallbackLocal
+}
+
+// +k8s:deepcopy-gen=true
+
+// RedisStorage holds the configuration of a Redis server.
+type RedisStorage struct {
+	Address  string          `json:"address,omitempty" toml:"address,omitempty" yaml:"address,omitempty"`
+	Password string          `json:"password,omitempty" toml:"password,omitempty" yaml:"password,omitempty"`
+	DB       int             `json:"db,omitempty" toml:"db,omitempty" yaml:"db,omitempty" export:"true"`
+	Timeout  ptypes.Duration `json:"timeout,omitempty" toml:"timeout,omitempty" yaml:"timeout,omitempty" export:"true"`
+}
+
+// SetDefaults sets the default values on a RedisStorage.
+func (r *RedisStorage) SetDefaults() {
+	r.Address = "127.0.0.1:6379"
+	r.Timeout = ptypes.Duration(100 * time.Millisecond)
+}
+
 // +k8s:deepcopy-gen=true
 
 // RedirectRegex holds the redirection configuration.
@@ -369,6 +553,17 @@ type ReplacePathRegex struct {
 type Retry struct {
 	Attempts        int             `json:"attempts,omitempty" toml:"attempts,omitempty" yaml:"attempts,omitempty" export:"true"`
 	InitialInterval ptypes.Duration `json:"initialInterval,omitempty" toml:"initialInterval,omitempty" yaml:"initialInterval,omitempty" export:"true"`
+	// MaxInterval makes the interval double at each attempt, up to MaxInterval.
+	// It also caps the wait requested by the Retry-After response header.
+	MaxInterval ptypes.Duration `json:"maxInterval,omitempty" toml:"maxInterval,omitempty" yaml:"maxInterval,omitempty" export:"true"`
+	// Status defines the response status codes, or ranges of status codes, for which the request is retried.
+	Status []string `json:"status,omitempty" toml:"status,omitempty" yaml:"status,omitempty" export:"true"`
+	// RetryNonIdempotent allows to retry the requests with a non-idempotent method, such as POST, on the Status codes.
+	RetryNonIdempotent bool `json:"retryNonIdempotent,omitempty" toml:"retryNonIdempotent,omitempty" yaml:"retryNonIdempotent,omitempty" export:"true"`
+	// PerTryTimeout is the maximum duration to wait for the response headers of an attempt.
+	PerTryTimeout ptypes.Duration `json:"perTryTimeout,omitempty" toml:"perTryTimeout,omitempty" yaml:"perTryTimeout,omitempty" export:"true"`
+	// BudgetPercent caps the retries to a percentage of the requests handled by the middleware.
+	BudgetPercent int `json:"budgetPercent,omitempty" toml:"budgetPercent,omitempty" yaml:"budgetPercent,omitempty" export:"true"`
 }
 
 // +k8s:deepcopy-gen=true
@@ -134,6 +134,9 @@ func TestDecodeConfiguration(t *testing.T) {
 		"traefik.http.routers.Router0.rule":                                                        "foobar",
 		"traefik.http.routers.Router0.tls":                                                         "true",
 		"traefik.http.routers.Router0.service":                                                     "foobar",
+		"traefik.http.routers.Router1.accesslog.disabled":                                          "true",
+		"traefik.http.routers.Router1.accesslog.sampling.keeperrors":                               "true",
+		"traefik.http.routers.Router1.accesslog.sampling.rate":                                     "0.42",
 		"traefik.http.routers.Router1.entrypoints":                                                 "foobar, fiibar",
 		"traefik.http.routers.Router1.middlewares":                                                 "foobar, fiibar",
 		"traefik.http.routers.Router1.priority":                                                    "42",
@@ -317,6 +320,13 @@ func TestDecodeConfiguration(t *testing.T) {
 					Service:  "foobar",
 					Rule:     "foobar",
 					Priority: 42,
+					AccessLog: &dynamic.RouterAccessLog{
+						Disabled: true,
+						Sampling: &dynamic.AccessLogSampling{
+							Rate:       0.42,
+							KeepErrors: true,
+						},
+					},
 				},
 			},
 			Middlewares: map[string]*dynamic.Middleware{
@@ -787,6 +797,13 @@ func TestEncodeConfiguration(t *testing.T) {
 					Service:  "foobar",
 					Rule:     "foobar",
 					Priority: 42,
+					AccessLog: &dynamic.RouterAccessLog{
+						Disabled: true,
+						Sampling: &dynamic.AccessLogSampling{
+							Rate:       0.42,
+							KeepErrors: true,
+						},
+					},
 				},
 			},
 			Middlewares: map[string]*dynamic.Middleware{
@@ -1157,6 +1174,7 @@ func TestEncodeConfiguration(t *testing.T) {
 		"traefik.HTTP.Middlewares.Middleware6.Errors.Query":                                        "foobar",
 		"traefik.HTTP.Middlewares.Middleware6.Errors.Service":                                      "foobar",
 		"traefik.HTTP.Middlewares.Middleware6.Errors.Status":                                       "foobar, fiibar",
+		"traefik.HTTP.Middlewares.Middleware6.Errors.TraefikErrorsOnly":                            "false",
 		"traefik.HTTP.Middlewares.Middleware7.ForwardAuth.Address":                                 "foobar",
 		"traefik.HTTP.Middlewares.Middleware7.ForwardAuth.AuthResponseHeaders":                     "foobar, fiibar",
 		"traefik.HTTP.Middlewares.Middleware7.ForwardAuth.AuthRequestHeaders":                      "foobar, fiibar",
@@ -1246,24 +1264,34 @@ func TestEncodeConfiguration(t *testing.T) {
 		"traefik.HTTP.Middlewares.Middleware15.ReplacePathRegex.Replacement":                       "foobar",
 		"traefik.HTTP.Middlewares.Middleware16.Retry.Attempts":                                     "42",
 		"traefik.HTTP.Middlewares.Middleware16.Retry.InitialInterval":                              "1000000000",
+		"traefik.HTTP.Middlewares.Middleware16.Retry.MaxInterval":                                  "0",
+		"traefik.HTTP.Middlewares.Middleware16.Retry.RetryNonIdempotent":                           "false",
+		"traefik.HTTP.Middlewares.Middleware16.Retry.PerTryTimeout":                                "0",
+		"traefik.HTTP.Middlewares.Middleware16.Retry.BudgetPercent":                                "0",
 		"traefik.HTTP.Middlewares.Middleware17.StripPrefix.Prefixes":                               "foobar, fiibar",
 		"traefik.HTTP.Middlewares.Middleware17.StripPrefix.ForceSlash":                             "true",
 		"traefik.HTTP.Middlewares.Middleware18.StripPrefixRegex.Regex":                             "foobar, fiibar",
-		"traefik.HTTP.Middlewares.Middleware19.Compress":                                           "true",
+		"traefik.HTTP.Middlewares.Middleware19.Compress.BrotliLevel":                               "0",
+		"traefik.HTTP.Middlewares.Middleware19.Compress.GzipLevel":                                 "0",
+		"traefik.HTTP.Middlewares.Middleware19.Compress.MinResponseBodyBytes":                      "0",
+		"traefik.HTTP.Middlewares.Middleware19.Compress.ZstdLevel":                                 "0",
 		"traefik.HTTP.Middlewares.Middleware20.Plugin.tomato.aaa":                                  "foo1",
 		"traefik.HTTP.Middlewares.Middleware20.Plugin.tomato.bbb":                                  "foo2",
 
-		"traefik.HTTP.Routers.Router0.EntryPoints": "foobar, fiibar",
-		"traefik.HTTP.Routers.Router0.Middlewares": "foobar, fiibar",
-		"traefik.HTTP.Routers.Router0.Priority":    "42",
-		"traefik.HTTP.Routers.Router0.Rule":        "foobar",
-		"traefik.HTTP.Routers.Router0.Service":     "foobar",
-		"traefik.HTTP.Routers.Router0.TLS":         "true",
-		"traefik.HTTP.Routers.Router1.EntryPoints": "foobar, fiibar",
-		"traefik.HTTP.Routers.Router1.Middlewares": "foobar, fiibar",
-		"traefik.HTTP.Routers.Router1.Priority":    "42",
-		"traefik.HTTP.Routers.Router1.Rule":        "foobar",
-		"traefik.HTTP.Routers.Router1.Service":     "foobar",
+		"traefik.HTTP.Routers.Router0.EntryPoints":                   "foobar, fiibar",
+		"traefik.HTTP.Routers.Router0.Middlewares":                   "foobar, fiibar",
+		"traefik.HTTP.Routers.Router0.Priority":                      "42",
+		"traefik.HTTP.Routers.Router0.Rule":                          "foobar",
+		"traefik.HTTP.Routers.Router0.Service":                       "foobar",
+		"traefik.HTTP.Routers.Router0.TLS":                           "true",
+		"traefik.HTTP.Routers.Router1.AccessLog.Disabled":            "true",
+		"traefik.HTTP.Routers.Router1.AccessLog.Sampling.KeepErrors": "true",
+		"traefik.HTTP.Routers.Router1.AccessLog.Sampling.Rate":       "0.420000",
+		"traefik.HTTP.Routers.Router1.EntryPoints":                   "foobar, fiibar",
+		"traefik.HTTP.Routers.Router1.Middlewares":                   "foobar, fiibar",
+		"traefik.HTTP.Routers.Router1.Priority":                      "42",
+		"traefik.HTTP.Routers.Router1.Rule":                          "foobar",
+		"traefik.HTTP.Routers.Router1.Service":                       "foobar",
 
 		"traefik.HTTP.Services.Service0.LoadBalancer.HealthCheck.Headers.name1":        "foobar",
 		"traefik.HTTP.Services.Service0.LoadBalancer.HealthCheck.Hostname":             "foobar",
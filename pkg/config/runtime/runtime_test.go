@@ -661,6 +661,77 @@ func TestPopulateUsedBy(t *testing.T) {
 				},
 			},
 		},
+		{
+			desc: "TCP, middlewares used by two routers",
+			conf: &runtime.Configuration{
+				TCPServices: map[string]*runtime.TCPServiceInfo{
+					"foo-service@myprovider": {
+						TCPService: &dynamic.TCPService{
+							LoadBalancer: &dynamic.TCPServersLoadBalancer{
+								Servers: []dynamic.TCPServer{
+									{
+										Address: "127.0.0.1:8085",
+									},
+								},
+							},
+						},
+					},
+				},
+				TCPMiddlewares: map[string]*runtime.TCPMiddlewareInfo{
+					"ipwhitelist@myprovider": {
+						TCPMiddleware: &dynamic.TCPMiddleware{
+							IPWhiteList: &dynamic.TCPIPWhiteList{
+								SourceRange: []string{"10.0.0.0/8"},
+							},
+						},
+					},
+					"inflightconn@anotherprovider": {
+						TCPMiddleware: &dynamic.TCPMiddleware{
+							InFlightConn: &dynamic.TCPInFlightConn{
+								Amount: 10,
+							},
+						},
+					},
+				},
+				TCPRouters: map[string]*runtime.TCPRouterInfo{
+					"foo@myprovider": {
+						TCPRouter: &dynamic.TCPRouter{
+							EntryPoints: []string{"web"},
+							Middlewares: []string{"ipwhitelist"},
+							Service:     "foo-service@myprovider",
+							Rule:        "HostSNI(`*`)",
+						},
+					},
+					"bar@myprovider": {
+						TCPRouter: &dynamic.TCPRouter{
+							EntryPoints: []string{"web"},
+							Middlewares: []string{"ipwhitelist", "inflightconn@anotherprovider"},
+							Service:     "foo-service@myprovider",
+							Rule:        "HostSNI(`*`)",
+						},
+					},
+				},
+			},
+			expected: runtime.Configuration{
+				TCPRouters: map[string]*runtime.TCPRouterInfo{
+					"bar@myprovider": {},
+					"foo@myprovider": {},
+				},
+				TCPServices: map[string]*runtime.TCPServiceInfo{
+					"foo-service@myprovider": {
+						UsedBy: []string{"bar@myprovider", "foo@myprovider"},
+					},
+				},
+				TCPMiddlewares: map[string]*runtime.TCPMiddlewareInfo{
+					"ipwhitelist@myprovider": {
+						UsedBy: []string{"bar@myprovider", "foo@myprovider"},
+					},
+					"inflightconn@anotherprovider": {
+						UsedBy: []string{"bar@myprovider"},
+					},
+				},
+			},
+		},
 	}
 	for _, test := range testCases {
 		test := test
@@ -685,6 +756,11 @@ func TestPopulateUsedBy(t *testing.T) {
 				require.NotNil(t, runtimeConf.TCPServices[key])
 				assert.Equal(t, expectedTCPService.UsedBy, runtimeConf.TCPServices[key].UsedBy)
 			}
+
+			for key, expectedTCPMiddleware := range test.expected.TCPMiddlewares {
+				require.NotNil(t, runtimeConf.TCPMiddlewares[key])
+				assert.Equal(t, expectedTCPMiddleware.UsedBy, runtimeConf.TCPMiddlewares[key].UsedBy)
+			}
 		})
 	}
 }
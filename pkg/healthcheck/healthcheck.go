@@ -35,6 +35,18 @@ type Balancer interface {
 	UpsertServer(u *url.URL, options ...roundrobin.ServerOption) error
 }
 
+// ejectingBalancer is a Balancer which keeps track of the servers ejected by the passive health check,
+// so that they are only reinstated if the active health check has not disabled them meanwhile.
+type ejectingBalancer interface {
+	EjectServer(u *url.URL) error
+	ReinstateServer(u *url.URL, options ...roundrobin.ServerOption) (bool, error)
+}
+
+// weightedBalancer is a Balancer which knows the weight of its servers.
+type weightedBalancer interface {
+	ServerWeight(u *url.URL) (int, bool)
+}
+
 // BalancerHandler includes functionality for load-balancing management.
 type BalancerHandler interface {
 	ServeHTTP(w http.ResponseWriter, req *http.Request)
@@ -106,9 +118,11 @@ func (b *BackendConfig) addHeadersAndHost(req *http.Request) *http.Request {
 
 // HealthCheck struct.
 type HealthCheck struct {
-	Backends map[string]*BackendConfig
-	metrics  metricsHealthcheck
-	cancel   context.CancelFunc
+	Backends    map[string]*BackendConfig
+	TCPBackends map[string]*TCPBackendConfig
+	metrics     metricsHealthcheck
+	cancel      context.CancelFunc
+	tcpCancel   context.CancelFunc
 }
 
 // SetBackendsConfiguration set backends configuration.
@@ -180,10 +194,10 @@ func (hc *HealthCheck) checkBackend(ctx context.Context, backend *BackendConfig)
 
 		if err := checkHealth(enableURL, backend); err != nil {
 			weight := 1
-			rr, ok := backend.LB.(*roundrobin.RoundRobin)
+			wb, ok := backend.LB.(weightedBalancer)
 			if ok {
 				var gotWeight bool
-				weight, gotWeight = rr.ServerWeight(enableURL)
+				weight, gotWeight = wb.ServerWeight(enableURL)
 				if !gotWeight {
 					weight = 1
 				}
@@ -214,7 +228,8 @@ func GetHealthCheck(registry metrics.Registry) *HealthCheck {
 
 func newHealthCheck(registry metrics.Registry) *HealthCheck {
 	return &HealthCheck{
-		Backends: make(map[string]*BackendConfig),
+		Backends:    make(map[string]*BackendConfig),
+		TCPBackends: make(map[string]*TCPBackendConfig),
 		metrics: metricsHealthcheck{
 			serverUpGauge: registry.ServiceServerUpGauge(),
 		},
@@ -269,6 +284,7 @@ func NewLBStatusUpdater(bh BalancerHandler, info *runtime.ServiceInfo) *LbStatus
 	return &LbStatusUpdater{
 		BalancerHandler: bh,
 		serviceInfo:     info,
+		ejected:         make(map[string]int),
 	}
 }
 
@@ -277,26 +293,118 @@ func NewLBStatusUpdater(bh BalancerHandler, info *runtime.ServiceInfo) *LbStatus
 type LbStatusUpdater struct {
 	BalancerHandler
 	serviceInfo *runtime.ServiceInfo // can be nil
+
+	mu sync.Mutex
+	// ejected holds the weights of the servers removed by the passive health check, which it can reinstate.
+	ejected map[string]int
 }
 
 // RemoveServer removes the given server from the BalancerHandler,
 // and updates the status of the server to "DOWN".
+// A server ejected by the passive health check is already removed,
+// it is then only marked as "DOWN", and is not reinstated by the passive health check anymore.
 func (lb *LbStatusUpdater) RemoveServer(u *url.URL) error {
+	lb.mu.Lock()
+	defer lb.mu.Unlock()
+
+	_, ejected := lb.ejected[u.String()]
+	delete(lb.ejected, u.String())
+
 	err := lb.BalancerHandler.RemoveServer(u)
-	if err == nil && lb.serviceInfo != nil {
-		lb.serviceInfo.UpdateServerStatus(u.String(), serverDown)
+	if err != nil && !ejected {
+		return err
 	}
-	return err
+
+	lb.updateStatus(u, serverDown)
+	return nil
 }
 
 // UpsertServer adds the given server to the BalancerHandler,
 // and updates the status of the server to "UP".
 func (lb *LbStatusUpdater) UpsertServer(u *url.URL, options ...roundrobin.ServerOption) error {
-	err := lb.BalancerHandler.UpsertServer(u, options...)
-	if err == nil && lb.serviceInfo != nil {
-		lb.serviceInfo.UpdateServerStatus(u.String(), serverUp)
+	lb.mu.Lock()
+	defer lb.mu.Unlock()
+
+	delete(lb.ejected, u.String())
+
+	return lb.upsertServer(u, options...)
+}
+
+// EjectServer removes the given server from the BalancerHandler on behalf of the passive health check,
+// and updates the status of the server to "EJECTED".
+func (lb *LbStatusUpdater) EjectServer(u *url.URL) error {
+	lb.mu.Lock()
+	defer lb.mu.Unlock()
+
+	weight, ok := lb.serverWeight(u)
+	if !ok {
+		weight = 1
+	}
+
+	if err := lb.BalancerHandler.RemoveServer(u); err != nil {
+		return err
+	}
+
+	lb.ejected[u.String()] = weight
+	lb.updateStatus(u, serverEjected)
+	return nil
+}
+
+// ReinstateServer adds back the given server ejected by the passive health check,
+// unless it has been removed by the active health check, or upserted, meanwhile.
+// It reports whether the server has been reinstated.
+func (lb *LbStatusUpdater) ReinstateServer(u *url.URL, options ...roundrobin.ServerOption) (bool, error) {
+	lb.mu.Lock()
+	defer lb.mu.Unlock()
+
+	if _, ok := lb.ejected[u.String()]; !ok {
+		return false, nil
+	}
+	delete(lb.ejected, u.String())
+
+	if err := lb.upsertServer(u, options...); err != nil {
+		return false, err
 	}
-	return err
+	return true, nil
+}
+
+// upsertServer must be called under the LbStatusUpdater lock.
+func (lb *LbStatusUpdater) upsertServer(u *url.URL, options ...roundrobin.ServerOption) error {
+	if err := lb.BalancerHandler.UpsertServer(u, options...); err != nil {
+		return err
+	}
+
+	lb.updateStatus(u, serverUp)
+	return nil
+}
+
+func (lb *LbStatusUpdater) updateStatus(u *url.URL, status string) {
+	if lb.serviceInfo != nil {
+		lb.serviceInfo.UpdateServerStatus(u.String(), status)
+	}
+}
+
+// ServerWeight returns the weight of the given server,
+// if the wrapped BalancerHandler knows it, or if the server is ejected.
+func (lb *LbStatusUpdater) ServerWeight(u *url.URL) (int, bool) {
+	lb.mu.Lock()
+	defer lb.mu.Unlock()
+
+	if weight, ok := lb.serverWeight(u); ok {
+		return weight, true
+	}
+
+	weight, ok := lb.ejected[u.String()]
+	return weight, ok
+}
+
+// serverWeight must be called under the LbStatusUpdater lock.
+func (lb *LbStatusUpdater) serverWeight(u *url.URL) (int, bool) {
+	wb, ok := lb.BalancerHandler.(weightedBalancer)
+	if !ok {
+		return 0, false
+	}
+	return wb.ServerWeight(u)
 }
 
 // Balancers is a list of Balancers(s) that implements the Balancer interface.
@@ -333,3 +441,18 @@ func (b Balancers) UpsertServer(u *url.URL, options ...roundrobin.ServerOption)
 	}
 	return nil
 }
+
+// ServerWeight returns the weight of the given server in the first BalancerHandler that knows it.
+func (b Balancers) ServerWeight(u *url.URL) (int, bool) {
+	for _, lb := range b {
+		wb, ok := lb.(weightedBalancer)
+		if !ok {
+			continue
+		}
+
+		if weight, ok := wb.ServerWeight(u); ok {
+			return weight, true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,270 @@
+package healthcheck
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/traefik/traefik/v2/pkg/config/runtime"
+	"github.com/traefik/traefik/v2/pkg/log"
+	"github.com/traefik/traefik/v2/pkg/safe"
+	"github.com/traefik/traefik/v2/pkg/tcp"
+)
+
+// TCPBalancer is the set of operations required to manage the list of servers in a TCP load-balancer.
+type TCPBalancer interface {
+	Servers() []string
+	RemoveServer(address string) error
+	UpsertServer(address string) error
+}
+
+// TCPBalancerHandler includes functionality for TCP load-balancing management.
+type TCPBalancerHandler interface {
+	ServeTCP(conn tcp.WriteCloser)
+	TCPBalancer
+}
+
+// TCPOptions are the public TCP health check options.
+type TCPOptions struct {
+	Send     string
+	Expect   string
+	Interval time.Duration
+	Timeout  time.Duration
+	LB       TCPBalancer
+}
+
+func (opt TCPOptions) String() string {
+	return fmt.Sprintf("[Send: %q Expect: %q Interval: %s Timeout: %s]", opt.Send, opt.Expect, opt.Interval, opt.Timeout)
+}
+
+// TCPBackendConfig HealthCheck configuration for a TCP backend.
+type TCPBackendConfig struct {
+	TCPOptions
+	name              string
+	disabledAddresses []string
+}
+
+// NewTCPBackendConfig Instantiate a new TCPBackendConfig.
+func NewTCPBackendConfig(options TCPOptions, backendName string) *TCPBackendConfig {
+	return &TCPBackendConfig{
+		TCPOptions: options,
+		name:       backendName,
+	}
+}
+
+// SetTCPBackendsConfiguration set TCP backends configuration.
+func (hc *HealthCheck) SetTCPBackendsConfiguration(parentCtx context.Context, backends map[string]*TCPBackendConfig) {
+	hc.TCPBackends = backends
+	if hc.tcpCancel != nil {
+		hc.tcpCancel()
+	}
+	ctx, cancel := context.WithCancel(parentCtx)
+	hc.tcpCancel = cancel
+
+	for _, backend := range backends {
+		currentBackend := backend
+		safe.Go(func() {
+			hc.executeTCP(ctx, currentBackend)
+		})
+	}
+}
+
+func (hc *HealthCheck) executeTCP(ctx context.Context, backend *TCPBackendConfig) {
+	logger := log.FromContext(ctx)
+	logger.Debugf("Initial health check for TCP backend: %q", backend.name)
+
+	hc.checkTCPBackend(ctx, backend)
+	ticker := time.NewTicker(backend.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			logger.Debugf("Stopping current health check goroutines of TCP backend: %s", backend.name)
+			return
+		case <-ticker.C:
+			logger.Debugf("Refreshing health check for TCP backend: %s", backend.name)
+			hc.checkTCPBackend(ctx, backend)
+		}
+	}
+}
+
+func (hc *HealthCheck) checkTCPBackend(ctx context.Context, backend *TCPBackendConfig) {
+	logger := log.FromContext(ctx)
+
+	enabledAddresses := backend.LB.Servers()
+
+	// The addresses are checked concurrently,
+	// so that a pass lasts at most one timeout whatever the number of unresponsive servers.
+	addresses := append(append([]string{}, backend.disabledAddresses...), enabledAddresses...)
+	errs := checkTCPAddresses(addresses, backend)
+	disabledErrs, enabledErrs := errs[:len(backend.disabledAddresses)], errs[len(backend.disabledAddresses):]
+
+	var newDisabledAddresses []string
+	for i, disabledAddress := range backend.disabledAddresses {
+		serverUpMetricValue := float64(0)
+
+		if err := disabledErrs[i]; err == nil {
+			logger.Warnf("Health check up: Returning to server list. TCP backend: %q Address: %q", backend.name, disabledAddress)
+			if err = backend.LB.UpsertServer(disabledAddress); err != nil {
+				logger.Error(err)
+			}
+
+			serverUpMetricValue = 1
+		} else {
+			logger.Warnf("Health check still failing. TCP backend: %q Address: %q Reason: %s", backend.name, disabledAddress, err)
+			newDisabledAddresses = append(newDisabledAddresses, disabledAddress)
+		}
+
+		labelValues := []string{"service", backend.name, "url", disabledAddress}
+		hc.metrics.serverUpGauge.With(labelValues...).Set(serverUpMetricValue)
+	}
+
+	backend.disabledAddresses = newDisabledAddresses
+
+	for i, enabledAddress := range enabledAddresses {
+		serverUpMetricValue := float64(1)
+
+		if err := enabledErrs[i]; err != nil {
+			logger.Warnf("Health check failed, removing from server list. TCP backend: %q Address: %q Reason: %s", backend.name, enabledAddress, err)
+			if err := backend.LB.RemoveServer(enabledAddress); err != nil {
+				logger.Error(err)
+			}
+
+			backend.disabledAddresses = append(backend.disabledAddresses, enabledAddress)
+			serverUpMetricValue = 0
+		}
+
+		labelValues := []string{"service", backend.name, "url", enabledAddress}
+		hc.metrics.serverUpGauge.With(labelValues...).Set(serverUpMetricValue)
+	}
+}
+
+// checkTCPAddresses checks the health of the addresses concurrently,
+// and returns the result of checkTCPHealth for each of them, in the same order.
+func checkTCPAddresses(addresses []string, backend *TCPBackendConfig) []error {
+	errs := make([]error, len(addresses))
+
+	var wg sync.WaitGroup
+	for i, address := range addresses {
+		wg.Add(1)
+		go func(i int, address string) {
+			defer wg.Done()
+			errs[i] = checkTCPHealth(address, backend)
+		}(i, address)
+	}
+	wg.Wait()
+
+	return errs
+}
+
+// checkTCPHealth returns a nil error in case it was successful and otherwise
+// a non-nil error with a meaningful description why the health check failed.
+func checkTCPHealth(address string, backend *TCPBackendConfig) error {
+	conn, err := net.DialTimeout("tcp", address, backend.Timeout)
+	if err != nil {
+		return fmt.Errorf("TCP dial failed: %w", err)
+	}
+
+	defer conn.Close()
+
+	if backend.Send == "" && backend.Expect == "" {
+		return nil
+	}
+
+	if err = conn.SetDeadline(time.Now().Add(backend.Timeout)); err != nil {
+		return fmt.Errorf("failed to set deadline: %w", err)
+	}
+
+	if backend.Send != "" {
+		if _, err = conn.Write([]byte(backend.Send)); err != nil {
+			return fmt.Errorf("failed to send payload: %w", err)
+		}
+	}
+
+	if backend.Expect != "" {
+		received := make([]byte, len(backend.Expect))
+		if _, err = io.ReadFull(conn, received); err != nil {
+			return fmt.Errorf("failed to read response: %w", err)
+		}
+
+		if !bytes.Equal(received, []byte(backend.Expect)) {
+			return fmt.Errorf("received unexpected response: %q", received)
+		}
+	}
+
+	return nil
+}
+
+// NewTCPLBStatusUpdater returns a new TCPLbStatusUpdater.
+func NewTCPLBStatusUpdater(bh TCPBalancerHandler, info *runtime.TCPServiceInfo) *TCPLbStatusUpdater {
+	return &TCPLbStatusUpdater{
+		TCPBalancerHandler: bh,
+		serviceInfo:        info,
+	}
+}
+
+// TCPLbStatusUpdater wraps a TCPBalancerHandler and a TCPServiceInfo,
+// so it can keep track of the status of a server in the TCPServiceInfo.
+type TCPLbStatusUpdater struct {
+	TCPBalancerHandler
+	serviceInfo *runtime.TCPServiceInfo // can be nil
+}
+
+// RemoveServer removes the given server from the TCPBalancerHandler,
+// and updates the status of the server to "DOWN".
+func (lb *TCPLbStatusUpdater) RemoveServer(address string) error {
+	err := lb.TCPBalancerHandler.RemoveServer(address)
+	if err == nil && lb.serviceInfo != nil {
+		lb.serviceInfo.UpdateServerStatus(address, serverDown)
+	}
+	return err
+}
+
+// UpsertServer adds the given server to the TCPBalancerHandler,
+// and updates the status of the server to "UP".
+func (lb *TCPLbStatusUpdater) UpsertServer(address string) error {
+	err := lb.TCPBalancerHandler.UpsertServer(address)
+	if err == nil && lb.serviceInfo != nil {
+		lb.serviceInfo.UpdateServerStatus(address, serverUp)
+	}
+	return err
+}
+
+// TCPBalancers is a list of TCPBalancer(s) that implements the TCPBalancer interface.
+type TCPBalancers []TCPBalancer
+
+// Servers returns the servers addresses from all the TCPBalancer.
+func (b TCPBalancers) Servers() []string {
+	var servers []string
+	for _, lb := range b {
+		servers = append(servers, lb.Servers()...)
+	}
+
+	return servers
+}
+
+// RemoveServer removes the given server from all the TCPBalancer,
+// and updates the status of the server to "DOWN".
+func (b TCPBalancers) RemoveServer(address string) error {
+	for _, lb := range b {
+		if err := lb.RemoveServer(address); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// UpsertServer adds the given server to all the TCPBalancer,
+// and updates the status of the server to "UP".
+func (b TCPBalancers) UpsertServer(address string) error {
+	for _, lb := range b {
+		if err := lb.UpsertServer(address); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,323 @@
+package healthcheck
+
+import (
+	"context"
+	"io"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traefik/traefik/v2/pkg/config/runtime"
+	"github.com/traefik/traefik/v2/pkg/tcp"
+	"github.com/traefik/traefik/v2/pkg/testhelpers"
+)
+
+func TestCheckTCPHealth(t *testing.T) {
+	testCases := []struct {
+		desc        string
+		response    string
+		send        string
+		expect      string
+		closed      bool
+		expectedErr bool
+	}{
+		{
+			desc: "connection only",
+		},
+		{
+			desc:     "send and expected response",
+			response: "PONG",
+			send:     "PING",
+			expect:   "PONG",
+		},
+		{
+			desc:     "expected response prefix",
+			response: "+OK ready",
+			expect:   "+OK",
+		},
+		{
+			desc:        "unexpected response",
+			response:    "-ERR",
+			send:        "PING",
+			expect:      "PONG",
+			expectedErr: true,
+		},
+		{
+			desc:        "no response",
+			send:        "PING",
+			expect:      "PONG",
+			expectedErr: true,
+		},
+		{
+			desc:        "server not listening",
+			closed:      true,
+			expectedErr: true,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			address := newTCPTestServer(t, test.send, test.response)
+			if test.closed {
+				address = closedAddress(t)
+			}
+
+			backend := NewTCPBackendConfig(TCPOptions{
+				Send:     test.send,
+				Expect:   test.expect,
+				Interval: healthCheckInterval,
+				Timeout:  healthCheckTimeout,
+			}, "backendName")
+
+			err := checkTCPHealth(address, backend)
+			if test.expectedErr {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestSetTCPBackendsConfiguration(t *testing.T) {
+	testCases := []struct {
+		desc                       string
+		startHealthy               bool
+		serverUp                   bool
+		expectedNumRemovedServers  int
+		expectedNumUpsertedServers int
+		expectedGaugeValue         float64
+	}{
+		{
+			desc:               "healthy server staying healthy",
+			startHealthy:       true,
+			serverUp:           true,
+			expectedGaugeValue: 1,
+		},
+		{
+			desc:                      "healthy server becoming sick",
+			startHealthy:              true,
+			expectedNumRemovedServers: 1,
+			expectedGaugeValue:        0,
+		},
+		{
+			desc:                       "sick server becoming healthy",
+			serverUp:                   true,
+			expectedNumUpsertedServers: 1,
+			expectedGaugeValue:         1,
+		},
+		{
+			desc:               "sick server staying sick",
+			expectedGaugeValue: 0,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			address := closedAddress(t)
+			if test.serverUp {
+				address = newTCPTestServer(t, "", "")
+			}
+
+			lb := &testTCPLoadBalancer{RWMutex: &sync.RWMutex{}}
+			backend := NewTCPBackendConfig(TCPOptions{
+				Interval: healthCheckInterval,
+				Timeout:  healthCheckTimeout,
+				LB:       lb,
+			}, "backendName")
+
+			if test.startHealthy {
+				lb.servers = append(lb.servers, address)
+			} else {
+				backend.disabledAddresses = append(backend.disabledAddresses, address)
+			}
+
+			collectingMetrics := &testhelpers.CollectingGauge{}
+			check := HealthCheck{
+				TCPBackends: make(map[string]*TCPBackendConfig),
+				metrics:     metricsHealthcheck{serverUpGauge: collectingMetrics},
+			}
+
+			check.checkTCPBackend(context.Background(), backend)
+
+			lb.Lock()
+			defer lb.Unlock()
+
+			assert.Equal(t, test.expectedNumRemovedServers, lb.numRemovedServers, "removed servers")
+			assert.Equal(t, test.expectedNumUpsertedServers, lb.numUpsertedServers, "upserted servers")
+			assert.Equal(t, test.expectedGaugeValue, collectingMetrics.GaugeValue, "ServerUp Gauge")
+		})
+	}
+}
+
+func TestCheckTCPBackend_concurrent(t *testing.T) {
+	lb := &testTCPLoadBalancer{RWMutex: &sync.RWMutex{}}
+	backend := NewTCPBackendConfig(TCPOptions{
+		Send:     "PING",
+		Expect:   "PONG",
+		Interval: healthCheckInterval,
+		Timeout:  healthCheckTimeout,
+		LB:       lb,
+	}, "backendName")
+
+	// The servers never answer, so each check lasts the whole timeout.
+	for i := 0; i < 10; i++ {
+		lb.servers = append(lb.servers, silentAddress(t))
+	}
+	healthyAddress := newTCPTestServer(t, "PING", "PONG")
+	lb.servers = append(lb.servers, healthyAddress)
+
+	check := HealthCheck{
+		TCPBackends: make(map[string]*TCPBackendConfig),
+		metrics:     metricsHealthcheck{serverUpGauge: &testhelpers.CollectingGauge{}},
+	}
+
+	start := time.Now()
+	check.checkTCPBackend(context.Background(), backend)
+
+	assert.Less(t, int64(time.Since(start)), int64(5*healthCheckTimeout))
+
+	lb.Lock()
+	defer lb.Unlock()
+
+	assert.Equal(t, 10, lb.numRemovedServers)
+	assert.Equal(t, []string{healthyAddress}, lb.servers)
+	assert.Len(t, backend.disabledAddresses, 10)
+}
+
+func TestTCPLBStatusUpdater(t *testing.T) {
+	lb := &testTCPLoadBalancer{RWMutex: &sync.RWMutex{}}
+	svInfo := &runtime.TCPServiceInfo{}
+	lbsu := NewTCPLBStatusUpdater(lb, svInfo)
+
+	err := lbsu.UpsertServer("127.0.0.1:8080")
+	require.NoError(t, err)
+	assert.Len(t, lbsu.Servers(), 1)
+	assert.Equal(t, map[string]string{"127.0.0.1:8080": serverUp}, svInfo.GetAllStatus())
+
+	err = lbsu.RemoveServer("127.0.0.1:8080")
+	require.NoError(t, err)
+	assert.Len(t, lbsu.Servers(), 0)
+	assert.Equal(t, map[string]string{"127.0.0.1:8080": serverDown}, svInfo.GetAllStatus())
+}
+
+type testTCPLoadBalancer struct {
+	// RWMutex needed due to parallel test execution: Both the system-under-test
+	// and the test assertions reference the counters.
+	*sync.RWMutex
+	numRemovedServers  int
+	numUpsertedServers int
+	servers            []string
+}
+
+func (lb *testTCPLoadBalancer) ServeTCP(conn tcp.WriteCloser) {
+	// noop
+}
+
+func (lb *testTCPLoadBalancer) RemoveServer(address string) error {
+	lb.Lock()
+	defer lb.Unlock()
+	lb.numRemovedServers++
+
+	for i, server := range lb.servers {
+		if server == address {
+			lb.servers = append(lb.servers[:i], lb.servers[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+func (lb *testTCPLoadBalancer) UpsertServer(address string) error {
+	lb.Lock()
+	defer lb.Unlock()
+	lb.numUpsertedServers++
+	lb.servers = append(lb.servers, address)
+	return nil
+}
+
+func (lb *testTCPLoadBalancer) Servers() []string {
+	lb.RLock()
+	defer lb.RUnlock()
+
+	return append([]string{}, lb.servers...)
+}
+
+// newTCPTestServer starts a TCP server which reads len(send) bytes,
+// then writes the given response on every accepted connection.
+func newTCPTestServer(t *testing.T, send, response string) string {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+
+			go func() {
+				defer conn.Close()
+
+				if _, err := io.ReadFull(conn, make([]byte, len(send))); err != nil {
+					return
+				}
+				_, _ = conn.Write([]byte(response))
+			}()
+		}
+	}()
+
+	return listener.Addr().String()
+}
+
+// silentAddress returns the address of a TCP server which accepts the connections but never answers.
+func silentAddress(t *testing.T) string {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = listener.Close() })
+
+	go func() {
+		var conns []net.Conn
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				// The listener is closed at the end of the test.
+				for _, c := range conns {
+					_ = c.Close()
+				}
+				return
+			}
+			conns = append(conns, conn)
+		}
+	}()
+
+	return listener.Addr().String()
+}
+
+// closedAddress returns the address of a TCP port nothing is listening on anymore.
+func closedAddress(t *testing.T) string {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	address := listener.Addr().String()
+	require.NoError(t, listener.Close())
+
+	return address
+}
@@ -471,6 +471,54 @@ func TestLBStatusUpdater(t *testing.T) {
 	}
 }
 
+func TestCheckBackend_keepsServerWeight(t *testing.T) {
+	var healthy bool
+	var mu sync.Mutex
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if !healthy {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer ts.Close()
+
+	rr, err := roundrobin.New(http.NotFoundHandler())
+	require.NoError(t, err)
+
+	lbsu := NewLBStatusUpdater(rr, nil)
+	serverURL := testhelpers.MustParseURL(ts.URL)
+	require.NoError(t, lbsu.UpsertServer(serverURL, roundrobin.Weight(3)))
+
+	backend := NewBackendConfig(Options{
+		Path:    "/path",
+		Timeout: healthCheckTimeout,
+		LB:      Balancers{lbsu},
+	}, "backendName")
+
+	check := HealthCheck{
+		Backends: make(map[string]*BackendConfig),
+		metrics:  metricsHealthcheck{serverUpGauge: &testhelpers.CollectingGauge{}},
+	}
+
+	check.checkBackend(context.Background(), backend)
+
+	assert.Empty(t, rr.Servers())
+	require.Len(t, backend.disabledURLs, 1)
+	assert.Equal(t, 3, backend.disabledURLs[0].weight)
+
+	mu.Lock()
+	healthy = true
+	mu.Unlock()
+
+	check.checkBackend(context.Background(), backend)
+
+	weight, ok := rr.ServerWeight(serverURL)
+	require.True(t, ok)
+	assert.Equal(t, 3, weight)
+}
+
 func TestNotFollowingRedirects(t *testing.T) {
 	redirectServerCalled := false
 	redirectTestServer := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
@@ -0,0 +1,268 @@
+package healthcheck
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/traefik/traefik/v2/pkg/config/runtime"
+	"github.com/traefik/traefik/v2/pkg/log"
+	"github.com/vulcand/oxy/roundrobin"
+)
+
+const serverEjected = "EJECTED"
+
+// PassiveOptions are the public passive health check options.
+type PassiveOptions struct {
+	MaxFailures     int
+	EjectionTime    time.Duration
+	MaxEjectionTime time.Duration
+}
+
+func (opt PassiveOptions) String() string {
+	return fmt.Sprintf("[MaxFailures: %d EjectionTime: %s MaxEjectionTime: %s]", opt.MaxFailures, opt.EjectionTime, opt.MaxEjectionTime)
+}
+
+type passiveServer struct {
+	// failures is the number of consecutive failed requests.
+	failures int
+	// ejections is the number of consecutive ejections, without a successful request in between.
+	ejections int
+	ejected   bool
+}
+
+// PassiveHealthCheck is a handler which observes the responses of the servers of a load-balancer,
+// and ejects the servers returning consecutive errors from the load-balancer for a backoff period.
+// It must be placed between the load-balancer and the forwarder,
+// so that the URL of the request is the URL of the selected server.
+type PassiveHealthCheck struct {
+	next        http.Handler
+	name        string
+	options     PassiveOptions
+	serviceInfo *runtime.ServiceInfo // can be nil
+
+	mu      sync.Mutex
+	lb      Balancer
+	servers map[string]*passiveServer
+}
+
+// NewPassiveHealthCheck creates a new PassiveHealthCheck for the backend named backendName.
+// The load-balancer whose servers are ejected must be set with SetBalancer.
+func NewPassiveHealthCheck(next http.Handler, options PassiveOptions, backendName string, info *runtime.ServiceInfo) *PassiveHealthCheck {
+	return &PassiveHealthCheck{
+		next:        next,
+		name:        backendName,
+		options:     options,
+		serviceInfo: info,
+		servers:     make(map[string]*passiveServer),
+	}
+}
+
+// SetBalancer sets the load-balancer from which the servers are ejected.
+func (p *PassiveHealthCheck) SetBalancer(lb Balancer) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.lb = lb
+}
+
+func (p *PassiveHealthCheck) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	serverURL := *req.URL
+
+	recorder := newStatusRecorder(rw)
+	p.next.ServeHTTP(recorder, req)
+
+	p.observe(req.Context(), &serverURL, !isServerFailure(recorder.getCode()))
+}
+
+// isServerFailure reports whether the status code denotes an unhealthy server.
+// Connection errors are reported by the forwarder with a 502 or a 504 status code,
+// while the other 5xx status codes are usually application errors, which ejecting the server would not fix.
+func isServerFailure(code int) bool {
+	switch code {
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	default:
+		return false
+	}
+}
+
+func (p *PassiveHealthCheck) observe(ctx context.Context, u *url.URL, success bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	srv, ok := p.servers[u.String()]
+	if !ok {
+		srv = &passiveServer{}
+		p.servers[u.String()] = srv
+	}
+
+	// Requests which were in flight when the server has been ejected are not taken into account.
+	if srv.ejected {
+		return
+	}
+
+	if success {
+		srv.failures = 0
+		srv.ejections = 0
+		return
+	}
+
+	srv.failures++
+	if srv.failures < p.options.MaxFailures || p.lb == nil {
+		return
+	}
+
+	p.eject(ctx, u, srv)
+}
+
+// eject must be called under the PassiveHealthCheck lock.
+func (p *PassiveHealthCheck) eject(ctx context.Context, u *url.URL, srv *passiveServer) {
+	logger := log.FromContext(ctx)
+
+	// The last server is never ejected, as it would only turn the errors into unavailability.
+	if len(p.lb.Servers()) <= 1 {
+		logger.Warnf("Passive health check: not ejecting the last server of the backend. Backend: %q URL: %q", p.name, u.String())
+		return
+	}
+
+	weight := 1
+	if wb, ok := p.lb.(weightedBalancer); ok {
+		if w, gotWeight := wb.ServerWeight(u); gotWeight {
+			weight = w
+		}
+	}
+
+	if err := p.removeServer(u); err != nil {
+		// The server may have already been removed by the active health check.
+		logger.Debugf("Passive health check: unable to eject the server. Backend: %q URL: %q Reason: %s", p.name, u.String(), err)
+		return
+	}
+
+	duration := p.options.EjectionTime << srv.ejections
+	if duration > p.options.MaxEjectionTime || duration <= 0 {
+		duration = p.options.MaxEjectionTime
+	}
+
+	logger.Warnf("Passive health check: ejecting the server after %d consecutive failures. Backend: %q URL: %q Duration: %s",
+		srv.failures, p.name, u.String(), duration)
+
+	srv.ejected = true
+	srv.failures = 0
+	srv.ejections++
+
+	time.AfterFunc(duration, func() {
+		p.reinstate(ctx, u, weight)
+	})
+}
+
+// removeServer must be called under the PassiveHealthCheck lock.
+func (p *PassiveHealthCheck) removeServer(u *url.URL) error {
+	if eb, ok := p.lb.(ejectingBalancer); ok {
+		return eb.EjectServer(u)
+	}
+
+	if err := p.lb.RemoveServer(u); err != nil {
+		return err
+	}
+
+	if p.serviceInfo != nil {
+		p.serviceInfo.UpdateServerStatus(u.String(), serverEjected)
+	}
+	return nil
+}
+
+func (p *PassiveHealthCheck) reinstate(ctx context.Context, u *url.URL, weight int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	logger := log.FromContext(ctx)
+
+	if srv, ok := p.servers[u.String()]; ok {
+		srv.ejected = false
+	}
+
+	// Only the servers still ejected are reinstated,
+	// the servers disabled by the active health check meanwhile are returned by the active health check.
+	if eb, ok := p.lb.(ejectingBalancer); ok {
+		reinstated, err := eb.ReinstateServer(u, roundrobin.Weight(weight))
+		if err != nil {
+			logger.Error(err)
+			return
+		}
+
+		if !reinstated {
+			logger.Debugf("Passive health check: not returning the server, which is not ejected anymore. Backend: %q URL: %q", p.name, u.String())
+			return
+		}
+
+		logger.Warnf("Passive health check: returned the server to the server list. Backend: %q URL: %q Weight: %d", p.name, u.String(), weight)
+		return
+	}
+
+	logger.Warnf("Passive health check: returning the server to the server list. Backend: %q URL: %q Weight: %d", p.name, u.String(), weight)
+
+	if err := p.lb.UpsertServer(u, roundrobin.Weight(weight)); err != nil {
+		logger.Error(err)
+	}
+}
+
+type statusRecorder interface {
+	http.ResponseWriter
+	http.Flusher
+	getCode() int
+}
+
+func newStatusRecorder(rw http.ResponseWriter) statusRecorder {
+	rec := &responseStatusRecorder{
+		ResponseWriter: rw,
+		statusCode:     http.StatusOK,
+	}
+	if _, ok := rw.(http.CloseNotifier); !ok {
+		return rec
+	}
+	return &responseStatusRecorderWithCloseNotify{rec}
+}
+
+// responseStatusRecorder captures the status code of the response.
+type responseStatusRecorder struct {
+	http.ResponseWriter
+	statusCode int
+}
+
+type responseStatusRecorderWithCloseNotify struct {
+	*responseStatusRecorder
+}
+
+// CloseNotify returns a channel that receives at most a
+// single value (true) when the client connection has gone away.
+func (r *responseStatusRecorderWithCloseNotify) CloseNotify() <-chan bool {
+	return r.ResponseWriter.(http.CloseNotifier).CloseNotify()
+}
+
+func (r *responseStatusRecorder) getCode() int {
+	return r.statusCode
+}
+
+// WriteHeader captures the status code for later retrieval.
+func (r *responseStatusRecorder) WriteHeader(status int) {
+	r.ResponseWriter.WriteHeader(status)
+	r.statusCode = status
+}
+
+// Hijack hijacks the connection.
+func (r *responseStatusRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return r.ResponseWriter.(http.Hijacker).Hijack()
+}
+
+// Flush sends any buffered data to the client.
+func (r *responseStatusRecorder) Flush() {
+	if f, ok := r.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
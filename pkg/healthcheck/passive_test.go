@@ -0,0 +1,217 @@
+package healthcheck
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traefik/traefik/v2/pkg/config/runtime"
+	"github.com/traefik/traefik/v2/pkg/testhelpers"
+	"github.com/vulcand/oxy/roundrobin"
+)
+
+// statusForwarder replies with the status code configured for the host of the request.
+type statusForwarder struct {
+	mu       sync.Mutex
+	statuses map[string]int
+}
+
+func (f *statusForwarder) setStatus(host string, status int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.statuses[host] = status
+}
+
+func (f *statusForwarder) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	f.mu.Lock()
+	status := f.statuses[req.URL.Host]
+	f.mu.Unlock()
+
+	rw.WriteHeader(status)
+}
+
+func newPassiveTestBalancer(t *testing.T, options PassiveOptions) (*LbStatusUpdater, *statusForwarder, *runtime.ServiceInfo) {
+	t.Helper()
+
+	fwd := &statusForwarder{statuses: map[string]int{"good": http.StatusOK, "bad": http.StatusOK}}
+	svInfo := &runtime.ServiceInfo{}
+
+	passive := NewPassiveHealthCheck(fwd, options, "backendName", svInfo)
+
+	rr, err := roundrobin.New(passive)
+	require.NoError(t, err)
+
+	lbsu := NewLBStatusUpdater(rr, svInfo)
+	passive.SetBalancer(lbsu)
+
+	require.NoError(t, lbsu.UpsertServer(testhelpers.MustParseURL("http://good"), roundrobin.Weight(1)))
+	require.NoError(t, lbsu.UpsertServer(testhelpers.MustParseURL("http://bad"), roundrobin.Weight(2)))
+
+	return lbsu, fwd, svInfo
+}
+
+func serveRequests(lb http.Handler, n int) {
+	for i := 0; i < n; i++ {
+		lb.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	}
+}
+
+func TestPassiveHealthCheck_ejection(t *testing.T) {
+	lbsu, fwd, svInfo := newPassiveTestBalancer(t, PassiveOptions{
+		MaxFailures:     3,
+		EjectionTime:    100 * time.Millisecond,
+		MaxEjectionTime: time.Second,
+	})
+
+	fwd.setStatus("bad", http.StatusBadGateway)
+
+	// The bad server receives 2 requests out of 3, so 6 requests make it fail 4 times.
+	serveRequests(lbsu, 6)
+
+	assert.Equal(t, []string{"http://good"}, urlsToStrings(lbsu.Servers()))
+	assert.Equal(t, map[string]string{"http://good": serverUp, "http://bad": serverEjected}, svInfo.GetAllStatus())
+
+	fwd.setStatus("bad", http.StatusOK)
+
+	assert.Eventually(t, func() bool {
+		return len(lbsu.Servers()) == 2
+	}, time.Second, 10*time.Millisecond)
+
+	assert.Equal(t, map[string]string{"http://good": serverUp, "http://bad": serverUp}, svInfo.GetAllStatus())
+
+	weight, ok := lbsu.ServerWeight(testhelpers.MustParseURL("http://bad"))
+	require.True(t, ok)
+	assert.Equal(t, 2, weight)
+}
+
+func TestPassiveHealthCheck_successResetsFailures(t *testing.T) {
+	lbsu, fwd, _ := newPassiveTestBalancer(t, PassiveOptions{
+		MaxFailures:     3,
+		EjectionTime:    time.Minute,
+		MaxEjectionTime: time.Minute,
+	})
+
+	fwd.setStatus("bad", http.StatusServiceUnavailable)
+	serveRequests(lbsu, 3)
+
+	fwd.setStatus("bad", http.StatusOK)
+	serveRequests(lbsu, 3)
+
+	fwd.setStatus("bad", http.StatusServiceUnavailable)
+	serveRequests(lbsu, 3)
+
+	assert.Len(t, lbsu.Servers(), 2)
+}
+
+func TestPassiveHealthCheck_applicationErrors(t *testing.T) {
+	lbsu, fwd, _ := newPassiveTestBalancer(t, PassiveOptions{
+		MaxFailures:     1,
+		EjectionTime:    time.Minute,
+		MaxEjectionTime: time.Minute,
+	})
+
+	fwd.setStatus("bad", http.StatusInternalServerError)
+	serveRequests(lbsu, 3)
+
+	fwd.setStatus("bad", http.StatusNotImplemented)
+	serveRequests(lbsu, 3)
+
+	assert.Len(t, lbsu.Servers(), 2)
+}
+
+func TestPassiveHealthCheck_lastServer(t *testing.T) {
+	lbsu, fwd, svInfo := newPassiveTestBalancer(t, PassiveOptions{
+		MaxFailures:     1,
+		EjectionTime:    time.Minute,
+		MaxEjectionTime: time.Minute,
+	})
+
+	fwd.setStatus("good", http.StatusServiceUnavailable)
+	fwd.setStatus("bad", http.StatusServiceUnavailable)
+
+	serveRequests(lbsu, 10)
+
+	require.Len(t, lbsu.Servers(), 1)
+
+	statuses := svInfo.GetAllStatus()
+	assert.Len(t, statuses, 2)
+	assert.Contains(t, statuses, lbsu.Servers()[0].String())
+	assert.Equal(t, serverUp, statuses[lbsu.Servers()[0].String()])
+}
+
+func TestPassiveHealthCheck_disabledByActiveHealthCheck(t *testing.T) {
+	lbsu, fwd, svInfo := newPassiveTestBalancer(t, PassiveOptions{
+		MaxFailures:     1,
+		EjectionTime:    50 * time.Millisecond,
+		MaxEjectionTime: 50 * time.Millisecond,
+	})
+
+	fwd.setStatus("bad", http.StatusBadGateway)
+	serveRequests(lbsu, 3)
+
+	require.Equal(t, []string{"http://good"}, urlsToStrings(lbsu.Servers()))
+
+	// The active health check disables the ejected server, keeping its weight.
+	bad := testhelpers.MustParseURL("http://bad")
+
+	weight, ok := lbsu.ServerWeight(bad)
+	require.True(t, ok)
+	assert.Equal(t, 2, weight)
+
+	require.NoError(t, lbsu.RemoveServer(bad))
+	assert.Equal(t, serverDown, svInfo.GetAllStatus()["http://bad"])
+
+	// The passive health check does not return the server disabled by the active health check.
+	time.Sleep(200 * time.Millisecond)
+
+	assert.Equal(t, []string{"http://good"}, urlsToStrings(lbsu.Servers()))
+	assert.Equal(t, serverDown, svInfo.GetAllStatus()["http://bad"])
+}
+
+func TestPassiveHealthCheck_observe(t *testing.T) {
+	passive := NewPassiveHealthCheck(http.NotFoundHandler(), PassiveOptions{
+		MaxFailures:     1,
+		EjectionTime:    time.Minute,
+		MaxEjectionTime: 3 * time.Minute,
+	}, "backendName", nil)
+
+	lb := &testLoadBalancer{RWMutex: &sync.RWMutex{}}
+	passive.SetBalancer(lb)
+
+	u := testhelpers.MustParseURL("http://bad")
+	lb.servers = append(lb.servers, u, testhelpers.MustParseURL("http://good"))
+
+	passive.observe(context.Background(), u, false)
+
+	srv := passive.servers[u.String()]
+	assert.True(t, srv.ejected)
+	assert.Equal(t, 1, srv.ejections)
+	assert.Equal(t, 1, lb.numRemovedServers)
+
+	// In flight requests are ignored while the server is ejected.
+	passive.observe(context.Background(), u, false)
+	assert.Equal(t, 1, lb.numRemovedServers)
+
+	passive.reinstate(context.Background(), u, 1)
+	assert.False(t, srv.ejected)
+	assert.Equal(t, 1, lb.numUpsertedServers)
+
+	// A successful request resets the backoff.
+	passive.observe(context.Background(), u, true)
+	assert.Equal(t, 0, srv.ejections)
+}
+
+func urlsToStrings(urls []*url.URL) []string {
+	var result []string
+	for _, u := range urls {
+		result = append(result, u.String())
+	}
+	return result
+}
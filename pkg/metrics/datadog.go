@@ -32,6 +32,7 @@ const (
 	ddEntryPointOpenConnsName       = "entrypoint.connections.open"
 	ddOpenConnsName                 = "service.connections.open"
 	ddServerUpName                  = "service.server.up"
+	ddMirrorMismatchesTotalName     = "service.mirror.mismatches.total"
 	ddTLSCertsNotAfterTimestampName = "tls.certs.notAfterTimestamp"
 )
 
@@ -63,6 +64,7 @@ func RegisterDatadog(ctx context.Context, config *types.Datadog) Registry {
 		registry.serviceRetriesCounter = datadogClient.NewCounter(ddRetriesTotalName, 1.0)
 		registry.serviceOpenConnsGauge = datadogClient.NewGauge(ddOpenConnsName)
 		registry.serviceServerUpGauge = datadogClient.NewGauge(ddServerUpName)
+		registry.serviceMirrorMismatchesCounter = datadogClient.NewCounter(ddMirrorMismatchesTotalName, 1.0)
 	}
 
 	return registry
@@ -38,6 +38,7 @@ const (
 	influxDBEntryPointOpenConnsName       = "traefik.entrypoint.connections.open"
 	influxDBOpenConnsName                 = "traefik.service.connections.open"
 	influxDBServerUpName                  = "traefik.service.server.up"
+	influxDBMirrorMismatchesTotalName     = "traefik.service.mirror.mismatches.total"
 	influxDBTLSCertsNotAfterTimestampName = "traefik.tls.certs.notAfterTimestamp"
 )
 
@@ -77,6 +78,7 @@ func RegisterInfluxDB(ctx context.Context, config *types.InfluxDB) Registry {
 		registry.serviceRetriesCounter = influxDBClient.NewCounter(influxDBRetriesTotalName)
 		registry.serviceOpenConnsGauge = influxDBClient.NewGauge(influxDBOpenConnsName)
 		registry.serviceServerUpGauge = influxDBClient.NewGauge(influxDBServerUpName)
+		registry.serviceMirrorMismatchesCounter = influxDBClient.NewCounter(influxDBMirrorMismatchesTotalName)
 	}
 
 	return registry
@@ -43,13 +43,14 @@ const (
 	// service level.
 
 	// MetricServicePrefix prefix of all service metric names.
-	MetricServicePrefix     = MetricNamePrefix + "service_"
-	serviceReqsTotalName    = MetricServicePrefix + "requests_total"
-	serviceReqsTLSTotalName = MetricServicePrefix + "requests_tls_total"
-	serviceReqDurationName  = MetricServicePrefix + "request_duration_seconds"
-	serviceOpenConnsName    = MetricServicePrefix + "open_connections"
-	serviceRetriesTotalName = MetricServicePrefix + "retries_total"
-	serviceServerUpName     = MetricServicePrefix + "server_up"
+	MetricServicePrefix              = MetricNamePrefix + "service_"
+	serviceReqsTotalName             = MetricServicePrefix + "requests_total"
+	serviceReqsTLSTotalName          = MetricServicePrefix + "requests_tls_total"
+	serviceReqDurationName           = MetricServicePrefix + "request_duration_seconds"
+	serviceOpenConnsName             = MetricServicePrefix + "open_connections"
+	serviceRetriesTotalName          = MetricServicePrefix + "retries_total"
+	serviceServerUpName              = MetricServicePrefix + "server_up"
+	serviceMirrorMismatchesTotalName = MetricServicePrefix + "mirror_mismatches_total"
 )
 
 // promState holds all metric state internally and acts as the only Collector we register for Prometheus.
@@ -206,6 +207,10 @@ func initStandardRegistry(config *types.Prometheus) Registry {
 			Name: serviceServerUpName,
 			Help: "service server is up, described by gauge value of 0 or 1.",
 		}, []string{"service", "url"})
+		serviceMirrorMismatches := newCounterFrom(promState.collectors, stdprometheus.CounterOpts{
+			Name: serviceMirrorMismatchesTotalName,
+			Help: "How many mirror responses differed from the main response on a mirroring service, partitioned by mirror.",
+		}, []string{"service", "mirror"})
 
 		promState.describers = append(promState.describers, []func(chan<- *stdprometheus.Desc){
 			serviceReqs.cv.Describe,
@@ -214,6 +219,7 @@ func initStandardRegistry(config *types.Prometheus) Registry {
 			serviceOpenConns.gv.Describe,
 			serviceRetries.cv.Describe,
 			serviceServerUp.gv.Describe,
+			serviceMirrorMismatches.cv.Describe,
 		}...)
 
 		reg.serviceReqsCounter = serviceReqs
@@ -222,6 +228,7 @@ func initStandardRegistry(config *types.Prometheus) Registry {
 		reg.serviceOpenConnsGauge = serviceOpenConns
 		reg.serviceRetriesCounter = serviceRetries
 		reg.serviceServerUpGauge = serviceServerUp
+		reg.serviceMirrorMismatchesCounter = serviceMirrorMismatches
 	}
 
 	return reg
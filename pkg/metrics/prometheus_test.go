@@ -154,6 +154,10 @@ func TestPrometheus(t *testing.T) {
 		ServiceServerUpGauge().
 		With("service", "service1", "url", "http://127.0.0.10:80").
 		Set(1)
+	prometheusRegistry.
+		ServiceMirrorMismatchesCounter().
+		With("service", "service1", "mirror", "mirror1").
+		Add(1)
 
 	delayForTrackingCompletion()
 
@@ -262,6 +266,14 @@ func TestPrometheus(t *testing.T) {
 			},
 			assert: buildGaugeAssert(t, serviceServerUpName, 1),
 		},
+		{
+			name: serviceMirrorMismatchesTotalName,
+			labels: map[string]string{
+				"service": "service1",
+				"mirror":  "mirror1",
+			},
+			assert: buildCounterAssert(t, serviceMirrorMismatchesTotalName, 1),
+		},
 	}
 
 	for _, test := range testCases {
@@ -29,6 +29,7 @@ const (
 	statsdEntryPointOpenConnsName       = "entrypoint.connections.open"
 	statsdOpenConnsName                 = "service.connections.open"
 	statsdServerUpName                  = "service.server.up"
+	statsdMirrorMismatchesTotalName     = "service.mirror.mismatches.total"
 	statsdTLSCertsNotAfterTimestampName = "tls.certs.notAfterTimestamp"
 )
 
@@ -70,6 +71,7 @@ func RegisterStatsd(ctx context.Context, config *types.Statsd) Registry {
 		registry.serviceRetriesCounter = statsdClient.NewCounter(statsdRetriesTotalName, 1.0)
 		registry.serviceOpenConnsGauge = statsdClient.NewGauge(statsdOpenConnsName)
 		registry.serviceServerUpGauge = statsdClient.NewGauge(statsdServerUpName)
+		registry.serviceMirrorMismatchesCounter = statsdClient.NewCounter(statsdMirrorMismatchesTotalName, 1.0)
 	}
 
 	return registry
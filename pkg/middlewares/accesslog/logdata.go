@@ -129,6 +129,9 @@ type LogData struct {
 	Request            request
 	OriginResponse     http.Header
 	DownstreamResponse downstreamResponse
+
+	// router holds the access log options of the router which handled the request, if any.
+	router *routerOptions
 }
 
 type downstreamResponse struct {
@@ -141,4 +144,7 @@ type request struct {
 	headers http.Header
 	// Request body size
 	size int64
+	// Whether the request matches the include and the exclude filters.
+	included bool
+	excluded bool
 }
@@ -2,6 +2,7 @@ package accesslog
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"net"
@@ -18,6 +19,8 @@ import (
 	"github.com/sirupsen/logrus"
 	ptypes "github.com/traefik/paerser/types"
 	"github.com/traefik/traefik/v2/pkg/log"
+	"github.com/traefik/traefik/v2/pkg/middlewares/requestdecorator"
+	"github.com/traefik/traefik/v2/pkg/rules"
 	traefiktls "github.com/traefik/traefik/v2/pkg/tls"
 	"github.com/traefik/traefik/v2/pkg/types"
 )
@@ -33,6 +36,15 @@ const (
 
 	// JSONFormat is the JSON logging format.
 	JSONFormat string = "json"
+
+	// CombinedFormat is the combined logging format, i.e. the common logging format with the referer and the user agent.
+	CombinedFormat string = "combined"
+
+	// LogfmtFormat is the logfmt logging format.
+	LogfmtFormat string = "logfmt"
+
+	// TemplateFormat is the logging format defined by a template.
+	TemplateFormat string = "template"
 )
 
 type noopCloser struct {
@@ -59,6 +71,9 @@ type Handler struct {
 	file           io.WriteCloser
 	mu             sync.Mutex
 	httpCodeRanges types.HTTPCodeRanges
+	include        rules.HTTPMatcher
+	exclude        rules.HTTPMatcher
+	sampler        *sampler
 	logHandlerChan chan handlerParams
 	wg             sync.WaitGroup
 }
@@ -74,16 +89,6 @@ func WrapHandler(handler *Handler) alice.Constructor {
 
 // NewHandler creates a new Handler.
 func NewHandler(config *types.AccessLog) (*Handler, error) {
-	var file io.WriteCloser = noopCloser{os.Stdout}
-	if len(config.FilePath) > 0 {
-		f, err := openAccessLogFile(config.FilePath)
-		if err != nil {
-			return nil, fmt.Errorf("error opening access log file: %w", err)
-		}
-		file = f
-	}
-	logHandlerChan := make(chan handlerParams, config.BufferingSize)
-
 	var formatter logrus.Formatter
 
 	switch config.Format {
@@ -91,11 +96,27 @@ func NewHandler(config *types.AccessLog) (*Handler, error) {
 		formatter = new(CommonLogFormatter)
 	case JSONFormat:
 		formatter = new(logrus.JSONFormatter)
+	case CombinedFormat:
+		formatter = new(CombinedLogFormatter)
+	case LogfmtFormat:
+		formatter = new(LogfmtFormatter)
+	case TemplateFormat:
+		var err error
+		formatter, err = NewTemplateFormatter(config.Template)
+		if err != nil {
+			return nil, err
+		}
 	default:
 		log.WithoutContext().Errorf("unsupported access log format: %q, defaulting to common format instead.", config.Format)
 		formatter = new(CommonLogFormatter)
 	}
 
+	file, err := openSink(config)
+	if err != nil {
+		return nil, err
+	}
+	logHandlerChan := make(chan handlerParams, config.BufferingSize)
+
 	logger := &logrus.Logger{
 		Out:       file,
 		Formatter: formatter,
@@ -127,6 +148,30 @@ func NewHandler(config *types.AccessLog) (*Handler, error) {
 		} else {
 			logHandler.httpCodeRanges = httpCodeRanges
 		}
+
+		if config.Filters.Include != "" {
+			logHandler.include, err = rules.NewHTTPMatcher(config.Filters.Include)
+			if err != nil {
+				_ = file.Close()
+				return nil, fmt.Errorf("invalid access log include filter: %w", err)
+			}
+		}
+
+		if config.Filters.Exclude != "" {
+			logHandler.exclude, err = rules.NewHTTPMatcher(config.Filters.Exclude)
+			if err != nil {
+				_ = file.Close()
+				return nil, fmt.Errorf("invalid access log exclude filter: %w", err)
+			}
+		}
+	}
+
+	if config.Sampling != nil {
+		logHandler.sampler, err = newSampler(config.Sampling.Rate, config.Sampling.KeepErrors)
+		if err != nil {
+			_ = file.Close()
+			return nil, fmt.Errorf("invalid access log sampling: %w", err)
+		}
 	}
 
 	if config.BufferingSize > 0 {
@@ -142,6 +187,39 @@ func NewHandler(config *types.AccessLog) (*Handler, error) {
 	return logHandler, nil
 }
 
+// openSink opens the destination of the access logs.
+func openSink(config *types.AccessLog) (io.WriteCloser, error) {
+	if config.Syslog != nil {
+		if len(config.FilePath) > 0 {
+			return nil, errors.New("the access logs cannot be sent to both a file and syslog")
+		}
+
+		w, err := newSyslogWriter(config.Syslog)
+		if err != nil {
+			return nil, fmt.Errorf("error creating access log syslog writer: %w", err)
+		}
+		return w, nil
+	}
+
+	if len(config.FilePath) == 0 {
+		return noopCloser{os.Stdout}, nil
+	}
+
+	if config.Rotation != nil {
+		f, err := newRotatingFile(config.FilePath, int64(config.Rotation.MaxSize)*1024*1024, time.Duration(config.Rotation.MaxAge), config.Rotation.MaxBackups)
+		if err != nil {
+			return nil, fmt.Errorf("error opening access log file: %w", err)
+		}
+		return f, nil
+	}
+
+	f, err := openAccessLogFile(config.FilePath)
+	if err != nil {
+		return nil, fmt.Errorf("error opening access log file: %w", err)
+	}
+	return f, nil
+}
+
 func openAccessLogFile(filePath string) (*os.File, error) {
 	dir := filepath.Dir(filePath)
 
@@ -181,6 +259,10 @@ func (h *Handler) ServeHTTP(rw http.ResponseWriter, req *http.Request, next http
 		},
 	}
 
+	if h.include != nil || h.exclude != nil {
+		logDataTable.Request.included, logDataTable.Request.excluded = h.matchFilters(req)
+	}
+
 	reqWithDataTable := req.WithContext(context.WithValue(req.Context(), DataTableKey, logDataTable))
 
 	var crr *captureRequestReader
@@ -247,6 +329,17 @@ func (h *Handler) ServeHTTP(rw http.ResponseWriter, req *http.Request, next http
 	}
 }
 
+// matchFilters reports whether the request matches the include and the exclude filters.
+func (h *Handler) matchFilters(req *http.Request) (included, excluded bool) {
+	// The request decorator, which canonizes the host used by the rules, comes after the access logger in the middleware chain.
+	requestdecorator.New(nil).ServeHTTP(nil, req, func(_ http.ResponseWriter, r *http.Request) {
+		included = h.include != nil && h.include(r)
+		excluded = h.exclude != nil && h.exclude(r)
+	})
+
+	return included, excluded
+}
+
 // Close closes the Logger (i.e. the file, drain logHandlerChan, etc).
 func (h *Handler) Close() error {
 	close(h.logHandlerChan)
@@ -255,7 +348,12 @@ func (h *Handler) Close() error {
 }
 
 // Rotate closes and reopens the log file to allow for rotation by an external source.
+// When the rotation of the log file is configured, the file is rotated instead.
 func (h *Handler) Rotate() error {
+	if rotator, ok := h.file.(interface{ Rotate() error }); ok {
+		return rotator.Rotate()
+	}
+
 	if h.config.FilePath == "" {
 		return nil
 	}
@@ -310,7 +408,7 @@ func (h *Handler) logTheRoundTrip(logDataTable *LogData) {
 	totalDuration := time.Now().UTC().Sub(core[StartUTC].(time.Time))
 	core[Duration] = totalDuration
 
-	if h.keepAccessLog(status, retryAttempts, totalDuration) {
+	if h.keepAccessLog(logDataTable, status, retryAttempts, totalDuration) {
 		size := logDataTable.DownstreamResponse.size
 		core[DownstreamContentSize] = size
 		if original, ok := core[OriginContentSize]; ok {
@@ -354,17 +452,43 @@ func (h *Handler) redactHeaders(headers http.Header, fields logrus.Fields, prefi
 	}
 }
 
-func (h *Handler) keepAccessLog(statusCode, retryAttempts int, duration time.Duration) bool {
+func (h *Handler) keepAccessLog(logDataTable *LogData, statusCode, retryAttempts int, duration time.Duration) bool {
+	if logDataTable.router != nil && logDataTable.router.disabled {
+		return false
+	}
+
+	if !h.filterAccessLog(logDataTable, statusCode, retryAttempts, duration) {
+		return false
+	}
+
+	// The sampling of the router takes precedence over the one of the access logger.
+	s := h.sampler
+	if logDataTable.router != nil && logDataTable.router.sampler != nil {
+		s = logDataTable.router.sampler
+	}
+
+	return s == nil || s.keep(statusCode)
+}
+
+func (h *Handler) filterAccessLog(logDataTable *LogData, statusCode, retryAttempts int, duration time.Duration) bool {
 	if h.config.Filters == nil {
 		// no filters were specified
 		return true
 	}
 
-	if len(h.httpCodeRanges) == 0 && !h.config.Filters.RetryAttempts && h.config.Filters.MinDuration == 0 {
+	if logDataTable.Request.excluded {
+		return false
+	}
+
+	if len(h.httpCodeRanges) == 0 && !h.config.Filters.RetryAttempts && h.config.Filters.MinDuration == 0 && h.include == nil {
 		// empty filters were specified, e.g. by passing --accessLog.filters only (without other filter options)
 		return true
 	}
 
+	if logDataTable.Request.included {
+		return true
+	}
+
 	if h.httpCodeRanges.Contains(statusCode) {
 		return true
 	}
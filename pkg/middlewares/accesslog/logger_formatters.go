@@ -2,8 +2,14 @@ package accesslog
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
 	"time"
+	"unicode"
 
 	"github.com/sirupsen/logrus"
 )
@@ -52,6 +58,129 @@ func (f *CommonLogFormatter) Format(entry *logrus.Entry) ([]byte, error) {
 	return b.Bytes(), err
 }
 
+// CombinedLogFormatter provides formatting in the combined log format,
+// i.e. the common log format followed by the referer and the user agent.
+type CombinedLogFormatter struct{}
+
+// Format formats the log entry in the combined log format.
+func (f *CombinedLogFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+	b := &bytes.Buffer{}
+
+	timestamp := defaultValue
+	if v, ok := entry.Data[StartUTC]; ok {
+		timestamp = v.(time.Time).Format(commonLogTimeFormat)
+	} else if v, ok := entry.Data[StartLocal]; ok {
+		timestamp = v.(time.Time).Local().Format(commonLogTimeFormat)
+	}
+
+	_, err := fmt.Fprintf(b, "%s - %s [%s] \"%s %s %s\" %v %v %s %s\n",
+		toLog(entry.Data, ClientHost, defaultValue, false),
+		toLog(entry.Data, ClientUsername, defaultValue, false),
+		timestamp,
+		toLog(entry.Data, RequestMethod, defaultValue, false),
+		toLog(entry.Data, RequestPath, defaultValue, false),
+		toLog(entry.Data, RequestProtocol, defaultValue, false),
+		toLog(entry.Data, DownstreamStatus, defaultValue, true),
+		toLog(entry.Data, DownstreamContentSize, defaultValue, true),
+		toLog(entry.Data, RequestRefererHeader, `"-"`, true),
+		toLog(entry.Data, RequestUserAgentHeader, `"-"`, true))
+
+	return b.Bytes(), err
+}
+
+// LogfmtFormatter provides formatting in the logfmt format, i.e. key=value pairs sorted by key.
+type LogfmtFormatter struct{}
+
+// Format formats the log entry in the logfmt format.
+func (f *LogfmtFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+	keys := make([]string, 0, len(entry.Data))
+	for k := range entry.Data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	b := &bytes.Buffer{}
+	for i, k := range keys {
+		if i > 0 {
+			b.WriteByte(' ')
+		}
+		b.WriteString(k)
+		b.WriteByte('=')
+		b.WriteString(logfmtValue(entry.Data[k]))
+	}
+	b.WriteByte('\n')
+
+	return b.Bytes(), nil
+}
+
+func logfmtValue(v interface{}) string {
+	var s string
+	switch value := v.(type) {
+	case nil:
+		return defaultValue
+	case string:
+		s = value
+	case time.Time:
+		s = value.Format(time.RFC3339Nano)
+	case time.Duration:
+		s = strconv.FormatInt(value.Nanoseconds(), 10)
+	default:
+		s = fmt.Sprint(value)
+	}
+
+	needsQuotes := s == "" || strings.IndexFunc(s, func(r rune) bool {
+		return r == '=' || r == '"' || unicode.IsSpace(r) || !unicode.IsPrint(r)
+	}) >= 0
+	if needsQuotes {
+		return strconv.Quote(s)
+	}
+	return s
+}
+
+// TemplateFormatter provides formatting with a template, whose data are the access log fields.
+// The missing core fields have the "-" value.
+type TemplateFormatter struct {
+	template *template.Template
+}
+
+// NewTemplateFormatter parses the template and returns the corresponding formatter.
+func NewTemplateFormatter(text string) (*TemplateFormatter, error) {
+	if text == "" {
+		return nil, errors.New("empty access log template")
+	}
+
+	tmpl, err := template.New("accessLog").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing access log template: %w", err)
+	}
+
+	return &TemplateFormatter{template: tmpl}, nil
+}
+
+// Format formats the log entry with the template.
+func (f *TemplateFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+	data := make(map[string]interface{}, len(allCoreKeys)+len(entry.Data))
+	for k := range allCoreKeys {
+		data[k] = defaultValue
+	}
+	for k, v := range entry.Data {
+		if v != nil {
+			data[k] = v
+		}
+	}
+
+	b := &bytes.Buffer{}
+	if err := f.template.Execute(b, data); err != nil {
+		return nil, err
+	}
+
+	if !bytes.HasSuffix(b.Bytes(), []byte("\n")) {
+		b.WriteByte('\n')
+	}
+
+	return b.Bytes(), nil
+}
+
 func toLog(fields logrus.Fields, key, defaultValue string, quoted bool) interface{} {
 	if v, ok := fields[key]; ok {
 		if v == nil {
@@ -8,6 +8,7 @@ import (
 
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestCommonLogFormatter_Format(t *testing.T) {
@@ -101,6 +102,140 @@ func TestCommonLogFormatter_Format(t *testing.T) {
 	}
 }
 
+func TestCombinedLogFormatter_Format(t *testing.T) {
+	testCases := []struct {
+		name        string
+		data        map[string]interface{}
+		expectedLog string
+	}{
+		{
+			name: "missing data",
+			data: map[string]interface{}{
+				StartUTC:      time.Date(2009, time.November, 10, 23, 0, 0, 0, time.UTC),
+				ClientHost:    "10.0.0.1",
+				RequestMethod: http.MethodGet,
+				RequestPath:   "/foo",
+			},
+			expectedLog: `10.0.0.1 - - [10/Nov/2009:23:00:00 +0000] "GET /foo -" - - "-" "-"
+`,
+		},
+		{
+			name: "all data",
+			data: map[string]interface{}{
+				StartUTC:               time.Date(2009, time.November, 10, 23, 0, 0, 0, time.UTC),
+				ClientHost:             "10.0.0.1",
+				ClientUsername:         "Client",
+				RequestMethod:          http.MethodGet,
+				RequestPath:            "/foo",
+				RequestProtocol:        "HTTP/1.1",
+				DownstreamStatus:       200,
+				DownstreamContentSize:  int64(132),
+				RequestRefererHeader:   "referer",
+				RequestUserAgentHeader: "agent",
+			},
+			expectedLog: `10.0.0.1 - Client [10/Nov/2009:23:00:00 +0000] "GET /foo HTTP/1.1" 200 132 "referer" "agent"
+`,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			raw, err := new(CombinedLogFormatter).Format(&logrus.Entry{Data: test.data})
+			assert.NoError(t, err)
+
+			assert.Equal(t, test.expectedLog, string(raw))
+		})
+	}
+}
+
+func TestLogfmtFormatter_Format(t *testing.T) {
+	data := map[string]interface{}{
+		StartUTC:               time.Date(2009, time.November, 10, 23, 0, 0, 0, time.UTC),
+		Duration:               123 * time.Millisecond,
+		RequestMethod:          http.MethodGet,
+		RequestPath:            "/foo?bar=baz",
+		DownstreamStatus:       200,
+		OriginStatus:           nil,
+		RouterName:             "",
+		RequestUserAgentHeader: `agent "quoted"`,
+	}
+
+	raw, err := new(LogfmtFormatter).Format(&logrus.Entry{Data: data})
+	require.NoError(t, err)
+
+	expected := `DownstreamStatus=200 Duration=123000000 OriginStatus=- RequestMethod=GET RequestPath="/foo?bar=baz" RouterName="" StartUTC=2009-11-10T23:00:00Z request_User-Agent="agent \"quoted\""
+`
+	assert.Equal(t, expected, string(raw))
+}
+
+func TestTemplateFormatter_Format(t *testing.T) {
+	testCases := []struct {
+		desc          string
+		template      string
+		expectedError bool
+		expectedLog   string
+	}{
+		{
+			desc:          "empty template",
+			template:      "",
+			expectedError: true,
+		},
+		{
+			desc:          "invalid template",
+			template:      "{{ .ClientHost",
+			expectedError: true,
+		},
+		{
+			desc:        "fields",
+			template:    `{{ .ClientHost }} {{ .RequestMethod }} {{ .RequestPath }} {{ .DownstreamStatus }} {{ index . "request_User-Agent" }}`,
+			expectedLog: "10.0.0.1 GET /foo 200 agent\n",
+		},
+		{
+			desc:        "missing fields",
+			template:    "{{ .ServiceName }} {{ .OriginStatus }}\n",
+			expectedLog: "- -\n",
+		},
+		{
+			desc:        "time formatting",
+			template:    `{{ .StartUTC.Format "2006-01-02" }} {{ .Duration.Milliseconds }}ms`,
+			expectedLog: "2009-11-10 123ms\n",
+		},
+	}
+
+	data := map[string]interface{}{
+		StartUTC:               time.Date(2009, time.November, 10, 23, 0, 0, 0, time.UTC),
+		Duration:               123 * time.Millisecond,
+		ClientHost:             "10.0.0.1",
+		RequestMethod:          http.MethodGet,
+		RequestPath:            "/foo",
+		DownstreamStatus:       200,
+		OriginStatus:           nil,
+		RequestUserAgentHeader: "agent",
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			formatter, err := NewTemplateFormatter(test.template)
+			if test.expectedError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			raw, err := formatter.Format(&logrus.Entry{Data: data})
+			require.NoError(t, err)
+
+			assert.Equal(t, test.expectedLog, string(raw))
+		})
+	}
+}
+
 func Test_toLog(t *testing.T) {
 	testCases := []struct {
 		desc         string
@@ -228,6 +228,94 @@ func TestLoggerCLF(t *testing.T) {
 	assertValidLogData(t, expectedLog, logData)
 }
 
+func TestLoggerTemplate(t *testing.T) {
+	tmpDir := createTempDir(t, TemplateFormat)
+
+	logFilePath := filepath.Join(tmpDir, logFileNameSuffix)
+	config := &types.AccessLog{
+		FilePath: logFilePath,
+		Format:   TemplateFormat,
+		Template: `{{ .ClientHost }} {{ .RequestMethod }} {{ .RequestPath }} {{ .DownstreamStatus }} {{ .ServiceURL }} {{ .OriginStatus }}`,
+	}
+	doLogging(t, config)
+
+	logData, err := ioutil.ReadFile(logFilePath)
+	require.NoError(t, err)
+
+	assert.Equal(t, "TestHost POST testpath 123 http://127.0.0.1/testService 123\n", string(logData))
+}
+
+func TestLoggerRotatingFile(t *testing.T) {
+	tmpDir := createTempDir(t, "traefik_")
+
+	logFilePath := filepath.Join(tmpDir, "access.log")
+	config := &types.AccessLog{FilePath: logFilePath, Format: CommonFormat, Rotation: &types.AccessLogRotation{MaxSize: 1}}
+
+	logHandler, err := NewHandler(config)
+	require.NoError(t, err)
+	defer logHandler.Close()
+
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.WriteHeader(http.StatusOK)
+	})
+
+	logHandler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "http://localhost", nil), next)
+
+	// The rotation is done by the handler, without losing lines.
+	require.NoError(t, logHandler.Rotate())
+
+	logHandler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "http://localhost", nil), next)
+
+	files, err := ioutil.ReadDir(tmpDir)
+	require.NoError(t, err)
+	require.Len(t, files, 2)
+
+	for _, file := range files {
+		assert.Equal(t, 1, lineCount(t, filepath.Join(tmpDir, file.Name())))
+	}
+}
+
+func TestNewHandler_fileAndSyslog(t *testing.T) {
+	config := &types.AccessLog{
+		FilePath: filepath.Join(createTempDir(t, "traefik_"), "access.log"),
+		Format:   CommonFormat,
+		Syslog:   &types.AccessLogSyslog{Network: "udp", Address: "localhost:514", Facility: "local0"},
+	}
+
+	_, err := NewHandler(config)
+	assert.Error(t, err)
+}
+
+func TestNewHandler_invalidFilters(t *testing.T) {
+	testCases := []struct {
+		desc   string
+		config *types.AccessLog
+	}{
+		{
+			desc:   "invalid include rule",
+			config: &types.AccessLog{Filters: &types.AccessLogFilters{Include: "Host(`foo`"}},
+		},
+		{
+			desc:   "invalid exclude rule",
+			config: &types.AccessLog{Filters: &types.AccessLogFilters{Exclude: "Foo(`bar`)"}},
+		},
+		{
+			desc:   "invalid sampling rate",
+			config: &types.AccessLog{Sampling: &types.AccessLogSampling{Rate: 2}},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := NewHandler(test.config)
+			assert.Error(t, err)
+		})
+	}
+}
+
 func TestAsyncLoggerCLF(t *testing.T) {
 	tmpDir := createTempDir(t, CommonFormat)
 
@@ -557,6 +645,75 @@ func TestNewLogHandlerOutputStdout(t *testing.T) {
 			},
 			expectedLog: `TestHost - TestUser [13/Apr/2016:07:14:19 -0700] "POST testpath HTTP/0.0" 123 12 "testReferer" "testUserAgent" 23 "testRouter" "http://127.0.0.1/testService" 1ms`,
 		},
+		{
+			desc: "Include filter matching",
+			config: &types.AccessLog{
+				FilePath: "",
+				Format:   CommonFormat,
+				Filters: &types.AccessLogFilters{
+					StatusCodes: []string{"200"},
+					Include:     "Host(`TestHost`)",
+				},
+			},
+			expectedLog: `TestHost - TestUser [13/Apr/2016:07:14:19 -0700] "POST testpath HTTP/0.0" 123 12 "testReferer" "testUserAgent" 23 "testRouter" "http://127.0.0.1/testService" 1ms`,
+		},
+		{
+			desc: "Include filter not matching",
+			config: &types.AccessLog{
+				FilePath: "",
+				Format:   CommonFormat,
+				Filters: &types.AccessLogFilters{
+					Include: "Method(`GET`)",
+				},
+			},
+			expectedLog: ``,
+		},
+		{
+			desc: "Exclude filter matching",
+			config: &types.AccessLog{
+				FilePath: "",
+				Format:   CommonFormat,
+				Filters: &types.AccessLogFilters{
+					StatusCodes: []string{"123"},
+					Exclude:     "Host(`TestHost`) && Method(`POST`)",
+				},
+			},
+			expectedLog: ``,
+		},
+		{
+			desc: "Exclude filter not matching",
+			config: &types.AccessLog{
+				FilePath: "",
+				Format:   CommonFormat,
+				Filters: &types.AccessLogFilters{
+					Exclude: "Host(`foo.bar`)",
+				},
+			},
+			expectedLog: `TestHost - TestUser [13/Apr/2016:07:14:19 -0700] "POST testpath HTTP/0.0" 123 12 "testReferer" "testUserAgent" 23 "testRouter" "http://127.0.0.1/testService" 1ms`,
+		},
+		{
+			desc: "Sampling keeping all the access logs",
+			config: &types.AccessLog{
+				FilePath: "",
+				Format:   CommonFormat,
+				Sampling: &types.AccessLogSampling{
+					Rate: 1,
+				},
+			},
+			expectedLog: `TestHost - TestUser [13/Apr/2016:07:14:19 -0700] "POST testpath HTTP/0.0" 123 12 "testReferer" "testUserAgent" 23 "testRouter" "http://127.0.0.1/testService" 1ms`,
+		},
+		{
+			desc: "Sampling dropping all the access logs",
+			config: &types.AccessLog{
+				FilePath: "",
+				Format:   CommonFormat,
+				Sampling: &types.AccessLogSampling{
+					Rate:       0,
+					KeepErrors: true,
+				},
+			},
+			expectedLog: ``,
+		},
 		{
 			desc: "Default mode keep",
 			config: &types.AccessLog{
@@ -0,0 +1,186 @@
+package accesslog
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/traefik/traefik/v2/pkg/log"
+)
+
+const backupTimeFormat = "2006-01-02T15-04-05.000"
+
+// rotatingFile is an access log file which is rotated when it reaches its maximum size, or when Rotate is called.
+// The rotated files are renamed with their rotation time, e.g. access-2006-01-02T15-04-05.000.log,
+// and are removed when they are older than maxAge, or when there are more than maxBackups of them.
+type rotatingFile struct {
+	path       string
+	maxSize    int64
+	maxAge     time.Duration
+	maxBackups int
+	now        func() time.Time
+	rename     func(oldpath, newpath string) error
+
+	mu   sync.Mutex
+	file *os.File
+	size int64
+}
+
+func newRotatingFile(path string, maxSize int64, maxAge time.Duration, maxBackups int) (*rotatingFile, error) {
+	r := &rotatingFile{
+		path:       path,
+		maxSize:    maxSize,
+		maxAge:     maxAge,
+		maxBackups: maxBackups,
+		now:        time.Now,
+		rename:     os.Rename,
+	}
+
+	if err := r.open(); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+func (r *rotatingFile) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.maxSize > 0 && r.size > 0 && r.size+int64(len(p)) > r.maxSize {
+		if err := r.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := r.file.Write(p)
+	r.size += int64(n)
+	return n, err
+}
+
+// Rotate rotates the file.
+func (r *rotatingFile) Rotate() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.rotate()
+}
+
+func (r *rotatingFile) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.file.Close()
+}
+
+func (r *rotatingFile) open() error {
+	file, err := openAccessLogFile(r.path)
+	if err != nil {
+		return err
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return fmt.Errorf("error getting the size of file %s: %w", r.path, err)
+	}
+
+	r.file = file
+	r.size = info.Size()
+	return nil
+}
+
+// rotate must be called under the lock.
+func (r *rotatingFile) rotate() error {
+	if err := r.file.Close(); err != nil {
+		log.WithoutContext().Errorf("Error closing access log file %s: %v", r.path, err)
+	}
+
+	if err := r.rename(r.path, r.nextBackupPath()); err != nil && !os.IsNotExist(err) {
+		// The file is reopened, so that the logs are still written to it.
+		if errOpen := r.open(); errOpen != nil {
+			log.WithoutContext().Errorf("Error reopening access log file %s: %v", r.path, errOpen)
+		}
+		return fmt.Errorf("error rotating file %s: %w", r.path, err)
+	}
+
+	if err := r.open(); err != nil {
+		return err
+	}
+
+	r.removeBackups()
+	return nil
+}
+
+// nextBackupPath returns the path of a new rotated file,
+// its time being shifted when a file has already been rotated at the same millisecond, to not overwrite it.
+func (r *rotatingFile) nextBackupPath() string {
+	t := r.now()
+	for {
+		path := r.backupPath(t)
+		if _, err := os.Lstat(path); os.IsNotExist(err) {
+			return path
+		}
+		t = t.Add(time.Millisecond)
+	}
+}
+
+func (r *rotatingFile) backupPath(t time.Time) string {
+	ext := filepath.Ext(r.path)
+	return strings.TrimSuffix(r.path, ext) + "-" + t.Format(backupTimeFormat) + ext
+}
+
+type backup struct {
+	path string
+	time time.Time
+}
+
+// removeBackups removes the rotated files which are too old or too many.
+func (r *rotatingFile) removeBackups() {
+	if r.maxAge <= 0 && r.maxBackups <= 0 {
+		return
+	}
+
+	ext := filepath.Ext(r.path)
+	prefix := strings.TrimSuffix(filepath.Base(r.path), ext) + "-"
+
+	files, err := ioutil.ReadDir(filepath.Dir(r.path))
+	if err != nil {
+		log.WithoutContext().Errorf("Error listing the rotated access log files: %v", err)
+		return
+	}
+
+	var backups []backup
+	for _, file := range files {
+		name := file.Name()
+		if file.IsDir() || !strings.HasPrefix(name, prefix) || !strings.HasSuffix(name, ext) {
+			continue
+		}
+
+		t, err := time.ParseInLocation(backupTimeFormat, strings.TrimSuffix(strings.TrimPrefix(name, prefix), ext), time.Local)
+		if err != nil {
+			continue
+		}
+
+		backups = append(backups, backup{path: filepath.Join(filepath.Dir(r.path), name), time: t})
+	}
+
+	// The most recent first.
+	sort.Slice(backups, func(i, j int) bool {
+		return backups[i].time.After(backups[j].time)
+	})
+
+	now := r.now()
+	for i, b := range backups {
+		if (r.maxBackups > 0 && i >= r.maxBackups) || (r.maxAge > 0 && now.Sub(b.time) > r.maxAge) {
+			if err := os.Remove(b.path); err != nil {
+				log.WithoutContext().Errorf("Error removing the rotated access log file %s: %v", b.path, err)
+			}
+		}
+	}
+}
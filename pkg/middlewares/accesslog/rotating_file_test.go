@@ -0,0 +1,165 @@
+package accesslog
+
+import (
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRotatingFile_maxSize(t *testing.T) {
+	dir := createTempDir(t, "traefik_")
+	path := filepath.Join(dir, "access.log")
+
+	file, err := newRotatingFile(path, 10, 0, 0)
+	require.NoError(t, err)
+
+	now := time.Date(2009, time.November, 10, 23, 0, 0, 0, time.Local)
+	file.now = func() time.Time {
+		now = now.Add(time.Second)
+		return now
+	}
+
+	for _, line := range []string{"line 1\n", "line 2\n", "line 3\n"} {
+		_, err = file.Write([]byte(line))
+		require.NoError(t, err)
+	}
+	require.NoError(t, file.Close())
+
+	assert.Equal(t, map[string]string{
+		"access.log":                         "line 3\n",
+		"access-2009-11-10T23-00-01.000.log": "line 1\n",
+		"access-2009-11-10T23-00-02.000.log": "line 2\n",
+	}, readDir(t, dir))
+}
+
+func TestRotatingFile_sameMillisecond(t *testing.T) {
+	dir := createTempDir(t, "traefik_")
+	path := filepath.Join(dir, "access.log")
+
+	file, err := newRotatingFile(path, 0, 0, 0)
+	require.NoError(t, err)
+
+	now := time.Date(2009, time.November, 10, 23, 0, 0, 0, time.Local)
+	file.now = func() time.Time { return now }
+
+	for _, line := range []string{"line 1\n", "line 2\n"} {
+		_, err = file.Write([]byte(line))
+		require.NoError(t, err)
+		require.NoError(t, file.Rotate())
+	}
+	require.NoError(t, file.Close())
+
+	assert.Equal(t, map[string]string{
+		"access.log":                         "",
+		"access-2009-11-10T23-00-00.000.log": "line 1\n",
+		"access-2009-11-10T23-00-00.001.log": "line 2\n",
+	}, readDir(t, dir))
+}
+
+func TestRotatingFile_renameError(t *testing.T) {
+	dir := createTempDir(t, "traefik_")
+	path := filepath.Join(dir, "access.log")
+
+	file, err := newRotatingFile(path, 0, 0, 0)
+	require.NoError(t, err)
+
+	file.rename = func(_, _ string) error {
+		return errors.New("rename error")
+	}
+
+	_, err = file.Write([]byte("line 1\n"))
+	require.NoError(t, err)
+
+	assert.Error(t, file.Rotate())
+
+	// The logs are still written to the file which could not be rotated.
+	_, err = file.Write([]byte("line 2\n"))
+	require.NoError(t, err)
+	require.NoError(t, file.Close())
+
+	assert.Equal(t, map[string]string{
+		"access.log": "line 1\nline 2\n",
+	}, readDir(t, dir))
+}
+
+func TestRotatingFile_removeBackups(t *testing.T) {
+	testCases := []struct {
+		desc          string
+		maxAge        time.Duration
+		maxBackups    int
+		expectedFiles []string
+	}{
+		{
+			desc:          "no limit",
+			expectedFiles: []string{"access-2009-11-10T23-00-00.000.log", "access-2009-11-10T23-01-00.000.log", "access-2009-11-10T23-02-00.000.log", "access.log"},
+		},
+		{
+			desc:          "max backups",
+			maxBackups:    2,
+			expectedFiles: []string{"access-2009-11-10T23-01-00.000.log", "access-2009-11-10T23-02-00.000.log", "access.log"},
+		},
+		{
+			desc:          "max age",
+			maxAge:        90 * time.Second,
+			expectedFiles: []string{"access-2009-11-10T23-01-00.000.log", "access-2009-11-10T23-02-00.000.log", "access.log"},
+		},
+		{
+			desc:          "max backups and max age",
+			maxAge:        90 * time.Second,
+			maxBackups:    1,
+			expectedFiles: []string{"access-2009-11-10T23-02-00.000.log", "access.log"},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			dir := createTempDir(t, "traefik_")
+
+			file, err := newRotatingFile(filepath.Join(dir, "access.log"), 0, test.maxAge, test.maxBackups)
+			require.NoError(t, err)
+
+			now := time.Date(2009, time.November, 10, 23, 0, 0, 0, time.Local)
+			file.now = func() time.Time { return now }
+
+			for i := 0; i < 3; i++ {
+				require.NoError(t, file.Rotate())
+				now = now.Add(time.Minute)
+			}
+			require.NoError(t, file.Close())
+
+			var files []string
+			for name := range readDir(t, dir) {
+				files = append(files, name)
+			}
+			sort.Strings(files)
+
+			assert.Equal(t, test.expectedFiles, files)
+		})
+	}
+}
+
+func readDir(t *testing.T, dir string) map[string]string {
+	t.Helper()
+
+	files, err := ioutil.ReadDir(dir)
+	require.NoError(t, err)
+
+	contents := make(map[string]string)
+	for _, file := range files {
+		content, err := ioutil.ReadFile(filepath.Join(dir, file.Name()))
+		require.NoError(t, err)
+
+		contents[file.Name()] = string(content)
+	}
+
+	return contents
+}
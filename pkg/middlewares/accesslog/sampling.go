@@ -0,0 +1,70 @@
+package accesslog
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/traefik/traefik/v2/pkg/config/dynamic"
+)
+
+// sampler keeps a deterministic share of the access logs:
+// among n access logs, the first one and then every 1/rate are kept.
+type sampler struct {
+	rate       float64
+	keepErrors bool
+
+	mu    sync.Mutex
+	total uint64
+	kept  uint64
+}
+
+func newSampler(rate float64, keepErrors bool) (*sampler, error) {
+	if rate < 0 || rate > 1 {
+		return nil, fmt.Errorf("sampling rate must be between 0 and 1, got %v", rate)
+	}
+
+	return &sampler{rate: rate, keepErrors: keepErrors}, nil
+}
+
+// keep reports whether the access log of a request with the given status code should be kept.
+func (s *sampler) keep(statusCode int) bool {
+	if s.keepErrors && statusCode >= http.StatusInternalServerError {
+		return true
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.total++
+	if float64(s.kept) < float64(s.total)*s.rate {
+		s.kept++
+		return true
+	}
+	return false
+}
+
+// routerOptions holds the access log options of a router, overriding the ones of the access logger.
+type routerOptions struct {
+	disabled bool
+	sampler  *sampler
+}
+
+// NewRouterOptions returns a FieldApply which overrides the access log options for the requests handled by a router.
+func NewRouterOptions(config *dynamic.RouterAccessLog) (FieldApply, error) {
+	options := &routerOptions{disabled: config.Disabled}
+
+	if config.Sampling != nil {
+		s, err := newSampler(config.Sampling.Rate, config.Sampling.KeepErrors)
+		if err != nil {
+			return nil, fmt.Errorf("invalid access log sampling: %w", err)
+		}
+		options.sampler = s
+	}
+
+	return func(rw http.ResponseWriter, req *http.Request, next http.Handler, data *LogData) {
+		data.router = options
+
+		next.ServeHTTP(rw, req)
+	}, nil
+}
@@ -0,0 +1,142 @@
+package accesslog
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traefik/traefik/v2/pkg/config/dynamic"
+	"github.com/traefik/traefik/v2/pkg/types"
+)
+
+func TestSampler_keep(t *testing.T) {
+	testCases := []struct {
+		desc       string
+		rate       float64
+		keepErrors bool
+		statusCode int
+		expected   int
+	}{
+		{
+			desc:       "keep all",
+			rate:       1,
+			statusCode: http.StatusOK,
+			expected:   100,
+		},
+		{
+			desc:       "keep none",
+			rate:       0,
+			statusCode: http.StatusOK,
+			expected:   0,
+		},
+		{
+			desc:       "keep a quarter",
+			rate:       0.25,
+			statusCode: http.StatusOK,
+			expected:   25,
+		},
+		{
+			desc:       "keep errors",
+			rate:       0.25,
+			keepErrors: true,
+			statusCode: http.StatusBadGateway,
+			expected:   100,
+		},
+		{
+			desc:       "keep errors with a client error",
+			rate:       0.25,
+			keepErrors: true,
+			statusCode: http.StatusNotFound,
+			expected:   25,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			s, err := newSampler(test.rate, test.keepErrors)
+			require.NoError(t, err)
+
+			var kept int
+			for i := 0; i < 100; i++ {
+				if s.keep(test.statusCode) {
+					kept++
+				}
+			}
+
+			assert.Equal(t, test.expected, kept)
+		})
+	}
+}
+
+func TestNewRouterOptions(t *testing.T) {
+	testCases := []struct {
+		desc          string
+		config        *dynamic.RouterAccessLog
+		accessLog     *types.AccessLog
+		expectedLines int
+	}{
+		{
+			desc:          "no override",
+			config:        &dynamic.RouterAccessLog{},
+			accessLog:     &types.AccessLog{},
+			expectedLines: 4,
+		},
+		{
+			desc:          "disabled",
+			config:        &dynamic.RouterAccessLog{Disabled: true},
+			accessLog:     &types.AccessLog{},
+			expectedLines: 0,
+		},
+		{
+			desc:          "sampling",
+			config:        &dynamic.RouterAccessLog{Sampling: &dynamic.AccessLogSampling{Rate: 0.5}},
+			accessLog:     &types.AccessLog{},
+			expectedLines: 2,
+		},
+		{
+			desc:          "sampling overriding the access log one",
+			config:        &dynamic.RouterAccessLog{Sampling: &dynamic.AccessLogSampling{Rate: 1}},
+			accessLog:     &types.AccessLog{Sampling: &types.AccessLogSampling{Rate: 0}},
+			expectedLines: 4,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			test.accessLog.FilePath = filepath.Join(createTempDir(t, "traefik_"), "access.log")
+			test.accessLog.Format = CommonFormat
+
+			logHandler, err := NewHandler(test.accessLog)
+			require.NoError(t, err)
+
+			options, err := NewRouterOptions(test.config)
+			require.NoError(t, err)
+
+			next := NewFieldHandler(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				rw.WriteHeader(http.StatusOK)
+			}), RouterName, "router", options)
+
+			for i := 0; i < 4; i++ {
+				logHandler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "http://localhost", nil), next)
+			}
+
+			require.NoError(t, logHandler.Close())
+
+			assert.Equal(t, test.expectedLines, lineCount(t, test.accessLog.FilePath))
+		})
+	}
+}
+
+func TestNewRouterOptions_invalidSampling(t *testing.T) {
+	_, err := NewRouterOptions(&dynamic.RouterAccessLog{Sampling: &dynamic.AccessLogSampling{Rate: -1}})
+	assert.Error(t, err)
+}
@@ -0,0 +1,155 @@
+package accesslog
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/traefik/traefik/v2/pkg/types"
+)
+
+const (
+	syslogSeverityInfo = 6
+	syslogDialTimeout  = 5 * time.Second
+)
+
+var syslogFacilities = map[string]int{
+	"kern":     0,
+	"user":     1,
+	"mail":     2,
+	"daemon":   3,
+	"auth":     4,
+	"syslog":   5,
+	"lpr":      6,
+	"news":     7,
+	"uucp":     8,
+	"cron":     9,
+	"authpriv": 10,
+	"ftp":      11,
+	"local0":   16,
+	"local1":   17,
+	"local2":   18,
+	"local3":   19,
+	"local4":   20,
+	"local5":   21,
+	"local6":   22,
+	"local7":   23,
+}
+
+// syslogWriter sends each access log line as a syslog message, in the BSD syslog format (RFC 3164),
+// with an RFC 3339 timestamp for the remote servers, as the log/syslog package does.
+// The connection is (re)established on demand, so that a restart of the syslog server loses at most one message.
+type syslogWriter struct {
+	network  string
+	address  string
+	tag      string
+	hostname string
+	priority int
+
+	mu   sync.Mutex
+	conn net.Conn
+}
+
+func newSyslogWriter(config *types.AccessLogSyslog) (*syslogWriter, error) {
+	switch config.Network {
+	case "udp", "tcp", "unix", "unixgram":
+	default:
+		return nil, fmt.Errorf("unsupported syslog network: %q", config.Network)
+	}
+
+	if config.Address == "" {
+		return nil, errors.New("empty syslog address")
+	}
+
+	facility, ok := syslogFacilities[strings.ToLower(config.Facility)]
+	if !ok {
+		return nil, fmt.Errorf("unsupported syslog facility: %q", config.Facility)
+	}
+
+	hostname, _ := os.Hostname()
+
+	return &syslogWriter{
+		network:  config.Network,
+		address:  config.Address,
+		tag:      config.Tag,
+		hostname: hostname,
+		priority: facility*8 + syslogSeverityInfo,
+	}, nil
+}
+
+func (w *syslogWriter) Write(p []byte) (int, error) {
+	msg := w.format(p)
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	err := w.write(msg)
+	if err != nil {
+		// The connection may have been closed by the server, retry once with a new connection.
+		w.close()
+		err = w.write(msg)
+	}
+	if err != nil {
+		w.close()
+		return 0, err
+	}
+
+	return len(p), nil
+}
+
+func (w *syslogWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.close()
+}
+
+// write must be called under the lock.
+func (w *syslogWriter) write(msg []byte) error {
+	if w.conn == nil {
+		conn, err := net.DialTimeout(w.network, w.address, syslogDialTimeout)
+		if err != nil {
+			return err
+		}
+		w.conn = conn
+	}
+
+	_, err := w.conn.Write(msg)
+	return err
+}
+
+// close must be called under the lock.
+func (w *syslogWriter) close() error {
+	if w.conn == nil {
+		return nil
+	}
+
+	err := w.conn.Close()
+	w.conn = nil
+	return err
+}
+
+func (w *syslogWriter) format(p []byte) []byte {
+	b := &bytes.Buffer{}
+
+	if w.network == "unix" || w.network == "unixgram" {
+		// The local syslog daemon adds the hostname itself.
+		fmt.Fprintf(b, "<%d>%s %s[%d]: ", w.priority, time.Now().Format(time.Stamp), w.tag, os.Getpid())
+	} else {
+		fmt.Fprintf(b, "<%d>%s %s %s[%d]: ", w.priority, time.Now().Format(time.RFC3339), w.hostname, w.tag, os.Getpid())
+	}
+
+	b.Write(bytes.TrimRight(p, "\n"))
+
+	// The messages sent over a stream are delimited by new lines.
+	if w.network == "tcp" || w.network == "unix" {
+		b.WriteByte('\n')
+	}
+
+	return b.Bytes()
+}
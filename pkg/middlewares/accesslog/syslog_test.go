@@ -0,0 +1,96 @@
+package accesslog
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"os"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traefik/traefik/v2/pkg/types"
+)
+
+func TestSyslogWriter_udp(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+
+	writer, err := newSyslogWriter(&types.AccessLogSyslog{Network: "udp", Address: conn.LocalAddr().String(), Tag: "traefik", Facility: "local0"})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = writer.Close() })
+
+	_, err = writer.Write([]byte("GET /foo 200\n"))
+	require.NoError(t, err)
+
+	buf := make([]byte, 1024)
+	n, _, err := conn.ReadFrom(buf)
+	require.NoError(t, err)
+
+	expected := fmt.Sprintf(`^<134>\S+ \S+ traefik\[%d\]: GET /foo 200$`, os.Getpid())
+	assert.Regexp(t, regexp.MustCompile(expected), string(buf[:n]))
+}
+
+func TestSyslogWriter_tcp(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = listener.Close() })
+
+	lines := make(chan string)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		scanner := bufio.NewScanner(conn)
+		for scanner.Scan() {
+			lines <- scanner.Text()
+		}
+	}()
+
+	writer, err := newSyslogWriter(&types.AccessLogSyslog{Network: "tcp", Address: listener.Addr().String(), Tag: "access", Facility: "daemon"})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = writer.Close() })
+
+	for _, line := range []string{"first\n", "second\n"} {
+		_, err = writer.Write([]byte(line))
+		require.NoError(t, err)
+	}
+
+	assert.Regexp(t, `^<30>\S+ \S+ access\[\d+\]: first$`, <-lines)
+	assert.Regexp(t, `^<30>\S+ \S+ access\[\d+\]: second$`, <-lines)
+}
+
+func TestNewSyslogWriter_invalid(t *testing.T) {
+	testCases := []struct {
+		desc   string
+		config types.AccessLogSyslog
+	}{
+		{
+			desc:   "unsupported network",
+			config: types.AccessLogSyslog{Network: "ip", Address: "localhost:514", Facility: "local0"},
+		},
+		{
+			desc:   "empty address",
+			config: types.AccessLogSyslog{Network: "udp", Facility: "local0"},
+		},
+		{
+			desc:   "unsupported facility",
+			config: types.AccessLogSyslog{Network: "udp", Address: "localhost:514", Facility: "foo"},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := newSyslogWriter(&test.config)
+			assert.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,160 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/opentracing/opentracing-go/ext"
+	"github.com/traefik/traefik/v2/pkg/config/dynamic"
+	"github.com/traefik/traefik/v2/pkg/log"
+	"github.com/traefik/traefik/v2/pkg/middlewares"
+	"github.com/traefik/traefik/v2/pkg/middlewares/passtlsclientcert"
+	"github.com/traefik/traefik/v2/pkg/tracing"
+)
+
+const clientCertAuthTypeName = "ClientCertAuth"
+
+// subjectOptions selects the subject attributes matched by the allow lists.
+var subjectOptions = &passtlsclientcert.DistinguishedNameOptions{
+	CommonName:       true,
+	OrganizationName: true,
+}
+
+type clientCertAuth struct {
+	next http.Handler
+	name string
+
+	commonNames   map[string]struct{}
+	organizations map[string]struct{}
+	dnsNames      map[string]struct{}
+	uris          map[string]struct{}
+	fingerprints  map[string]struct{}
+}
+
+// NewClientCertAuth creates a client certificate authorization middleware.
+func NewClientCertAuth(ctx context.Context, next http.Handler, config dynamic.ClientCertAuth, name string) (http.Handler, error) {
+	log.FromContext(middlewares.GetLoggerCtx(ctx, name, clientCertAuthTypeName)).Debug("Creating middleware")
+
+	ca := &clientCertAuth{
+		next:          next,
+		name:          name,
+		commonNames:   toSet(config.AllowedCommonNames, nil),
+		organizations: toSet(config.AllowedOrganizations, nil),
+		dnsNames:      toSet(config.AllowedDNSNames, normalizeDNSName),
+		uris:          toSet(config.AllowedURIs, nil),
+		fingerprints:  toSet(config.AllowedFingerprints, normalizeFingerprint),
+	}
+
+	for fingerprint := range ca.fingerprints {
+		if _, err := hex.DecodeString(fingerprint); err != nil || len(fingerprint) != 2*sha256.Size {
+			return nil, fmt.Errorf("invalid SHA-256 fingerprint: %s", fingerprint)
+		}
+	}
+
+	if len(ca.commonNames)+len(ca.organizations)+len(ca.dnsNames)+len(ca.uris)+len(ca.fingerprints) == 0 {
+		return nil, errors.New("empty allow lists: at least one common name, organization, DNS name, URI or fingerprint must be allowed")
+	}
+
+	return ca, nil
+}
+
+func (c *clientCertAuth) GetTracingInformation() (string, ext.SpanKindEnum) {
+	return c.name, tracing.SpanKindNoneEnum
+}
+
+func (c *clientCertAuth) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	ctx := middlewares.GetLoggerCtx(req.Context(), c.name, clientCertAuthTypeName)
+	logger := log.FromContext(ctx)
+
+	if req.TLS == nil || len(req.TLS.PeerCertificates) == 0 {
+		logger.Debug("Authorization failed: no client certificate")
+		tracing.SetErrorWithEvent(req, "Authorization failed")
+
+		http.Error(rw, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+		return
+	}
+
+	cert := req.TLS.PeerCertificates[0]
+
+	if !c.isAllowed(cert, len(req.TLS.VerifiedChains) > 0) {
+		logger.Debugf("Authorization failed: client certificate %q is not allowed", cert.Subject.CommonName)
+		tracing.SetErrorWithEvent(req, "Authorization failed")
+
+		http.Error(rw, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+		return
+	}
+
+	logger.Debugf("Authorization succeeded for the client certificate %q", cert.Subject.CommonName)
+
+	c.next.ServeHTTP(rw, req)
+}
+
+// isAllowed reports whether the certificate matches an entry of the allow lists.
+// The subject and the subject alternative names of a certificate which has not been verified
+// against the CA files of the TLS options can be forged, so only its fingerprint is matched.
+func (c *clientCertAuth) isAllowed(cert *x509.Certificate, verified bool) bool {
+	sum := sha256.Sum256(cert.Raw)
+	if _, ok := c.fingerprints[hex.EncodeToString(sum[:])]; ok {
+		return true
+	}
+
+	if !verified {
+		return false
+	}
+
+	for _, attribute := range passtlsclientcert.GetDNAttributes(subjectOptions, &cert.Subject) {
+		switch attribute.Type {
+		case "CN":
+			if _, ok := c.commonNames[attribute.Value]; ok {
+				return true
+			}
+		case "O":
+			if _, ok := c.organizations[attribute.Value]; ok {
+				return true
+			}
+		}
+	}
+
+	for _, dnsName := range cert.DNSNames {
+		if _, ok := c.dnsNames[normalizeDNSName(dnsName)]; ok {
+			return true
+		}
+	}
+
+	for _, uri := range cert.URIs {
+		if _, ok := c.uris[uri.String()]; ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+func toSet(values []string, normalize func(string) string) map[string]struct{} {
+	set := make(map[string]struct{})
+	for _, value := range values {
+		if normalize != nil {
+			value = normalize(value)
+		}
+		if value != "" {
+			set[value] = struct{}{}
+		}
+	}
+
+	return set
+}
+
+func normalizeDNSName(name string) string {
+	return strings.TrimSuffix(strings.ToLower(strings.TrimSpace(name)), ".")
+}
+
+// normalizeFingerprint accepts the fingerprints in upper or lower case, with or without colons.
+func normalizeFingerprint(fingerprint string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(fingerprint), ":", ""))
+}
@@ -0,0 +1,236 @@
+package auth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traefik/traefik/v2/pkg/config/dynamic"
+)
+
+func TestNewClientCertAuth(t *testing.T) {
+	testCases := []struct {
+		desc          string
+		config        dynamic.ClientCertAuth
+		expectedError bool
+	}{
+		{
+			desc:          "empty allow lists",
+			config:        dynamic.ClientCertAuth{AllowedCommonNames: []string{""}},
+			expectedError: true,
+		},
+		{
+			desc:   "allowed common names",
+			config: dynamic.ClientCertAuth{AllowedCommonNames: []string{"client"}},
+		},
+		{
+			desc:   "fingerprint with colons",
+			config: dynamic.ClientCertAuth{AllowedFingerprints: []string{strings.Repeat("AB:", 31) + "AB"}},
+		},
+		{
+			desc:          "fingerprint with an invalid length",
+			config:        dynamic.ClientCertAuth{AllowedFingerprints: []string{"abcd"}},
+			expectedError: true,
+		},
+		{
+			desc:          "fingerprint with invalid characters",
+			config:        dynamic.ClientCertAuth{AllowedFingerprints: []string{strings.Repeat("zz", 32)}},
+			expectedError: true,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := NewClientCertAuth(context.Background(), nil, test.config, "clientCertAuth")
+			if test.expectedError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestClientCertAuth(t *testing.T) {
+	cert := createClientCert(t)
+
+	sum := sha256.Sum256(cert.Raw)
+	fingerprint := strings.ToUpper(hex.EncodeToString(sum[:]))
+
+	testCases := []struct {
+		desc               string
+		config             dynamic.ClientCertAuth
+		tls                *tls.ConnectionState
+		expectedStatusCode int
+	}{
+		{
+			desc:               "no TLS",
+			config:             dynamic.ClientCertAuth{AllowedCommonNames: []string{"client"}},
+			expectedStatusCode: http.StatusForbidden,
+		},
+		{
+			desc:               "no client certificate",
+			config:             dynamic.ClientCertAuth{AllowedCommonNames: []string{"client"}},
+			tls:                &tls.ConnectionState{},
+			expectedStatusCode: http.StatusForbidden,
+		},
+		{
+			desc:               "allowed common name",
+			config:             dynamic.ClientCertAuth{AllowedCommonNames: []string{"other", "client"}},
+			tls:                verifiedState(cert),
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			desc:               "common name not allowed",
+			config:             dynamic.ClientCertAuth{AllowedCommonNames: []string{"other"}},
+			tls:                verifiedState(cert),
+			expectedStatusCode: http.StatusForbidden,
+		},
+		{
+			desc:               "common name is case sensitive",
+			config:             dynamic.ClientCertAuth{AllowedCommonNames: []string{"Client"}},
+			tls:                verifiedState(cert),
+			expectedStatusCode: http.StatusForbidden,
+		},
+		{
+			desc:               "allowed organization",
+			config:             dynamic.ClientCertAuth{AllowedOrganizations: []string{"Payments"}},
+			tls:                verifiedState(cert),
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			desc:               "organization not allowed",
+			config:             dynamic.ClientCertAuth{AllowedOrganizations: []string{"Billing"}},
+			tls:                verifiedState(cert),
+			expectedStatusCode: http.StatusForbidden,
+		},
+		{
+			desc:               "allowed DNS name",
+			config:             dynamic.ClientCertAuth{AllowedDNSNames: []string{"API.internal.example.com."}},
+			tls:                verifiedState(cert),
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			desc:               "DNS name not allowed",
+			config:             dynamic.ClientCertAuth{AllowedDNSNames: []string{"web.internal.example.com"}},
+			tls:                verifiedState(cert),
+			expectedStatusCode: http.StatusForbidden,
+		},
+		{
+			desc:               "allowed SPIFFE ID",
+			config:             dynamic.ClientCertAuth{AllowedURIs: []string{"spiffe://example.com/ns/prod/sa/api"}},
+			tls:                verifiedState(cert),
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			desc:               "SPIFFE ID not allowed",
+			config:             dynamic.ClientCertAuth{AllowedURIs: []string{"spiffe://example.com/ns/prod/sa/web"}},
+			tls:                verifiedState(cert),
+			expectedStatusCode: http.StatusForbidden,
+		},
+		{
+			desc:               "allowed fingerprint",
+			config:             dynamic.ClientCertAuth{AllowedFingerprints: []string{fingerprint}},
+			tls:                verifiedState(cert),
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			desc:               "allowed fingerprint of an unverified certificate",
+			config:             dynamic.ClientCertAuth{AllowedFingerprints: []string{fingerprint}},
+			tls:                &tls.ConnectionState{PeerCertificates: []*x509.Certificate{cert}},
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			desc:               "allowed common name of an unverified certificate",
+			config:             dynamic.ClientCertAuth{AllowedCommonNames: []string{"client"}},
+			tls:                &tls.ConnectionState{PeerCertificates: []*x509.Certificate{cert}},
+			expectedStatusCode: http.StatusForbidden,
+		},
+		{
+			desc: "one of the allow lists matching",
+			config: dynamic.ClientCertAuth{
+				AllowedCommonNames:   []string{"other"},
+				AllowedOrganizations: []string{"Billing"},
+				AllowedURIs:          []string{"spiffe://example.com/ns/prod/sa/api"},
+			},
+			tls:                verifiedState(cert),
+			expectedStatusCode: http.StatusOK,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {})
+
+			handler, err := NewClientCertAuth(context.Background(), next, test.config, "clientCertAuth")
+			require.NoError(t, err)
+
+			req := httptest.NewRequest(http.MethodGet, "https://localhost", nil)
+			req.TLS = test.tls
+
+			rw := httptest.NewRecorder()
+			handler.ServeHTTP(rw, req)
+
+			assert.Equal(t, test.expectedStatusCode, rw.Code)
+		})
+	}
+}
+
+func createClientCert(t *testing.T) *x509.Certificate {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	spiffeID, err := url.Parse("spiffe://example.com/ns/prod/sa/api")
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject: pkix.Name{
+			CommonName:   "client",
+			Organization: []string{"Example", "Payments"},
+		},
+		DNSNames:    []string{"api.internal.example.com"},
+		URIs:        []*url.URL{spiffeID},
+		NotBefore:   time.Now().Add(-time.Hour),
+		NotAfter:    time.Now().Add(time.Hour),
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+	require.NoError(t, err)
+
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	return cert
+}
+
+func verifiedState(cert *x509.Certificate) *tls.ConnectionState {
+	return &tls.ConnectionState{
+		PeerCertificates: []*x509.Certificate{cert},
+		VerifiedChains:   [][]*x509.Certificate{{cert}},
+	}
+}
@@ -0,0 +1,244 @@
+package auth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/traefik/traefik/v2/pkg/log"
+	"golang.org/x/sync/singleflight"
+)
+
+// jwksRetryInterval is the minimum interval between two fetches of a JSON Web Key Set,
+// when the previous fetch failed or when a token is signed with an unknown key.
+const jwksRetryInterval = 10 * time.Second
+
+// jsonWebKey is a key of a JSON Web Key Set, as defined by RFC 7517.
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	// RSA keys.
+	N string `json:"n"`
+	E string `json:"e"`
+	// EC keys.
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+	// Symmetric keys.
+	K string `json:"k"`
+}
+
+// jwks is a JSON Web Key Set fetched from a URL.
+// It is fetched again when it is older than the refresh interval,
+// or when a token is signed with a key it does not contain.
+type jwks struct {
+	url             string
+	refreshInterval time.Duration
+	client          *http.Client
+
+	// group shares a fetch between the concurrent requests, which are not blocked by the lock meanwhile.
+	group singleflight.Group
+
+	mu        sync.RWMutex
+	keys      []verificationKey
+	fetchedAt time.Time
+	failed    bool
+}
+
+func newJWKS(url string, refreshInterval time.Duration) *jwks {
+	return &jwks{
+		url:             url,
+		refreshInterval: refreshInterval,
+		client:          &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// getKeys returns the keys of the set, fetching it again if needed.
+// While the set is fetched, the previous keys are returned if they contain the key of the token,
+// otherwise the fetch is awaited.
+func (j *jwks) getKeys(ctx context.Context, kid string) []verificationKey {
+	j.mu.RLock()
+	keys := j.keys
+	refresh, hasKey := j.needsRefresh(kid)
+	j.mu.RUnlock()
+
+	if !refresh {
+		return keys
+	}
+
+	done := j.group.DoChan(j.url, func() (interface{}, error) {
+		j.refresh(ctx)
+		return nil, nil
+	})
+
+	if hasKey {
+		return keys
+	}
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		return keys
+	}
+
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+
+	return j.keys
+}
+
+// needsRefresh reports whether the set must be fetched again, and whether it contains the key.
+// It must be called under the lock.
+func (j *jwks) needsRefresh(kid string) (bool, bool) {
+	if j.fetchedAt.IsZero() {
+		return true, false
+	}
+
+	hasKey := len(j.keys) > 0 && (kid == "" || j.hasKey(kid))
+
+	age := time.Since(j.fetchedAt)
+	return age > j.refreshInterval || (j.failed || !hasKey) && age > jwksRetryInterval, hasKey
+}
+
+func (j *jwks) refresh(ctx context.Context) {
+	keys, err := j.fetch(ctx)
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	j.fetchedAt = time.Now()
+	j.failed = err != nil
+
+	if err != nil {
+		// The previous keys are kept until the set can be fetched again.
+		log.FromContext(ctx).Errorf("Unable to fetch the JSON Web Key Set from %s: %v", j.url, err)
+		return
+	}
+
+	j.keys = keys
+}
+
+func (j *jwks) hasKey(kid string) bool {
+	for _, k := range j.keys {
+		if k.id == kid {
+			return true
+		}
+	}
+	return false
+}
+
+func (j *jwks) fetch(ctx context.Context) ([]verificationKey, error) {
+	// The request context is not used, so that a client going away does not make the fetch fail.
+	req, err := http.NewRequest(http.MethodGet, j.url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := j.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	var set struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&set); err != nil {
+		return nil, fmt.Errorf("unable to decode the key set: %w", err)
+	}
+
+	logger := log.FromContext(ctx)
+
+	var keys []verificationKey
+	for _, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+
+		key, err := jwk.decode()
+		if err != nil {
+			logger.Warnf("Ignoring the key %q of the JSON Web Key Set from %s: %v", jwk.Kid, j.url, err)
+			continue
+		}
+
+		keys = append(keys, verificationKey{id: jwk.Kid, key: key})
+	}
+
+	return keys, nil
+}
+
+func (k jsonWebKey) decode() (interface{}, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		if !e.IsInt64() || e.Int64() > 1<<31-1 {
+			return nil, fmt.Errorf("invalid exponent: %s", k.E)
+		}
+
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve: %q", k.Crv)
+		}
+
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+
+		if !curve.IsOnCurve(x, y) {
+			return nil, fmt.Errorf("the point is not on the curve %s", k.Crv)
+		}
+
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+
+	case "oct":
+		return base64.RawURLEncoding.DecodeString(k.K)
+
+	default:
+		return nil, fmt.Errorf("unsupported key type: %q", k.Kty)
+	}
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(b), nil
+}
@@ -0,0 +1,320 @@
+package auth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/opentracing/opentracing-go/ext"
+	"github.com/traefik/traefik/v2/pkg/config/dynamic"
+	"github.com/traefik/traefik/v2/pkg/log"
+	"github.com/traefik/traefik/v2/pkg/middlewares"
+	traefiktls "github.com/traefik/traefik/v2/pkg/tls"
+	"github.com/traefik/traefik/v2/pkg/tracing"
+)
+
+const (
+	jwtTypeName  = "JWTAuth"
+	bearerPrefix = "Bearer "
+)
+
+// verificationKey is a key able to verify the signature of a token.
+type verificationKey struct {
+	id  string
+	key interface{} // *rsa.PublicKey, *ecdsa.PublicKey or []byte.
+}
+
+// tokenValidator verifies the signature and the registered claims of JSON Web Tokens.
+type tokenValidator struct {
+	keys     []verificationKey
+	jwks     *jwks
+	issuer   string
+	audience string
+}
+
+type jwtAuth struct {
+	tokenValidator
+
+	next          http.Handler
+	name          string
+	claimsHeaders map[string]string
+	removeHeader  bool
+}
+
+// NewJWT creates a JWT authentication middleware.
+func NewJWT(ctx context.Context, next http.Handler, config dynamic.JWT, name string) (http.Handler, error) {
+	log.FromContext(middlewares.GetLoggerCtx(ctx, name, jwtTypeName)).Debug("Creating middleware")
+
+	ja := &jwtAuth{
+		tokenValidator: tokenValidator{
+			issuer:   config.Issuer,
+			audience: config.Audience,
+		},
+		next:          next,
+		name:          name,
+		claimsHeaders: config.ClaimsHeaders,
+		removeHeader:  config.RemoveHeader,
+	}
+
+	for _, k := range config.Keys {
+		key, err := parsePublicKey(traefiktls.FileOrContent(k))
+		if err != nil {
+			return nil, err
+		}
+		ja.keys = append(ja.keys, verificationKey{key: key})
+	}
+
+	if config.Secret != "" {
+		ja.keys = append(ja.keys, verificationKey{key: []byte(config.Secret)})
+	}
+
+	if config.JWKSURL != "" {
+		refreshInterval := time.Duration(config.JWKSRefreshInterval)
+		if refreshInterval <= 0 {
+			refreshInterval = 15 * time.Minute
+		}
+		ja.jwks = newJWKS(config.JWKSURL, refreshInterval)
+	}
+
+	if len(ja.keys) == 0 && ja.jwks == nil {
+		return nil, errors.New("no keys to verify the tokens: at least one of keys, secret and jwksURL must be set")
+	}
+
+	return ja, nil
+}
+
+func (j *jwtAuth) GetTracingInformation() (string, ext.SpanKindEnum) {
+	return j.name, tracing.SpanKindNoneEnum
+}
+
+func (j *jwtAuth) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	ctx := middlewares.GetLoggerCtx(req.Context(), j.name, jwtTypeName)
+	logger := log.FromContext(ctx)
+
+	token, ok := bearerToken(req.Header.Get(authorizationHeader))
+	if !ok {
+		logger.Debug("Authentication failed: no bearer token")
+		tracing.SetErrorWithEvent(req, "Authentication failed")
+
+		rw.Header().Set("WWW-Authenticate", fmt.Sprintf("Bearer realm=%q", defaultRealm))
+		http.Error(rw, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		return
+	}
+
+	claims, err := j.validate(ctx, token)
+	if err != nil {
+		logger.Debugf("Authentication failed: %v", err)
+		tracing.SetErrorWithEvent(req, "Authentication failed")
+
+		rw.Header().Set("WWW-Authenticate", fmt.Sprintf("Bearer realm=%q, error=\"invalid_token\"", defaultRealm))
+		http.Error(rw, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		return
+	}
+
+	logger.Debug("Authentication succeeded")
+
+	setClaimsHeaders(req, j.claimsHeaders, claims)
+
+	if j.removeHeader {
+		logger.Debug("Removing authorization header")
+		req.Header.Del(authorizationHeader)
+	}
+
+	j.next.ServeHTTP(rw, req)
+}
+
+// bearerToken returns the token of a bearer Authorization header.
+// The authentication scheme is case-insensitive (RFC 7235).
+func bearerToken(authorization string) (string, bool) {
+	if len(authorization) < len(bearerPrefix) || !strings.EqualFold(authorization[:len(bearerPrefix)], bearerPrefix) {
+		return "", false
+	}
+
+	return strings.TrimSpace(authorization[len(bearerPrefix):]), true
+}
+
+// validate verifies the signature of the token and its registered claims, and returns its claims.
+func (j *tokenValidator) validate(ctx context.Context, raw string) (jwt.MapClaims, error) {
+	parts := strings.Split(raw, ".")
+	if len(parts) != 3 {
+		return nil, errors.New("malformed token")
+	}
+
+	claims := jwt.MapClaims{}
+	token, _, err := new(jwt.Parser).ParseUnverified(raw, claims)
+	if err != nil {
+		return nil, err
+	}
+
+	kid, _ := token.Header["kid"].(string)
+
+	keys := j.keys
+	if j.jwks != nil {
+		keys = append(keys[:len(keys):len(keys)], j.jwks.getKeys(ctx, kid)...)
+	}
+
+	if !verifySignature(token.Method, strings.Join(parts[:2], "."), parts[2], kid, keys) {
+		return nil, fmt.Errorf("invalid signature for the algorithm %q", token.Method.Alg())
+	}
+
+	// Checks the exp, iat and nbf claims.
+	if err := claims.Valid(); err != nil {
+		return nil, err
+	}
+
+	// Valid accepts the tokens without exp claim, which would never expire.
+	if !claims.VerifyExpiresAt(time.Now().Unix(), true) {
+		return nil, errors.New("missing exp claim")
+	}
+
+	if j.issuer != "" && !claims.VerifyIssuer(j.issuer, true) {
+		return nil, fmt.Errorf("invalid issuer: %v", claims["iss"])
+	}
+
+	if j.audience != "" && !hasAudience(claims["aud"], j.audience) {
+		return nil, fmt.Errorf("invalid audience: %v", claims["aud"])
+	}
+
+	return claims, nil
+}
+
+// verifySignature checks the signature with the keys matching the algorithm of the token.
+// When the token has a key ID, only the keys with the same ID, or without ID, are used.
+func verifySignature(method jwt.SigningMethod, signingString, signature, kid string, keys []verificationKey) bool {
+	for _, k := range keys {
+		if kid != "" && k.id != "" && k.id != kid {
+			continue
+		}
+
+		// The type of the key must match the algorithm,
+		// otherwise a public key could be used as an HMAC secret.
+		switch method.(type) {
+		case *jwt.SigningMethodRSA, *jwt.SigningMethodRSAPSS:
+			if _, ok := k.key.(*rsa.PublicKey); !ok {
+				continue
+			}
+		case *jwt.SigningMethodECDSA:
+			if _, ok := k.key.(*ecdsa.PublicKey); !ok {
+				continue
+			}
+		case *jwt.SigningMethodHMAC:
+			if _, ok := k.key.([]byte); !ok {
+				continue
+			}
+		default:
+			return false
+		}
+
+		if method.Verify(signingString, signature, k.key) == nil {
+			return true
+		}
+	}
+
+	return false
+}
+
+// setClaimsHeaders copies the claims into the request headers, the keys of claimsHeaders being the header names.
+func setClaimsHeaders(req *http.Request, claimsHeaders map[string]string, claims map[string]interface{}) {
+	for header, claim := range claimsHeaders {
+		// The headers sent by the client are never forwarded, so that they cannot be mistaken for claims.
+		req.Header.Del(header)
+
+		if value, ok := claimValue(claims[claim]); ok {
+			req.Header.Set(header, value)
+		}
+	}
+}
+
+func hasAudience(aud interface{}, audience string) bool {
+	switch value := aud.(type) {
+	case string:
+		return value == audience
+	case []interface{}:
+		for _, v := range value {
+			if s, ok := v.(string); ok && s == audience {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// claimValue returns the string representation of a claim to be set in a header.
+// Arrays of strings are joined with commas, and objects are JSON encoded.
+func claimValue(claim interface{}) (string, bool) {
+	switch value := claim.(type) {
+	case nil:
+		return "", false
+	case string:
+		return value, true
+	case []interface{}:
+		var values []string
+		for _, v := range value {
+			s, ok := v.(string)
+			if !ok {
+				return jsonClaimValue(claim)
+			}
+			values = append(values, s)
+		}
+		return strings.Join(values, ","), true
+	default:
+		return jsonClaimValue(claim)
+	}
+}
+
+func jsonClaimValue(claim interface{}) (string, bool) {
+	b, err := json.Marshal(claim)
+	if err != nil {
+		return "", false
+	}
+	return string(b), true
+}
+
+func parsePublicKey(key traefiktls.FileOrContent) (interface{}, error) {
+	content, err := key.Read()
+	if err != nil {
+		return nil, fmt.Errorf("unable to read the key: %w", err)
+	}
+
+	block, _ := pem.Decode(content)
+	if block == nil {
+		return nil, errors.New("unable to decode the PEM encoded key")
+	}
+
+	var publicKey interface{}
+	switch block.Type {
+	case "CERTIFICATE":
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse the certificate: %w", err)
+		}
+		publicKey = cert.PublicKey
+	case "RSA PUBLIC KEY":
+		publicKey, err = x509.ParsePKCS1PublicKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse the public key: %w", err)
+		}
+	default:
+		publicKey, err = x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse the public key: %w", err)
+		}
+	}
+
+	switch publicKey.(type) {
+	case *rsa.PublicKey, *ecdsa.PublicKey:
+		return publicKey, nil
+	default:
+		return nil, fmt.Errorf("unsupported public key type: %T", publicKey)
+	}
+}
@@ -0,0 +1,369 @@
+package auth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traefik/traefik/v2/pkg/config/dynamic"
+)
+
+func signToken(t *testing.T, method jwt.SigningMethod, key interface{}, kid string, claims jwt.MapClaims) string {
+	t.Helper()
+
+	token := jwt.NewWithClaims(method, claims)
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+
+	signed, err := token.SignedString(key)
+	require.NoError(t, err)
+
+	return signed
+}
+
+func encodePublicKey(t *testing.T, key interface{}) string {
+	t.Helper()
+
+	der, err := x509.MarshalPKIXPublicKey(key)
+	require.NoError(t, err)
+
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+}
+
+func TestJWTAuth(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	otherRSAKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	rsaPEM := encodePublicKey(t, &rsaKey.PublicKey)
+
+	config := dynamic.JWT{
+		Keys:     []string{rsaPEM, encodePublicKey(t, &ecKey.PublicKey)},
+		Secret:   "secret",
+		Issuer:   "https://issuer.example.com",
+		Audience: "api",
+	}
+
+	validClaims := func() jwt.MapClaims {
+		return jwt.MapClaims{
+			"iss": "https://issuer.example.com",
+			"aud": "api",
+			"sub": "user",
+			"exp": time.Now().Add(time.Hour).Unix(),
+		}
+	}
+
+	withClaim := func(name string, value interface{}) jwt.MapClaims {
+		claims := validClaims()
+		claims[name] = value
+		return claims
+	}
+
+	withoutClaim := func(name string) jwt.MapClaims {
+		claims := validClaims()
+		delete(claims, name)
+		return claims
+	}
+
+	testCases := []struct {
+		desc           string
+		authorization  string
+		expectedStatus int
+	}{
+		{
+			desc:           "no token",
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			desc:           "not a bearer token",
+			authorization:  "Basic dGVzdDp0ZXN0",
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			desc:           "malformed token",
+			authorization:  "Bearer foo.bar",
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			desc:           "HS256",
+			authorization:  "Bearer " + signToken(t, jwt.SigningMethodHS256, []byte("secret"), "", validClaims()),
+			expectedStatus: http.StatusOK,
+		},
+		{
+			desc:           "RS256",
+			authorization:  "Bearer " + signToken(t, jwt.SigningMethodRS256, rsaKey, "", validClaims()),
+			expectedStatus: http.StatusOK,
+		},
+		{
+			desc:           "lowercase scheme",
+			authorization:  "bearer " + signToken(t, jwt.SigningMethodRS256, rsaKey, "", validClaims()),
+			expectedStatus: http.StatusOK,
+		},
+		{
+			desc:           "PS384",
+			authorization:  "Bearer " + signToken(t, jwt.SigningMethodPS384, rsaKey, "", validClaims()),
+			expectedStatus: http.StatusOK,
+		},
+		{
+			desc:           "ES256",
+			authorization:  "Bearer " + signToken(t, jwt.SigningMethodES256, ecKey, "", validClaims()),
+			expectedStatus: http.StatusOK,
+		},
+		{
+			desc:           "unknown key",
+			authorization:  "Bearer " + signToken(t, jwt.SigningMethodRS256, otherRSAKey, "", validClaims()),
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			desc:           "wrong secret",
+			authorization:  "Bearer " + signToken(t, jwt.SigningMethodHS256, []byte("foo"), "", validClaims()),
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			desc:           "public key used as an HMAC secret",
+			authorization:  "Bearer " + signToken(t, jwt.SigningMethodHS256, []byte(rsaPEM), "", validClaims()),
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			desc:           "none algorithm",
+			authorization:  "Bearer " + signToken(t, jwt.SigningMethodNone, jwt.UnsafeAllowNoneSignatureType, "", validClaims()),
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			desc:           "expired",
+			authorization:  "Bearer " + signToken(t, jwt.SigningMethodHS256, []byte("secret"), "", withClaim("exp", time.Now().Add(-time.Minute).Unix())),
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			desc:           "without expiration",
+			authorization:  "Bearer " + signToken(t, jwt.SigningMethodHS256, []byte("secret"), "", withoutClaim("exp")),
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			desc:           "not yet valid",
+			authorization:  "Bearer " + signToken(t, jwt.SigningMethodHS256, []byte("secret"), "", withClaim("nbf", time.Now().Add(time.Minute).Unix())),
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			desc:           "wrong issuer",
+			authorization:  "Bearer " + signToken(t, jwt.SigningMethodHS256, []byte("secret"), "", withClaim("iss", "https://other.example.com")),
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			desc:           "wrong audience",
+			authorization:  "Bearer " + signToken(t, jwt.SigningMethodHS256, []byte("secret"), "", withClaim("aud", "other")),
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			desc:           "audience list",
+			authorization:  "Bearer " + signToken(t, jwt.SigningMethodHS256, []byte("secret"), "", withClaim("aud", []string{"other", "api"})),
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {})
+
+	handler, err := NewJWT(context.Background(), next, config, "jwt")
+	require.NoError(t, err)
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			req := httptest.NewRequest(http.MethodGet, "http://example.com", nil)
+			if test.authorization != "" {
+				req.Header.Set("Authorization", test.authorization)
+			}
+
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, req)
+
+			assert.Equal(t, test.expectedStatus, recorder.Code)
+			if test.expectedStatus == http.StatusUnauthorized {
+				assert.Contains(t, recorder.Header().Get("WWW-Authenticate"), "Bearer")
+			}
+		})
+	}
+}
+
+func TestJWTAuth_claimsHeaders(t *testing.T) {
+	config := dynamic.JWT{
+		Secret: "secret",
+		ClaimsHeaders: map[string]string{
+			"X-User":   "sub",
+			"X-Groups": "groups",
+			"X-Admin":  "admin",
+			"X-Tenant": "tenant",
+		},
+		RemoveHeader: true,
+	}
+
+	var forwarded http.Header
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		forwarded = req.Header
+	})
+
+	handler, err := NewJWT(context.Background(), next, config, "jwt")
+	require.NoError(t, err)
+
+	token := signToken(t, jwt.SigningMethodHS256, []byte("secret"), "", jwt.MapClaims{
+		"sub":    "user",
+		"groups": []string{"dev", "ops"},
+		"admin":  true,
+		"exp":    time.Now().Add(time.Hour).Unix(),
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "http://example.com", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("X-Tenant", "spoofed")
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, req)
+
+	require.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "user", forwarded.Get("X-User"))
+	assert.Equal(t, "dev,ops", forwarded.Get("X-Groups"))
+	assert.Equal(t, "true", forwarded.Get("X-Admin"))
+	assert.Empty(t, forwarded.Values("X-Tenant"))
+	assert.Empty(t, forwarded.Get("Authorization"))
+}
+
+func TestJWTAuth_jwks(t *testing.T) {
+	firstKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	secondKey, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	require.NoError(t, err)
+
+	rsaJWK := map[string]string{
+		"kty": "RSA",
+		"kid": "first",
+		"use": "sig",
+		"n":   base64.RawURLEncoding.EncodeToString(firstKey.N.Bytes()),
+		"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(firstKey.E)).Bytes()),
+	}
+	ecJWK := map[string]string{
+		"kty": "EC",
+		"kid": "second",
+		"crv": "P-384",
+		"x":   base64.RawURLEncoding.EncodeToString(secondKey.X.Bytes()),
+		"y":   base64.RawURLEncoding.EncodeToString(secondKey.Y.Bytes()),
+	}
+
+	var rotated int32
+	var fetches int32
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		atomic.AddInt32(&fetches, 1)
+
+		keys := []map[string]string{rsaJWK}
+		if atomic.LoadInt32(&rotated) == 1 {
+			keys = []map[string]string{ecJWK}
+		}
+
+		_ = json.NewEncoder(rw).Encode(map[string]interface{}{"keys": keys})
+	}))
+	defer server.Close()
+
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {})
+
+	handler, err := NewJWT(context.Background(), next, dynamic.JWT{JWKSURL: server.URL}, "jwt")
+	require.NoError(t, err)
+
+	serve := func(token string) int {
+		req := httptest.NewRequest(http.MethodGet, "http://example.com", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, req)
+
+		return recorder.Code
+	}
+
+	firstToken := signToken(t, jwt.SigningMethodRS256, firstKey, "first", jwt.MapClaims{"sub": "user", "exp": time.Now().Add(time.Hour).Unix()})
+	secondToken := signToken(t, jwt.SigningMethodES384, secondKey, "second", jwt.MapClaims{"sub": "user", "exp": time.Now().Add(time.Hour).Unix()})
+
+	assert.Equal(t, http.StatusOK, serve(firstToken))
+	assert.Equal(t, http.StatusOK, serve(firstToken))
+	assert.Equal(t, int32(1), atomic.LoadInt32(&fetches))
+
+	// The key set is not fetched again right away for an unknown key.
+	atomic.StoreInt32(&rotated, 1)
+	assert.Equal(t, http.StatusUnauthorized, serve(secondToken))
+	assert.Equal(t, int32(1), atomic.LoadInt32(&fetches))
+
+	// Once the retry interval is elapsed, the rotated key set is fetched.
+	ja := handler.(*jwtAuth)
+	ja.jwks.mu.Lock()
+	ja.jwks.fetchedAt = time.Now().Add(-2 * jwksRetryInterval)
+	ja.jwks.mu.Unlock()
+
+	assert.Equal(t, http.StatusOK, serve(secondToken))
+	assert.Equal(t, http.StatusUnauthorized, serve(firstToken))
+	assert.Equal(t, int32(2), atomic.LoadInt32(&fetches))
+}
+
+func TestJWKS_getKeys_stale(t *testing.T) {
+	release := make(chan struct{})
+	var fetches int32
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if atomic.AddInt32(&fetches, 1) > 1 {
+			<-release
+		}
+
+		_ = json.NewEncoder(rw).Encode(map[string]interface{}{
+			"keys": []map[string]string{{"kty": "oct", "kid": "first", "k": "c2VjcmV0"}},
+		})
+	}))
+	defer server.Close()
+
+	set := newJWKS(server.URL, time.Minute)
+	require.Len(t, set.getKeys(context.Background(), "first"), 1)
+
+	set.mu.Lock()
+	set.fetchedAt = time.Now().Add(-2 * time.Minute)
+	set.mu.Unlock()
+
+	// While the expired set is fetched again, the requests are not blocked and get the previous keys.
+	for i := 0; i < 3; i++ {
+		assert.Len(t, set.getKeys(context.Background(), "first"), 1)
+	}
+
+	close(release)
+
+	assert.Eventually(t, func() bool {
+		set.mu.RLock()
+		defer set.mu.RUnlock()
+
+		return time.Since(set.fetchedAt) < time.Minute
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// The concurrent requests shared the same fetch.
+	assert.Equal(t, int32(2), atomic.LoadInt32(&fetches))
+}
+
+func TestNewJWT_noKeys(t *testing.T) {
+	_, err := NewJWT(context.Background(), http.NotFoundHandler(), dynamic.JWT{}, "jwt")
+	assert.Error(t, err)
+}
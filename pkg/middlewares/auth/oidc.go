@@ -0,0 +1,654 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/opentracing/opentracing-go/ext"
+	"github.com/traefik/traefik/v2/pkg/config/dynamic"
+	"github.com/traefik/traefik/v2/pkg/log"
+	"github.com/traefik/traefik/v2/pkg/middlewares"
+	"github.com/traefik/traefik/v2/pkg/tracing"
+	"golang.org/x/oauth2"
+	"golang.org/x/sync/singleflight"
+)
+
+const (
+	oidcTypeName = "OIDCAuth"
+
+	// oidcLoginTimeout is the maximum duration between the redirection of a user to the OpenID Provider and the callback.
+	oidcLoginTimeout = 10 * time.Minute
+	// maxCookieSize is the size above which the browsers may refuse a cookie.
+	maxCookieSize = 4096
+)
+
+// oidcProviderMetadata is the part of the OpenID Provider configuration used by the middleware.
+type oidcProviderMetadata struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+	EndSessionEndpoint    string `json:"end_session_endpoint"`
+}
+
+// oidcProvider is a discovered OpenID Provider.
+type oidcProvider struct {
+	metadata  oidcProviderMetadata
+	oauth2    oauth2.Config
+	validator *tokenValidator
+}
+
+type oidcAuth struct {
+	next   http.Handler
+	name   string
+	config dynamic.OIDC
+	codec  *cookieCodec
+	client *http.Client
+
+	// discovery shares the discovery of the OpenID Provider between the concurrent requests, which are not blocked by the lock meanwhile.
+	discovery singleflight.Group
+	// refreshes shares the refresh of the tokens of a session between its concurrent requests, keyed by refresh token,
+	// as the OpenID Provider may rotate the refresh tokens, and then refuse their reuse.
+	refreshes singleflight.Group
+
+	mu           sync.Mutex
+	provider     *oidcProvider
+	discoveredAt time.Time
+}
+
+// NewOIDC creates an OpenID Connect authentication middleware.
+func NewOIDC(ctx context.Context, next http.Handler, config dynamic.OIDC, name string) (http.Handler, error) {
+	log.FromContext(middlewares.GetLoggerCtx(ctx, name, oidcTypeName)).Debug("Creating middleware")
+
+	if config.Issuer == "" || config.ClientID == "" {
+		return nil, errors.New("issuer and clientID must be set")
+	}
+
+	if config.SessionSecret == "" {
+		return nil, errors.New("sessionSecret must be set")
+	}
+
+	defaults := dynamic.OIDC{}
+	defaults.SetDefaults()
+
+	if len(config.Scopes) == 0 {
+		config.Scopes = defaults.Scopes
+	}
+	if config.CallbackPath == "" {
+		config.CallbackPath = defaults.CallbackPath
+	}
+	if config.LogoutPath == "" {
+		config.LogoutPath = defaults.LogoutPath
+	}
+	if config.SessionCookieName == "" {
+		config.SessionCookieName = defaults.SessionCookieName
+	}
+	if config.SessionMaxAge <= 0 {
+		config.SessionMaxAge = defaults.SessionMaxAge
+	}
+
+	codec, err := newCookieCodec(config.SessionSecret)
+	if err != nil {
+		return nil, err
+	}
+
+	return &oidcAuth{
+		next:   next,
+		name:   name,
+		config: config,
+		codec:  codec,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}, nil
+}
+
+func (o *oidcAuth) GetTracingInformation() (string, ext.SpanKindEnum) {
+	return o.name, tracing.SpanKindNoneEnum
+}
+
+func (o *oidcAuth) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	ctx := middlewares.GetLoggerCtx(req.Context(), o.name, oidcTypeName)
+	logger := log.FromContext(ctx)
+
+	provider, err := o.getProvider(ctx)
+	if err != nil {
+		logger.Errorf("Unable to discover the OpenID Provider %s: %v", o.config.Issuer, err)
+		tracing.SetErrorWithEvent(req, "OpenID Provider discovery failed")
+
+		http.Error(rw, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	switch req.URL.Path {
+	case o.config.CallbackPath:
+		o.serveCallback(ctx, rw, req, provider)
+		return
+	case o.config.LogoutPath:
+		o.serveLogout(rw, req, provider)
+		return
+	}
+
+	session, err := o.getSession(ctx, rw, req, provider)
+	if err != nil {
+		logger.Debugf("Authentication required: %v", err)
+
+		// Only the navigations of browsers can be redirected to the login page of the OpenID Provider.
+		if req.Method != http.MethodGet || req.Header.Get("X-Requested-With") == "XMLHttpRequest" {
+			tracing.SetErrorWithEvent(req, "Authentication failed")
+			http.Error(rw, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+			return
+		}
+
+		o.redirectToProvider(ctx, rw, req, provider)
+		return
+	}
+
+	logger.Debug("Authentication succeeded")
+
+	removeCookies(req, o.config.SessionCookieName, o.stateCookieName())
+	setClaimsHeaders(req, o.config.ClaimsHeaders, session.Claims)
+
+	if o.config.ForwardAccessToken {
+		req.Header.Set(authorizationHeader, bearerPrefix+session.AccessToken)
+	}
+
+	o.next.ServeHTTP(rw, req)
+}
+
+// getSession returns the session of the request, refreshing its tokens if they are expired.
+func (o *oidcAuth) getSession(ctx context.Context, rw http.ResponseWriter, req *http.Request, provider *oidcProvider) (*oidcSession, error) {
+	cookie, err := req.Cookie(o.config.SessionCookieName)
+	if err != nil {
+		return nil, errors.New("no session cookie")
+	}
+
+	var session oidcSession
+	if err := o.codec.decode(o.config.SessionCookieName, cookie.Value, &session); err != nil {
+		return nil, fmt.Errorf("invalid session cookie: %w", err)
+	}
+
+	now := time.Now()
+	if now.After(time.Unix(session.Created, 0).Add(time.Duration(o.config.SessionMaxAge))) {
+		return nil, errors.New("session expired")
+	}
+
+	if now.Before(time.Unix(session.Expiry, 0)) {
+		return &session, nil
+	}
+
+	if session.RefreshToken == "" {
+		return nil, errors.New("tokens expired")
+	}
+
+	refreshed, err := o.refreshSession(ctx, req, provider, &session)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := o.setSessionCookie(ctx, rw, req, refreshed); err != nil {
+		return nil, err
+	}
+
+	return refreshed, nil
+}
+
+// refreshSession refreshes the tokens of a session, sharing the refresh between the concurrent requests of the session.
+func (o *oidcAuth) refreshSession(ctx context.Context, req *http.Request, provider *oidcProvider, session *oidcSession) (*oidcSession, error) {
+	done := o.refreshes.DoChan(session.RefreshToken, func() (interface{}, error) {
+		// The request context is not used, so that a client going away does not lose the new refresh token of the other requests.
+		token, err := o.oauth2Config(provider, req).
+			TokenSource(o.clientContext(context.Background()), &oauth2.Token{RefreshToken: session.RefreshToken}).
+			Token()
+		if err != nil {
+			return nil, fmt.Errorf("unable to refresh the tokens: %w", err)
+		}
+
+		refreshed, err := o.newSession(ctx, provider, token, "")
+		if err != nil {
+			return nil, err
+		}
+
+		// A refresh response is not required to contain an ID token.
+		if refreshed.Claims == nil {
+			refreshed.Claims = session.Claims
+		}
+		refreshed.Created = session.Created
+
+		return refreshed, nil
+	})
+
+	select {
+	case result := <-done:
+		if result.Err != nil {
+			return nil, result.Err
+		}
+		return result.Val.(*oidcSession), nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// newSession creates a session from the tokens returned by the OpenID Provider.
+// When nonce is not empty, the token response must contain an ID token with the same nonce.
+func (o *oidcAuth) newSession(ctx context.Context, provider *oidcProvider, token *oauth2.Token, nonce string) (*oidcSession, error) {
+	now := time.Now()
+
+	session := &oidcSession{
+		RefreshToken: token.RefreshToken,
+		Expiry:       token.Expiry.Unix(),
+		Created:      now.Unix(),
+	}
+
+	// Without expiration time, the tokens are valid as long as the session.
+	if token.Expiry.IsZero() {
+		session.Expiry = now.Add(time.Duration(o.config.SessionMaxAge)).Unix()
+	}
+
+	if o.config.ForwardAccessToken {
+		session.AccessToken = token.AccessToken
+	}
+
+	rawIDToken, _ := token.Extra("id_token").(string)
+	if rawIDToken == "" {
+		if nonce != "" {
+			return nil, errors.New("no ID token in the token response")
+		}
+		return session, nil
+	}
+
+	claims, err := provider.validator.validate(ctx, rawIDToken)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ID token: %w", err)
+	}
+
+	if nonce != "" && claims["nonce"] != nonce {
+		return nil, errors.New("invalid ID token: nonce mismatch")
+	}
+
+	if token.Expiry.IsZero() {
+		if exp, ok := claims["exp"].(float64); ok {
+			session.Expiry = int64(exp)
+		}
+	}
+
+	session.Claims = map[string]interface{}{"sub": claims["sub"]}
+	for _, claim := range o.config.ClaimsHeaders {
+		if value, ok := claims[claim]; ok {
+			session.Claims[claim] = value
+		}
+	}
+
+	return session, nil
+}
+
+// redirectToProvider starts the authorization code flow, with a PKCE code challenge.
+func (o *oidcAuth) redirectToProvider(ctx context.Context, rw http.ResponseWriter, req *http.Request, provider *oidcProvider) {
+	state, err := newLoginState(req.URL.RequestURI())
+	if err != nil {
+		log.FromContext(ctx).Errorf("Unable to create the login state: %v", err)
+		http.Error(rw, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	value, err := o.codec.encode(o.stateCookieName(), state)
+	if err != nil {
+		log.FromContext(ctx).Errorf("Unable to encode the login state: %v", err)
+		http.Error(rw, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	http.SetCookie(rw, &http.Cookie{
+		Name:     o.stateCookieName(),
+		Value:    value,
+		Path:     "/",
+		MaxAge:   int(oidcLoginTimeout.Seconds()),
+		Secure:   isSecure(req),
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	challenge := sha256.Sum256([]byte(state.Verifier))
+
+	authURL := o.oauth2Config(provider, req).AuthCodeURL(state.State,
+		oauth2.SetAuthURLParam("nonce", state.Nonce),
+		oauth2.SetAuthURLParam("code_challenge", base64.RawURLEncoding.EncodeToString(challenge[:])),
+		oauth2.SetAuthURLParam("code_challenge_method", "S256"),
+	)
+
+	http.Redirect(rw, req, authURL, http.StatusFound)
+}
+
+// serveCallback exchanges the authorization code for the tokens, and opens the session.
+func (o *oidcAuth) serveCallback(ctx context.Context, rw http.ResponseWriter, req *http.Request, provider *oidcProvider) {
+	logger := log.FromContext(ctx)
+
+	state, err := o.checkCallback(req)
+	if err != nil {
+		logger.Debugf("Invalid callback: %v", err)
+		tracing.SetErrorWithEvent(req, "Authentication failed")
+
+		http.Error(rw, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		return
+	}
+
+	token, err := o.oauth2Config(provider, req).Exchange(o.clientContext(ctx), req.URL.Query().Get("code"),
+		oauth2.SetAuthURLParam("code_verifier", state.Verifier))
+	if err != nil {
+		logger.Debugf("Unable to exchange the authorization code: %v", err)
+		tracing.SetErrorWithEvent(req, "Authentication failed")
+
+		http.Error(rw, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		return
+	}
+
+	session, err := o.newSession(ctx, provider, token, state.Nonce)
+	if err != nil {
+		logger.Debugf("Unable to create the session: %v", err)
+		tracing.SetErrorWithEvent(req, "Authentication failed")
+
+		http.Error(rw, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		return
+	}
+
+	if err := o.setSessionCookie(ctx, rw, req, session); err != nil {
+		logger.Errorf("Unable to set the session cookie: %v", err)
+		http.Error(rw, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	o.deleteCookie(rw, req, o.stateCookieName())
+
+	http.Redirect(rw, req, state.Redirect, http.StatusFound)
+}
+
+func (o *oidcAuth) checkCallback(req *http.Request) (*oidcLoginState, error) {
+	query := req.URL.Query()
+	if errCode := query.Get("error"); errCode != "" {
+		return nil, fmt.Errorf("authorization error: %s: %s", errCode, query.Get("error_description"))
+	}
+
+	cookie, err := req.Cookie(o.stateCookieName())
+	if err != nil {
+		return nil, errors.New("no state cookie")
+	}
+
+	var state oidcLoginState
+	if err := o.codec.decode(o.stateCookieName(), cookie.Value, &state); err != nil {
+		return nil, fmt.Errorf("invalid state cookie: %w", err)
+	}
+
+	if time.Now().After(time.Unix(state.Expiry, 0)) {
+		return nil, errors.New("login expired")
+	}
+
+	if query.Get("state") != state.State {
+		return nil, errors.New("state mismatch")
+	}
+
+	// Only redirects to a path of the same host, so that the callback cannot be used as an open redirect.
+	if !strings.HasPrefix(state.Redirect, "/") || strings.HasPrefix(state.Redirect, "//") {
+		state.Redirect = "/"
+	}
+
+	return &state, nil
+}
+
+// serveLogout closes the session, and ends the session of the OpenID Provider when it supports it.
+// The logout must be a POST request from the same site, so that another site cannot log the users out.
+func (o *oidcAuth) serveLogout(rw http.ResponseWriter, req *http.Request, provider *oidcProvider) {
+	if req.Method != http.MethodPost {
+		rw.Header().Set("Allow", http.MethodPost)
+		http.Error(rw, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	if isCrossSite(req) {
+		tracing.SetErrorWithEvent(req, "Cross-site logout refused")
+		http.Error(rw, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+		return
+	}
+
+	o.deleteCookie(rw, req, o.config.SessionCookieName)
+
+	redirect := o.config.PostLogoutRedirectURL
+	if provider.metadata.EndSessionEndpoint != "" {
+		endSessionURL, err := url.Parse(provider.metadata.EndSessionEndpoint)
+		if err == nil {
+			query := endSessionURL.Query()
+			query.Set("client_id", o.config.ClientID)
+			if o.config.PostLogoutRedirectURL != "" {
+				query.Set("post_logout_redirect_uri", o.config.PostLogoutRedirectURL)
+			}
+			endSessionURL.RawQuery = query.Encode()
+
+			redirect = endSessionURL.String()
+		}
+	}
+
+	if redirect == "" {
+		redirect = "/"
+	}
+
+	http.Redirect(rw, req, redirect, http.StatusFound)
+}
+
+func (o *oidcAuth) setSessionCookie(ctx context.Context, rw http.ResponseWriter, req *http.Request, session *oidcSession) error {
+	value, err := o.codec.encode(o.config.SessionCookieName, session)
+	if err != nil {
+		return err
+	}
+
+	if len(value) > maxCookieSize {
+		log.FromContext(ctx).Warnf("The session cookie is %d bytes long, and may be refused by the browsers", len(value))
+	}
+
+	maxAge := time.Until(time.Unix(session.Created, 0).Add(time.Duration(o.config.SessionMaxAge)))
+
+	http.SetCookie(rw, &http.Cookie{
+		Name:     o.config.SessionCookieName,
+		Value:    value,
+		Path:     "/",
+		MaxAge:   int(maxAge.Seconds()),
+		Secure:   isSecure(req),
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	return nil
+}
+
+func (o *oidcAuth) deleteCookie(rw http.ResponseWriter, req *http.Request, name string) {
+	http.SetCookie(rw, &http.Cookie{
+		Name:     name,
+		Path:     "/",
+		MaxAge:   -1,
+		Secure:   isSecure(req),
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+func (o *oidcAuth) stateCookieName() string {
+	return o.config.SessionCookieName + "_state"
+}
+
+// oauth2Config returns the OAuth 2.0 configuration, with a redirection URI on the host of the request.
+func (o *oidcAuth) oauth2Config(provider *oidcProvider, req *http.Request) *oauth2.Config {
+	config := provider.oauth2
+
+	scheme := "http"
+	if isSecure(req) {
+		scheme = "https"
+	}
+	config.RedirectURL = (&url.URL{Scheme: scheme, Host: req.Host, Path: o.config.CallbackPath}).String()
+
+	return &config
+}
+
+func (o *oidcAuth) clientContext(ctx context.Context) context.Context {
+	return context.WithValue(ctx, oauth2.HTTPClient, o.client)
+}
+
+// getProvider returns the OpenID Provider, discovering its configuration on the first call.
+// When the discovery fails, it is attempted again after a while.
+func (o *oidcAuth) getProvider(ctx context.Context) (*oidcProvider, error) {
+	o.mu.Lock()
+	provider, discoveredAt := o.provider, o.discoveredAt
+	o.mu.Unlock()
+
+	if provider != nil {
+		return provider, nil
+	}
+
+	if time.Since(discoveredAt) < jwksRetryInterval {
+		return nil, errors.New("waiting before the next discovery attempt")
+	}
+
+	done := o.discovery.DoChan(o.config.Issuer, func() (interface{}, error) {
+		return o.discoverProvider(ctx)
+	})
+
+	select {
+	case result := <-done:
+		if result.Err != nil {
+			return nil, result.Err
+		}
+		return result.Val.(*oidcProvider), nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// discoverProvider discovers the configuration of the OpenID Provider, without holding the lock during the discovery.
+func (o *oidcAuth) discoverProvider(ctx context.Context) (*oidcProvider, error) {
+	metadata, err := o.discover()
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.discoveredAt = time.Now()
+
+	if err != nil {
+		return nil, err
+	}
+
+	o.provider = &oidcProvider{
+		metadata: *metadata,
+		oauth2: oauth2.Config{
+			ClientID:     o.config.ClientID,
+			ClientSecret: o.config.ClientSecret,
+			Endpoint: oauth2.Endpoint{
+				AuthURL:  metadata.AuthorizationEndpoint,
+				TokenURL: metadata.TokenEndpoint,
+			},
+			Scopes: o.config.Scopes,
+		},
+		validator: &tokenValidator{
+			jwks:     newJWKS(metadata.JWKSURI, 15*time.Minute),
+			issuer:   metadata.Issuer,
+			audience: o.config.ClientID,
+		},
+	}
+
+	log.FromContext(ctx).Debugf("OpenID Provider %s discovered", o.config.Issuer)
+
+	return o.provider, nil
+}
+
+func (o *oidcAuth) discover() (*oidcProviderMetadata, error) {
+	resp, err := o.client.Get(strings.TrimSuffix(o.config.Issuer, "/") + "/.well-known/openid-configuration")
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	var metadata oidcProviderMetadata
+	if err := json.NewDecoder(resp.Body).Decode(&metadata); err != nil {
+		return nil, fmt.Errorf("unable to decode the configuration: %w", err)
+	}
+
+	if strings.TrimSuffix(metadata.Issuer, "/") != strings.TrimSuffix(o.config.Issuer, "/") {
+		return nil, fmt.Errorf("issuer mismatch: %q", metadata.Issuer)
+	}
+
+	if metadata.AuthorizationEndpoint == "" || metadata.TokenEndpoint == "" || metadata.JWKSURI == "" {
+		return nil, errors.New("missing authorization_endpoint, token_endpoint or jwks_uri")
+	}
+
+	return &metadata, nil
+}
+
+// removeCookies removes the given cookies from the request, so that they are not forwarded to the service.
+func removeCookies(req *http.Request, names ...string) {
+	cookies := req.Cookies()
+	req.Header.Del("Cookie")
+
+	for _, cookie := range cookies {
+		keep := true
+		for _, name := range names {
+			if cookie.Name == name {
+				keep = false
+				break
+			}
+		}
+
+		if keep {
+			req.AddCookie(cookie)
+		}
+	}
+}
+
+// isCrossSite reports whether the request was sent by another site, from the Sec-Fetch-Site header,
+// or from the Origin header for the browsers which do not send it.
+func isCrossSite(req *http.Request) bool {
+	if site := req.Header.Get("Sec-Fetch-Site"); site != "" {
+		return site == "cross-site"
+	}
+
+	origin := req.Header.Get("Origin")
+	if origin == "" {
+		return false
+	}
+
+	originURL, err := url.Parse(origin)
+	return err != nil || !strings.EqualFold(originURL.Host, req.Host)
+}
+
+func isSecure(req *http.Request) bool {
+	if proto := req.Header.Get("X-Forwarded-Proto"); proto != "" {
+		return proto == "https"
+	}
+	return req.TLS != nil
+}
+
+func newLoginState(redirect string) (*oidcLoginState, error) {
+	values := make([]string, 3)
+	for i := range values {
+		b := make([]byte, 32)
+		if _, err := rand.Read(b); err != nil {
+			return nil, err
+		}
+		values[i] = base64.RawURLEncoding.EncodeToString(b)
+	}
+
+	return &oidcLoginState{
+		State:    values[0],
+		Verifier: values[1],
+		Nonce:    values[2],
+		Redirect: redirect,
+		Expiry:   time.Now().Add(oidcLoginTimeout).Unix(),
+	}, nil
+}
@@ -0,0 +1,91 @@
+package auth
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+)
+
+// oidcSession is the content of the session cookie.
+// Only the claims copied into headers are kept, so that the cookie stays small.
+type oidcSession struct {
+	Claims       map[string]interface{} `json:"c,omitempty"`
+	AccessToken  string                 `json:"a,omitempty"`
+	RefreshToken string                 `json:"r,omitempty"`
+	// Expiry is the Unix time at which the tokens must be refreshed.
+	Expiry int64 `json:"e"`
+	// Created is the Unix time at which the user logged in.
+	Created int64 `json:"t"`
+}
+
+// oidcLoginState is the content of the cookie keeping the state of an authorization request, until the callback.
+type oidcLoginState struct {
+	State    string `json:"s"`
+	Verifier string `json:"v"`
+	Nonce    string `json:"n"`
+	Redirect string `json:"r"`
+	// Expiry is the Unix time after which the callback is refused.
+	Expiry int64 `json:"e"`
+}
+
+// cookieCodec encrypts and authenticates the content of cookies with AES-GCM.
+type cookieCodec struct {
+	aead cipher.AEAD
+}
+
+func newCookieCodec(secret string) (*cookieCodec, error) {
+	key := sha256.Sum256([]byte(secret))
+
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		return nil, err
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	return &cookieCodec{aead: aead}, nil
+}
+
+// encode encrypts the JSON representation of value.
+// The cookie name is authenticated too, so that the content of a cookie cannot be used as another one.
+func (c *cookieCodec) encode(name string, value interface{}) (string, error) {
+	plaintext, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, c.aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(c.aead.Seal(nonce, nonce, plaintext, []byte(name))), nil
+}
+
+func (c *cookieCodec) decode(name, encoded string, value interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return err
+	}
+
+	if len(data) < c.aead.NonceSize() {
+		return errors.New("invalid cookie length")
+	}
+
+	nonce, ciphertext := data[:c.aead.NonceSize()], data[c.aead.NonceSize():]
+
+	plaintext, err := c.aead.Open(nil, nonce, ciphertext, []byte(name))
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(plaintext, value)
+}
@@ -0,0 +1,560 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	ptypes "github.com/traefik/paerser/types"
+	"github.com/traefik/traefik/v2/pkg/config/dynamic"
+)
+
+// fakeProvider is a stand-in OpenID Provider.
+type fakeProvider struct {
+	*httptest.Server
+	t   *testing.T
+	key *rsa.PrivateKey
+
+	mu sync.Mutex
+	// codes holds the code challenge and the nonce of the issued authorization codes.
+	codes     map[string][2]string
+	expiresIn int
+	refreshes int
+	// discoveries is the number of discovery requests, which wait for discoveryGate to be closed when it is set.
+	discoveries   int
+	discoveryGate chan struct{}
+	// refreshGate is waited for before answering the refresh requests, when it is set.
+	refreshGate chan struct{}
+}
+
+func newFakeProvider(t *testing.T) *fakeProvider {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	p := &fakeProvider{t: t, key: key, codes: make(map[string][2]string), expiresIn: 3600}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(rw http.ResponseWriter, req *http.Request) {
+		p.mu.Lock()
+		p.discoveries++
+		gate := p.discoveryGate
+		p.mu.Unlock()
+
+		if gate != nil {
+			<-gate
+		}
+
+		_ = json.NewEncoder(rw).Encode(map[string]string{
+			"issuer":                 p.URL,
+			"authorization_endpoint": p.URL + "/authorize",
+			"token_endpoint":         p.URL + "/token",
+			"jwks_uri":               p.URL + "/jwks",
+			"end_session_endpoint":   p.URL + "/logout",
+		})
+	})
+	mux.HandleFunc("/jwks", func(rw http.ResponseWriter, req *http.Request) {
+		_ = json.NewEncoder(rw).Encode(map[string]interface{}{
+			"keys": []map[string]string{{
+				"kty": "RSA",
+				"kid": "key",
+				"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			}},
+		})
+	})
+	mux.HandleFunc("/token", p.serveToken)
+
+	p.Server = httptest.NewServer(mux)
+	t.Cleanup(p.Close)
+
+	return p
+}
+
+// authorize simulates the login of the user, and returns the authorization code.
+func (p *fakeProvider) authorize(authURL *url.URL) string {
+	query := authURL.Query()
+	assert.Equal(p.t, "S256", query.Get("code_challenge_method"))
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	code := "code" + query.Get("state")
+	p.codes[code] = [2]string{query.Get("code_challenge"), query.Get("nonce")}
+
+	return code
+}
+
+func (p *fakeProvider) serveToken(rw http.ResponseWriter, req *http.Request) {
+	if err := req.ParseForm(); err != nil {
+		http.Error(rw, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	p.mu.Lock()
+	gate := p.refreshGate
+	p.mu.Unlock()
+
+	if gate != nil && req.Form.Get("grant_type") == "refresh_token" {
+		<-gate
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	clientID, clientSecret, _ := req.BasicAuth()
+	if clientID != "client" || clientSecret != "secret" {
+		http.Error(rw, `{"error":"invalid_client"}`, http.StatusUnauthorized)
+		return
+	}
+
+	claims := jwt.MapClaims{
+		"iss":   p.URL,
+		"aud":   "client",
+		"sub":   "user",
+		"email": "user@example.com",
+		"exp":   time.Now().Add(time.Hour).Unix(),
+	}
+
+	switch req.Form.Get("grant_type") {
+	case "authorization_code":
+		code, ok := p.codes[req.Form.Get("code")]
+		delete(p.codes, req.Form.Get("code"))
+
+		challenge := sha256.Sum256([]byte(req.Form.Get("code_verifier")))
+		if !ok || base64.RawURLEncoding.EncodeToString(challenge[:]) != code[0] {
+			http.Error(rw, `{"error":"invalid_grant"}`, http.StatusBadRequest)
+			return
+		}
+		claims["nonce"] = code[1]
+
+	case "refresh_token":
+		if req.Form.Get("refresh_token") != "refresh" {
+			http.Error(rw, `{"error":"invalid_grant"}`, http.StatusBadRequest)
+			return
+		}
+		p.refreshes++
+		claims["email"] = "refreshed@example.com"
+
+	default:
+		http.Error(rw, `{"error":"unsupported_grant_type"}`, http.StatusBadRequest)
+		return
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = "key"
+	idToken, err := token.SignedString(p.key)
+	require.NoError(p.t, err)
+
+	rw.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(rw).Encode(map[string]interface{}{
+		"access_token":  "access",
+		"token_type":    "Bearer",
+		"expires_in":    p.expiresIn,
+		"refresh_token": "refresh",
+		"id_token":      idToken,
+	})
+}
+
+func newOIDCTestHandler(t *testing.T, provider *fakeProvider) http.Handler {
+	t.Helper()
+
+	config := dynamic.OIDC{}
+	config.SetDefaults()
+	config.Issuer = provider.URL
+	config.ClientID = "client"
+	config.ClientSecret = "secret"
+	config.SessionSecret = "session-secret"
+	config.PostLogoutRedirectURL = "http://app.example.com/bye"
+	config.ClaimsHeaders = map[string]string{"X-Email": "email"}
+	config.ForwardAccessToken = true
+
+	// The middleware updates the headers of the request forwarded to the service in place.
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {})
+
+	handler, err := NewOIDC(context.Background(), next, config, "oidc")
+	require.NoError(t, err)
+
+	return handler
+}
+
+func doRequest(handler http.Handler, method, target string, cookies []*http.Cookie) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, nil)
+	for _, cookie := range cookies {
+		req.AddCookie(cookie)
+	}
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, req)
+
+	return recorder
+}
+
+// login goes through the authorization code flow, and returns the session cookie.
+func login(t *testing.T, handler http.Handler, provider *fakeProvider) *http.Cookie {
+	t.Helper()
+
+	recorder := doRequest(handler, http.MethodGet, "http://app.example.com/private?foo=bar", nil)
+	require.Equal(t, http.StatusFound, recorder.Code)
+
+	authURL, err := url.Parse(recorder.Header().Get("Location"))
+	require.NoError(t, err)
+	assert.Equal(t, provider.URL+"/authorize", authURL.Scheme+"://"+authURL.Host+authURL.Path)
+	assert.Equal(t, "http://app.example.com/oauth2/callback", authURL.Query().Get("redirect_uri"))
+
+	stateCookies := recorder.Result().Cookies()
+	require.Len(t, stateCookies, 1)
+
+	code := provider.authorize(authURL)
+
+	callback := "http://app.example.com/oauth2/callback?" + url.Values{"code": {code}, "state": {authURL.Query().Get("state")}}.Encode()
+	recorder = doRequest(handler, http.MethodGet, callback, stateCookies)
+	require.Equal(t, http.StatusFound, recorder.Code)
+	assert.Equal(t, "/private?foo=bar", recorder.Header().Get("Location"))
+
+	for _, cookie := range recorder.Result().Cookies() {
+		if cookie.Name == "_traefik_oidc" {
+			assert.True(t, cookie.HttpOnly)
+			return cookie
+		}
+	}
+
+	require.Fail(t, "no session cookie")
+	return nil
+}
+
+func TestOIDCAuth(t *testing.T) {
+	provider := newFakeProvider(t)
+	handler := newOIDCTestHandler(t, provider)
+
+	session := login(t, handler, provider)
+
+	req := httptest.NewRequest(http.MethodGet, "http://app.example.com/private", nil)
+	req.AddCookie(session)
+	req.AddCookie(&http.Cookie{Name: "app", Value: "value"})
+	req.Header.Set("X-Email", "spoofed@example.com")
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, req)
+
+	require.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "user@example.com", req.Header.Get("X-Email"))
+	assert.Equal(t, "Bearer access", req.Header.Get("Authorization"))
+
+	// The session cookie is not forwarded to the service.
+	_, err := req.Cookie("_traefik_oidc")
+	assert.Error(t, err)
+	_, err = req.Cookie("app")
+	assert.NoError(t, err)
+
+	// Logout.
+	recorder = doRequest(handler, http.MethodPost, "http://app.example.com/oauth2/logout", []*http.Cookie{session})
+	require.Equal(t, http.StatusFound, recorder.Code)
+
+	logoutURL, err := url.Parse(recorder.Header().Get("Location"))
+	require.NoError(t, err)
+	assert.Equal(t, provider.URL+"/logout", logoutURL.Scheme+"://"+logoutURL.Host+logoutURL.Path)
+	assert.Equal(t, "http://app.example.com/bye", logoutURL.Query().Get("post_logout_redirect_uri"))
+
+	cookies := recorder.Result().Cookies()
+	require.Len(t, cookies, 1)
+	assert.Equal(t, "_traefik_oidc", cookies[0].Name)
+	assert.Equal(t, -1, cookies[0].MaxAge)
+}
+
+func TestOIDCAuth_logout(t *testing.T) {
+	provider := newFakeProvider(t)
+	handler := newOIDCTestHandler(t, provider)
+
+	testCases := []struct {
+		desc           string
+		method         string
+		headers        map[string]string
+		expectedStatus int
+	}{
+		{
+			desc:           "GET request",
+			method:         http.MethodGet,
+			expectedStatus: http.StatusMethodNotAllowed,
+		},
+		{
+			desc:           "cross-site request",
+			method:         http.MethodPost,
+			headers:        map[string]string{"Sec-Fetch-Site": "cross-site", "Origin": "http://app.example.com"},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			desc:           "request from another origin",
+			method:         http.MethodPost,
+			headers:        map[string]string{"Origin": "http://attacker.net"},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			desc:           "same-origin request",
+			method:         http.MethodPost,
+			headers:        map[string]string{"Sec-Fetch-Site": "same-origin", "Origin": "http://app.example.com"},
+			expectedStatus: http.StatusFound,
+		},
+		{
+			desc:           "request from the same origin",
+			method:         http.MethodPost,
+			headers:        map[string]string{"Origin": "http://app.example.com"},
+			expectedStatus: http.StatusFound,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			req := httptest.NewRequest(test.method, "http://app.example.com/oauth2/logout", nil)
+			for name, value := range test.headers {
+				req.Header.Set(name, value)
+			}
+
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, req)
+
+			assert.Equal(t, test.expectedStatus, recorder.Code)
+			if test.expectedStatus != http.StatusFound {
+				assert.Empty(t, recorder.Result().Cookies())
+			}
+		})
+	}
+}
+
+func TestOIDCAuth_refresh(t *testing.T) {
+	provider := newFakeProvider(t)
+	provider.expiresIn = -10
+
+	handler := newOIDCTestHandler(t, provider)
+
+	session := login(t, handler, provider)
+
+	req := httptest.NewRequest(http.MethodGet, "http://app.example.com/private", nil)
+	req.AddCookie(session)
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, req)
+
+	require.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, 1, provider.refreshes)
+	assert.Equal(t, "refreshed@example.com", req.Header.Get("X-Email"))
+
+	cookies := recorder.Result().Cookies()
+	require.Len(t, cookies, 1)
+	assert.Equal(t, "_traefik_oidc", cookies[0].Name)
+	assert.NotEqual(t, session.Value, cookies[0].Value)
+}
+
+func TestOIDCAuth_concurrentRefreshes(t *testing.T) {
+	provider := newFakeProvider(t)
+	provider.expiresIn = -10
+
+	handler := newOIDCTestHandler(t, provider)
+
+	session := login(t, handler, provider)
+
+	gate := make(chan struct{})
+	provider.mu.Lock()
+	provider.refreshGate = gate
+	provider.mu.Unlock()
+
+	const requests = 5
+
+	var wg sync.WaitGroup
+	codes := make(chan int, requests)
+
+	for i := 0; i < requests; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			codes <- doRequest(handler, http.MethodGet, "http://app.example.com/private", []*http.Cookie{session}).Code
+		}()
+	}
+
+	// Gives the requests the time to reach the refresh, which the OpenID Provider holds meanwhile.
+	time.Sleep(100 * time.Millisecond)
+	close(gate)
+
+	wg.Wait()
+	close(codes)
+
+	for code := range codes {
+		assert.Equal(t, http.StatusOK, code)
+	}
+
+	provider.mu.Lock()
+	defer provider.mu.Unlock()
+	assert.Equal(t, 1, provider.refreshes)
+}
+
+func TestOIDCAuth_concurrentDiscovery(t *testing.T) {
+	provider := newFakeProvider(t)
+	gate := make(chan struct{})
+	provider.discoveryGate = gate
+
+	handler := newOIDCTestHandler(t, provider)
+
+	done := make(chan int)
+	go func() {
+		done <- doRequest(handler, http.MethodGet, "http://app.example.com/", nil).Code
+	}()
+
+	assert.Eventually(t, func() bool {
+		provider.mu.Lock()
+		defer provider.mu.Unlock()
+		return provider.discoveries == 1
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// A request whose client goes away does not wait for the discovery.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "http://app.example.com/", nil).WithContext(ctx))
+	assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+
+	close(gate)
+	assert.Equal(t, http.StatusFound, <-done)
+
+	assert.Equal(t, http.StatusFound, doRequest(handler, http.MethodGet, "http://app.example.com/", nil).Code)
+
+	provider.mu.Lock()
+	defer provider.mu.Unlock()
+	assert.Equal(t, 1, provider.discoveries)
+}
+
+func TestOIDCAuth_unauthenticated(t *testing.T) {
+	provider := newFakeProvider(t)
+	handler := newOIDCTestHandler(t, provider)
+
+	testCases := []struct {
+		desc           string
+		method         string
+		target         string
+		cookies        []*http.Cookie
+		expectedStatus int
+	}{
+		{
+			desc:           "browser navigation",
+			method:         http.MethodGet,
+			target:         "http://app.example.com/",
+			expectedStatus: http.StatusFound,
+		},
+		{
+			desc:           "API call",
+			method:         http.MethodPost,
+			target:         "http://app.example.com/",
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			desc:           "invalid session cookie",
+			method:         http.MethodPost,
+			target:         "http://app.example.com/",
+			cookies:        []*http.Cookie{{Name: "_traefik_oidc", Value: "foo"}},
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			desc:           "callback without state cookie",
+			method:         http.MethodGet,
+			target:         "http://app.example.com/oauth2/callback?code=foo&state=bar",
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			desc:           "callback with an error",
+			method:         http.MethodGet,
+			target:         "http://app.example.com/oauth2/callback?error=access_denied",
+			expectedStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			recorder := doRequest(handler, test.method, test.target, test.cookies)
+			assert.Equal(t, test.expectedStatus, recorder.Code)
+		})
+	}
+}
+
+func TestOIDCAuth_stateMismatch(t *testing.T) {
+	provider := newFakeProvider(t)
+	handler := newOIDCTestHandler(t, provider)
+
+	recorder := doRequest(handler, http.MethodGet, "http://app.example.com/", nil)
+	require.Equal(t, http.StatusFound, recorder.Code)
+
+	authURL, err := url.Parse(recorder.Header().Get("Location"))
+	require.NoError(t, err)
+
+	code := provider.authorize(authURL)
+
+	callback := "http://app.example.com/oauth2/callback?" + url.Values{"code": {code}, "state": {"other"}}.Encode()
+	recorder = doRequest(handler, http.MethodGet, callback, recorder.Result().Cookies())
+	assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+}
+
+func TestOIDCAuth_sessionMaxAge(t *testing.T) {
+	provider := newFakeProvider(t)
+
+	config := dynamic.OIDC{
+		Issuer:        provider.URL,
+		ClientID:      "client",
+		SessionSecret: "session-secret",
+		SessionMaxAge: ptypes.Duration(time.Hour),
+	}
+
+	handler, err := NewOIDC(context.Background(), http.NotFoundHandler(), config, "oidc")
+	require.NoError(t, err)
+
+	ja := handler.(*oidcAuth)
+	value, err := ja.codec.encode("_traefik_oidc", oidcSession{
+		Expiry:  time.Now().Add(time.Hour).Unix(),
+		Created: time.Now().Add(-2 * time.Hour).Unix(),
+	})
+	require.NoError(t, err)
+
+	recorder := doRequest(handler, http.MethodPost, "http://app.example.com/", []*http.Cookie{{Name: "_traefik_oidc", Value: value}})
+	assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+}
+
+func TestNewOIDC_invalidConfig(t *testing.T) {
+	_, err := NewOIDC(context.Background(), http.NotFoundHandler(), dynamic.OIDC{Issuer: "http://issuer"}, "oidc")
+	assert.Error(t, err)
+
+	_, err = NewOIDC(context.Background(), http.NotFoundHandler(), dynamic.OIDC{Issuer: "http://issuer", ClientID: "client"}, "oidc")
+	assert.Error(t, err)
+}
+
+func TestCookieCodec(t *testing.T) {
+	codec, err := newCookieCodec("secret")
+	require.NoError(t, err)
+
+	encoded, err := codec.encode("name", oidcLoginState{State: "state"})
+	require.NoError(t, err)
+
+	var state oidcLoginState
+	require.NoError(t, codec.decode("name", encoded, &state))
+	assert.Equal(t, "state", state.State)
+
+	// The content of a cookie cannot be used as another cookie.
+	assert.Error(t, codec.decode("other", encoded, &state))
+
+	other, err := newCookieCodec("other")
+	require.NoError(t, err)
+	assert.Error(t, other.decode("name", encoded, &state))
+}
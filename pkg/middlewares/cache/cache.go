@@ -0,0 +1,549 @@
+// Package cache implements a middleware caching the HTTP responses, following RFC 7234.
+package cache
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/opentracing/opentracing-go/ext"
+	"github.com/traefik/traefik/v2/pkg/config/dynamic"
+	"github.com/traefik/traefik/v2/pkg/log"
+	"github.com/traefik/traefik/v2/pkg/middlewares"
+	"github.com/traefik/traefik/v2/pkg/tracing"
+	"golang.org/x/net/http/httpguts"
+)
+
+const typeName = "Cache"
+
+// cacheStatusHeader is the response header telling how the cache handled the request.
+const cacheStatusHeader = "X-Cache"
+
+// Values of the cache status header.
+const (
+	statusHit         = "HIT"
+	statusMiss        = "MISS"
+	statusStale       = "STALE"
+	statusRevalidated = "REVALIDATED"
+)
+
+var (
+	sharedCachesMu sync.Mutex
+	// sharedCaches are the caches of the middlewares, keyed by middleware name,
+	// so that the routers using the same middleware share the same cache, which is kept across configuration reloads,
+	// until the middleware is removed (see Prune).
+	sharedCaches = map[string]*sharedCache{}
+)
+
+type sharedCache struct {
+	config dynamic.Cache
+	store  store
+	calls  *callGroup
+}
+
+func getSharedCache(name string, config dynamic.Cache) (*sharedCache, error) {
+	sharedCachesMu.Lock()
+	defer sharedCachesMu.Unlock()
+
+	if shared, ok := sharedCaches[name]; ok && reflect.DeepEqual(shared.config, config) {
+		return shared, nil
+	}
+
+	var st store = newMemoryStore(config.MaxEntries)
+	if config.DiskPath != "" {
+		var err error
+		st, err = newDiskStore(config.DiskPath, config.MaxEntries)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	shared := &sharedCache{
+		config: config,
+		store:  st,
+		calls:  &callGroup{calls: make(map[string]*call)},
+	}
+	sharedCaches[name] = shared
+
+	return shared, nil
+}
+
+// Prune drops the caches of the middlewares which are not in names anymore, e.g. after a configuration reload.
+// The responses stored on disk are kept, and loaded again if a middleware with the same configuration is created.
+func Prune(names map[string]struct{}) {
+	sharedCachesMu.Lock()
+	defer sharedCachesMu.Unlock()
+
+	for name := range sharedCaches {
+		if _, ok := names[name]; !ok {
+			delete(sharedCaches, name)
+		}
+	}
+}
+
+// Purge removes the responses cached by the cache middleware with the given name.
+// When path is not empty, only the responses to the requests with this path are removed.
+// It returns the number of removed entries.
+func Purge(name, path string) int {
+	sharedCachesMu.Lock()
+	shared, ok := sharedCaches[name]
+	sharedCachesMu.Unlock()
+
+	if !ok {
+		return 0
+	}
+
+	return shared.store.removeFunc(func(_, p string) bool {
+		return path == "" || p == path
+	})
+}
+
+// cache is a middleware caching the responses of the GET requests.
+type cache struct {
+	next                 http.Handler
+	name                 string
+	store                store
+	calls                *callGroup
+	maxResponseBodyBytes int64
+	defaultTTL           time.Duration
+}
+
+// New creates a cache middleware.
+func New(ctx context.Context, next http.Handler, config dynamic.Cache, name string) (http.Handler, error) {
+	log.FromContext(middlewares.GetLoggerCtx(ctx, name, typeName)).Debug("Creating middleware")
+
+	if config.MaxEntries <= 0 {
+		return nil, errors.New("maxEntries must be greater than 0")
+	}
+
+	if config.MaxResponseBodyBytes <= 0 {
+		return nil, errors.New("maxResponseBodyBytes must be greater than 0")
+	}
+
+	shared, err := getSharedCache(name, config)
+	if err != nil {
+		return nil, err
+	}
+
+	return &cache{
+		next:                 next,
+		name:                 name,
+		store:                shared.store,
+		calls:                shared.calls,
+		maxResponseBodyBytes: config.MaxResponseBodyBytes,
+		defaultTTL:           time.Duration(config.DefaultTTL),
+	}, nil
+}
+
+func (c *cache) GetTracingInformation() (string, ext.SpanKindEnum) {
+	return c.name, tracing.SpanKindNoneEnum
+}
+
+func (c *cache) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	// The upgraded connections, e.g. WebSockets, are not HTTP responses which could be cached.
+	if isUpgrade(req) {
+		c.next.ServeHTTP(rw, req)
+		return
+	}
+
+	switch req.Method {
+	case http.MethodGet:
+	case http.MethodHead, http.MethodOptions, http.MethodTrace:
+		c.next.ServeHTTP(rw, req)
+		return
+	default:
+		c.serveUnsafe(rw, req)
+		return
+	}
+
+	cc := parseCacheControl(req.Header)
+	if cc.has("no-store") {
+		c.next.ServeHTTP(rw, req)
+		return
+	}
+
+	key := cacheKey(req)
+
+	e := c.lookup(key, req)
+	if e != nil {
+		age := e.age(time.Now())
+
+		// The request can require the response to be fresher than its freshness lifetime, or to be revalidated.
+		maxAge := e.Lifetime
+		reqMaxAge, restricted := cc.duration("max-age")
+		if restricted && reqMaxAge < maxAge {
+			maxAge = reqMaxAge
+		}
+
+		noCache := noCacheRequest(req, cc)
+
+		if age < maxAge && !noCache {
+			serveEntry(rw, req, e, statusHit)
+			return
+		}
+
+		if age < e.Lifetime+e.StaleWhileRevalidate && !restricted && !noCache {
+			serveEntry(rw, req, e, statusStale)
+			c.revalidateInBackground(key, req, e)
+			return
+		}
+	}
+
+	fetched, leader, err := c.calls.do(req.Context(), key, func(release func()) *entry {
+		return c.fetch(rw, req, key, e, release)
+	})
+
+	if leader {
+		return
+	}
+
+	if err != nil {
+		log.FromContext(middlewares.GetLoggerCtx(req.Context(), c.name, typeName)).Debugf("Stopped waiting for the response of a concurrent request: %v", err)
+		return
+	}
+
+	// The response fetched for another request is used only if it was stored for the same variant.
+	if fetched != nil && fetched.Key == variantKey(key, fetched.VaryHeaders, req) {
+		serveEntry(rw, req, fetched, statusHit)
+		return
+	}
+
+	c.next.ServeHTTP(rw, req)
+}
+
+// serveUnsafe forwards a request with an unsafe method, and when it succeeds,
+// invalidates the cached responses of its URI, and of the URIs of the Location and Content-Location headers of the response (RFC 7234 section 4.4).
+func (c *cache) serveUnsafe(rw http.ResponseWriter, req *http.Request) {
+	recorder := &statusRecorder{ResponseWriter: rw}
+	c.next.ServeHTTP(recorder, req)
+
+	if recorder.status >= http.StatusBadRequest {
+		return
+	}
+
+	key := cacheKey(req)
+	c.invalidate(key)
+
+	for _, name := range []string{"Location", "Content-Location"} {
+		if locationKey, ok := sameHostKey(req, key, rw.Header().Get(name)); ok {
+			c.invalidate(locationKey)
+		}
+	}
+}
+
+// invalidate removes the stored responses of a primary key, including all its variants.
+func (c *cache) invalidate(key string) {
+	c.store.removeFunc(func(k, _ string) bool {
+		return k == key || strings.HasPrefix(k, key+"\x00")
+	})
+}
+
+// sameHostKey returns the primary key of a location, resolved against the request URI,
+// unless the location is invalid or on another host, whose responses cannot be invalidated (RFC 7234 section 4.4).
+func sameHostKey(req *http.Request, key, location string) (string, bool) {
+	if location == "" {
+		return "", false
+	}
+
+	base, err := url.Parse(key)
+	if err != nil {
+		return "", false
+	}
+
+	ref, err := url.Parse(location)
+	if err != nil {
+		return "", false
+	}
+
+	target := base.ResolveReference(ref)
+	if !strings.EqualFold(target.Host, req.Host) {
+		return "", false
+	}
+
+	return target.Scheme + "://" + req.Host + target.RequestURI(), true
+}
+
+// isUpgrade reports whether the request asks to upgrade the connection to another protocol.
+func isUpgrade(req *http.Request) bool {
+	return req.Header.Get("Upgrade") != "" && httpguts.HeaderValuesContainsToken(req.Header["Connection"], "Upgrade")
+}
+
+// lookup returns the entry stored for the request, if any.
+func (c *cache) lookup(key string, req *http.Request) *entry {
+	e, ok := c.store.get(key)
+	if !ok {
+		return nil
+	}
+
+	if !e.Marker {
+		return e
+	}
+
+	e, ok = c.store.get(variantKey(key, e.VaryHeaders, req))
+	if !ok {
+		return nil
+	}
+
+	return e
+}
+
+func (c *cache) revalidateInBackground(key string, req *http.Request, stale *entry) {
+	bgReq := req.Clone(context.Background())
+	bgReq.Body = http.NoBody
+
+	go c.calls.do(bgReq.Context(), key, func(release func()) *entry {
+		return c.fetch(nil, bgReq, key, stale, release)
+	})
+}
+
+// fetch forwards the request to the next handler, stores the response when it is cacheable, and returns the stored entry.
+// The response is written to rw as it is received, unless it is a 304 Not Modified answer to the revalidation of stale,
+// in which case the revalidated entry is written instead.
+// rw is nil when the response is fetched in the background.
+// release is called as soon as the response is known not to be stored, e.g. from its status code and headers.
+func (c *cache) fetch(rw http.ResponseWriter, req *http.Request, key string, stale *entry, release func()) *entry {
+	logger := log.FromContext(middlewares.GetLoggerCtx(req.Context(), c.name, typeName))
+
+	outReq := req.Clone(req.Context())
+	// The conditional headers of the client are handled by the cache, from the stored response.
+	outReq.Header.Del("If-None-Match")
+	outReq.Header.Del("If-Modified-Since")
+
+	revalidating := stale != nil && stale.hasValidators()
+	if revalidating {
+		if etag := stale.Header.Get("ETag"); etag != "" {
+			outReq.Header.Set("If-None-Match", etag)
+		}
+		if lastModified := stale.Header.Get("Last-Modified"); lastModified != "" {
+			outReq.Header.Set("If-Modified-Since", lastModified)
+		}
+	}
+
+	requestTime := time.Now()
+
+	recorder := &responseRecorder{
+		rw:               rw,
+		header:           make(http.Header),
+		maxBodySize:      c.maxResponseBodyBytes,
+		holdNotModified:  revalidating,
+		cacheStatusValue: statusMiss,
+		storable: func(code int, header http.Header) bool {
+			return c.newEntry(req, key, code, header, nil, requestTime, time.Now()) != nil
+		},
+		release: release,
+	}
+
+	c.next.ServeHTTP(recorder, outReq)
+	recorder.finish()
+	responseTime := time.Now()
+
+	// The connection was taken over by the next handler, there is no response to store.
+	if recorder.hijacked {
+		return nil
+	}
+
+	var e *entry
+	if recorder.held {
+		e = c.newEntry(req, key, stale.Status, mergeHeaders(stale.Header, recorder.header), stale.Body, requestTime, responseTime)
+		if rw != nil {
+			revalidated := e
+			if revalidated == nil {
+				revalidated = stale
+			}
+			serveEntry(rw, req, revalidated, statusRevalidated)
+		}
+	} else if !recorder.tooLarge {
+		e = c.newEntry(req, key, recorder.code, recorder.header, recorder.body, requestTime, responseTime)
+	}
+
+	if e == nil {
+		c.store.remove(key)
+		return nil
+	}
+
+	if len(e.VaryHeaders) > 0 {
+		marker := &entry{Key: key, Path: e.Path, VaryHeaders: e.VaryHeaders, Marker: true}
+		if err := c.store.set(marker); err != nil {
+			logger.Errorf("Unable to store the response: %v", err)
+			return nil
+		}
+	}
+
+	if err := c.store.set(e); err != nil {
+		logger.Errorf("Unable to store the response: %v", err)
+		return nil
+	}
+
+	return e
+}
+
+// newEntry creates the entry of a response, or returns nil if the response is not cacheable.
+func (c *cache) newEntry(req *http.Request, key string, status int, header http.Header, body []byte, requestTime, responseTime time.Time) *entry {
+	if !cacheableStatusCodes[status] {
+		return nil
+	}
+
+	cc := parseCacheControl(header)
+	if cc.has("no-store") || cc.has("private") || parseCacheControl(req.Header).has("no-store") {
+		return nil
+	}
+
+	// The responses setting cookies are specific to a client.
+	if header.Get("Set-Cookie") != "" {
+		return nil
+	}
+
+	if req.Header.Get("Authorization") != "" && !cc.has("public") && !cc.has("s-maxage") && !cc.has("must-revalidate") {
+		return nil
+	}
+
+	names := varyHeaders(header)
+	for _, name := range names {
+		if name == "*" {
+			return nil
+		}
+	}
+
+	date, err := http.ParseTime(header.Get("Date"))
+	if err != nil {
+		date = responseTime
+	}
+
+	lifetime, explicit := freshness(header, cc, date)
+	if !explicit {
+		lifetime = c.defaultTTL
+	}
+
+	if cc.has("no-cache") {
+		lifetime = 0
+	}
+
+	e := &entry{
+		Key:         key,
+		Path:        req.URL.Path,
+		VaryHeaders: names,
+		Status:      status,
+		Header:      header,
+		Body:        body,
+		Date:        responseTime.Add(-initialAge(header, date, requestTime, responseTime)),
+		Lifetime:    lifetime,
+	}
+
+	if !cc.has("must-revalidate") && !cc.has("proxy-revalidate") {
+		e.StaleWhileRevalidate, _ = cc.duration("stale-while-revalidate")
+	}
+
+	if e.Lifetime <= 0 && e.StaleWhileRevalidate <= 0 && !e.hasValidators() {
+		return nil
+	}
+
+	if len(names) > 0 {
+		e.Key = variantKey(key, names, req)
+	}
+
+	return e
+}
+
+// mergeHeaders returns the headers of a stored response updated with the headers of a 304 Not Modified response.
+func mergeHeaders(stored, notModified http.Header) http.Header {
+	header := stored.Clone()
+	for name, values := range notModified {
+		if name == "Content-Length" {
+			continue
+		}
+		header[name] = values
+	}
+	return header
+}
+
+// serveEntry writes a cached response, or a 304 Not Modified response when the request conditions match it.
+func serveEntry(rw http.ResponseWriter, req *http.Request, e *entry, status string) {
+	header := rw.Header()
+	for name, values := range e.Header {
+		header[name] = append([]string(nil), values...)
+	}
+
+	header.Set("Age", strconv.FormatInt(int64(e.age(time.Now())/time.Second), 10))
+	header.Set(cacheStatusHeader, status)
+
+	if e.notModified(req) {
+		header.Del("Content-Length")
+		rw.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	rw.WriteHeader(e.Status)
+
+	if _, err := rw.Write(e.Body); err != nil {
+		log.FromContext(req.Context()).Debugf("Unable to write the cached response: %v", err)
+	}
+}
+
+// cacheKey returns the primary key of a request, i.e. its absolute URI.
+func cacheKey(req *http.Request) string {
+	scheme := "http"
+	if req.TLS != nil {
+		scheme = "https"
+	}
+
+	return scheme + "://" + req.Host + req.URL.RequestURI()
+}
+
+// callGroup coalesces the concurrent fetches of the same key.
+type callGroup struct {
+	mu    sync.Mutex
+	calls map[string]*call
+}
+
+type call struct {
+	done  chan struct{}
+	once  sync.Once
+	entry *entry
+}
+
+// do calls fn, unless a call for the same key is in progress,
+// in which case it waits for this call to release its result, or for ctx to be done.
+// fn can release the waiting calls with a nil result before it returns, e.g. when the response will not be stored.
+// leader reports whether fn was called.
+func (g *callGroup) do(ctx context.Context, key string, fn func(release func()) *entry) (e *entry, leader bool, err error) {
+	g.mu.Lock()
+	if c, ok := g.calls[key]; ok {
+		g.mu.Unlock()
+
+		select {
+		case <-c.done:
+			return c.entry, false, nil
+		case <-ctx.Done():
+			return nil, false, ctx.Err()
+		}
+	}
+
+	c := &call{done: make(chan struct{})}
+	g.calls[key] = c
+	g.mu.Unlock()
+
+	e = fn(func() { g.release(key, c, nil) })
+	g.release(key, c, e)
+
+	return e, true, nil
+}
+
+// release gives the result of a call to the waiting calls, and lets the next calls for the same key start a new one.
+func (g *callGroup) release(key string, c *call, e *entry) {
+	c.once.Do(func() {
+		g.mu.Lock()
+		if g.calls[key] == c {
+			delete(g.calls, key)
+		}
+		g.mu.Unlock()
+
+		c.entry = e
+		close(c.done)
+	})
+}
@@ -0,0 +1,801 @@
+package cache
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	ptypes "github.com/traefik/paerser/types"
+	"github.com/traefik/traefik/v2/pkg/config/dynamic"
+)
+
+func newTestCache(t *testing.T, config dynamic.Cache, next http.Handler) http.Handler {
+	t.Helper()
+
+	if config.MaxEntries == 0 {
+		config.MaxEntries = 100
+	}
+	if config.MaxResponseBodyBytes == 0 {
+		config.MaxResponseBodyBytes = 1024
+	}
+
+	handler, err := New(context.Background(), next, config, t.Name())
+	require.NoError(t, err)
+
+	t.Cleanup(func() { removeSharedCache(t.Name()) })
+
+	return handler
+}
+
+func removeSharedCache(name string) {
+	sharedCachesMu.Lock()
+	delete(sharedCaches, name)
+	sharedCachesMu.Unlock()
+}
+
+func serve(handler http.Handler, req *http.Request) *httptest.ResponseRecorder {
+	rw := httptest.NewRecorder()
+	handler.ServeHTTP(rw, req)
+	return rw
+}
+
+func TestNew(t *testing.T) {
+	testCases := []struct {
+		desc      string
+		config    dynamic.Cache
+		expectErr bool
+	}{
+		{
+			desc:   "valid",
+			config: dynamic.Cache{MaxEntries: 1, MaxResponseBodyBytes: 1},
+		},
+		{
+			desc:      "no max entries",
+			config:    dynamic.Cache{MaxResponseBodyBytes: 1},
+			expectErr: true,
+		},
+		{
+			desc:      "no max response body size",
+			config:    dynamic.Cache{MaxEntries: 1},
+			expectErr: true,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := New(context.Background(), http.NotFoundHandler(), test.config, t.Name())
+			t.Cleanup(func() { removeSharedCache(t.Name()) })
+			if test.expectErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestCache_cacheability(t *testing.T) {
+	testCases := []struct {
+		desc           string
+		reqHeader      http.Header
+		respHeader     http.Header
+		status         int
+		bodySize       int
+		defaultTTL     time.Duration
+		expectedCached bool
+	}{
+		{
+			desc:           "max-age",
+			respHeader:     http.Header{"Cache-Control": {"max-age=60"}},
+			expectedCached: true,
+		},
+		{
+			desc:           "s-maxage",
+			respHeader:     http.Header{"Cache-Control": {"s-maxage=60"}},
+			expectedCached: true,
+		},
+		{
+			desc:           "Expires",
+			respHeader:     http.Header{"Expires": {time.Now().Add(time.Minute).UTC().Format(http.TimeFormat)}},
+			expectedCached: true,
+		},
+		{
+			desc:       "no freshness information",
+			respHeader: http.Header{},
+		},
+		{
+			desc:           "default TTL",
+			respHeader:     http.Header{},
+			defaultTTL:     time.Minute,
+			expectedCached: true,
+		},
+		{
+			desc:       "no-store response",
+			respHeader: http.Header{"Cache-Control": {"max-age=60, no-store"}},
+		},
+		{
+			desc:       "private response",
+			respHeader: http.Header{"Cache-Control": {"private, max-age=60"}},
+		},
+		{
+			desc:       "no-store request",
+			reqHeader:  http.Header{"Cache-Control": {"no-store"}},
+			respHeader: http.Header{"Cache-Control": {"max-age=60"}},
+		},
+		{
+			desc:       "Set-Cookie",
+			respHeader: http.Header{"Cache-Control": {"max-age=60"}, "Set-Cookie": {"foo=bar"}},
+		},
+		{
+			desc:       "Vary *",
+			respHeader: http.Header{"Cache-Control": {"max-age=60"}, "Vary": {"*"}},
+		},
+		{
+			desc:       "Authorization",
+			reqHeader:  http.Header{"Authorization": {"Bearer foo"}},
+			respHeader: http.Header{"Cache-Control": {"max-age=60"}},
+		},
+		{
+			desc:           "Authorization with public response",
+			reqHeader:      http.Header{"Authorization": {"Bearer foo"}},
+			respHeader:     http.Header{"Cache-Control": {"public, max-age=60"}},
+			expectedCached: true,
+		},
+		{
+			desc:       "non cacheable status code",
+			respHeader: http.Header{"Cache-Control": {"max-age=60"}},
+			status:     http.StatusInternalServerError,
+		},
+		{
+			desc:       "body too large",
+			respHeader: http.Header{"Cache-Control": {"max-age=60"}},
+			bodySize:   2048,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			body := make([]byte, test.bodySize)
+			if len(body) == 0 {
+				body = []byte("foo")
+			}
+
+			var calls int32
+			next := http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+				atomic.AddInt32(&calls, 1)
+
+				for name, values := range test.respHeader {
+					rw.Header()[name] = values
+				}
+
+				if test.status != 0 {
+					rw.WriteHeader(test.status)
+				}
+
+				_, _ = rw.Write(body)
+			})
+
+			handler := newTestCache(t, dynamic.Cache{DefaultTTL: ptypes.Duration(test.defaultTTL)}, next)
+
+			for i := 0; i < 2; i++ {
+				req := httptest.NewRequest(http.MethodGet, "http://localhost/foo", nil)
+				for name, values := range test.reqHeader {
+					req.Header[name] = values
+				}
+
+				rw := serve(handler, req)
+				assert.Equal(t, body, rw.Body.Bytes())
+			}
+
+			if test.expectedCached {
+				assert.EqualValues(t, 1, atomic.LoadInt32(&calls))
+			} else {
+				assert.EqualValues(t, 2, atomic.LoadInt32(&calls))
+			}
+		})
+	}
+}
+
+func TestCache_hit(t *testing.T) {
+	var calls int32
+	next := http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		rw.Header().Set("Cache-Control", "max-age=60")
+		rw.Header().Set("Age", "10")
+		rw.WriteHeader(http.StatusNonAuthoritativeInfo)
+		_, _ = rw.Write([]byte("foo"))
+	})
+
+	handler := newTestCache(t, dynamic.Cache{}, next)
+
+	rw := serve(handler, httptest.NewRequest(http.MethodGet, "http://localhost/foo", nil))
+	assert.Equal(t, statusMiss, rw.Header().Get(cacheStatusHeader))
+	assert.Equal(t, http.StatusNonAuthoritativeInfo, rw.Code)
+
+	rw = serve(handler, httptest.NewRequest(http.MethodGet, "http://localhost/foo", nil))
+	assert.Equal(t, statusHit, rw.Header().Get(cacheStatusHeader))
+	assert.Equal(t, http.StatusNonAuthoritativeInfo, rw.Code)
+	assert.Equal(t, "10", rw.Header().Get("Age"))
+	assert.Equal(t, "foo", rw.Body.String())
+
+	// Another host is another resource.
+	rw = serve(handler, httptest.NewRequest(http.MethodGet, "http://example.com/foo", nil))
+	assert.Equal(t, statusMiss, rw.Header().Get(cacheStatusHeader))
+
+	// The request can require a fresher response.
+	req := httptest.NewRequest(http.MethodGet, "http://localhost/foo", nil)
+	req.Header.Set("Cache-Control", "max-age=5")
+	rw = serve(handler, req)
+	assert.Equal(t, statusMiss, rw.Header().Get(cacheStatusHeader))
+
+	req = httptest.NewRequest(http.MethodGet, "http://localhost/foo", nil)
+	req.Header.Set("Pragma", "no-cache")
+	rw = serve(handler, req)
+	assert.Equal(t, statusMiss, rw.Header().Get(cacheStatusHeader))
+
+	assert.EqualValues(t, 4, atomic.LoadInt32(&calls))
+}
+
+func TestCache_expired(t *testing.T) {
+	var calls int32
+	next := http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		rw.Header().Set("Cache-Control", "max-age=10")
+		rw.Header().Set("Age", "10")
+		_, _ = rw.Write([]byte("foo"))
+	})
+
+	handler := newTestCache(t, dynamic.Cache{}, next)
+
+	serve(handler, httptest.NewRequest(http.MethodGet, "http://localhost/foo", nil))
+	rw := serve(handler, httptest.NewRequest(http.MethodGet, "http://localhost/foo", nil))
+
+	assert.Equal(t, statusMiss, rw.Header().Get(cacheStatusHeader))
+	assert.EqualValues(t, 2, atomic.LoadInt32(&calls))
+}
+
+func TestCache_vary(t *testing.T) {
+	var calls int32
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		rw.Header().Set("Cache-Control", "max-age=60")
+		rw.Header().Set("Vary", "Accept-Language")
+		_, _ = rw.Write([]byte(req.Header.Get("Accept-Language")))
+	})
+
+	handler := newTestCache(t, dynamic.Cache{}, next)
+
+	for _, lang := range []string{"en", "fr", "en", "fr"} {
+		req := httptest.NewRequest(http.MethodGet, "http://localhost/foo", nil)
+		req.Header.Set("Accept-Language", lang)
+
+		rw := serve(handler, req)
+		assert.Equal(t, lang, rw.Body.String())
+	}
+
+	assert.EqualValues(t, 2, atomic.LoadInt32(&calls))
+}
+
+func TestCache_revalidation(t *testing.T) {
+	var calls, notModified int32
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		rw.Header().Set("Cache-Control", "no-cache")
+		rw.Header().Set("ETag", `"v1"`)
+
+		if req.Header.Get("If-None-Match") == `"v1"` {
+			atomic.AddInt32(&notModified, 1)
+			rw.WriteHeader(http.StatusNotModified)
+			return
+		}
+
+		_, _ = rw.Write([]byte("foo"))
+	})
+
+	handler := newTestCache(t, dynamic.Cache{}, next)
+
+	rw := serve(handler, httptest.NewRequest(http.MethodGet, "http://localhost/foo", nil))
+	assert.Equal(t, statusMiss, rw.Header().Get(cacheStatusHeader))
+	assert.Equal(t, "foo", rw.Body.String())
+
+	rw = serve(handler, httptest.NewRequest(http.MethodGet, "http://localhost/foo", nil))
+	assert.Equal(t, statusRevalidated, rw.Header().Get(cacheStatusHeader))
+	assert.Equal(t, http.StatusOK, rw.Code)
+	assert.Equal(t, "foo", rw.Body.String())
+
+	// The conditional request of the client is answered from the revalidated response.
+	req := httptest.NewRequest(http.MethodGet, "http://localhost/foo", nil)
+	req.Header.Set("If-None-Match", `"v0", "v1"`)
+	rw = serve(handler, req)
+	assert.Equal(t, statusRevalidated, rw.Header().Get(cacheStatusHeader))
+	assert.Equal(t, http.StatusNotModified, rw.Code)
+	assert.Empty(t, rw.Body.String())
+
+	assert.EqualValues(t, 3, atomic.LoadInt32(&calls))
+	assert.EqualValues(t, 2, atomic.LoadInt32(&notModified))
+}
+
+func TestCache_conditionalHit(t *testing.T) {
+	lastModified := time.Now().Add(-time.Hour).UTC().Format(http.TimeFormat)
+
+	next := http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+		rw.Header().Set("Cache-Control", "max-age=60")
+		rw.Header().Set("ETag", `W/"v1"`)
+		rw.Header().Set("Last-Modified", lastModified)
+		_, _ = rw.Write([]byte("foo"))
+	})
+
+	handler := newTestCache(t, dynamic.Cache{}, next)
+
+	serve(handler, httptest.NewRequest(http.MethodGet, "http://localhost/foo", nil))
+
+	testCases := []struct {
+		desc           string
+		header         http.Header
+		expectedStatus int
+	}{
+		{
+			desc:           "matching If-None-Match",
+			header:         http.Header{"If-None-Match": {`"v1"`}},
+			expectedStatus: http.StatusNotModified,
+		},
+		{
+			desc:           "not matching If-None-Match",
+			header:         http.Header{"If-None-Match": {`"v2"`}},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			desc:           "If-Modified-Since",
+			header:         http.Header{"If-Modified-Since": {lastModified}},
+			expectedStatus: http.StatusNotModified,
+		},
+		{
+			desc:           "older If-Modified-Since",
+			header:         http.Header{"If-Modified-Since": {time.Now().Add(-2 * time.Hour).UTC().Format(http.TimeFormat)}},
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "http://localhost/foo", nil)
+			req.Header = test.header
+
+			rw := serve(handler, req)
+			assert.Equal(t, statusHit, rw.Header().Get(cacheStatusHeader))
+			assert.Equal(t, test.expectedStatus, rw.Code)
+		})
+	}
+}
+
+func TestCache_staleWhileRevalidate(t *testing.T) {
+	var calls int32
+	revalidated := make(chan struct{}, 1)
+
+	next := http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+		n := atomic.AddInt32(&calls, 1)
+		rw.Header().Set("Cache-Control", "max-age=1, stale-while-revalidate=60")
+		rw.Header().Set("Age", "5")
+
+		if n == 1 {
+			_, _ = rw.Write([]byte("v1"))
+			return
+		}
+
+		_, _ = rw.Write([]byte("v2"))
+		revalidated <- struct{}{}
+	})
+
+	handler := newTestCache(t, dynamic.Cache{}, next)
+
+	serve(handler, httptest.NewRequest(http.MethodGet, "http://localhost/foo", nil))
+
+	rw := serve(handler, httptest.NewRequest(http.MethodGet, "http://localhost/foo", nil))
+	assert.Equal(t, statusStale, rw.Header().Get(cacheStatusHeader))
+	assert.Equal(t, "v1", rw.Body.String())
+
+	select {
+	case <-revalidated:
+	case <-time.After(5 * time.Second):
+		require.Fail(t, "the response has not been revalidated")
+	}
+
+	assert.Eventually(t, func() bool {
+		rw = serve(handler, httptest.NewRequest(http.MethodGet, "http://localhost/foo", nil))
+		return rw.Body.String() == "v2"
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestCache_coalescing(t *testing.T) {
+	const requests = 10
+
+	var calls int32
+	release := make(chan struct{})
+
+	next := http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		<-release
+
+		rw.Header().Set("Cache-Control", "max-age=60")
+		_, _ = rw.Write([]byte("foo"))
+	})
+
+	handler := newTestCache(t, dynamic.Cache{}, next)
+	c := handler.(*cache)
+
+	var wg sync.WaitGroup
+	bodies := make(chan string, requests)
+
+	for i := 0; i < requests; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			rw := serve(handler, httptest.NewRequest(http.MethodGet, "http://localhost/foo", nil))
+			bodies <- rw.Body.String()
+		}()
+	}
+
+	// Waits for the first request to reach the next handler before releasing it.
+	assert.Eventually(t, func() bool {
+		return atomic.LoadInt32(&calls) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+
+	c.calls.mu.Lock()
+	assert.Len(t, c.calls.calls, 1)
+	c.calls.mu.Unlock()
+
+	close(release)
+	wg.Wait()
+	close(bodies)
+
+	for body := range bodies {
+		assert.Equal(t, "foo", body)
+	}
+
+	assert.EqualValues(t, 1, atomic.LoadInt32(&calls))
+}
+
+func TestCache_coalescing_uncacheable(t *testing.T) {
+	var calls int32
+	release := make(chan struct{})
+
+	// The backend streams an uncacheable response, whose body is written once both requests reached it.
+	next := http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+		atomic.AddInt32(&calls, 1)
+
+		rw.Header().Set("Cache-Control", "no-store")
+		rw.WriteHeader(http.StatusOK)
+		rw.(http.Flusher).Flush()
+
+		<-release
+		_, _ = rw.Write([]byte("foo"))
+	})
+
+	handler := newTestCache(t, dynamic.Cache{}, next)
+
+	var wg sync.WaitGroup
+	bodies := make(chan string, 2)
+
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			rw := serve(handler, httptest.NewRequest(http.MethodGet, "http://localhost/foo", nil))
+			bodies <- rw.Body.String()
+		}()
+	}
+
+	// The second request is not held until the end of the first response.
+	assert.Eventually(t, func() bool {
+		return atomic.LoadInt32(&calls) == 2
+	}, 5*time.Second, 10*time.Millisecond)
+
+	close(release)
+	wg.Wait()
+	close(bodies)
+
+	for body := range bodies {
+		assert.Equal(t, "foo", body)
+	}
+}
+
+func TestCache_coalescing_canceled(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+
+	reached := make(chan struct{})
+	next := http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+		close(reached)
+		<-release
+
+		rw.Header().Set("Cache-Control", "max-age=60")
+		_, _ = rw.Write([]byte("foo"))
+	})
+
+	handler := newTestCache(t, dynamic.Cache{}, next)
+
+	go serve(handler, httptest.NewRequest(http.MethodGet, "http://localhost/foo", nil))
+	<-reached
+
+	ctx, cancel := context.WithCancel(context.Background())
+	req := httptest.NewRequest(http.MethodGet, "http://localhost/foo", nil).WithContext(ctx)
+
+	done := make(chan struct{})
+	go func() {
+		serve(handler, req)
+		close(done)
+	}()
+
+	cancel()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the canceled request is still waiting for the concurrent one")
+	}
+}
+
+func TestCache_unsafeMethodInvalidation(t *testing.T) {
+	var calls int32
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.Method == http.MethodGet {
+			atomic.AddInt32(&calls, 1)
+		}
+		rw.Header().Set("Cache-Control", "max-age=60")
+		_, _ = rw.Write([]byte("foo"))
+	})
+
+	handler := newTestCache(t, dynamic.Cache{}, next)
+
+	serve(handler, httptest.NewRequest(http.MethodGet, "http://localhost/foo", nil))
+	serve(handler, httptest.NewRequest(http.MethodGet, "http://localhost/foo", nil))
+	assert.EqualValues(t, 1, atomic.LoadInt32(&calls))
+
+	rw := serve(handler, httptest.NewRequest(http.MethodPost, "http://localhost/foo", nil))
+	assert.Empty(t, rw.Header().Get(cacheStatusHeader))
+
+	serve(handler, httptest.NewRequest(http.MethodGet, "http://localhost/foo", nil))
+	assert.EqualValues(t, 2, atomic.LoadInt32(&calls))
+}
+
+func TestCache_unsafeMethodInvalidation_variants(t *testing.T) {
+	var calls int32
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.Method == http.MethodGet {
+			atomic.AddInt32(&calls, 1)
+		}
+		rw.Header().Set("Cache-Control", "max-age=60")
+		rw.Header().Set("Vary", "Accept-Language")
+		_, _ = rw.Write([]byte(req.Header.Get("Accept-Language")))
+	})
+
+	handler := newTestCache(t, dynamic.Cache{}, next)
+
+	get := func(lang string) {
+		req := httptest.NewRequest(http.MethodGet, "http://localhost/foo", nil)
+		req.Header.Set("Accept-Language", lang)
+		serve(handler, req)
+	}
+
+	get("en")
+	get("fr")
+	get("en")
+	get("fr")
+	assert.EqualValues(t, 2, atomic.LoadInt32(&calls))
+
+	serve(handler, httptest.NewRequest(http.MethodDelete, "http://localhost/foo", nil))
+
+	get("en")
+	get("fr")
+	assert.EqualValues(t, 4, atomic.LoadInt32(&calls))
+}
+
+func TestCache_unsafeMethodInvalidation_location(t *testing.T) {
+	calls := map[string]int{}
+	var mu sync.Mutex
+
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.Method == http.MethodGet {
+			mu.Lock()
+			calls[req.Host+req.URL.Path]++
+			mu.Unlock()
+
+			rw.Header().Set("Cache-Control", "max-age=60")
+			_, _ = rw.Write([]byte("foo"))
+			return
+		}
+
+		rw.Header().Set("Location", "/items/1")
+		rw.Header().Set("Content-Location", "http://other.com/items/2")
+		rw.WriteHeader(http.StatusCreated)
+	})
+
+	handler := newTestCache(t, dynamic.Cache{}, next)
+
+	for _, target := range []string{"http://localhost/items/1", "http://other.com/items/2"} {
+		serve(handler, httptest.NewRequest(http.MethodGet, target, nil))
+		serve(handler, httptest.NewRequest(http.MethodGet, target, nil))
+	}
+
+	serve(handler, httptest.NewRequest(http.MethodPost, "http://localhost/items", nil))
+
+	for _, target := range []string{"http://localhost/items/1", "http://other.com/items/2"} {
+		serve(handler, httptest.NewRequest(http.MethodGet, target, nil))
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	// The Location on the same host is invalidated.
+	assert.Equal(t, 2, calls["localhost/items/1"])
+	// The Content-Location on another host is not.
+	assert.Equal(t, 1, calls["other.com/items/2"])
+}
+
+func TestCache_upgrade(t *testing.T) {
+	var calls int32
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		atomic.AddInt32(&calls, 1)
+
+		conn, brw, err := rw.(http.Hijacker).Hijack()
+		require.NoError(t, err)
+		defer func() { _ = conn.Close() }()
+
+		if req.Header.Get("Upgrade") != "" {
+			_, _ = brw.WriteString("HTTP/1.1 101 Switching Protocols\r\nConnection: Upgrade\r\nUpgrade: websocket\r\n\r\n")
+		} else {
+			_, _ = brw.WriteString("HTTP/1.1 200 OK\r\nCache-Control: max-age=60\r\nContent-Length: 3\r\n\r\nfoo")
+		}
+		_ = brw.Flush()
+	})
+
+	server := httptest.NewServer(newTestCache(t, dynamic.Cache{}, next))
+	defer server.Close()
+
+	req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+	require.NoError(t, err)
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Upgrade", "websocket")
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+
+	assert.Equal(t, http.StatusSwitchingProtocols, resp.StatusCode)
+
+	// A response written on a hijacked connection is not stored.
+	for i := 0; i < 2; i++ {
+		resp, err = http.Get(server.URL)
+		require.NoError(t, err)
+		_ = resp.Body.Close()
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Empty(t, resp.Header.Get(cacheStatusHeader))
+	}
+
+	assert.EqualValues(t, 3, atomic.LoadInt32(&calls))
+}
+
+func TestCache_diskStore(t *testing.T) {
+	dir := t.TempDir()
+
+	var calls int32
+	next := http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		rw.Header().Set("Cache-Control", "max-age=60")
+		rw.Header().Set("Vary", "Accept-Encoding")
+		_, _ = rw.Write([]byte("foo"))
+	})
+
+	config := dynamic.Cache{MaxEntries: 10, MaxResponseBodyBytes: 1024, DiskPath: dir}
+
+	t.Cleanup(func() {
+		removeSharedCache(t.Name() + "1")
+		removeSharedCache(t.Name() + "2")
+	})
+
+	handler, err := New(context.Background(), next, config, t.Name()+"1")
+	require.NoError(t, err)
+
+	serve(handler, httptest.NewRequest(http.MethodGet, "http://localhost/foo", nil))
+
+	// The stored responses are loaded by another cache using the same directory.
+	handler, err = New(context.Background(), next, config, t.Name()+"2")
+	require.NoError(t, err)
+
+	rw := serve(handler, httptest.NewRequest(http.MethodGet, "http://localhost/foo", nil))
+	assert.Equal(t, statusHit, rw.Header().Get(cacheStatusHeader))
+	assert.Equal(t, "foo", rw.Body.String())
+	assert.EqualValues(t, 1, atomic.LoadInt32(&calls))
+
+	assert.Equal(t, 2, Purge(t.Name()+"2", "/foo"))
+
+	rw = serve(handler, httptest.NewRequest(http.MethodGet, "http://localhost/foo", nil))
+	assert.Equal(t, statusMiss, rw.Header().Get(cacheStatusHeader))
+}
+
+func TestPurge(t *testing.T) {
+	next := http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+		rw.Header().Set("Cache-Control", "max-age=60")
+		_, _ = rw.Write([]byte("foo"))
+	})
+
+	handler := newTestCache(t, dynamic.Cache{}, next)
+
+	for _, path := range []string{"/foo", "/foo?bar=baz", "/bar"} {
+		serve(handler, httptest.NewRequest(http.MethodGet, "http://localhost"+path, nil))
+	}
+
+	assert.Equal(t, 0, Purge("unknown", ""))
+	assert.Equal(t, 2, Purge(t.Name(), "/foo"))
+	assert.Equal(t, 1, Purge(t.Name(), ""))
+}
+
+func TestPrune(t *testing.T) {
+	for _, name := range []string{"kept", "removed"} {
+		_, err := New(context.Background(), http.NotFoundHandler(), dynamic.Cache{MaxEntries: 10, MaxResponseBodyBytes: 1024}, name)
+		require.NoError(t, err)
+
+		name := name
+		t.Cleanup(func() { removeSharedCache(name) })
+	}
+
+	Prune(map[string]struct{}{"kept": {}})
+
+	sharedCachesMu.Lock()
+	defer sharedCachesMu.Unlock()
+
+	assert.Contains(t, sharedCaches, "kept")
+	assert.NotContains(t, sharedCaches, "removed")
+}
+
+func TestLRU(t *testing.T) {
+	var evicted []string
+	l := newLRU(2, func(key string, _ interface{}) {
+		evicted = append(evicted, key)
+	})
+
+	l.add("a", 1)
+	l.add("b", 2)
+
+	_, ok := l.get("a")
+	require.True(t, ok)
+
+	l.add("c", 3)
+	assert.Equal(t, []string{"b"}, evicted)
+
+	_, ok = l.get("b")
+	assert.False(t, ok)
+
+	l.remove("a")
+	assert.Equal(t, []string{"b", "a"}, evicted)
+}
+
+func TestParseCacheControl(t *testing.T) {
+	header := http.Header{"Cache-Control": {`Max-Age=60, no-cache="Set-Cookie"`, "public"}}
+
+	cc := parseCacheControl(header)
+
+	assert.Equal(t, cacheControl{"max-age": "60", "no-cache": "Set-Cookie", "public": ""}, cc)
+
+	maxAge, ok := cc.duration("max-age")
+	assert.True(t, ok)
+	assert.Equal(t, time.Minute, maxAge)
+
+	_, ok = cc.duration("public")
+	assert.False(t, ok)
+}
@@ -0,0 +1,221 @@
+package cache
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cacheableStatusCodes are the status codes which are cacheable by default (RFC 7231 section 6.1).
+var cacheableStatusCodes = map[int]bool{
+	http.StatusOK:                   true,
+	http.StatusNonAuthoritativeInfo: true,
+	http.StatusNoContent:            true,
+	http.StatusMultipleChoices:      true,
+	http.StatusMovedPermanently:     true,
+	http.StatusNotFound:             true,
+	http.StatusMethodNotAllowed:     true,
+	http.StatusGone:                 true,
+	http.StatusRequestURITooLong:    true,
+	http.StatusNotImplemented:       true,
+}
+
+// entry is a cached response.
+// Entries are never modified once stored, a revalidated response being stored as a new entry.
+type entry struct {
+	// Key is the key under which the entry is stored.
+	Key string
+	// Path is the path of the request, used to purge the entries.
+	Path string
+	// VaryHeaders are the names of the request headers listed in the Vary header of the response.
+	// When they are not empty, the response is stored under a secondary key built from their values,
+	// and a marker entry with only the header names is stored under the primary key.
+	VaryHeaders []string
+	// Marker is set on the marker entries.
+	Marker bool
+
+	Status int
+	Header http.Header
+	Body   []byte
+
+	// Date is the time at which the response was generated by the origin server, as seen by the cache.
+	Date time.Time
+	// Lifetime is the freshness lifetime of the response.
+	Lifetime time.Duration
+	// StaleWhileRevalidate is the duration during which the response can be served while stale,
+	// and revalidated in the background.
+	StaleWhileRevalidate time.Duration
+}
+
+func (e *entry) age(now time.Time) time.Duration {
+	age := now.Sub(e.Date)
+	if age < 0 {
+		return 0
+	}
+	return age
+}
+
+func (e *entry) hasValidators() bool {
+	return e.Header.Get("ETag") != "" || e.Header.Get("Last-Modified") != ""
+}
+
+// notModified reports whether the conditional headers of the request match the entry,
+// in which case a 304 Not Modified response can be sent instead of the entry.
+func (e *entry) notModified(req *http.Request) bool {
+	if e.Status != http.StatusOK {
+		return false
+	}
+
+	if inm := req.Header.Get("If-None-Match"); inm != "" {
+		etag := e.Header.Get("ETag")
+		if etag == "" {
+			return false
+		}
+
+		for _, candidate := range strings.Split(inm, ",") {
+			candidate = strings.TrimSpace(candidate)
+			if candidate == "*" || weakETag(candidate) == weakETag(etag) {
+				return true
+			}
+		}
+		return false
+	}
+
+	ims, err := http.ParseTime(req.Header.Get("If-Modified-Since"))
+	if err != nil {
+		return false
+	}
+
+	lastModified, err := http.ParseTime(e.Header.Get("Last-Modified"))
+	if err != nil {
+		return false
+	}
+
+	return !lastModified.After(ims)
+}
+
+func weakETag(etag string) string {
+	return strings.TrimPrefix(etag, "W/")
+}
+
+// cacheControl holds the directives of a Cache-Control header.
+type cacheControl map[string]string
+
+func parseCacheControl(header http.Header) cacheControl {
+	cc := cacheControl{}
+
+	for _, value := range header.Values("Cache-Control") {
+		for _, directive := range strings.Split(value, ",") {
+			directive = strings.TrimSpace(directive)
+			if directive == "" {
+				continue
+			}
+
+			name, arg := directive, ""
+			if i := strings.Index(directive, "="); i >= 0 {
+				name, arg = directive[:i], strings.Trim(strings.TrimSpace(directive[i+1:]), `"`)
+			}
+
+			cc[strings.ToLower(strings.TrimSpace(name))] = arg
+		}
+	}
+
+	return cc
+}
+
+func (c cacheControl) has(directive string) bool {
+	_, ok := c[directive]
+	return ok
+}
+
+// duration returns the value, in seconds, of a delta-seconds directive.
+func (c cacheControl) duration(directive string) (time.Duration, bool) {
+	value, ok := c[directive]
+	if !ok {
+		return 0, false
+	}
+
+	seconds, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || seconds < 0 {
+		return 0, false
+	}
+
+	return time.Duration(seconds) * time.Second, true
+}
+
+// noCacheRequest reports whether the request requires the cached response to be revalidated.
+func noCacheRequest(req *http.Request, cc cacheControl) bool {
+	if len(req.Header.Values("Cache-Control")) == 0 {
+		return strings.Contains(strings.ToLower(req.Header.Get("Pragma")), "no-cache")
+	}
+	return cc.has("no-cache")
+}
+
+// freshness returns the freshness lifetime of a response, and whether it is explicit.
+func freshness(header http.Header, cc cacheControl, date time.Time) (time.Duration, bool) {
+	if lifetime, ok := cc.duration("s-maxage"); ok {
+		return lifetime, true
+	}
+
+	if lifetime, ok := cc.duration("max-age"); ok {
+		return lifetime, true
+	}
+
+	if value := header.Get("Expires"); value != "" {
+		expires, err := http.ParseTime(value)
+		if err != nil || expires.Before(date) {
+			// An invalid Expires header means the response is already expired.
+			return 0, true
+		}
+		return expires.Sub(date), true
+	}
+
+	return 0, false
+}
+
+// initialAge computes the age of a response when it is received (RFC 7234 section 4.2.3).
+func initialAge(header http.Header, date, requestTime, responseTime time.Time) time.Duration {
+	apparentAge := responseTime.Sub(date)
+	if apparentAge < 0 {
+		apparentAge = 0
+	}
+
+	var ageValue time.Duration
+	if seconds, err := strconv.ParseInt(header.Get("Age"), 10, 64); err == nil && seconds > 0 {
+		ageValue = time.Duration(seconds) * time.Second
+	}
+
+	correctedAgeValue := ageValue + responseTime.Sub(requestTime)
+	if correctedAgeValue > apparentAge {
+		return correctedAgeValue
+	}
+	return apparentAge
+}
+
+// varyHeaders returns the canonical names of the request headers listed in the Vary header of a response.
+func varyHeaders(header http.Header) []string {
+	var names []string
+	for _, value := range header.Values("Vary") {
+		for _, name := range strings.Split(value, ",") {
+			name = strings.TrimSpace(name)
+			if name != "" {
+				names = append(names, http.CanonicalHeaderKey(name))
+			}
+		}
+	}
+	return names
+}
+
+// variantKey returns the secondary key of a request, built from the values of the Vary headers.
+func variantKey(key string, names []string, req *http.Request) string {
+	var b strings.Builder
+	b.WriteString(key)
+	for _, name := range names {
+		b.WriteByte(0)
+		b.WriteString(name)
+		b.WriteByte('=')
+		b.WriteString(strings.Join(req.Header.Values(name), ","))
+	}
+	return b.String()
+}
@@ -0,0 +1,175 @@
+package cache
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+)
+
+// statusRecorder captures the status code of a response.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	if r.status == 0 {
+		r.status = status
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *statusRecorder) Write(b []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	return r.ResponseWriter.Write(b)
+}
+
+// Flush sends any buffered data to the client.
+func (r *statusRecorder) Flush() {
+	if f, ok := r.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Hijack hijacks the connection.
+func (r *statusRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	if h, ok := r.ResponseWriter.(http.Hijacker); ok {
+		return h.Hijack()
+	}
+	return nil, nil, fmt.Errorf("%T is not a http.Hijacker", r.ResponseWriter)
+}
+
+// responseRecorder records a response to store it, while forwarding it to the client.
+type responseRecorder struct {
+	// rw is the writer of the client, or nil when the response is fetched in the background.
+	rw     http.ResponseWriter
+	header http.Header
+	code   int
+	body   []byte
+
+	maxBodySize int64
+	// tooLarge is set when the body is larger than maxBodySize, in which case it is not recorded anymore.
+	tooLarge bool
+
+	// holdNotModified is set when the request revalidates a stored response:
+	// a 304 Not Modified response is then recorded, but not forwarded to the client.
+	holdNotModified bool
+	held            bool
+
+	// cacheStatusValue is the value of the cache status header of the forwarded response.
+	cacheStatusValue string
+	forwarding       bool
+
+	// hijacked is set when the next handler takes over the connection, in which case the response is not stored.
+	hijacked bool
+
+	// storable reports whether a response with the given status code and headers can be stored.
+	storable func(code int, header http.Header) bool
+	// release is called as soon as the response is known not to be stored.
+	release func()
+}
+
+func (r *responseRecorder) Header() http.Header {
+	return r.header
+}
+
+func (r *responseRecorder) WriteHeader(code int) {
+	if r.code != 0 {
+		return
+	}
+	r.code = code
+
+	if code == http.StatusNotModified && r.holdNotModified {
+		r.held = true
+		return
+	}
+
+	if r.storable != nil && !r.storable(code, r.header) {
+		r.doRelease()
+	}
+
+	if r.rw == nil {
+		return
+	}
+
+	header := r.rw.Header()
+	for name, values := range r.header {
+		header[name] = append([]string(nil), values...)
+	}
+	header.Set(cacheStatusHeader, r.cacheStatusValue)
+
+	r.rw.WriteHeader(code)
+	r.forwarding = true
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	if r.code == 0 {
+		r.WriteHeader(http.StatusOK)
+	}
+
+	if !r.tooLarge {
+		if int64(len(r.body)+len(b)) > r.maxBodySize {
+			r.tooLarge = true
+			r.body = nil
+			r.doRelease()
+		} else {
+			r.body = append(r.body, b...)
+		}
+	}
+
+	if r.forwarding {
+		return r.rw.Write(b)
+	}
+	return len(b), nil
+}
+
+// Flush sends any buffered data to the client.
+func (r *responseRecorder) Flush() {
+	if r.code == 0 {
+		r.WriteHeader(http.StatusOK)
+	}
+
+	if !r.forwarding {
+		return
+	}
+
+	if f, ok := r.rw.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Hijack hijacks the connection of the client, the response is then not stored.
+func (r *responseRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	if r.rw == nil {
+		return nil, nil, errors.New("no client connection to hijack")
+	}
+
+	h, ok := r.rw.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("%T is not a http.Hijacker", r.rw)
+	}
+
+	r.hijacked = true
+	r.body = nil
+	r.doRelease()
+
+	return h.Hijack()
+}
+
+// doRelease calls release, if any.
+func (r *responseRecorder) doRelease() {
+	if r.release != nil {
+		r.release()
+	}
+}
+
+// finish writes the headers of the response if the next handler did not write anything.
+func (r *responseRecorder) finish() {
+	if r.code == 0 && !r.hijacked {
+		r.WriteHeader(http.StatusOK)
+	}
+}
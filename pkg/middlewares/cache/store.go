@@ -0,0 +1,272 @@
+package cache
+
+import (
+	"bytes"
+	"container/list"
+	"crypto/sha256"
+	"encoding/gob"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+const tmpFileSuffix = ".tmp"
+
+// store keeps the cached responses.
+type store interface {
+	get(key string) (*entry, bool)
+	set(e *entry) error
+	remove(key string)
+	// removeFunc removes the entries for which fn returns true, and returns the number of removed entries.
+	removeFunc(fn func(key, path string) bool) int
+}
+
+// lru is a thread-safe least recently used list, bounded by a maximum number of items.
+type lru struct {
+	mu         sync.Mutex
+	maxEntries int
+	ll         *list.List
+	items      map[string]*list.Element
+	// onEvict is called, with the lock held, when an item is removed from the list.
+	onEvict func(key string, value interface{})
+}
+
+type lruItem struct {
+	key   string
+	value interface{}
+}
+
+func newLRU(maxEntries int, onEvict func(key string, value interface{})) *lru {
+	return &lru{
+		maxEntries: maxEntries,
+		ll:         list.New(),
+		items:      make(map[string]*list.Element),
+		onEvict:    onEvict,
+	}
+}
+
+func (l *lru) get(key string) (interface{}, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	elt, ok := l.items[key]
+	if !ok {
+		return nil, false
+	}
+
+	l.ll.MoveToFront(elt)
+	return elt.Value.(*lruItem).value, true
+}
+
+func (l *lru) add(key string, value interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if elt, ok := l.items[key]; ok {
+		l.ll.MoveToFront(elt)
+		elt.Value.(*lruItem).value = value
+		return
+	}
+
+	l.items[key] = l.ll.PushFront(&lruItem{key: key, value: value})
+
+	for l.ll.Len() > l.maxEntries {
+		l.removeElement(l.ll.Back())
+	}
+}
+
+func (l *lru) remove(key string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if elt, ok := l.items[key]; ok {
+		l.removeElement(elt)
+	}
+}
+
+func (l *lru) removeFunc(fn func(key string, value interface{}) bool) int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var removed int
+	for elt := l.ll.Front(); elt != nil; {
+		next := elt.Next()
+
+		item := elt.Value.(*lruItem)
+		if fn(item.key, item.value) {
+			l.removeElement(elt)
+			removed++
+		}
+
+		elt = next
+	}
+
+	return removed
+}
+
+func (l *lru) removeElement(elt *list.Element) {
+	item := l.ll.Remove(elt).(*lruItem)
+	delete(l.items, item.key)
+
+	if l.onEvict != nil {
+		l.onEvict(item.key, item.value)
+	}
+}
+
+// memoryStore keeps the entries in memory.
+type memoryStore struct {
+	entries *lru
+}
+
+func newMemoryStore(maxEntries int) *memoryStore {
+	return &memoryStore{entries: newLRU(maxEntries, nil)}
+}
+
+func (s *memoryStore) get(key string) (*entry, bool) {
+	value, ok := s.entries.get(key)
+	if !ok {
+		return nil, false
+	}
+	return value.(*entry), true
+}
+
+func (s *memoryStore) set(e *entry) error {
+	s.entries.add(e.Key, e)
+	return nil
+}
+
+func (s *memoryStore) remove(key string) {
+	s.entries.remove(key)
+}
+
+func (s *memoryStore) removeFunc(fn func(key, path string) bool) int {
+	return s.entries.removeFunc(func(key string, value interface{}) bool {
+		return fn(key, value.(*entry).Path)
+	})
+}
+
+// diskStore keeps the entries in files, one per entry, named after the hash of their key.
+// Only the keys and paths of the entries are kept in memory.
+type diskStore struct {
+	dir   string
+	index *lru
+}
+
+// newDiskStore creates a diskStore, and indexes the entries already stored in dir.
+func newDiskStore(dir string, maxEntries int) (*diskStore, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+
+	s := &diskStore{dir: dir}
+	s.index = newLRU(maxEntries, func(key string, _ interface{}) {
+		_ = os.Remove(s.filename(key))
+	})
+
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	// The most recently modified files are added last, so that they are the last evicted ones.
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].ModTime().Before(files[j].ModTime())
+	})
+
+	for _, file := range files {
+		if file.IsDir() {
+			continue
+		}
+
+		filename := filepath.Join(dir, file.Name())
+		if strings.HasSuffix(file.Name(), tmpFileSuffix) {
+			_ = os.Remove(filename)
+			continue
+		}
+
+		e, err := readEntry(filename)
+		if err != nil || s.filename(e.Key) != filename {
+			continue
+		}
+
+		s.index.add(e.Key, e.Path)
+	}
+
+	return s, nil
+}
+
+func (s *diskStore) get(key string) (*entry, bool) {
+	if _, ok := s.index.get(key); !ok {
+		return nil, false
+	}
+
+	e, err := readEntry(s.filename(key))
+	if err != nil {
+		s.index.remove(key)
+		return nil, false
+	}
+
+	return e, true
+}
+
+func (s *diskStore) set(e *entry) error {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(e); err != nil {
+		return err
+	}
+
+	tmp, err := ioutil.TempFile(s.dir, "*"+tmpFileSuffix)
+	if err != nil {
+		return err
+	}
+
+	_, err = tmp.Write(buf.Bytes())
+	if errClose := tmp.Close(); err == nil {
+		err = errClose
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), s.filename(e.Key))
+	}
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+		return fmt.Errorf("unable to write cache entry: %w", err)
+	}
+
+	s.index.add(e.Key, e.Path)
+	return nil
+}
+
+func (s *diskStore) remove(key string) {
+	s.index.remove(key)
+}
+
+func (s *diskStore) removeFunc(fn func(key, path string) bool) int {
+	return s.index.removeFunc(func(key string, value interface{}) bool {
+		return fn(key, value.(string))
+	})
+}
+
+func (s *diskStore) filename(key string) string {
+	hash := sha256.Sum256([]byte(key))
+	return filepath.Join(s.dir, hex.EncodeToString(hash[:]))
+}
+
+func readEntry(filename string) (*entry, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = file.Close() }()
+
+	var e entry
+	if err := gob.NewDecoder(file).Decode(&e); err != nil {
+		return nil, err
+	}
+
+	return &e, nil
+}
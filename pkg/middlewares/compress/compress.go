@@ -3,9 +3,14 @@ package compress
 import (
 	"compress/gzip"
 	"context"
+	"fmt"
 	"mime"
 	"net/http"
+	"strconv"
+	"strings"
 
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/zstd"
 	"github.com/opentracing/opentracing-go/ext"
 	"github.com/traefik/gziphandler"
 	"github.com/traefik/traefik/v2/pkg/config/dynamic"
@@ -18,11 +23,32 @@ const (
 	typeName = "Compress"
 )
 
+const (
+	brotliEncoding = "br"
+	zstdEncoding   = "zstd"
+	gzipEncoding   = "gzip"
+)
+
+// maxZstdLevel is the highest level of the zstd command line tool, to which the zstd levels correspond.
+const maxZstdLevel = 22
+
+// encodingPreferences ranks the supported encodings, to choose between the ones with the same q-value.
+var encodingPreferences = map[string]int{
+	gzipEncoding:   1,
+	zstdEncoding:   2,
+	brotliEncoding: 3,
+}
+
 // Compress is a middleware that allows to compress the response.
 type compress struct {
 	next     http.Handler
 	name     string
 	excludes []string
+	// The zero values of minSize and of the levels stand for their default values.
+	minSize     int
+	gzipLevel   int
+	brotliLevel int
+	zstdLevel   int
 }
 
 // New creates a new compress middleware.
@@ -39,7 +65,31 @@ func New(ctx context.Context, next http.Handler, conf dynamic.Compress, name str
 		excludes = append(excludes, mediaType)
 	}
 
-	return &compress{next: next, name: name, excludes: excludes}, nil
+	if conf.MinResponseBodyBytes < 0 {
+		return nil, fmt.Errorf("invalid minimum response body size: %d", conf.MinResponseBodyBytes)
+	}
+
+	if conf.GzipLevel != 0 && (conf.GzipLevel < gzip.BestSpeed || conf.GzipLevel > gzip.BestCompression) {
+		return nil, fmt.Errorf("invalid gzip compression level: %d", conf.GzipLevel)
+	}
+
+	if conf.BrotliLevel != 0 && (conf.BrotliLevel < 1 || conf.BrotliLevel > brotli.BestCompression) {
+		return nil, fmt.Errorf("invalid brotli compression level: %d", conf.BrotliLevel)
+	}
+
+	if conf.ZstdLevel != 0 && (conf.ZstdLevel < 1 || conf.ZstdLevel > maxZstdLevel) {
+		return nil, fmt.Errorf("invalid zstd compression level: %d", conf.ZstdLevel)
+	}
+
+	return &compress{
+		next:        next,
+		name:        name,
+		excludes:    excludes,
+		minSize:     conf.MinResponseBodyBytes,
+		gzipLevel:   conf.GzipLevel,
+		brotliLevel: conf.BrotliLevel,
+		zstdLevel:   conf.ZstdLevel,
+	}, nil
 }
 
 func (c *compress) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
@@ -50,8 +100,15 @@ func (c *compress) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 
 	if contains(c.excludes, mediaType) {
 		c.next.ServeHTTP(rw, req)
-	} else {
-		ctx := middlewares.GetLoggerCtx(req.Context(), c.name, typeName)
+		return
+	}
+
+	ctx := middlewares.GetLoggerCtx(req.Context(), c.name, typeName)
+
+	switch encoding := negotiateEncoding(req.Header.Values(acceptEncoding)); encoding {
+	case brotliEncoding, zstdEncoding:
+		c.serveEncoded(ctx, rw, req, encoding)
+	default:
 		c.gzipHandler(ctx).ServeHTTP(rw, req)
 	}
 }
@@ -61,10 +118,15 @@ func (c *compress) GetTracingInformation() (string, ext.SpanKindEnum) {
 }
 
 func (c *compress) gzipHandler(ctx context.Context) http.Handler {
+	level := gzip.DefaultCompression
+	if c.gzipLevel != 0 {
+		level = c.gzipLevel
+	}
+
 	wrapper, err := gziphandler.GzipHandlerWithOpts(
 		gziphandler.ContentTypeExceptions(c.excludes),
-		gziphandler.CompressionLevel(gzip.DefaultCompression),
-		gziphandler.MinSize(gziphandler.DefaultMinSize))
+		gziphandler.CompressionLevel(level),
+		gziphandler.MinSize(c.minResponseBodyBytes()))
 	if err != nil {
 		log.FromContext(ctx).Error(err)
 	}
@@ -72,6 +134,107 @@ func (c *compress) gzipHandler(ctx context.Context) http.Handler {
 	return wrapper(c.next)
 }
 
+func (c *compress) serveEncoded(ctx context.Context, rw http.ResponseWriter, req *http.Request, encoding string) {
+	rw.Header().Add(vary, acceptEncoding)
+
+	ew := &encodingResponseWriter{
+		ResponseWriter: rw,
+		encoding:       encoding,
+		level:          c.level(encoding),
+		minSize:        c.minResponseBodyBytes(),
+		excludes:       c.excludes,
+	}
+
+	defer func() {
+		if err := ew.Close(); err != nil {
+			log.FromContext(ctx).Debugf("Error while closing the %s response: %v", encoding, err)
+		}
+	}()
+
+	if _, ok := rw.(http.CloseNotifier); ok {
+		c.next.ServeHTTP(encodingResponseWriterWithCloseNotify{ew}, req)
+	} else {
+		c.next.ServeHTTP(ew, req)
+	}
+}
+
+// level returns the level of the brotli writer, or of the zstd encoder, for the encoding.
+func (c *compress) level(encoding string) int {
+	if encoding == zstdEncoding {
+		if c.zstdLevel == 0 {
+			return int(zstd.SpeedDefault)
+		}
+		return int(zstd.EncoderLevelFromZstd(c.zstdLevel))
+	}
+
+	if c.brotliLevel == 0 {
+		return brotli.DefaultCompression
+	}
+	return c.brotliLevel
+}
+
+func (c *compress) minResponseBodyBytes() int {
+	if c.minSize == 0 {
+		return gziphandler.DefaultMinSize
+	}
+	return c.minSize
+}
+
+// negotiateEncoding returns the supported encoding with the highest q-value in the Accept-Encoding header values,
+// brotli, then zstd, being preferred to gzip when they have the same q-value,
+// or an empty string if none of them is accepted.
+func negotiateEncoding(values []string) string {
+	var encoding string
+	var bestQ float64
+
+	for _, value := range values {
+		for _, coding := range strings.Split(value, ",") {
+			name, q, ok := parseCoding(coding)
+			if !ok || q <= 0 {
+				continue
+			}
+
+			preference, supported := encodingPreferences[name]
+			if !supported {
+				continue
+			}
+
+			if q > bestQ || (q == bestQ && preference > encodingPreferences[encoding]) {
+				encoding = name
+				bestQ = q
+			}
+		}
+	}
+
+	return encoding
+}
+
+// parseCoding parses a content-coding of the Accept-Encoding header, with its optional q-value.
+func parseCoding(coding string) (string, float64, bool) {
+	parts := strings.Split(coding, ";")
+
+	name := strings.ToLower(strings.TrimSpace(parts[0]))
+	if name == "" {
+		return "", 0, false
+	}
+
+	q := 1.0
+	for _, param := range parts[1:] {
+		param = strings.TrimSpace(param)
+		if !strings.HasPrefix(param, "q=") {
+			continue
+		}
+
+		var err error
+		q, err = strconv.ParseFloat(strings.TrimPrefix(param, "q="), 64)
+		if err != nil {
+			return "", 0, false
+		}
+	}
+
+	return name, q, true
+}
+
 func contains(values []string, val string) bool {
 	for _, v := range values {
 		if v == val {
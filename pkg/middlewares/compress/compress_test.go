@@ -5,8 +5,11 @@ import (
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
 	"testing"
 
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/zstd"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/traefik/gziphandler"
@@ -20,6 +23,8 @@ const (
 	contentTypeHeader     = "Content-Type"
 	varyHeader            = "Vary"
 	gzipValue             = "gzip"
+	brotliValue           = "br"
+	zstdValue             = "zstd"
 )
 
 func TestShouldCompressWhenNoContentEncodingHeader(t *testing.T) {
@@ -296,6 +301,342 @@ func TestIntegrationShouldCompress(t *testing.T) {
 	}
 }
 
+func TestShouldCompressWithBrotli(t *testing.T) {
+	baseBody := generateBytes(gziphandler.DefaultMinSize)
+
+	testCases := []struct {
+		desc             string
+		acceptEncoding   string
+		respContentType  string
+		respEncoding     string
+		expectedEncoding string
+	}{
+		{
+			desc:             "br and gzip",
+			acceptEncoding:   "gzip, deflate, br",
+			expectedEncoding: brotliValue,
+		},
+		{
+			desc:             "gzip preferred with q-values",
+			acceptEncoding:   "br;q=0.5, gzip",
+			expectedEncoding: gzipValue,
+		},
+		{
+			desc:             "br refused",
+			acceptEncoding:   "br;q=0, gzip;q=0.1",
+			expectedEncoding: gzipValue,
+		},
+		{
+			desc:            "excluded response Content-Type",
+			acceptEncoding:  brotliValue,
+			respContentType: "application/grpc",
+		},
+		{
+			desc:             "already encoded",
+			acceptEncoding:   brotliValue,
+			respEncoding:     gzipValue,
+			expectedEncoding: gzipValue,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			req := testhelpers.MustNewRequest(http.MethodGet, "http://localhost", nil)
+			req.Header.Add(acceptEncodingHeader, test.acceptEncoding)
+
+			next := http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+				if test.respContentType != "" {
+					rw.Header().Set(contentTypeHeader, test.respContentType)
+				}
+				if test.respEncoding != "" {
+					rw.Header().Set(contentEncodingHeader, test.respEncoding)
+				}
+
+				_, err := rw.Write(baseBody)
+				assert.NoError(t, err)
+			})
+			handler := &compress{next: next, excludes: []string{"application/grpc"}}
+
+			rw := httptest.NewRecorder()
+			handler.ServeHTTP(rw, req)
+
+			assert.Equal(t, test.expectedEncoding, rw.Header().Get(contentEncodingHeader))
+			assert.Equal(t, acceptEncodingHeader, rw.Header().Get(varyHeader))
+
+			if test.expectedEncoding != brotliValue {
+				return
+			}
+
+			body, err := ioutil.ReadAll(brotli.NewReader(rw.Body))
+			require.NoError(t, err)
+			assert.Equal(t, baseBody, body)
+		})
+	}
+}
+
+func TestShouldCompressWithZstd(t *testing.T) {
+	baseBody := generateBytes(gziphandler.DefaultMinSize)
+
+	next := http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		_, err := rw.Write(baseBody)
+		assert.NoError(t, err)
+	})
+
+	handler, err := New(context.Background(), next, dynamic.Compress{ZstdLevel: 19}, "test")
+	require.NoError(t, err)
+
+	req := testhelpers.MustNewRequest(http.MethodGet, "http://localhost", nil)
+	req.Header.Add(acceptEncodingHeader, "gzip, zstd")
+
+	rw := httptest.NewRecorder()
+	handler.ServeHTTP(rw, req)
+
+	assert.Equal(t, zstdValue, rw.Header().Get(contentEncodingHeader))
+	assert.Equal(t, acceptEncodingHeader, rw.Header().Get(varyHeader))
+
+	decoder, err := zstd.NewReader(rw.Body)
+	require.NoError(t, err)
+	defer decoder.Close()
+
+	body, err := ioutil.ReadAll(decoder)
+	require.NoError(t, err)
+	assert.Equal(t, baseBody, body)
+}
+
+func TestShouldCompressAfterEmptyWrite(t *testing.T) {
+	baseBody := generateBytes(3000)
+
+	testCases := []struct {
+		desc           string
+		acceptEncoding string
+	}{
+		{
+			desc:           "br",
+			acceptEncoding: brotliValue,
+		},
+		{
+			desc:           "zstd",
+			acceptEncoding: zstdValue,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			next := http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+				rw.Header().Set("Content-Length", strconv.Itoa(len(baseBody)))
+
+				_, err := rw.Write(nil)
+				assert.NoError(t, err)
+
+				_, err = rw.Write(baseBody)
+				assert.NoError(t, err)
+			})
+			handler := &compress{next: next}
+
+			req := testhelpers.MustNewRequest(http.MethodGet, "http://localhost", nil)
+			req.Header.Add(acceptEncodingHeader, test.acceptEncoding)
+
+			rw := httptest.NewRecorder()
+			handler.ServeHTTP(rw, req)
+
+			require.Equal(t, test.acceptEncoding, rw.Header().Get(contentEncodingHeader))
+			assert.Empty(t, rw.Header().Get("Content-Length"))
+			assert.NotEqual(t, baseBody, rw.Body.Bytes())
+
+			var body []byte
+			var err error
+			if test.acceptEncoding == brotliValue {
+				body, err = ioutil.ReadAll(brotli.NewReader(rw.Body))
+			} else {
+				var decoder *zstd.Decoder
+				decoder, err = zstd.NewReader(rw.Body)
+				require.NoError(t, err)
+				defer decoder.Close()
+
+				body, err = ioutil.ReadAll(decoder)
+			}
+			require.NoError(t, err)
+			assert.Equal(t, baseBody, body)
+		})
+	}
+}
+
+func TestMinResponseBodyBytes(t *testing.T) {
+	testCases := []struct {
+		desc             string
+		acceptEncoding   string
+		bodySize         int
+		expectedEncoding string
+	}{
+		{
+			desc:             "gzip, body larger than the minimum",
+			acceptEncoding:   gzipValue,
+			bodySize:         200,
+			expectedEncoding: gzipValue,
+		},
+		{
+			desc:           "gzip, body smaller than the minimum",
+			acceptEncoding: gzipValue,
+			bodySize:       50,
+		},
+		{
+			desc:             "br, body larger than the minimum",
+			acceptEncoding:   brotliValue,
+			bodySize:         200,
+			expectedEncoding: brotliValue,
+		},
+		{
+			desc:           "br, body smaller than the minimum",
+			acceptEncoding: brotliValue,
+			bodySize:       50,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			baseBody := generateBytes(test.bodySize)
+
+			next := http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+				_, err := rw.Write(baseBody)
+				assert.NoError(t, err)
+			})
+
+			handler, err := New(context.Background(), next, dynamic.Compress{MinResponseBodyBytes: 100, GzipLevel: 9, BrotliLevel: 11}, "test")
+			require.NoError(t, err)
+
+			req := testhelpers.MustNewRequest(http.MethodGet, "http://localhost", nil)
+			req.Header.Add(acceptEncodingHeader, test.acceptEncoding)
+
+			rw := httptest.NewRecorder()
+			handler.ServeHTTP(rw, req)
+
+			assert.Equal(t, test.expectedEncoding, rw.Header().Get(contentEncodingHeader))
+
+			if test.expectedEncoding == "" {
+				assert.Equal(t, baseBody, rw.Body.Bytes())
+			}
+		})
+	}
+}
+
+func TestNewInvalidConfiguration(t *testing.T) {
+	testCases := []struct {
+		desc string
+		conf dynamic.Compress
+	}{
+		{
+			desc: "negative minimum response body size",
+			conf: dynamic.Compress{MinResponseBodyBytes: -1},
+		},
+		{
+			desc: "invalid gzip level",
+			conf: dynamic.Compress{GzipLevel: 10},
+		},
+		{
+			desc: "invalid brotli level",
+			conf: dynamic.Compress{BrotliLevel: 12},
+		},
+		{
+			desc: "invalid zstd level",
+			conf: dynamic.Compress{ZstdLevel: 23},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := New(context.Background(), http.NotFoundHandler(), test.conf, "test")
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestNegotiateEncoding(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		values   []string
+		expected string
+	}{
+		{
+			desc: "no header",
+		},
+		{
+			desc:   "unsupported encodings",
+			values: []string{"deflate, identity"},
+		},
+		{
+			desc:     "gzip",
+			values:   []string{"gzip"},
+			expected: gzipValue,
+		},
+		{
+			desc:     "br preferred on same q-value",
+			values:   []string{"gzip, br"},
+			expected: brotliValue,
+		},
+		{
+			desc:     "zstd preferred to gzip on same q-value",
+			values:   []string{"gzip, zstd"},
+			expected: zstdValue,
+		},
+		{
+			desc:     "br preferred to zstd on same q-value",
+			values:   []string{"zstd, br, gzip"},
+			expected: brotliValue,
+		},
+		{
+			desc:     "zstd with the highest q-value",
+			values:   []string{"br;q=0.5, zstd;q=0.9, gzip;q=0.7"},
+			expected: zstdValue,
+		},
+		{
+			desc:     "highest q-value",
+			values:   []string{"br;q=0.8, gzip;q=0.9"},
+			expected: gzipValue,
+		},
+		{
+			desc:     "case insensitive with spaces",
+			values:   []string{"GZIP ; q=0.2 , BR ; q=0.3"},
+			expected: brotliValue,
+		},
+		{
+			desc:     "several headers",
+			values:   []string{"gzip;q=0.5", "br"},
+			expected: brotliValue,
+		},
+		{
+			desc:     "refused encoding",
+			values:   []string{"br;q=0, gzip;q=0.1"},
+			expected: gzipValue,
+		},
+		{
+			desc:     "invalid q-value",
+			values:   []string{"br;q=foo, gzip"},
+			expected: gzipValue,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, test.expected, negotiateEncoding(test.values))
+		})
+	}
+}
+
 func generateBytes(length int) []byte {
 	var value []byte
 	for i := 0; i < length; i++ {
@@ -0,0 +1,245 @@
+package compress
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/zstd"
+)
+
+const (
+	vary            = "Vary"
+	acceptEncoding  = "Accept-Encoding"
+	contentEncoding = "Content-Encoding"
+	contentLength   = "Content-Length"
+	contentType     = "Content-Type"
+)
+
+// zstdWindowSize is the maximum window size of the zstd encoder,
+// the one the clients are required to support for the zstd content-coding (RFC 8878).
+const zstdWindowSize = 8 << 20
+
+// encoder is a streaming compressor which is reset to be reused for several responses.
+type encoder interface {
+	io.WriteCloser
+	Flush() error
+	Reset(w io.Writer)
+}
+
+var (
+	// brotliWriterPools stores a sync.Pool of brotli.Writer for each compression level.
+	brotliWriterPools [brotli.BestCompression + 1]sync.Pool
+	// zstdEncoderPools stores a sync.Pool of zstd.Encoder for each encoder level.
+	zstdEncoderPools [zstd.SpeedBestCompression + 1]sync.Pool
+)
+
+func getEncoder(encoding string, level int, w io.Writer) (encoder, error) {
+	switch encoding {
+	case brotliEncoding:
+		if bw, ok := brotliWriterPools[level].Get().(*brotli.Writer); ok {
+			bw.Reset(w)
+			return bw, nil
+		}
+		return brotli.NewWriterLevel(w, level), nil
+
+	case zstdEncoding:
+		if zw, ok := zstdEncoderPools[level].Get().(*zstd.Encoder); ok {
+			zw.Reset(w)
+			return zw, nil
+		}
+		return zstd.NewWriter(w,
+			zstd.WithEncoderLevel(zstd.EncoderLevel(level)),
+			zstd.WithEncoderConcurrency(1),
+			zstd.WithWindowSize(zstdWindowSize))
+
+	default:
+		return nil, fmt.Errorf("unsupported encoding: %s", encoding)
+	}
+}
+
+func putEncoder(encoding string, level int, enc encoder) {
+	switch encoding {
+	case brotliEncoding:
+		brotliWriterPools[level].Put(enc)
+	case zstdEncoding:
+		zstdEncoderPools[level].Put(enc)
+	}
+}
+
+// encodingResponseWriter compresses the response body with brotli or zstd.
+// As gziphandler.GzipResponseWriter, it buffers the body until it is larger than minSize,
+// and leaves the response untouched when it is smaller, already encoded, or of an excluded content type.
+type encodingResponseWriter struct {
+	http.ResponseWriter
+
+	encoding string
+	level    int
+	minSize  int
+	excludes []string
+
+	enc    encoder
+	code   int
+	buf    []byte
+	ignore bool
+}
+
+type encodingResponseWriterWithCloseNotify struct {
+	*encodingResponseWriter
+}
+
+func (w encodingResponseWriterWithCloseNotify) CloseNotify() <-chan bool {
+	return w.ResponseWriter.(http.CloseNotifier).CloseNotify()
+}
+
+func (w *encodingResponseWriter) WriteHeader(code int) {
+	if w.code == 0 {
+		w.code = code
+	}
+}
+
+func (w *encodingResponseWriter) Write(b []byte) (int, error) {
+	// Nothing is decided before the first bytes of the body, so that an empty body is not encoded.
+	if len(b) == 0 {
+		return 0, nil
+	}
+
+	if w.enc != nil {
+		return w.enc.Write(b)
+	}
+
+	if w.ignore {
+		return w.ResponseWriter.Write(b)
+	}
+
+	w.buf = append(w.buf, b...)
+
+	cl, _ := strconv.Atoi(w.Header().Get(contentLength))
+	ct := w.Header().Get(contentType)
+
+	if w.Header().Get(contentEncoding) == "" && (cl == 0 || cl >= w.minSize) && !w.isExcluded(ct) {
+		if len(w.buf) < w.minSize && cl == 0 {
+			return len(b), nil
+		}
+
+		if ct == "" {
+			ct = http.DetectContentType(w.buf)
+			w.Header().Set(contentType, ct)
+		}
+
+		if !w.isExcluded(ct) {
+			if err := w.startEncoding(); err != nil {
+				return 0, err
+			}
+			return len(b), nil
+		}
+	}
+
+	if err := w.startPlain(); err != nil {
+		return 0, err
+	}
+	return len(b), nil
+}
+
+func (w *encodingResponseWriter) isExcluded(ct string) bool {
+	if ct == "" {
+		return false
+	}
+
+	mediaType, _, err := mime.ParseMediaType(ct)
+	if err != nil {
+		return true
+	}
+
+	return contains(w.excludes, mediaType)
+}
+
+func (w *encodingResponseWriter) startEncoding() error {
+	enc, err := getEncoder(w.encoding, w.level, w.ResponseWriter)
+	if err != nil {
+		return err
+	}
+	w.enc = enc
+
+	// The headers are only written once the encoder exists, so that the Content-Encoding always matches the body.
+	w.Header().Set(contentEncoding, w.encoding)
+	w.Header().Del(contentLength)
+
+	w.writeHeader()
+
+	n, err := w.enc.Write(w.buf)
+	if err == nil && n < len(w.buf) {
+		err = io.ErrShortWrite
+	}
+	w.buf = nil
+	return err
+}
+
+func (w *encodingResponseWriter) startPlain() error {
+	w.writeHeader()
+	w.ignore = true
+
+	if w.buf == nil {
+		return nil
+	}
+
+	n, err := w.ResponseWriter.Write(w.buf)
+	if err == nil && n < len(w.buf) {
+		err = io.ErrShortWrite
+	}
+	w.buf = nil
+	return err
+}
+
+func (w *encodingResponseWriter) writeHeader() {
+	if w.code != 0 {
+		w.ResponseWriter.WriteHeader(w.code)
+		w.code = 0
+	}
+}
+
+// Close writes the buffered response, if it has not been written yet, or the end of the compressed stream.
+func (w *encodingResponseWriter) Close() error {
+	if w.ignore {
+		return nil
+	}
+
+	if w.enc == nil {
+		return w.startPlain()
+	}
+
+	err := w.enc.Close()
+	putEncoder(w.encoding, w.level, w.enc)
+	w.enc = nil
+	return err
+}
+
+// Flush is a no-op until the response is known to be compressed or not.
+func (w *encodingResponseWriter) Flush() {
+	if w.enc == nil && !w.ignore {
+		return
+	}
+
+	if w.enc != nil {
+		if err := w.enc.Flush(); err != nil {
+			return
+		}
+	}
+
+	if fw, ok := w.ResponseWriter.(http.Flusher); ok {
+		fw.Flush()
+	}
+}
+
+func (w *encodingResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	if hj, ok := w.ResponseWriter.(http.Hijacker); ok {
+		return hj.Hijack()
+	}
+	return nil, nil, fmt.Errorf("%T is not a http.Hijacker", w.ResponseWriter)
+}
@@ -4,6 +4,7 @@ import (
 	"bufio"
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"net"
 	"net/http"
@@ -43,6 +44,9 @@ type customErrors struct {
 	backendHandler http.Handler
 	httpCodeRanges types.HTTPCodeRanges
 	backendQuery   string
+	pages          *pages
+
+	traefikErrorsOnly bool
 }
 
 // New creates a new custom error pages middleware.
@@ -54,18 +58,39 @@ func New(ctx context.Context, next http.Handler, config dynamic.ErrorPage, servi
 		return nil, err
 	}
 
-	backend, err := serviceBuilder.BuildHTTP(ctx, config.Service)
+	c := &customErrors{
+		name:              name,
+		next:              next,
+		httpCodeRanges:    httpCodeRanges,
+		backendQuery:      config.Query,
+		traefikErrorsOnly: config.TraefikErrorsOnly,
+	}
+
+	inline := config.HTMLTemplate != "" || config.JSONTemplate != ""
+
+	var sources int
+	for _, set := range []bool{config.Service != "", config.Directory != "", inline} {
+		if set {
+			sources++
+		}
+	}
+	if sources != 1 {
+		return nil, errors.New("error pages: exactly one of service, directory, or templates must be set")
+	}
+
+	switch {
+	case config.Directory != "":
+		c.pages, err = newDirectoryPages(config.Directory)
+	case inline:
+		c.pages, err = newInlinePages(config.HTMLTemplate, config.JSONTemplate)
+	default:
+		c.backendHandler, err = serviceBuilder.BuildHTTP(ctx, config.Service)
+	}
 	if err != nil {
 		return nil, err
 	}
 
-	return &customErrors{
-		name:           name,
-		next:           next,
-		backendHandler: backend,
-		httpCodeRanges: httpCodeRanges,
-		backendQuery:   config.Query,
-	}, nil
+	return c, nil
 }
 
 func (c *customErrors) GetTracingInformation() (string, ext.SpanKindEnum) {
@@ -76,14 +101,21 @@ func (c *customErrors) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 	ctx := middlewares.GetLoggerCtx(req.Context(), c.name, typeName)
 	logger := log.FromContext(ctx)
 
-	if c.backendHandler == nil {
+	if c.backendHandler == nil && c.pages == nil {
 		logger.Error("Error pages: no backend handler.")
 		tracing.SetErrorWithEvent(req, "Error pages: no backend handler.")
 		c.next.ServeHTTP(rw, req)
 		return
 	}
 
-	catcher := newCodeCatcher(rw, c.httpCodeRanges)
+	var generated *generatedError
+	if c.traefikErrorsOnly {
+		var generatedCtx context.Context
+		generatedCtx, generated = withGeneratedError(req.Context())
+		req = req.WithContext(generatedCtx)
+	}
+
+	catcher := newCodeCatcher(rw, c.httpCodeRanges, generated)
 	c.next.ServeHTTP(catcher, req)
 	if !catcher.isFilteredCode() {
 		return
@@ -95,6 +127,13 @@ func (c *customErrors) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 		if code >= block[0] && code <= block[1] {
 			logger.Debugf("Caught HTTP Status Code %d, returning error page", code)
 
+			if c.pages != nil {
+				if !c.pages.serve(rw, req, code) {
+					writeStatusText(rw, code)
+				}
+				return
+			}
+
 			var query string
 			if len(c.backendQuery) > 0 {
 				query = "/" + strings.TrimPrefix(c.backendQuery, "/")
@@ -104,11 +143,7 @@ func (c *customErrors) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 			pageReq, err := newRequest(backendURL + query)
 			if err != nil {
 				logger.Error(err)
-				rw.WriteHeader(code)
-				_, err = fmt.Fprint(rw, http.StatusText(code))
-				if err != nil {
-					http.Error(rw, err.Error(), http.StatusInternalServerError)
-				}
+				writeStatusText(rw, code)
 				return
 			}
 
@@ -128,6 +163,14 @@ func (c *customErrors) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 	}
 }
 
+func writeStatusText(rw http.ResponseWriter, code int) {
+	rw.WriteHeader(code)
+	_, err := fmt.Fprint(rw, http.StatusText(code))
+	if err != nil {
+		http.Error(rw, err.Error(), http.StatusInternalServerError)
+	}
+}
+
 func newRequest(baseURL string) (*http.Request, error) {
 	u, err := url.Parse(baseURL)
 	if err != nil {
@@ -162,6 +205,8 @@ type codeCatcher struct {
 	caughtFilteredCode bool
 	responseWriter     http.ResponseWriter
 	headersSent        bool
+	// generated, when not nil, restricts the caught codes to the ones of the errors generated by Traefik.
+	generated *generatedError
 }
 
 type codeCatcherWithCloseNotify struct {
@@ -174,13 +219,14 @@ func (cc *codeCatcherWithCloseNotify) CloseNotify() <-chan bool {
 	return cc.responseWriter.(http.CloseNotifier).CloseNotify()
 }
 
-func newCodeCatcher(rw http.ResponseWriter, httpCodeRanges types.HTTPCodeRanges) responseInterceptor {
+func newCodeCatcher(rw http.ResponseWriter, httpCodeRanges types.HTTPCodeRanges, generated *generatedError) responseInterceptor {
 	catcher := &codeCatcher{
 		headerMap:      make(http.Header),
 		code:           http.StatusOK, // If backend does not call WriteHeader on us, we consider it's a 200.
 		responseWriter: rw,
 		httpCodeRanges: httpCodeRanges,
 		firstWrite:     true,
+		generated:      generated,
 	}
 	if _, ok := rw.(http.CloseNotifier); ok {
 		return &codeCatcherWithCloseNotify{catcher}
@@ -236,7 +282,7 @@ func (cc *codeCatcher) WriteHeader(code int) {
 	cc.code = code
 	for _, block := range cc.httpCodeRanges {
 		if cc.code >= block[0] && cc.code <= block[1] {
-			cc.caughtFilteredCode = true
+			cc.caughtFilteredCode = cc.generated == nil || cc.generated.getCode() == code
 			break
 		}
 	}
@@ -0,0 +1,32 @@
+package customerrors
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+)
+
+type generatedErrorKey struct{}
+
+// generatedError records the status code of the last error response generated by Traefik for a request.
+type generatedError struct {
+	code int32
+}
+
+func (g *generatedError) getCode() int {
+	return int(atomic.LoadInt32(&g.code))
+}
+
+func withGeneratedError(ctx context.Context) (context.Context, *generatedError) {
+	generated := &generatedError{}
+	return context.WithValue(ctx, generatedErrorKey{}, generated), generated
+}
+
+// SetGenerated records that the error response about to be written for the request, with the given status code,
+// is generated by Traefik and not by a server,
+// so that it can be replaced by the errors middlewares handling only the errors generated by Traefik.
+func SetGenerated(req *http.Request, statusCode int) {
+	if generated, ok := req.Context().Value(generatedErrorKey{}).(*generatedError); ok {
+		atomic.StoreInt32(&generated.code, int32(statusCode))
+	}
+}
@@ -0,0 +1,246 @@
+package customerrors
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	texttemplate "text/template"
+
+	"github.com/traefik/traefik/v2/pkg/log"
+)
+
+const (
+	formatHTML = "html"
+	formatJSON = "json"
+)
+
+var contentTypes = map[string]string{
+	formatHTML: "text/html; charset=utf-8",
+	formatJSON: "application/json",
+}
+
+// pageFileName matches the names of the error page files: the status code (502.html),
+// the status code class (5xx.html), or the fallback page (error.html).
+var pageFileName = regexp.MustCompile(`^(\d{3}|\dxx|error)\.(html|json)$`)
+
+// pageTemplate is implemented by both the html and the text templates.
+type pageTemplate interface {
+	Execute(wr io.Writer, data interface{}) error
+}
+
+// pageData holds the variables available in the error page templates.
+type pageData struct {
+	Status     int
+	StatusText string
+	RequestID  string
+	Host       string
+}
+
+// pages serves the error pages from templates, indexed by format and then by name (502, 5xx, or error).
+type pages struct {
+	templates map[string]map[string]pageTemplate
+}
+
+func newInlinePages(htmlTemplate, jsonTemplate string) (*pages, error) {
+	p := &pages{templates: make(map[string]map[string]pageTemplate)}
+
+	if htmlTemplate != "" {
+		if err := p.add(formatHTML, "error", htmlTemplate); err != nil {
+			return nil, err
+		}
+	}
+
+	if jsonTemplate != "" {
+		if err := p.add(formatJSON, "error", jsonTemplate); err != nil {
+			return nil, err
+		}
+	}
+
+	return p, nil
+}
+
+func newDirectoryPages(directory string) (*pages, error) {
+	files, err := ioutil.ReadDir(directory)
+	if err != nil {
+		return nil, fmt.Errorf("error pages: unable to read directory: %w", err)
+	}
+
+	p := &pages{templates: make(map[string]map[string]pageTemplate)}
+	for _, file := range files {
+		parts := pageFileName.FindStringSubmatch(file.Name())
+		if file.IsDir() || parts == nil {
+			continue
+		}
+
+		content, err := ioutil.ReadFile(filepath.Join(directory, file.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("error pages: unable to read file: %w", err)
+		}
+
+		if err := p.add(parts[2], parts[1], string(content)); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(p.templates) == 0 {
+		return nil, fmt.Errorf("error pages: no error page found in directory %s", directory)
+	}
+
+	return p, nil
+}
+
+func (p *pages) add(format, name, content string) error {
+	var (
+		tmpl pageTemplate
+		err  error
+	)
+
+	if format == formatHTML {
+		tmpl, err = htmltemplate.New(name).Parse(content)
+	} else {
+		tmpl, err = texttemplate.New(name).Parse(content)
+	}
+	if err != nil {
+		return fmt.Errorf("error pages: unable to parse the %s template %s: %w", format, name, err)
+	}
+
+	if p.templates[format] == nil {
+		p.templates[format] = make(map[string]pageTemplate)
+	}
+	p.templates[format][name] = tmpl
+
+	return nil
+}
+
+// lookup returns the most specific template for the status code and the format.
+func (p *pages) lookup(format string, code int) pageTemplate {
+	templates := p.templates[format]
+
+	status := strconv.Itoa(code)
+	for _, name := range []string{status, status[:1] + "xx", "error"} {
+		if tmpl, ok := templates[name]; ok {
+			return tmpl
+		}
+	}
+
+	return nil
+}
+
+// serve writes the error page for the status code, in the format preferred by the client.
+// It reports whether an error page is available for the status code.
+func (p *pages) serve(rw http.ResponseWriter, req *http.Request, code int) bool {
+	var available []string
+	for _, format := range []string{formatHTML, formatJSON} {
+		if p.lookup(format, code) != nil {
+			available = append(available, format)
+		}
+	}
+
+	if len(available) == 0 {
+		return false
+	}
+
+	format := negotiateFormat(req.Header.Get("Accept"), available)
+
+	data := pageData{
+		Status:     code,
+		StatusText: http.StatusText(code),
+		RequestID:  req.Header.Get("X-Request-Id"),
+		Host:       req.Host,
+	}
+	if format == formatJSON {
+		// The values are escaped, so that they can be used in JSON strings.
+		data.StatusText = jsonEscape(data.StatusText)
+		data.RequestID = jsonEscape(data.RequestID)
+		data.Host = jsonEscape(data.Host)
+	}
+
+	var body bytes.Buffer
+	if err := p.lookup(format, code).Execute(&body, data); err != nil {
+		log.FromContext(req.Context()).Errorf("Error pages: unable to execute the %s template: %v", format, err)
+		return false
+	}
+
+	rw.Header().Set("Content-Type", contentTypes[format])
+	rw.Header().Set("Content-Length", strconv.Itoa(body.Len()))
+	rw.WriteHeader(code)
+
+	if _, err := rw.Write(body.Bytes()); err != nil {
+		log.FromContext(req.Context()).Error(err)
+	}
+
+	return true
+}
+
+// negotiateFormat returns the available format preferred by the client according to its Accept header,
+// the first available format being preferred when the client has no preference.
+func negotiateFormat(accept string, available []string) string {
+	best, bestQuality := available[0], -1.0
+
+	for _, format := range available {
+		if quality := acceptQuality(accept, contentTypes[format]); quality > bestQuality {
+			best, bestQuality = format, quality
+		}
+	}
+
+	return best
+}
+
+// acceptQuality returns the quality of the content type in the Accept header.
+func acceptQuality(accept, contentType string) float64 {
+	if accept == "" {
+		return 1
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	mainType := strings.SplitN(mediaType, "/", 2)[0]
+
+	quality, specificity := 0.0, -1
+	for _, part := range strings.Split(accept, ",") {
+		accepted, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+
+		var matchSpecificity int
+		switch accepted {
+		case mediaType:
+			matchSpecificity = 2
+		case mainType + "/*":
+			matchSpecificity = 1
+		case "*/*":
+			matchSpecificity = 0
+		default:
+			continue
+		}
+
+		if matchSpecificity <= specificity {
+			continue
+		}
+
+		specificity = matchSpecificity
+		quality = 1
+		if q, err := strconv.ParseFloat(params["q"], 64); err == nil {
+			quality = q
+		}
+	}
+
+	return quality
+}
+
+func jsonEscape(value string) string {
+	escaped, err := json.Marshal(value)
+	if err != nil {
+		return ""
+	}
+	return string(escaped[1 : len(escaped)-1])
+}
@@ -0,0 +1,226 @@
+package customerrors
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traefik/traefik/v2/pkg/config/dynamic"
+	"github.com/traefik/traefik/v2/pkg/testhelpers"
+)
+
+func TestHandler_pages(t *testing.T) {
+	directory := t.TempDir()
+	files := map[string]string{
+		"502.html":   "<p>{{ .Status }} from {{ .Host }}</p>",
+		"5xx.json":   `{"status":{{ .Status }},"requestId":"{{ .RequestID }}"}`,
+		"error.html": "<p>{{ .StatusText }}</p>",
+		"readme.md":  "not an error page",
+	}
+	for name, content := range files {
+		require.NoError(t, ioutil.WriteFile(filepath.Join(directory, name), []byte(content), 0o600))
+	}
+
+	testCases := []struct {
+		desc                string
+		errorPage           dynamic.ErrorPage
+		backendCode         int
+		accept              string
+		requestID           string
+		expectedCode        int
+		expectedContentType string
+		expectedBody        string
+	}{
+		{
+			desc:                "inline HTML template",
+			errorPage:           dynamic.ErrorPage{Status: []string{"500-599"}, HTMLTemplate: "<p>{{ .Status }} {{ .RequestID }}</p>"},
+			backendCode:         http.StatusBadGateway,
+			requestID:           "<id>",
+			expectedCode:        http.StatusBadGateway,
+			expectedContentType: "text/html; charset=utf-8",
+			expectedBody:        "<p>502 &lt;id&gt;</p>",
+		},
+		{
+			desc:                "inline templates, JSON accepted",
+			errorPage:           dynamic.ErrorPage{Status: []string{"500-599"}, HTMLTemplate: "<p>{{ .Status }}</p>", JSONTemplate: `{"requestId":"{{ .RequestID }}"}`},
+			backendCode:         http.StatusBadGateway,
+			accept:              "application/json",
+			requestID:           `"id"`,
+			expectedCode:        http.StatusBadGateway,
+			expectedContentType: "application/json",
+			expectedBody:        `{"requestId":"\"id\""}`,
+		},
+		{
+			desc:                "inline templates, HTML preferred",
+			errorPage:           dynamic.ErrorPage{Status: []string{"500-599"}, HTMLTemplate: "<p>{{ .Status }}</p>", JSONTemplate: `{"status":{{ .Status }}}`},
+			backendCode:         http.StatusBadGateway,
+			accept:              "application/json;q=0.8, text/html",
+			expectedCode:        http.StatusBadGateway,
+			expectedContentType: "text/html; charset=utf-8",
+			expectedBody:        "<p>502</p>",
+		},
+		{
+			desc:                "only the JSON template",
+			errorPage:           dynamic.ErrorPage{Status: []string{"500-599"}, JSONTemplate: `{"status":{{ .Status }}}`},
+			backendCode:         http.StatusServiceUnavailable,
+			accept:              "text/html",
+			expectedCode:        http.StatusServiceUnavailable,
+			expectedContentType: "application/json",
+			expectedBody:        `{"status":503}`,
+		},
+		{
+			desc:                "status code page from directory",
+			errorPage:           dynamic.ErrorPage{Status: []string{"400-599"}, Directory: directory},
+			backendCode:         http.StatusBadGateway,
+			expectedCode:        http.StatusBadGateway,
+			expectedContentType: "text/html; charset=utf-8",
+			expectedBody:        "<p>502 from localhost</p>",
+		},
+		{
+			desc:                "status code class page from directory",
+			errorPage:           dynamic.ErrorPage{Status: []string{"400-599"}, Directory: directory},
+			backendCode:         http.StatusServiceUnavailable,
+			accept:              "application/json",
+			requestID:           "foo",
+			expectedCode:        http.StatusServiceUnavailable,
+			expectedContentType: "application/json",
+			expectedBody:        `{"status":503,"requestId":"foo"}`,
+		},
+		{
+			desc:                "fallback page from directory",
+			errorPage:           dynamic.ErrorPage{Status: []string{"400-599"}, Directory: directory},
+			backendCode:         http.StatusNotFound,
+			accept:              "application/json",
+			expectedCode:        http.StatusNotFound,
+			expectedContentType: "text/html; charset=utf-8",
+			expectedBody:        "<p>Not Found</p>",
+		},
+		{
+			desc:         "not an error",
+			errorPage:    dynamic.ErrorPage{Status: []string{"400-599"}, Directory: directory},
+			backendCode:  http.StatusOK,
+			expectedCode: http.StatusOK,
+			expectedBody: "OK",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			next := http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+				rw.WriteHeader(test.backendCode)
+				_, _ = rw.Write([]byte(http.StatusText(test.backendCode)))
+			})
+
+			handler, err := New(context.Background(), next, test.errorPage, &mockServiceBuilder{}, "test")
+			require.NoError(t, err)
+
+			req := testhelpers.MustNewRequest(http.MethodGet, "http://localhost/test", nil)
+			if test.accept != "" {
+				req.Header.Set("Accept", test.accept)
+			}
+			if test.requestID != "" {
+				req.Header.Set("X-Request-Id", test.requestID)
+			}
+
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, req)
+
+			assert.Equal(t, test.expectedCode, recorder.Code)
+			assert.Equal(t, test.expectedContentType, recorder.Header().Get("Content-Type"))
+			assert.Equal(t, test.expectedBody, recorder.Body.String())
+		})
+	}
+}
+
+func TestNew_pagesSources(t *testing.T) {
+	testCases := []struct {
+		desc      string
+		errorPage dynamic.ErrorPage
+	}{
+		{
+			desc:      "no source",
+			errorPage: dynamic.ErrorPage{Status: []string{"500-599"}},
+		},
+		{
+			desc:      "service and templates",
+			errorPage: dynamic.ErrorPage{Status: []string{"500-599"}, Service: "error", HTMLTemplate: "foo"},
+		},
+		{
+			desc:      "directory and templates",
+			errorPage: dynamic.ErrorPage{Status: []string{"500-599"}, Directory: "/foo", JSONTemplate: "foo"},
+		},
+		{
+			desc:      "unknown directory",
+			errorPage: dynamic.ErrorPage{Status: []string{"500-599"}, Directory: filepath.Join(t.TempDir(), "unknown")},
+		},
+		{
+			desc:      "directory without error pages",
+			errorPage: dynamic.ErrorPage{Status: []string{"500-599"}, Directory: t.TempDir()},
+		},
+		{
+			desc:      "invalid template",
+			errorPage: dynamic.ErrorPage{Status: []string{"500-599"}, HTMLTemplate: "{{ .Status"},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := New(context.Background(), http.NotFoundHandler(), test.errorPage, &mockServiceBuilder{}, "test")
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestHandler_traefikErrorsOnly(t *testing.T) {
+	testCases := []struct {
+		desc         string
+		generated    bool
+		expectedBody string
+	}{
+		{
+			desc:         "error generated by Traefik",
+			generated:    true,
+			expectedBody: "<p>502</p>",
+		},
+		{
+			desc:         "error returned by the server",
+			expectedBody: "server error",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				if test.generated {
+					SetGenerated(req, http.StatusBadGateway)
+				}
+				rw.WriteHeader(http.StatusBadGateway)
+				_, _ = rw.Write([]byte("server error"))
+			})
+
+			config := dynamic.ErrorPage{Status: []string{"500-599"}, HTMLTemplate: "<p>{{ .Status }}</p>", TraefikErrorsOnly: true}
+			handler, err := New(context.Background(), next, config, &mockServiceBuilder{}, "test")
+			require.NoError(t, err)
+
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, testhelpers.MustNewRequest(http.MethodGet, "http://localhost/test", nil))
+
+			assert.Equal(t, http.StatusBadGateway, recorder.Code)
+			assert.Equal(t, test.expectedBody, recorder.Body.String())
+		})
+	}
+}
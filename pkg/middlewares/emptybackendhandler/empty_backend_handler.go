@@ -4,6 +4,7 @@ import (
 	"net/http"
 
 	"github.com/traefik/traefik/v2/pkg/healthcheck"
+	"github.com/traefik/traefik/v2/pkg/middlewares/customerrors"
 )
 
 // EmptyBackend is a middleware that checks whether the current Backend
@@ -22,6 +23,7 @@ func New(lb healthcheck.BalancerHandler) http.Handler {
 // invokes the next handler in the middleware chain.
 func (e *emptyBackend) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 	if len(e.next.Servers()) == 0 {
+		customerrors.SetGenerated(req, http.StatusServiceUnavailable)
 		rw.WriteHeader(http.StatusServiceUnavailable)
 		_, err := rw.Write([]byte(http.StatusText(http.StatusServiceUnavailable)))
 		if err != nil {
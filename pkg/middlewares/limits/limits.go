@@ -0,0 +1,226 @@
+package limits
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sync/atomic"
+
+	"github.com/opentracing/opentracing-go/ext"
+	"github.com/traefik/traefik/v2/pkg/config/dynamic"
+	"github.com/traefik/traefik/v2/pkg/log"
+	"github.com/traefik/traefik/v2/pkg/middlewares"
+	"github.com/traefik/traefik/v2/pkg/tracing"
+)
+
+const typeName = "Limits"
+
+var errRequestBodyTooLarge = errors.New("request body too large")
+
+// limits is a middleware rejecting the requests exceeding size limits.
+// The request body is checked while it is streamed to the next handler, and is never buffered.
+type limits struct {
+	next                http.Handler
+	name                string
+	maxRequestBodyBytes int64
+	maxHeaderCount      int
+	maxHeaderBytes      int
+	maxURILength        int
+}
+
+// New creates a limits middleware.
+func New(ctx context.Context, next http.Handler, config dynamic.Limits, name string) (http.Handler, error) {
+	log.FromContext(middlewares.GetLoggerCtx(ctx, name, typeName)).Debug("Creating middleware")
+
+	if config.MaxRequestBodyBytes < 0 || config.MaxHeaderCount < 0 || config.MaxHeaderBytes < 0 || config.MaxURILength < 0 {
+		return nil, errors.New("limits cannot be negative")
+	}
+
+	return &limits{
+		next:                next,
+		name:                name,
+		maxRequestBodyBytes: config.MaxRequestBodyBytes,
+		maxHeaderCount:      config.MaxHeaderCount,
+		maxHeaderBytes:      config.MaxHeaderBytes,
+		maxURILength:        config.MaxURILength,
+	}, nil
+}
+
+func (l *limits) GetTracingInformation() (string, ext.SpanKindEnum) {
+	return l.name, tracing.SpanKindNoneEnum
+}
+
+func (l *limits) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	logger := log.FromContext(middlewares.GetLoggerCtx(req.Context(), l.name, typeName))
+
+	if l.maxURILength > 0 {
+		uri := req.URL.RequestURI()
+		if len(uri) > l.maxURILength {
+			logger.Debugf("Rejecting request: URI length %d is larger than %d", len(uri), l.maxURILength)
+			reject(rw, http.StatusRequestURITooLong)
+			return
+		}
+	}
+
+	if l.maxHeaderCount > 0 || l.maxHeaderBytes > 0 {
+		var count, size int
+		for name, values := range req.Header {
+			count += len(values)
+			for _, value := range values {
+				size += len(name) + len(value)
+			}
+		}
+
+		if l.maxHeaderCount > 0 && count > l.maxHeaderCount {
+			logger.Debugf("Rejecting request: %d headers, more than %d", count, l.maxHeaderCount)
+			reject(rw, http.StatusRequestHeaderFieldsTooLarge)
+			return
+		}
+
+		if l.maxHeaderBytes > 0 && size > l.maxHeaderBytes {
+			logger.Debugf("Rejecting request: headers size %d is larger than %d", size, l.maxHeaderBytes)
+			reject(rw, http.StatusRequestHeaderFieldsTooLarge)
+			return
+		}
+	}
+
+	if l.maxRequestBodyBytes <= 0 || req.Body == nil || req.Body == http.NoBody {
+		l.next.ServeHTTP(rw, req)
+		return
+	}
+
+	if req.ContentLength > l.maxRequestBodyBytes {
+		logger.Debugf("Rejecting request: Content-Length %d is larger than %d", req.ContentLength, l.maxRequestBodyBytes)
+		rejectBody(rw)
+		return
+	}
+
+	body := &limitedBody{ReadCloser: req.Body, remaining: l.maxRequestBodyBytes}
+	req.Body = body
+
+	lrw := &limitedResponseWriter{ResponseWriter: rw, body: body}
+	l.next.ServeHTTP(lrw, req)
+
+	if body.isExceeded() {
+		logger.Debugf("Rejecting request: body is larger than %d", l.maxRequestBodyBytes)
+		lrw.rejectBody()
+	}
+}
+
+func reject(rw http.ResponseWriter, code int) {
+	http.Error(rw, http.StatusText(code), code)
+}
+
+func rejectBody(rw http.ResponseWriter) {
+	// The rest of the body is not read, so the connection cannot be reused.
+	rw.Header().Set("Connection", "close")
+	reject(rw, http.StatusRequestEntityTooLarge)
+}
+
+// limitedBody is a request body failing with errRequestBodyTooLarge as soon as more than remaining bytes are read.
+type limitedBody struct {
+	io.ReadCloser
+	remaining int64
+	// exceeded is read by the goroutine writing the response, while the body can be read by another one.
+	exceeded int32
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	if b.isExceeded() {
+		return 0, errRequestBodyTooLarge
+	}
+
+	// Reads one more byte than allowed, to know whether the limit is crossed.
+	if int64(len(p)) > b.remaining+1 {
+		p = p[:b.remaining+1]
+	}
+
+	n, err := b.ReadCloser.Read(p)
+	if int64(n) > b.remaining {
+		n = int(b.remaining)
+		b.remaining = 0
+		atomic.StoreInt32(&b.exceeded, 1)
+		return n, errRequestBodyTooLarge
+	}
+
+	b.remaining -= int64(n)
+	return n, err
+}
+
+func (b *limitedBody) isExceeded() bool {
+	return atomic.LoadInt32(&b.exceeded) == 1
+}
+
+// limitedResponseWriter replaces the response of the next handler with a 413 response,
+// when the request body has been found too large before the response is written.
+type limitedResponseWriter struct {
+	http.ResponseWriter
+	body        *limitedBody
+	wroteHeader bool
+	rejected    bool
+}
+
+func (w *limitedResponseWriter) WriteHeader(code int) {
+	if w.wroteHeader {
+		return
+	}
+
+	if w.body.isExceeded() {
+		w.rejectBody()
+		return
+	}
+
+	w.wroteHeader = true
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *limitedResponseWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+
+	if w.rejected {
+		return 0, errRequestBodyTooLarge
+	}
+
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *limitedResponseWriter) rejectBody() {
+	if w.wroteHeader {
+		return
+	}
+
+	w.wroteHeader = true
+	w.rejected = true
+
+	// The headers set by the next handler are dropped.
+	for name := range w.ResponseWriter.Header() {
+		w.ResponseWriter.Header().Del(name)
+	}
+
+	rejectBody(w.ResponseWriter)
+}
+
+// Flush sends any buffered data to the client.
+func (w *limitedResponseWriter) Flush() {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Hijack hijacks the connection.
+func (w *limitedResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	if h, ok := w.ResponseWriter.(http.Hijacker); ok {
+		return h.Hijack()
+	}
+	return nil, nil, fmt.Errorf("%T is not a http.Hijacker", w.ResponseWriter)
+}
@@ -0,0 +1,203 @@
+package limits
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httputil"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traefik/traefik/v2/pkg/config/dynamic"
+)
+
+func TestNew(t *testing.T) {
+	_, err := New(context.Background(), http.NotFoundHandler(), dynamic.Limits{MaxHeaderCount: -1}, "test")
+	assert.Error(t, err)
+
+	_, err = New(context.Background(), http.NotFoundHandler(), dynamic.Limits{}, "test")
+	assert.NoError(t, err)
+}
+
+func TestLimits(t *testing.T) {
+	testCases := []struct {
+		desc           string
+		config         dynamic.Limits
+		target         string
+		header         http.Header
+		body           io.Reader
+		contentLength  int64
+		expectedStatus int
+		expectedCalled bool
+		expectedBody   string
+	}{
+		{
+			desc:           "no limits",
+			target:         "/foo?bar=baz",
+			header:         http.Header{"X-Foo": {"bar"}},
+			body:           strings.NewReader("foobar"),
+			expectedStatus: http.StatusOK,
+			expectedCalled: true,
+			expectedBody:   "foobar",
+		},
+		{
+			desc:           "URI within the limit",
+			config:         dynamic.Limits{MaxURILength: 12},
+			target:         "/foo?bar=baz",
+			expectedStatus: http.StatusOK,
+			expectedCalled: true,
+		},
+		{
+			desc:           "URI too long",
+			config:         dynamic.Limits{MaxURILength: 11},
+			target:         "/foo?bar=baz",
+			expectedStatus: http.StatusRequestURITooLong,
+		},
+		{
+			desc:           "headers within the count limit",
+			config:         dynamic.Limits{MaxHeaderCount: 2},
+			header:         http.Header{"X-Foo": {"bar", "baz"}},
+			expectedStatus: http.StatusOK,
+			expectedCalled: true,
+		},
+		{
+			desc:           "too many headers",
+			config:         dynamic.Limits{MaxHeaderCount: 2},
+			header:         http.Header{"X-Foo": {"bar", "baz"}, "X-Bar": {"foo"}},
+			expectedStatus: http.StatusRequestHeaderFieldsTooLarge,
+		},
+		{
+			desc:           "headers within the size limit",
+			config:         dynamic.Limits{MaxHeaderBytes: 8},
+			header:         http.Header{"X-Foo": {"bar"}},
+			expectedStatus: http.StatusOK,
+			expectedCalled: true,
+		},
+		{
+			desc:           "headers too large",
+			config:         dynamic.Limits{MaxHeaderBytes: 7},
+			header:         http.Header{"X-Foo": {"bar"}},
+			expectedStatus: http.StatusRequestHeaderFieldsTooLarge,
+		},
+		{
+			desc:           "body within the limit",
+			config:         dynamic.Limits{MaxRequestBodyBytes: 6},
+			body:           strings.NewReader("foobar"),
+			expectedStatus: http.StatusOK,
+			expectedCalled: true,
+			expectedBody:   "foobar",
+		},
+		{
+			desc:           "Content-Length too large",
+			config:         dynamic.Limits{MaxRequestBodyBytes: 5},
+			body:           strings.NewReader("foobar"),
+			expectedStatus: http.StatusRequestEntityTooLarge,
+		},
+		{
+			desc:           "chunked body too large",
+			config:         dynamic.Limits{MaxRequestBodyBytes: 5},
+			body:           strings.NewReader("foobar"),
+			contentLength:  -1,
+			expectedStatus: http.StatusRequestEntityTooLarge,
+			expectedCalled: true,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			var called bool
+			var receivedBody string
+			next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				called = true
+
+				body, err := ioutil.ReadAll(req.Body)
+				if err != nil {
+					http.Error(rw, err.Error(), http.StatusBadGateway)
+					return
+				}
+
+				receivedBody = string(body)
+			})
+
+			handler, err := New(context.Background(), next, test.config, "test")
+			require.NoError(t, err)
+
+			target := test.target
+			if target == "" {
+				target = "/"
+			}
+
+			req := httptest.NewRequest(http.MethodPost, "http://localhost"+target, test.body)
+			for name, values := range test.header {
+				req.Header[name] = values
+			}
+			if test.contentLength != 0 {
+				req.ContentLength = test.contentLength
+			}
+
+			rw := httptest.NewRecorder()
+			handler.ServeHTTP(rw, req)
+
+			assert.Equal(t, test.expectedStatus, rw.Code)
+			assert.Equal(t, test.expectedCalled, called)
+			assert.Equal(t, test.expectedBody, receivedBody)
+
+			if test.expectedStatus == http.StatusRequestEntityTooLarge {
+				assert.Equal(t, "close", rw.Header().Get("Connection"))
+			}
+		})
+	}
+}
+
+func TestLimits_streamedBody(t *testing.T) {
+	const maxBytes = 1024
+
+	var received int64
+	backend := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		n, _ := io.Copy(ioutil.Discard, req.Body)
+		atomic.StoreInt64(&received, n)
+		rw.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(backend.Close)
+
+	backendURL, err := url.Parse(backend.URL)
+	require.NoError(t, err)
+
+	handler, err := New(context.Background(), httputil.NewSingleHostReverseProxy(backendURL), dynamic.Limits{MaxRequestBodyBytes: maxBytes}, "test")
+	require.NoError(t, err)
+
+	proxy := httptest.NewServer(handler)
+	t.Cleanup(proxy.Close)
+
+	// The body is sent with chunked transfer encoding, and would never end if it was buffered.
+	pr, pw := io.Pipe()
+	go func() {
+		chunk := bytes.Repeat([]byte("a"), 256)
+		for {
+			if _, err := pw.Write(chunk); err != nil {
+				return
+			}
+		}
+	}()
+	t.Cleanup(func() { _ = pw.Close() })
+
+	req, err := http.NewRequest(http.MethodPost, proxy.URL, pr)
+	require.NoError(t, err)
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = resp.Body.Close() })
+
+	assert.Equal(t, http.StatusRequestEntityTooLarge, resp.StatusCode)
+	assert.LessOrEqual(t, atomic.LoadInt64(&received), int64(maxBytes))
+}
@@ -6,7 +6,6 @@ import (
 	"crypto/x509/pkix"
 	"encoding/pem"
 	"fmt"
-	"io"
 	"net/http"
 	"net/url"
 	"strings"
@@ -187,61 +186,77 @@ func (p *passTLSClientCert) getCertInfo(ctx context.Context, certs []*x509.Certi
 	return strings.Join(headerValues, certSeparator)
 }
 
-func getDNInfo(ctx context.Context, options *DistinguishedNameOptions, cs *pkix.Name) string {
-	if options == nil {
-		return ""
+// DNAttribute is an attribute of a distinguished name, such as its common name (CN).
+type DNAttribute struct {
+	Type  string
+	Value string
+}
+
+// GetDNAttributes returns the non empty attributes of the distinguished name selected by the options,
+// in the order they are written in the certificate info header.
+func GetDNAttributes(options *DistinguishedNameOptions, cs *pkix.Name) []DNAttribute {
+	if options == nil || cs == nil {
+		return nil
 	}
 
-	content := &strings.Builder{}
+	var attributes []DNAttribute
 
 	// Manage non standard attributes
 	for _, name := range cs.Names {
 		// Domain Component - RFC 2247
 		if options.DomainComponent && attributeTypeNames[name.Type.String()] == "DC" {
-			content.WriteString(fmt.Sprintf("DC=%s%s", name.Value, subFieldSeparator))
+			attributes = append(attributes, DNAttribute{Type: "DC", Value: fmt.Sprint(name.Value)})
 		}
 	}
 
 	if options.CountryName {
-		writeParts(ctx, content, cs.Country, "C")
+		attributes = appendAttributes(attributes, "C", cs.Country...)
 	}
 
 	if options.StateOrProvinceName {
-		writeParts(ctx, content, cs.Province, "ST")
+		attributes = appendAttributes(attributes, "ST", cs.Province...)
 	}
 
 	if options.LocalityName {
-		writeParts(ctx, content, cs.Locality, "L")
+		attributes = appendAttributes(attributes, "L", cs.Locality...)
 	}
 
 	if options.OrganizationName {
-		writeParts(ctx, content, cs.Organization, "O")
+		attributes = appendAttributes(attributes, "O", cs.Organization...)
 	}
 
 	if options.SerialNumber {
-		writePart(ctx, content, cs.SerialNumber, "SN")
+		attributes = appendAttributes(attributes, "SN", cs.SerialNumber)
 	}
 
 	if options.CommonName {
-		writePart(ctx, content, cs.CommonName, "CN")
+		attributes = appendAttributes(attributes, "CN", cs.CommonName)
 	}
 
-	return content.String()
+	return attributes
 }
 
-func writeParts(ctx context.Context, content io.StringWriter, entries []string, prefix string) {
-	for _, entry := range entries {
-		writePart(ctx, content, entry, prefix)
+func appendAttributes(attributes []DNAttribute, attributeType string, values ...string) []DNAttribute {
+	for _, value := range values {
+		if len(value) > 0 {
+			attributes = append(attributes, DNAttribute{Type: attributeType, Value: value})
+		}
 	}
+
+	return attributes
 }
 
-func writePart(ctx context.Context, content io.StringWriter, entry, prefix string) {
-	if len(entry) > 0 {
-		_, err := content.WriteString(fmt.Sprintf("%s=%s%s", prefix, entry, subFieldSeparator))
+func getDNInfo(ctx context.Context, options *DistinguishedNameOptions, cs *pkix.Name) string {
+	content := &strings.Builder{}
+
+	for _, attribute := range GetDNAttributes(options, cs) {
+		_, err := content.WriteString(fmt.Sprintf("%s=%s%s", attribute.Type, attribute.Value, subFieldSeparator))
 		if err != nil {
 			log.FromContext(ctx).Error(err)
 		}
 	}
+
+	return content.String()
 }
 
 // sanitize As we pass the raw certificates, remove the useless data and make it http request compliant.
@@ -35,6 +35,11 @@ type rateLimiter struct {
 	next          http.Handler
 
 	buckets *ttlmap.TtlMap // actual buckets, keyed by source.
+
+	// storage, when set, keeps the buckets shared by all the Traefik instances.
+	storage *redisStorage
+	// fallback defines how the requests are handled when the storage is unreachable.
+	fallback string
 }
 
 // New returns a rate limiter middleware.
@@ -86,7 +91,7 @@ func New(ctx context.Context, next http.Handler, config dynamic.RateLimit, name
 		}
 	}
 
-	return &rateLimiter{
+	rl := &rateLimiter{
 		name:          name,
 		rate:          rate.Limit(rtl),
 		burst:         burst,
@@ -94,7 +99,22 @@ func New(ctx context.Context, next http.Handler, config dynamic.RateLimit, name
 		next:          next,
 		sourceMatcher: sourceMatcher,
 		buckets:       buckets,
-	}, nil
+	}
+
+	if config.Storage != nil && config.Storage.Redis != nil {
+		switch config.Storage.Fallback {
+		case "":
+			rl.fallback = dynamic.RateLimitFallbackLocal
+		case dynamic.RateLimitFallbackLocal, dynamic.RateLimitFallbackAllow, dynamic.RateLimitFallbackDeny:
+			rl.fallback = config.Storage.Fallback
+		default:
+			return nil, fmt.Errorf("unknown storage fallback: %q", config.Storage.Fallback)
+		}
+
+		rl.storage = newRedisStorage(*config.Storage.Redis, name, rl.rate, burst, maxDelay)
+	}
+
+	return rl, nil
 }
 
 func (rl *rateLimiter) GetTracingInformation() (string, ext.SpanKindEnum) {
@@ -116,6 +136,25 @@ func (rl *rateLimiter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		logger.Infof("ignoring token bucket amount > 1: %d", amount)
 	}
 
+	if rl.storage != nil {
+		delay, err := rl.storage.reserve(source)
+		if err == nil {
+			rl.serveDelayed(ctx, w, r, delay)
+			return
+		}
+
+		logger.Errorf("could not use the rate limiter storage, falling back to %q: %v", rl.fallback, err)
+
+		switch rl.fallback {
+		case dynamic.RateLimitFallbackAllow:
+			rl.next.ServeHTTP(w, r)
+			return
+		case dynamic.RateLimitFallbackDeny:
+			http.Error(w, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+			return
+		}
+	}
+
 	var bucket *rate.Limiter
 	if rlSource, exists := rl.buckets.Get(source); exists {
 		bucket = rlSource.(*rate.Limiter)
@@ -145,6 +184,17 @@ func (rl *rateLimiter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	rl.next.ServeHTTP(w, r)
 }
 
+// serveDelayed forwards the request after the given delay, or rejects it if the delay is too long.
+func (rl *rateLimiter) serveDelayed(ctx context.Context, w http.ResponseWriter, r *http.Request, delay time.Duration) {
+	if delay > rl.maxDelay {
+		rl.serveDelayError(ctx, w, r, delay)
+		return
+	}
+
+	time.Sleep(delay)
+	rl.next.ServeHTTP(w, r)
+}
+
 func (rl *rateLimiter) serveDelayError(ctx context.Context, w http.ResponseWriter, r *http.Request, delay time.Duration) {
 	w.Header().Set("Retry-After", fmt.Sprintf("%.0f", delay.Seconds()))
 	w.Header().Set("X-Retry-In", delay.String())
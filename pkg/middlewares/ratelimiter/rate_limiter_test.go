@@ -73,6 +73,18 @@ func TestNewRateLimiter(t *testing.T) {
 			},
 			expectedError: "iPStrategy and RequestHeaderName are mutually exclusive",
 		},
+		{
+			desc: "unknown storage fallback",
+			config: dynamic.RateLimit{
+				Average: 200,
+				Burst:   10,
+				Storage: &dynamic.RateLimitStorage{
+					Redis:    &dynamic.RedisStorage{Address: "127.0.0.1:6379"},
+					Fallback: "foo",
+				},
+			},
+			expectedError: `unknown storage fallback: "foo"`,
+		},
 	}
 
 	for _, test := range testCases {
@@ -0,0 +1,157 @@
+package ratelimiter
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/traefik/traefik/v2/pkg/config/dynamic"
+	"github.com/traefik/traefik/v2/pkg/log"
+	"golang.org/x/time/rate"
+	"gopkg.in/redis.v5"
+)
+
+// reserveScript atomically takes a token from the bucket stored at KEYS[1].
+// It mirrors the reservation of rate.Limiter: the number of tokens can become negative,
+// and the returned value is the delay, in microseconds, before the reservation becomes effective.
+// A reservation whose delay exceeds the maximum delay is not recorded.
+var reserveScript = redis.NewScript(`
+local rate = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+local maxDelay = tonumber(ARGV[4])
+local ttl = tonumber(ARGV[5])
+
+local bucket = redis.call("HMGET", KEYS[1], "tokens", "ts")
+local tokens = tonumber(bucket[1])
+local ts = tonumber(bucket[2])
+if tokens == nil or ts == nil then
+	tokens = burst
+	ts = now
+end
+
+-- The clocks of the Traefik instances may be slightly off, so time never goes backward.
+if now > ts then
+	tokens = math.min(burst, tokens + (now - ts) * rate / 1000000)
+	ts = now
+end
+
+tokens = tokens - 1
+
+local delay = 0
+if tokens < 0 then
+	delay = math.ceil(-tokens * 1000000 / rate)
+end
+
+if delay > maxDelay then
+	return delay
+end
+
+redis.call("HMSET", KEYS[1], "tokens", tostring(tokens), "ts", tostring(ts))
+redis.call("PEXPIRE", KEYS[1], ttl)
+
+return delay
+`)
+
+const redisKeyPrefix = "traefik:ratelimit:"
+
+var (
+	redisClientsMu sync.Mutex
+	// redisClients are shared between the rate limiters, as they are created again on each configuration reload.
+	redisClients = make(map[dynamic.RedisStorage]*redis.Client)
+)
+
+func getRedisClient(config dynamic.RedisStorage) *redis.Client {
+	redisClientsMu.Lock()
+	defer redisClientsMu.Unlock()
+
+	if client, ok := redisClients[config]; ok {
+		return client
+	}
+
+	timeout := time.Duration(config.Timeout)
+	client := redis.NewClient(&redis.Options{
+		Addr:         config.Address,
+		Password:     config.Password,
+		DB:           config.DB,
+		DialTimeout:  timeout,
+		ReadTimeout:  timeout,
+		WriteTimeout: timeout,
+	})
+
+	redisClients[config] = client
+	return client
+}
+
+// PruneRedisClients closes the Redis clients which are not used by the given storage configurations anymore,
+// e.g. after a configuration reload.
+func PruneRedisClients(configs map[dynamic.RedisStorage]struct{}) {
+	redisClientsMu.Lock()
+	defer redisClientsMu.Unlock()
+
+	for config, client := range redisClients {
+		if _, ok := configs[config]; ok {
+			continue
+		}
+
+		if err := client.Close(); err != nil {
+			log.WithoutContext().Debugf("Unable to close the Redis client of %s: %v", config.Address, err)
+		}
+		delete(redisClients, config)
+	}
+}
+
+// redisStorage keeps the token buckets in Redis, so that they are shared by all the Traefik instances.
+type redisStorage struct {
+	client    *redis.Client
+	keyPrefix string
+	rate      rate.Limit
+	burst     int64
+	maxDelay  time.Duration
+	ttl       time.Duration
+}
+
+func newRedisStorage(config dynamic.RedisStorage, name string, rtl rate.Limit, burst int64, maxDelay time.Duration) *redisStorage {
+	// A bucket which has not been used for the time it takes to be refilled is the same as a new one.
+	ttl := time.Second
+	if rtl > 0 {
+		ttl += time.Duration(float64(burst) / float64(rtl) * float64(time.Second))
+	}
+
+	return &redisStorage{
+		client:    getRedisClient(config),
+		keyPrefix: redisKeyPrefix + name + ":",
+		rate:      rtl,
+		burst:     burst,
+		maxDelay:  maxDelay,
+		ttl:       ttl,
+	}
+}
+
+// reserve takes a token from the bucket of the given source,
+// and returns the delay to wait before the request can be served.
+// When the delay is greater than the maximum delay, no token is taken.
+func (s *redisStorage) reserve(source string) (time.Duration, error) {
+	if s.rate <= 0 {
+		return 0, nil
+	}
+
+	result, err := reserveScript.Run(s.client, []string{s.keyPrefix + source},
+		float64(s.rate),
+		s.burst,
+		time.Now().UnixNano()/int64(time.Microsecond),
+		s.maxDelay.Microseconds(),
+		s.ttl.Milliseconds(),
+	).Result()
+	if err != nil {
+		return 0, fmt.Errorf("could not reserve a token: %w", err)
+	}
+
+	delay, ok := result.(int64)
+	if !ok || delay < 0 || delay > math.MaxInt64/int64(time.Microsecond) {
+		return 0, fmt.Errorf("invalid delay: %v", result)
+	}
+
+	return time.Duration(delay) * time.Microsecond, nil
+}
@@ -0,0 +1,138 @@
+package ratelimiter
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	ptypes "github.com/traefik/paerser/types"
+	"github.com/traefik/traefik/v2/pkg/config/dynamic"
+)
+
+func newRedisRateLimiter(t *testing.T, address, fallback string) http.Handler {
+	t.Helper()
+
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+
+	config := dynamic.RateLimit{
+		Average: 1,
+		Period:  ptypes.Duration(time.Minute),
+		Burst:   3,
+		Storage: &dynamic.RateLimitStorage{
+			Redis: &dynamic.RedisStorage{
+				Address: address,
+				Timeout: ptypes.Duration(100 * time.Millisecond),
+			},
+			Fallback: fallback,
+		},
+	}
+
+	h, err := New(context.Background(), next, config, "rate-limiter")
+	require.NoError(t, err)
+
+	return h
+}
+
+func serve(h http.Handler) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "http://localhost", nil)
+	req.RemoteAddr = "10.0.0.1:1234"
+
+	recorder := httptest.NewRecorder()
+	h.ServeHTTP(recorder, req)
+
+	return recorder
+}
+
+func TestRateLimit_redis(t *testing.T) {
+	server := miniredis.RunT(t)
+
+	// Two rate limiters sharing the same storage, as two Traefik instances would.
+	first := newRedisRateLimiter(t, server.Addr(), "")
+	second := newRedisRateLimiter(t, server.Addr(), "")
+
+	assert.Equal(t, http.StatusOK, serve(first).Code)
+	assert.Equal(t, http.StatusOK, serve(second).Code)
+	assert.Equal(t, http.StatusOK, serve(first).Code)
+
+	recorder := serve(second)
+	assert.Equal(t, http.StatusTooManyRequests, recorder.Code)
+	assert.NotEmpty(t, recorder.Header().Get("Retry-After"))
+
+	assert.Equal(t, http.StatusTooManyRequests, serve(first).Code)
+
+	key := "traefik:ratelimit:rate-limiter:10.0.0.1"
+	require.True(t, server.Exists(key))
+	assert.True(t, server.TTL(key) > 0)
+
+	// The rejected requests do not take any token.
+	tokens, err := strconv.ParseFloat(server.HGet(key, "tokens"), 64)
+	require.NoError(t, err)
+	assert.InDelta(t, 0, tokens, 0.1)
+}
+
+func TestRateLimit_redisFallback(t *testing.T) {
+	testCases := []struct {
+		desc          string
+		fallback      string
+		expectedCodes []int
+	}{
+		{
+			desc:          "local",
+			fallback:      dynamic.RateLimitFallbackLocal,
+			expectedCodes: []int{http.StatusOK, http.StatusOK, http.StatusOK, http.StatusTooManyRequests},
+		},
+		{
+			desc:          "allow",
+			fallback:      dynamic.RateLimitFallbackAllow,
+			expectedCodes: []int{http.StatusOK, http.StatusOK, http.StatusOK, http.StatusOK},
+		},
+		{
+			desc:          "deny",
+			fallback:      dynamic.RateLimitFallbackDeny,
+			expectedCodes: []int{http.StatusServiceUnavailable, http.StatusServiceUnavailable, http.StatusServiceUnavailable, http.StatusServiceUnavailable},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			server := miniredis.RunT(t)
+			h := newRedisRateLimiter(t, server.Addr(), test.fallback)
+			server.Close()
+
+			var codes []int
+			for range test.expectedCodes {
+				codes = append(codes, serve(h).Code)
+			}
+
+			assert.Equal(t, test.expectedCodes, codes)
+		})
+	}
+}
+
+func TestPruneRedisClients(t *testing.T) {
+	server := miniredis.RunT(t)
+
+	kept := dynamic.RedisStorage{Address: server.Addr()}
+	removed := dynamic.RedisStorage{Address: server.Addr(), DB: 1}
+
+	keptClient := getRedisClient(kept)
+	removedClient := getRedisClient(removed)
+
+	PruneRedisClients(map[dynamic.RedisStorage]struct{}{kept: {}})
+	t.Cleanup(func() { PruneRedisClients(nil) })
+
+	assert.NoError(t, keptClient.Ping().Err())
+	assert.Error(t, removedClient.Ping().Err())
+
+	// A client is created again when its configuration is used again.
+	assert.NoError(t, getRedisClient(removed).Ping().Err())
+}
@@ -0,0 +1,74 @@
+package retry
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"sync"
+)
+
+type attemptsKey struct{}
+
+// Attempts keeps track of the servers to which a request has been forwarded,
+// so that the load-balancers can select another server when the request is retried.
+type Attempts struct {
+	mu      sync.Mutex
+	servers []*url.URL
+}
+
+// AttemptsFromContext returns the Attempts of the request, or nil when the request cannot be retried.
+func AttemptsFromContext(ctx context.Context) *Attempts {
+	attempts, _ := ctx.Value(attemptsKey{}).(*Attempts)
+	return attempts
+}
+
+// Tried reports whether the request has already been forwarded to the server.
+func (a *Attempts) Tried(u *url.URL) bool {
+	if a == nil {
+		return false
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	for _, server := range a.servers {
+		if server.Host == u.Host && server.Scheme == u.Scheme && server.Path == u.Path {
+			return true
+		}
+	}
+	return false
+}
+
+// Len returns the number of times the request has been forwarded to a server.
+func (a *Attempts) Len() int {
+	if a == nil {
+		return 0
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	return len(a.servers)
+}
+
+func (a *Attempts) add(u *url.URL) {
+	if a == nil {
+		return
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	copied := *u
+	a.servers = append(a.servers, &copied)
+}
+
+// TrackServers returns a handler recording the servers to which the requests are forwarded, in their Attempts.
+// It must be placed between the load-balancer and the forwarder,
+// so that the URL of the request is the URL of the selected server.
+func TrackServers(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		AttemptsFromContext(req.Context()).add(req.URL)
+		next.ServeHTTP(rw, req)
+	})
+}
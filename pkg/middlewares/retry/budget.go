@@ -0,0 +1,98 @@
+package retry
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	budgetWindow = 10 * time.Second
+	// budgetMinRetries is the number of retries always allowed per window,
+	// so that the requests of a low traffic service can still be retried.
+	budgetMinRetries = 3
+)
+
+// budget caps the number of retries to a percentage of the requests.
+// The requests and retries are counted over the current and the previous windows,
+// which smooths the transition between two windows.
+type budget struct {
+	percent int
+	now     func() time.Time
+
+	mu           sync.Mutex
+	windowStart  time.Time
+	requests     int
+	retries      int
+	prevRequests int
+	prevRetries  int
+}
+
+func newBudget(percent int) *budget {
+	return &budget{percent: percent, now: time.Now}
+}
+
+// request records a request.
+func (b *budget) request() {
+	if b == nil {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.rotate()
+	b.requests++
+}
+
+// allowed reports whether a retry would be allowed.
+func (b *budget) allowed() bool {
+	if b == nil {
+		return true
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.rotate()
+
+	retries := b.retries + b.prevRetries
+	if retries < budgetMinRetries {
+		return true
+	}
+
+	return (retries+1)*100 <= (b.requests+b.prevRequests)*b.percent
+}
+
+// retried records a retry.
+func (b *budget) retried() {
+	if b == nil {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.rotate()
+	b.retries++
+}
+
+// rotate starts a new window when the current one is over.
+// It must be called under the budget lock.
+func (b *budget) rotate() {
+	now := b.now()
+
+	elapsed := now.Sub(b.windowStart)
+	if elapsed < budgetWindow {
+		return
+	}
+
+	if elapsed < 2*budgetWindow {
+		b.prevRequests, b.prevRetries = b.requests, b.retries
+		b.windowStart = b.windowStart.Add(budgetWindow)
+	} else {
+		b.prevRequests, b.prevRetries = 0, 0
+		b.windowStart = now
+	}
+
+	b.requests, b.retries = 0, 0
+}
@@ -0,0 +1,47 @@
+package retry
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBudget(t *testing.T) {
+	now := time.Now()
+	b := newBudget(20)
+	b.now = func() time.Time { return now }
+
+	for i := 0; i < 10; i++ {
+		b.request()
+	}
+
+	// The first retries are always allowed.
+	for i := 0; i < budgetMinRetries; i++ {
+		assert.True(t, b.allowed())
+		b.retried()
+	}
+
+	// 20% of 10 requests.
+	assert.False(t, b.allowed())
+
+	for i := 0; i < 10; i++ {
+		b.request()
+	}
+	assert.True(t, b.allowed())
+	b.retried()
+	assert.False(t, b.allowed())
+
+	// The previous window is still taken into account.
+	now = now.Add(budgetWindow)
+	assert.False(t, b.allowed())
+
+	for i := 0; i < 5; i++ {
+		b.request()
+	}
+	assert.True(t, b.allowed())
+
+	// Both windows are over.
+	now = now.Add(2 * budgetWindow)
+	assert.True(t, b.allowed())
+}
@@ -2,13 +2,17 @@ package retry
 
 import (
 	"bufio"
+	"bytes"
 	"context"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"math"
 	"net"
 	"net/http"
 	"net/http/httptrace"
+	"strconv"
+	"sync/atomic"
 	"time"
 
 	"github.com/cenkalti/backoff/v4"
@@ -17,6 +21,7 @@ import (
 	"github.com/traefik/traefik/v2/pkg/log"
 	"github.com/traefik/traefik/v2/pkg/middlewares"
 	"github.com/traefik/traefik/v2/pkg/tracing"
+	"github.com/traefik/traefik/v2/pkg/types"
 )
 
 // Compile time validation that the response writer implements http interfaces correctly.
@@ -24,6 +29,20 @@ var _ middlewares.Stateful = &responseWriterWithCloseNotify{}
 
 const (
 	typeName = "Retry"
+
+	// defaultMaxRetryAfter caps the wait requested by the Retry-After header when no MaxInterval is configured.
+	defaultMaxRetryAfter = 10 * time.Second
+
+	// maxReplayableBodyBytes is the size above which a request body is not kept to be replayed,
+	// in which case the request is not retried on the response status codes.
+	maxReplayableBodyBytes = 1 << 20
+)
+
+// The states of an attempt with a per-try timeout.
+const (
+	attemptPending int32 = iota
+	attemptResponded
+	attemptTimedOut
 )
 
 // Listener is used to inform about retry attempts.
@@ -44,11 +63,16 @@ type nexter interface {
 
 // retry is a middleware that retries requests.
 type retry struct {
-	attempts        int
-	initialInterval time.Duration
-	next            http.Handler
-	listener        Listener
-	name            string
+	attempts           int
+	initialInterval    time.Duration
+	maxInterval        time.Duration
+	statuses           types.HTTPCodeRanges
+	retryNonIdempotent bool
+	perTryTimeout      time.Duration
+	budget             *budget
+	next               http.Handler
+	listener           Listener
+	name               string
 }
 
 // New returns a new retry middleware.
@@ -59,12 +83,31 @@ func New(ctx context.Context, next http.Handler, config dynamic.Retry, listener
 		return nil, fmt.Errorf("incorrect (or empty) value for attempt (%d)", config.Attempts)
 	}
 
+	statuses, err := types.NewHTTPCodeRanges(config.Status)
+	if err != nil {
+		return nil, fmt.Errorf("invalid status: %w", err)
+	}
+
+	if config.BudgetPercent < 0 || config.BudgetPercent > 100 {
+		return nil, fmt.Errorf("budgetPercent must be between 0 and 100, got %d", config.BudgetPercent)
+	}
+
+	var b *budget
+	if config.BudgetPercent > 0 {
+		b = newBudget(config.BudgetPercent)
+	}
+
 	return &retry{
-		attempts:        config.Attempts,
-		initialInterval: time.Duration(config.InitialInterval),
-		next:            next,
-		listener:        listener,
-		name:            name,
+		attempts:           config.Attempts,
+		initialInterval:    time.Duration(config.InitialInterval),
+		maxInterval:        time.Duration(config.MaxInterval),
+		statuses:           statuses,
+		retryNonIdempotent: config.RetryNonIdempotent,
+		perTryTimeout:      time.Duration(config.PerTryTimeout),
+		budget:             b,
+		next:               next,
+		listener:           listener,
+		name:               name,
 	}, nil
 }
 
@@ -73,14 +116,35 @@ func (r *retry) GetTracingInformation() (string, ext.SpanKindEnum) {
 }
 
 func (r *retry) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	r.budget.request()
+
+	retryStatus := r.canRetryStatus(req)
+
+	var replayBody []byte
+	if retryStatus && req.Body != nil && req.Body != http.NoBody && req.ContentLength != 0 {
+		body, err := ioutil.ReadAll(io.LimitReader(req.Body, maxReplayableBodyBytes))
+		_ = req.Body.Close()
+		if err != nil {
+			log.FromContext(middlewares.GetLoggerCtx(req.Context(), r.name, typeName)).
+				Debugf("Error while reading request body: %v", err)
+			http.Error(rw, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+			return
+		}
+		replayBody = body
+	}
+
 	// if we might make multiple attempts, swap the body for an ioutil.NopCloser
 	// cf https://github.com/traefik/traefik/issues/1008
-	if r.attempts > 1 {
+	if r.attempts > 1 && replayBody == nil {
 		body := req.Body
 		defer body.Close()
 		req.Body = ioutil.NopCloser(body)
 	}
 
+	if r.attempts > 1 {
+		req = req.WithContext(context.WithValue(req.Context(), attemptsKey{}, &Attempts{}))
+	}
+
 	attempts := 1
 	backOff := r.newBackOff()
 	currentInterval := 0 * time.Millisecond
@@ -88,33 +152,75 @@ func (r *retry) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 		select {
 		case <-time.After(currentInterval):
 
-			shouldRetry := attempts < r.attempts
+			shouldRetry := attempts < r.attempts && r.budget.allowed()
 			retryResponseWriter := newResponseWriter(rw, shouldRetry)
 
+			var retryAfter time.Duration
+			if shouldRetry && retryStatus {
+				retryResponseWriter.setRetryStatus(func(code int, header http.Header) bool {
+					if !r.statuses.Contains(code) {
+						return false
+					}
+
+					wait, ok := parseRetryAfter(header.Get("Retry-After"))
+					if ok && wait > r.maxRetryAfter() {
+						return false
+					}
+
+					retryAfter = wait
+					return true
+				})
+			}
+
 			// Disable retries when the backend already received request data
 			trace := &httptrace.ClientTrace{
 				WroteHeaders: func() {
-					retryResponseWriter.DisableRetries()
+					retryResponseWriter.RequestSent()
 				},
 				WroteRequest: func(httptrace.WroteRequestInfo) {
-					retryResponseWriter.DisableRetries()
+					retryResponseWriter.RequestSent()
 				},
 			}
 			newCtx := httptrace.WithClientTrace(req.Context(), trace)
 
-			r.next.ServeHTTP(retryResponseWriter, req.WithContext(newCtx))
+			stop := func() {}
+			if r.perTryTimeout > 0 {
+				var cancel context.CancelFunc
+				newCtx, cancel = context.WithCancel(newCtx)
+				timer := time.AfterFunc(r.perTryTimeout, func() {
+					if retryResponseWriter.timeOut() {
+						cancel()
+					}
+				})
+				stop = func() {
+					timer.Stop()
+					cancel()
+				}
+			}
+
+			outReq := req.WithContext(newCtx)
+			if replayBody != nil {
+				outReq.Body = ioutil.NopCloser(bytes.NewReader(replayBody))
+			}
+
+			r.next.ServeHTTP(retryResponseWriter, outReq)
+			stop()
 
 			if !retryResponseWriter.ShouldRetry() {
 				return
 			}
 
 			currentInterval = backOff.NextBackOff()
+			if retryAfter > currentInterval {
+				currentInterval = retryAfter
+			}
 
 			attempts++
 
 			log.FromContext(middlewares.GetLoggerCtx(req.Context(), r.name, typeName)).
 				Debugf("New attempt %d for request: %v", attempts, req.URL)
 
+			r.budget.retried()
 			r.listener.Retried(req, attempts)
 
 		case <-req.Context().Done():
@@ -123,6 +229,30 @@ func (r *retry) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 	}
 }
 
+// canRetryStatus reports whether the request can be retried on the response status codes.
+// As the server has already received the request, its method must be idempotent, and its body must be kept to be sent again.
+func (r *retry) canRetryStatus(req *http.Request) bool {
+	if r.attempts < 2 || len(r.statuses) == 0 {
+		return false
+	}
+
+	if !r.retryNonIdempotent && !isIdempotent(req.Method) {
+		return false
+	}
+
+	if req.Body == nil || req.Body == http.NoBody || req.ContentLength == 0 {
+		return true
+	}
+	return req.ContentLength > 0 && req.ContentLength <= maxReplayableBodyBytes
+}
+
+func (r *retry) maxRetryAfter() time.Duration {
+	if r.maxInterval > 0 {
+		return r.maxInterval
+	}
+	return defaultMaxRetryAfter
+}
+
 func (r *retry) newBackOff() nexter {
 	if r.attempts < 2 || r.initialInterval <= 0 {
 		return &backoff.ZeroBackOff{}
@@ -131,6 +261,13 @@ func (r *retry) newBackOff() nexter {
 	b := backoff.NewExponentialBackOff()
 	b.InitialInterval = r.initialInterval
 
+	if r.maxInterval > 0 {
+		b.Multiplier = 2
+		b.MaxInterval = r.maxInterval
+		b.Reset()
+		return b
+	}
+
 	// calculate the multiplier for the given number of attempts
 	// so that applying the multiplier for the given number of attempts will not exceed 2 times the initial interval
 	// it allows to control the progression along the attempts
@@ -141,6 +278,42 @@ func (r *retry) newBackOff() nexter {
 	return b
 }
 
+// isIdempotent reports whether the method is idempotent, as defined by RFC 7231, section 4.2.2.
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+		return true
+	default:
+		return false
+	}
+}
+
+// parseRetryAfter parses the value of a Retry-After header,
+// which is either a number of seconds or an HTTP date.
+func parseRetryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	date, err := http.ParseTime(value)
+	if err != nil {
+		return 0, false
+	}
+
+	wait := time.Until(date)
+	if wait < 0 {
+		wait = 0
+	}
+	return wait, true
+}
+
 // Retried exists to implement the Listener interface. It calls Retried on each of its slice entries.
 func (l Listeners) Retried(req *http.Request, attempt int) {
 	for _, listener := range l {
@@ -153,6 +326,9 @@ type responseWriter interface {
 	http.Flusher
 	ShouldRetry() bool
 	DisableRetries()
+	RequestSent()
+	setRetryStatus(retryStatus func(code int, header http.Header) bool)
+	timeOut() bool
 }
 
 func newResponseWriter(rw http.ResponseWriter, shouldRetry bool) responseWriter {
@@ -174,6 +350,16 @@ type responseWriterWithoutCloseNotify struct {
 	headers        http.Header
 	shouldRetry    bool
 	written        bool
+	discard        bool
+
+	// requestSent is true when the server received request data.
+	// The request is then only retried if retryStatus allows it.
+	requestSent bool
+	retryStatus func(code int, header http.Header) bool
+
+	// state is the state of the attempt regarding its per-try timeout.
+	// It must be accessed atomically, as the timeout is triggered from another goroutine.
+	state int32
 }
 
 func (r *responseWriterWithoutCloseNotify) ShouldRetry() bool {
@@ -184,6 +370,20 @@ func (r *responseWriterWithoutCloseNotify) DisableRetries() {
 	r.shouldRetry = false
 }
 
+func (r *responseWriterWithoutCloseNotify) RequestSent() {
+	r.requestSent = true
+	r.DisableRetries()
+}
+
+func (r *responseWriterWithoutCloseNotify) setRetryStatus(retryStatus func(code int, header http.Header) bool) {
+	r.retryStatus = retryStatus
+}
+
+// timeOut marks the attempt as timed out, unless the response has already started.
+func (r *responseWriterWithoutCloseNotify) timeOut() bool {
+	return atomic.CompareAndSwapInt32(&r.state, attemptPending, attemptTimedOut)
+}
+
 func (r *responseWriterWithoutCloseNotify) Header() http.Header {
 	if r.written {
 		return r.responseWriter.Header()
@@ -192,13 +392,20 @@ func (r *responseWriterWithoutCloseNotify) Header() http.Header {
 }
 
 func (r *responseWriterWithoutCloseNotify) Write(buf []byte) (int, error) {
-	if r.ShouldRetry() {
+	if r.ShouldRetry() || r.discard {
 		return len(buf), nil
 	}
 	return r.responseWriter.Write(buf)
 }
 
 func (r *responseWriterWithoutCloseNotify) WriteHeader(code int) {
+	timedOut := !atomic.CompareAndSwapInt32(&r.state, attemptPending, attemptResponded) &&
+		atomic.LoadInt32(&r.state) == attemptTimedOut
+	if timedOut {
+		// The response comes from the cancellation of the attempt by its per-try timeout.
+		code = http.StatusGatewayTimeout
+	}
+
 	if r.ShouldRetry() && code == http.StatusServiceUnavailable {
 		// We get a 503 HTTP Status Code when there is no backend server in the pool
 		// to which the request could be sent.  Also, note that r.ShouldRetry()
@@ -208,10 +415,21 @@ func (r *responseWriterWithoutCloseNotify) WriteHeader(code int) {
 		r.DisableRetries()
 	}
 
+	if r.requestSent && !r.written && r.retryStatus != nil && r.retryStatus(code, r.headers) {
+		r.shouldRetry = true
+	}
+
 	if r.ShouldRetry() {
 		return
 	}
 
+	if timedOut {
+		r.discard = true
+		r.written = true
+		http.Error(r.responseWriter, http.StatusText(code), code)
+		return
+	}
+
 	// In that case retry case is set to false which means we at least managed
 	// to write headers to the backend : we are not going to perform any further retry.
 	// So it is now safe to alter current response headers with headers collected during
@@ -234,6 +452,11 @@ func (r *responseWriterWithoutCloseNotify) Hijack() (net.Conn, *bufio.ReadWriter
 }
 
 func (r *responseWriterWithoutCloseNotify) Flush() {
+	// Nothing is sent to the client while the attempt can be retried.
+	if r.ShouldRetry() || r.discard {
+		return
+	}
+
 	if flusher, ok := r.responseWriter.(http.Flusher); ok {
 		flusher.Flush()
 	}
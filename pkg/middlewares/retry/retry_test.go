@@ -3,6 +3,7 @@ package retry
 import (
 	"context"
 	"fmt"
+	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
 	"net/http/httptrace"
@@ -350,3 +351,242 @@ func TestRetryWebsocket(t *testing.T) {
 		})
 	}
 }
+
+func TestRetry_status(t *testing.T) {
+	testCases := []struct {
+		desc               string
+		config             dynamic.Retry
+		method             string
+		body               string
+		header             http.Header
+		wantRetryAttempts  int
+		wantResponseStatus int
+	}{
+		{
+			desc:               "no retry without status",
+			config:             dynamic.Retry{Attempts: 3},
+			method:             http.MethodGet,
+			wantRetryAttempts:  0,
+			wantResponseStatus: http.StatusBadGateway,
+		},
+		{
+			desc:               "retry on status",
+			config:             dynamic.Retry{Attempts: 3, Status: []string{"502-504"}},
+			method:             http.MethodGet,
+			wantRetryAttempts:  1,
+			wantResponseStatus: http.StatusOK,
+		},
+		{
+			desc:               "no retry on other status",
+			config:             dynamic.Retry{Attempts: 3, Status: []string{"503"}},
+			method:             http.MethodGet,
+			wantRetryAttempts:  0,
+			wantResponseStatus: http.StatusBadGateway,
+		},
+		{
+			desc:               "retry with body on idempotent method",
+			config:             dynamic.Retry{Attempts: 3, Status: []string{"502"}},
+			method:             http.MethodPut,
+			body:               "foobar",
+			wantRetryAttempts:  1,
+			wantResponseStatus: http.StatusOK,
+		},
+		{
+			desc:               "no retry on non-idempotent method",
+			config:             dynamic.Retry{Attempts: 3, Status: []string{"502"}},
+			method:             http.MethodPost,
+			body:               "foobar",
+			wantRetryAttempts:  0,
+			wantResponseStatus: http.StatusBadGateway,
+		},
+		{
+			desc:               "retry on non-idempotent method",
+			config:             dynamic.Retry{Attempts: 3, Status: []string{"502"}, RetryNonIdempotent: true},
+			method:             http.MethodPost,
+			body:               "foobar",
+			wantRetryAttempts:  1,
+			wantResponseStatus: http.StatusOK,
+		},
+		{
+			desc:               "no retry when Retry-After is larger than max interval",
+			config:             dynamic.Retry{Attempts: 3, Status: []string{"502"}, MaxInterval: ptypes.Duration(time.Second)},
+			method:             http.MethodGet,
+			header:             http.Header{"Retry-After": {"2"}},
+			wantRetryAttempts:  0,
+			wantResponseStatus: http.StatusBadGateway,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			var bodies []string
+			next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				// The request is sent to the server.
+				httptrace.ContextClientTrace(req.Context()).WroteHeaders()
+
+				body, err := ioutil.ReadAll(req.Body)
+				require.NoError(t, err)
+				bodies = append(bodies, string(body))
+
+				if len(bodies) == 1 {
+					for name, values := range test.header {
+						rw.Header()[name] = values
+					}
+					rw.WriteHeader(http.StatusBadGateway)
+					_, _ = rw.Write([]byte("first attempt"))
+					return
+				}
+
+				rw.WriteHeader(http.StatusOK)
+			})
+
+			retryListener := &countingRetryListener{}
+			retry, err := New(context.Background(), next, test.config, retryListener, "traefikTest")
+			require.NoError(t, err)
+
+			recorder := httptest.NewRecorder()
+			retry.ServeHTTP(recorder, httptest.NewRequest(test.method, "http://localhost/", strings.NewReader(test.body)))
+
+			assert.Equal(t, test.wantResponseStatus, recorder.Code)
+			assert.Equal(t, test.wantRetryAttempts, retryListener.timesCalled)
+
+			for _, body := range bodies {
+				assert.Equal(t, test.body, body)
+			}
+
+			if test.wantRetryAttempts > 0 {
+				assert.Empty(t, recorder.Body.String())
+			}
+		})
+	}
+}
+
+func TestRetry_retryAfter(t *testing.T) {
+	var attempts []time.Time
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		httptrace.ContextClientTrace(req.Context()).WroteHeaders()
+
+		attempts = append(attempts, time.Now())
+		if len(attempts) == 1 {
+			rw.Header().Set("Retry-After", "1")
+			rw.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		rw.WriteHeader(http.StatusOK)
+	})
+
+	retry, err := New(context.Background(), next, dynamic.Retry{Attempts: 2, Status: []string{"503"}}, &countingRetryListener{}, "traefikTest")
+	require.NoError(t, err)
+
+	recorder := httptest.NewRecorder()
+	retry.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "http://localhost/", nil))
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	require.Len(t, attempts, 2)
+	assert.GreaterOrEqual(t, int64(attempts[1].Sub(attempts[0])), int64(time.Second))
+}
+
+func TestRetry_perTryTimeout(t *testing.T) {
+	testCases := []struct {
+		desc               string
+		status             []string
+		wantRetryAttempts  int
+		wantResponseStatus int
+	}{
+		{
+			desc:               "retry on timeout",
+			status:             []string{"504"},
+			wantRetryAttempts:  1,
+			wantResponseStatus: http.StatusOK,
+		},
+		{
+			desc:               "no retry on timeout",
+			wantRetryAttempts:  0,
+			wantResponseStatus: http.StatusGatewayTimeout,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			var calls int
+			next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				httptrace.ContextClientTrace(req.Context()).WroteHeaders()
+
+				calls++
+				if calls == 1 {
+					// The first attempt hangs until it is canceled.
+					<-req.Context().Done()
+					http.Error(rw, "canceled", 499)
+					return
+				}
+
+				rw.WriteHeader(http.StatusOK)
+			})
+
+			config := dynamic.Retry{
+				Attempts:      2,
+				Status:        test.status,
+				PerTryTimeout: ptypes.Duration(50 * time.Millisecond),
+			}
+
+			retryListener := &countingRetryListener{}
+			retry, err := New(context.Background(), next, config, retryListener, "traefikTest")
+			require.NoError(t, err)
+
+			recorder := httptest.NewRecorder()
+			retry.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "http://localhost/", nil))
+
+			assert.Equal(t, test.wantResponseStatus, recorder.Code)
+			assert.Equal(t, test.wantRetryAttempts, retryListener.timesCalled)
+		})
+	}
+}
+
+func TestRetry_budget(t *testing.T) {
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		httptrace.ContextClientTrace(req.Context()).WroteHeaders()
+		rw.WriteHeader(http.StatusBadGateway)
+	})
+
+	retryListener := &countingRetryListener{}
+	retry, err := New(context.Background(), next, dynamic.Retry{Attempts: 2, Status: []string{"502"}, BudgetPercent: 10}, retryListener, "traefikTest")
+	require.NoError(t, err)
+
+	for i := 0; i < 20; i++ {
+		recorder := httptest.NewRecorder()
+		retry.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "http://localhost/", nil))
+
+		assert.Equal(t, http.StatusBadGateway, recorder.Code)
+	}
+
+	// The first retries are always allowed, the next ones are limited to 10% of the requests.
+	assert.Equal(t, budgetMinRetries, retryListener.timesCalled)
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	testCases := []struct {
+		value    string
+		expected time.Duration
+		ok       bool
+	}{
+		{value: ""},
+		{value: "foo"},
+		{value: "-1"},
+		{value: "0", ok: true},
+		{value: "120", expected: 2 * time.Minute, ok: true},
+		{value: "Wed, 21 Oct 2015 07:28:00 GMT", ok: true},
+	}
+
+	for _, test := range testCases {
+		wait, ok := parseRetryAfter(test.value)
+		assert.Equal(t, test.ok, ok, test.value)
+		assert.Equal(t, test.expected, wait, test.value)
+	}
+}
@@ -14,6 +14,15 @@ type MockTracer struct {
 // StartSpan belongs to the Tracer interface.
 func (n MockTracer) StartSpan(operationName string, opts ...opentracing.StartSpanOption) opentracing.Span {
 	n.Span.OpName = operationName
+
+	options := opentracing.StartSpanOptions{}
+	for _, opt := range opts {
+		opt.Apply(&options)
+	}
+	for k, v := range options.Tags {
+		n.Span.SetTag(k, v)
+	}
+
 	return n.Span
 }
 
@@ -0,0 +1,278 @@
+package acme
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"reflect"
+	"sync"
+
+	"github.com/kvtools/valkeyrie/store"
+	"github.com/traefik/traefik/v2/pkg/log"
+	"github.com/traefik/traefik/v2/pkg/provider/kv"
+	"github.com/traefik/traefik/v2/pkg/types"
+)
+
+const (
+	accountKey      = "account"
+	certificatesKey = "certificates"
+	lockKey         = "lock"
+
+	// maxSaveAttempts is the number of attempts to save the certificates when they are concurrently modified by another instance.
+	maxSaveAttempts = 10
+)
+
+var kvBackends = map[string]store.Backend{
+	"consul":    store.CONSUL,
+	"etcd":      store.ETCDV3,
+	"redis":     store.REDIS,
+	"zookeeper": store.ZK,
+}
+
+var _ SharedStore = (*KVStore)(nil)
+
+// KVStorage holds the configuration of the KV store where the account and the certificates are shared by the Traefik instances.
+type KVStorage struct {
+	Backend   string           `description:"KV store backend: consul, etcd, redis or zookeeper." json:"backend,omitempty" toml:"backend,omitempty" yaml:"backend,omitempty" export:"true"`
+	Endpoints []string         `description:"KV store endpoints." json:"endpoints,omitempty" toml:"endpoints,omitempty" yaml:"endpoints,omitempty"`
+	Username  string           `description:"KV store username." json:"username,omitempty" toml:"username,omitempty" yaml:"username,omitempty"`
+	Password  string           `description:"KV store password." json:"password,omitempty" toml:"password,omitempty" yaml:"password,omitempty"`
+	TLS       *types.ClientTLS `description:"Enable TLS support." json:"tls,omitempty" toml:"tls,omitempty" yaml:"tls,omitempty" export:"true"`
+	RootKey   string           `description:"Root key of the ACME data." json:"rootKey,omitempty" toml:"rootKey,omitempty" yaml:"rootKey,omitempty" export:"true"`
+}
+
+// SetDefaults sets the default values.
+func (k *KVStorage) SetDefaults() {
+	k.RootKey = "acme"
+}
+
+// KVStore Stores implementation for KV stores, shared by the Traefik instances.
+// The account and the certificates of each resolver are stored as JSON under the root key.
+type KVStore struct {
+	client  store.Store
+	rootKey string
+}
+
+// NewKVStore creates a KVStore with a client of the configured KV store.
+func NewKVStore(ctx context.Context, config *KVStorage) (*KVStore, error) {
+	backend, ok := kvBackends[config.Backend]
+	if !ok {
+		return nil, fmt.Errorf("unsupported KV store backend: %q", config.Backend)
+	}
+
+	client, err := kv.NewStore(ctx, backend, config.Endpoints, config.Username, config.Password, config.TLS)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to the KV store: %w", err)
+	}
+
+	return &KVStore{client: client, rootKey: config.RootKey}, nil
+}
+
+func (s *KVStore) key(resolverName, name string) string {
+	return path.Join(s.rootKey, resolverName, name)
+}
+
+// GetAccount returns ACME Account.
+func (s *KVStore) GetAccount(resolverName string) (*Account, error) {
+	pair, err := s.client.Get(s.key(resolverName, accountKey), nil)
+	if errors.Is(err, store.ErrKeyNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	account := &Account{}
+	if err := json.Unmarshal(pair.Value, account); err != nil {
+		return nil, err
+	}
+
+	return account, nil
+}
+
+// SaveAccount stores ACME Account.
+func (s *KVStore) SaveAccount(resolverName string, account *Account) error {
+	data, err := json.Marshal(account)
+	if err != nil {
+		return err
+	}
+
+	return s.client.Put(s.key(resolverName, accountKey), data, nil)
+}
+
+// GetCertificates returns ACME Certificates list.
+func (s *KVStore) GetCertificates(resolverName string) ([]*CertAndStore, error) {
+	certificates, _, err := s.getCertificates(resolverName)
+	return certificates, err
+}
+
+func (s *KVStore) getCertificates(resolverName string) ([]*CertAndStore, *store.KVPair, error) {
+	pair, err := s.client.Get(s.key(resolverName, certificatesKey), nil)
+	if errors.Is(err, store.ErrKeyNotFound) {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	certificates, err := decodeCertificates(pair.Value)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return certificates, pair, nil
+}
+
+// SaveCertificates stores ACME Certificates list.
+// The certificates are merged with the ones stored by the other instances,
+// those with the same domains being replaced.
+func (s *KVStore) SaveCertificates(resolverName string, certificates []*CertAndStore) error {
+	key := s.key(resolverName, certificatesKey)
+
+	for i := 0; i < maxSaveAttempts; i++ {
+		stored, previous, err := s.getCertificates(resolverName)
+		if err != nil {
+			return err
+		}
+
+		data, err := json.Marshal(mergeCertificates(stored, certificates))
+		if err != nil {
+			return err
+		}
+
+		ok, _, err := s.client.AtomicPut(key, data, previous, nil)
+		if ok && err == nil {
+			return nil
+		}
+		if err != nil && !errors.Is(err, store.ErrKeyModified) && !errors.Is(err, store.ErrKeyExists) && !errors.Is(err, store.ErrKeyNotFound) {
+			return err
+		}
+	}
+
+	return fmt.Errorf("unable to save the certificates of the resolver %q: modified concurrently %d times", resolverName, maxSaveAttempts)
+}
+
+// Lock acquires the lock of the resolver in the KV store.
+// The lock is released when the returned function is called, or when the context is done.
+func (s *KVStore) Lock(ctx context.Context, resolverName string) (func(), error) {
+	renewCh := make(chan struct{})
+
+	locker, err := s.client.NewLock(s.key(resolverName, lockKey), &store.LockOptions{RenewLock: renewCh})
+	if err != nil {
+		return nil, err
+	}
+
+	// The stop channel aborts the acquisition, and stops holding the lock, when the context is done.
+	stopCh := make(chan struct{})
+	releasedCh := make(chan struct{})
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-releasedCh:
+		}
+		close(stopCh)
+	}()
+
+	if _, err = locker.Lock(stopCh); err != nil {
+		close(releasedCh)
+		close(renewCh)
+		return nil, fmt.Errorf("unable to acquire the lock of the resolver %q: %w", resolverName, err)
+	}
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			if err := locker.Unlock(); err != nil {
+				log.FromContext(ctx).Errorf("Unable to release the lock of the resolver %q: %v", resolverName, err)
+			}
+			close(renewCh)
+			close(releasedCh)
+		})
+	}, nil
+}
+
+// WatchCertificates sends the certificates of the resolver each time they are saved by an instance.
+func (s *KVStore) WatchCertificates(ctx context.Context, resolverName string) (<-chan []*CertAndStore, error) {
+	key := s.key(resolverName, certificatesKey)
+
+	// Some backends cannot watch a missing key.
+	_, _, err := s.client.AtomicPut(key, []byte("[]"), nil, nil)
+	if err != nil && !errors.Is(err, store.ErrKeyExists) && !errors.Is(err, store.ErrKeyModified) {
+		return nil, err
+	}
+
+	pairs, err := s.client.Watch(key, ctx.Done(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	certificatesChan := make(chan []*CertAndStore)
+	go func() {
+		defer close(certificatesChan)
+
+		for pair := range pairs {
+			if pair == nil {
+				continue
+			}
+
+			certificates, err := decodeCertificates(pair.Value)
+			if err != nil {
+				log.FromContext(ctx).Errorf("Unable to decode the certificates of the resolver %q: %v", resolverName, err)
+				continue
+			}
+
+			select {
+			case certificatesChan <- certificates:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return certificatesChan, nil
+}
+
+// decodeCertificates decodes the stored certificates, without the ones with no value.
+func decodeCertificates(data []byte) ([]*CertAndStore, error) {
+	var stored []*CertAndStore
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &stored); err != nil {
+			return nil, err
+		}
+	}
+
+	var certificates []*CertAndStore
+	for _, certificate := range stored {
+		if certificate == nil || len(certificate.Certificate.Certificate) == 0 || len(certificate.Key) == 0 {
+			continue
+		}
+		certificates = append(certificates, certificate)
+	}
+
+	return certificates, nil
+}
+
+// mergeCertificates adds the certificates to the stored ones, replacing the stored certificates with the same domains.
+func mergeCertificates(stored, certificates []*CertAndStore) []*CertAndStore {
+	merged := make([]*CertAndStore, len(stored))
+	copy(merged, stored)
+
+	for _, certificate := range certificates {
+		replaced := false
+		for i, storedCertificate := range merged {
+			if reflect.DeepEqual(certificate.Domain, storedCertificate.Domain) {
+				merged[i] = certificate
+				replaced = true
+				break
+			}
+		}
+
+		if !replaced {
+			merged = append(merged, certificate)
+		}
+	}
+
+	return merged
+}
@@ -0,0 +1,419 @@
+package acme
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kvtools/valkeyrie/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traefik/traefik/v2/pkg/tls/generate"
+	"github.com/traefik/traefik/v2/pkg/types"
+)
+
+func TestNewKVStore_unsupportedBackend(t *testing.T) {
+	_, err := NewKVStore(context.Background(), &KVStorage{Backend: "boltdb"})
+	assert.Error(t, err)
+}
+
+func TestKVStore_Account(t *testing.T) {
+	s := &KVStore{client: newMemoryKV(), rootKey: "acme"}
+
+	account, err := s.GetAccount("test")
+	require.NoError(t, err)
+	assert.Nil(t, account)
+
+	expected := &Account{Email: "some42@email.com", KeyType: "RSA4096"}
+	err = s.SaveAccount("test", expected)
+	require.NoError(t, err)
+
+	account, err = s.GetAccount("test")
+	require.NoError(t, err)
+	assert.Equal(t, expected, account)
+
+	// The accounts are stored by resolver.
+	account, err = s.GetAccount("other")
+	require.NoError(t, err)
+	assert.Nil(t, account)
+}
+
+func TestKVStore_SaveCertificates(t *testing.T) {
+	client := newMemoryKV()
+
+	// Two instances sharing the KV store.
+	s1 := &KVStore{client: client, rootKey: "acme"}
+	s2 := &KVStore{client: client, rootKey: "acme"}
+
+	certificates, err := s1.GetCertificates("test")
+	require.NoError(t, err)
+	assert.Empty(t, certificates)
+
+	foo := newCertAndStore("foo.com", "foo")
+	bar := newCertAndStore("bar.com", "bar")
+
+	err = s1.SaveCertificates("test", []*CertAndStore{foo})
+	require.NoError(t, err)
+
+	err = s2.SaveCertificates("test", []*CertAndStore{bar})
+	require.NoError(t, err)
+
+	certificates, err = s1.GetCertificates("test")
+	require.NoError(t, err)
+	assert.Equal(t, []*CertAndStore{foo, bar}, certificates)
+
+	// The certificate with the same domains is replaced.
+	renewed := newCertAndStore("foo.com", "renewed")
+	err = s2.SaveCertificates("test", []*CertAndStore{renewed})
+	require.NoError(t, err)
+
+	certificates, err = s1.GetCertificates("test")
+	require.NoError(t, err)
+	assert.Equal(t, []*CertAndStore{renewed, bar}, certificates)
+
+	// The certificates with no value are ignored.
+	err = s1.SaveCertificates("test", []*CertAndStore{{Certificate: Certificate{Domain: types.Domain{Main: "empty.com"}}}})
+	require.NoError(t, err)
+
+	certificates, err = s2.GetCertificates("test")
+	require.NoError(t, err)
+	assert.Equal(t, []*CertAndStore{renewed, bar}, certificates)
+}
+
+func TestKVStore_SaveCertificates_concurrent(t *testing.T) {
+	client := newMemoryKV()
+
+	var wg sync.WaitGroup
+	for _, domain := range []string{"a.com", "b.com", "c.com", "d.com"} {
+		domain := domain
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			s := &KVStore{client: client, rootKey: "acme"}
+			assert.NoError(t, s.SaveCertificates("test", []*CertAndStore{newCertAndStore(domain, domain)}))
+		}()
+	}
+	wg.Wait()
+
+	s := &KVStore{client: client, rootKey: "acme"}
+	certificates, err := s.GetCertificates("test")
+	require.NoError(t, err)
+	assert.Len(t, certificates, 4)
+}
+
+func TestKVStore_Lock(t *testing.T) {
+	client := newMemoryKV()
+
+	s1 := &KVStore{client: client, rootKey: "acme"}
+	s2 := &KVStore{client: client, rootKey: "acme"}
+
+	unlock, err := s1.Lock(context.Background(), "test")
+	require.NoError(t, err)
+
+	// The lock of another resolver is independent.
+	unlockOther, err := s2.Lock(context.Background(), "other")
+	require.NoError(t, err)
+	unlockOther()
+
+	locked := make(chan func())
+	go func() {
+		unlock2, err := s2.Lock(context.Background(), "test")
+		assert.NoError(t, err)
+		locked <- unlock2
+	}()
+
+	select {
+	case <-locked:
+		t.Fatal("the lock is acquired by two instances")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	unlock()
+	// Releasing the lock twice is a no-op.
+	unlock()
+
+	select {
+	case unlock2 := <-locked:
+		unlock2()
+	case <-time.After(time.Second):
+		t.Fatal("the lock is not acquired after being released")
+	}
+}
+
+func TestKVStore_Lock_canceled(t *testing.T) {
+	client := newMemoryKV()
+
+	s1 := &KVStore{client: client, rootKey: "acme"}
+	s2 := &KVStore{client: client, rootKey: "acme"}
+
+	unlock, err := s1.Lock(context.Background(), "test")
+	require.NoError(t, err)
+	defer unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	_, err = s2.Lock(ctx, "test")
+	assert.Error(t, err)
+}
+
+func TestKVStore_WatchCertificates(t *testing.T) {
+	client := newMemoryKV()
+
+	s1 := &KVStore{client: client, rootKey: "acme"}
+	s2 := &KVStore{client: client, rootKey: "acme"}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	certificatesChan, err := s1.WatchCertificates(ctx, "test")
+	require.NoError(t, err)
+
+	// The current certificates are sent first.
+	select {
+	case certificates := <-certificatesChan:
+		assert.Empty(t, certificates)
+	case <-time.After(time.Second):
+		t.Fatal("the current certificates are not sent")
+	}
+
+	foo := newCertAndStore("foo.com", "foo")
+	err = s2.SaveCertificates("test", []*CertAndStore{foo})
+	require.NoError(t, err)
+
+	select {
+	case certificates := <-certificatesChan:
+		assert.Equal(t, []*CertAndStore{foo}, certificates)
+	case <-time.After(time.Second):
+		t.Fatal("the saved certificates are not sent")
+	}
+
+	cancel()
+
+	select {
+	case _, ok := <-certificatesChan:
+		assert.False(t, ok)
+	case <-time.After(time.Second):
+		t.Fatal("the watch is not stopped")
+	}
+}
+
+func TestProvider_getSharedCertificate(t *testing.T) {
+	client := newMemoryKV()
+	s := &KVStore{client: client, rootKey: "acme"}
+
+	valid := newX509CertAndStore(t, "foo.com", time.Now().Add(90*24*time.Hour))
+	valid.Domain.SANs = []string{"www.foo.com"}
+	expiring := newX509CertAndStore(t, "bar.com", time.Now().Add(24*time.Hour))
+
+	err := s.SaveCertificates("test", []*CertAndStore{valid, expiring})
+	require.NoError(t, err)
+
+	testCases := []struct {
+		desc     string
+		store    Store
+		domains  []string
+		expected *CertAndStore
+	}{
+		{
+			desc:     "certificate checking the domains",
+			store:    s,
+			domains:  []string{"www.foo.com", "foo.com"},
+			expected: valid,
+		},
+		{
+			desc:    "certificate checking only some domains",
+			store:   s,
+			domains: []string{"foo.com", "api.foo.com"},
+		},
+		{
+			desc:    "certificate to renew",
+			store:   s,
+			domains: []string{"bar.com"},
+		},
+		{
+			desc:    "local store",
+			store:   NewLocalStore(t.TempDir() + "/acme.json"),
+			domains: []string{"foo.com"},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			p := &Provider{ResolverName: "test", Store: test.store}
+
+			assert.Equal(t, test.expected, p.getSharedCertificate(context.Background(), test.domains))
+		})
+	}
+}
+
+func newCertAndStore(domain, value string) *CertAndStore {
+	return &CertAndStore{
+		Certificate: Certificate{
+			Domain:      types.Domain{Main: domain},
+			Certificate: []byte(value),
+			Key:         []byte(value),
+		},
+		Store: "default",
+	}
+}
+
+func newX509CertAndStore(t *testing.T, domain string, expiration time.Time) *CertAndStore {
+	t.Helper()
+
+	cert, key, err := generate.KeyPair(domain, expiration)
+	require.NoError(t, err)
+
+	return &CertAndStore{
+		Certificate: Certificate{
+			Domain:      types.Domain{Main: domain},
+			Certificate: cert,
+			Key:         key,
+		},
+		Store: "default",
+	}
+}
+
+// memoryKV is an in-memory KV store, implementing the operations used by the KVStore.
+type memoryKV struct {
+	store.Store
+
+	mu       sync.Mutex
+	index    uint64
+	pairs    map[string]*store.KVPair
+	watchers map[string][]chan *store.KVPair
+	locks    map[string]chan struct{}
+}
+
+func newMemoryKV() *memoryKV {
+	return &memoryKV{
+		pairs:    make(map[string]*store.KVPair),
+		watchers: make(map[string][]chan *store.KVPair),
+		locks:    make(map[string]chan struct{}),
+	}
+}
+
+func (m *memoryKV) Get(key string, _ *store.ReadOptions) (*store.KVPair, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	pair, ok := m.pairs[key]
+	if !ok {
+		return nil, store.ErrKeyNotFound
+	}
+	return pair, nil
+}
+
+func (m *memoryKV) Put(key string, value []byte, _ *store.WriteOptions) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.put(key, value)
+	return nil
+}
+
+func (m *memoryKV) AtomicPut(key string, value []byte, previous *store.KVPair, _ *store.WriteOptions) (bool, *store.KVPair, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	pair, ok := m.pairs[key]
+	switch {
+	case previous == nil && ok:
+		return false, nil, store.ErrKeyExists
+	case previous != nil && !ok:
+		return false, nil, store.ErrKeyNotFound
+	case previous != nil && pair.LastIndex != previous.LastIndex:
+		return false, nil, store.ErrKeyModified
+	}
+
+	return true, m.put(key, value), nil
+}
+
+// put must be called under the lock.
+func (m *memoryKV) put(key string, value []byte) *store.KVPair {
+	m.index++
+	pair := &store.KVPair{Key: key, Value: value, LastIndex: m.index}
+	m.pairs[key] = pair
+
+	for _, watcher := range m.watchers[key] {
+		select {
+		case watcher <- pair:
+		default:
+		}
+	}
+
+	return pair
+}
+
+func (m *memoryKV) Watch(key string, stopCh <-chan struct{}, _ *store.ReadOptions) (<-chan *store.KVPair, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	pair, ok := m.pairs[key]
+	if !ok {
+		return nil, store.ErrKeyNotFound
+	}
+
+	watcher := make(chan *store.KVPair, 10)
+	watcher <- pair
+	m.watchers[key] = append(m.watchers[key], watcher)
+
+	pairs := make(chan *store.KVPair)
+	go func() {
+		defer close(pairs)
+
+		for {
+			select {
+			case <-stopCh:
+				return
+			case pair := <-watcher:
+				select {
+				case pairs <- pair:
+				case <-stopCh:
+					return
+				}
+			}
+		}
+	}()
+
+	return pairs, nil
+}
+
+func (m *memoryKV) NewLock(key string, _ *store.LockOptions) (store.Locker, error) {
+	if !strings.HasSuffix(key, "/"+lockKey) {
+		return nil, errors.New("unexpected lock key")
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.locks[key]; !ok {
+		m.locks[key] = make(chan struct{}, 1)
+	}
+
+	return &memoryLock{sem: m.locks[key]}, nil
+}
+
+type memoryLock struct {
+	sem chan struct{}
+}
+
+func (l *memoryLock) Lock(stopCh chan struct{}) (<-chan struct{}, error) {
+	select {
+	case l.sem <- struct{}{}:
+		return make(chan struct{}), nil
+	case <-stopCh:
+		return nil, errors.New("lock aborted")
+	}
+}
+
+func (l *memoryLock) Unlock() error {
+	<-l.sem
+	return nil
+}
@@ -0,0 +1,279 @@
+package acme
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/go-acme/lego/v4/certificate"
+	"github.com/patrickmn/go-cache"
+	ptypes "github.com/traefik/paerser/types"
+	"github.com/traefik/traefik/v2/pkg/log"
+	traefiktls "github.com/traefik/traefik/v2/pkg/tls"
+	"github.com/traefik/traefik/v2/pkg/types"
+	"golang.org/x/time/rate"
+)
+
+const (
+	// askTimeout is the timeout of the requests to the ask URL.
+	askTimeout = 5 * time.Second
+
+	// onDemandCertsTTL is the duration the on-demand certificates are kept in memory,
+	// until they are served by the certificate store.
+	onDemandCertsTTL = 10 * time.Minute
+
+	// onDemandFailureTTL is the duration during which a domain is not resolved again after a failure.
+	onDemandFailureTTL = 5 * time.Minute
+
+	// onDemandDenialTTL is the duration during which a domain which is not allowed is not checked again.
+	onDemandDenialTTL = time.Minute
+
+	// askRate and askBurst limit the rate of the requests to the ask URL,
+	// as any TLS client can trigger them with an unknown SNI.
+	askRate  = 10
+	askBurst = 10
+)
+
+var _ traefiktls.OnDemandResolver = (*onDemandResolver)(nil)
+
+// OnDemand holds the configuration of the on-demand certificates,
+// obtained at handshake time for the domains which are not known beforehand.
+type OnDemand struct {
+	Ask            string             `description:"URL asked, with the domain query parameter, whether a certificate can be obtained for a domain. A 200 response allows it." json:"ask,omitempty" toml:"ask,omitempty" yaml:"ask,omitempty"`
+	AllowedDomains []string           `description:"Regular expressions matching the whole domains allowed to get a certificate." json:"allowedDomains,omitempty" toml:"allowedDomains,omitempty" yaml:"allowedDomains,omitempty"`
+	Timeout        ptypes.Duration    `description:"Duration the handshake waits for the certificate, before the default certificate is served." json:"timeout,omitempty" toml:"timeout,omitempty" yaml:"timeout,omitempty" export:"true"`
+	RateLimit      *OnDemandRateLimit `description:"Limits the rate of the certificates obtained on demand." json:"rateLimit,omitempty" toml:"rateLimit,omitempty" yaml:"rateLimit,omitempty" export:"true"`
+}
+
+// SetDefaults sets the default values.
+func (o *OnDemand) SetDefaults() {
+	o.Timeout = ptypes.Duration(30 * time.Second)
+	o.RateLimit = &OnDemandRateLimit{}
+	o.RateLimit.SetDefaults()
+}
+
+// OnDemandRateLimit holds the rate limit of the certificates obtained on demand.
+// The rate is defined by dividing Average by Period.
+type OnDemandRateLimit struct {
+	Average int64           `description:"Maximum number of certificates obtained per period. 0 means no rate limiting." json:"average,omitempty" toml:"average,omitempty" yaml:"average,omitempty" export:"true"`
+	Period  ptypes.Duration `description:"Period of the rate limit." json:"period,omitempty" toml:"period,omitempty" yaml:"period,omitempty" export:"true"`
+	Burst   int64           `description:"Maximum number of certificates obtained at once." json:"burst,omitempty" toml:"burst,omitempty" yaml:"burst,omitempty" export:"true"`
+}
+
+// SetDefaults sets the default values.
+func (r *OnDemandRateLimit) SetDefaults() {
+	r.Average = 10
+	r.Period = ptypes.Duration(time.Minute)
+	r.Burst = 10
+}
+
+// onDemandCall is an on-demand resolution of a domain, shared by the concurrent handshakes.
+type onDemandCall struct {
+	done chan struct{}
+	cert *tls.Certificate
+	err  error
+}
+
+// onDemandResolver obtains the certificates of the allowed domains at handshake time.
+type onDemandResolver struct {
+	ctx            context.Context
+	askURL         *url.URL
+	allowedDomains []*regexp.Regexp
+	timeout        time.Duration
+	limiter        *rate.Limiter
+	askLimiter     *rate.Limiter
+	client         *http.Client
+
+	resolve func(ctx context.Context, domain types.Domain) (*certificate.Resource, error)
+
+	certs    *cache.Cache
+	failures *cache.Cache
+	denials  *cache.Cache
+
+	callsMu sync.Mutex
+	calls   map[string]*onDemandCall
+}
+
+func newOnDemandResolver(ctx context.Context, config *OnDemand, resolve func(ctx context.Context, domain types.Domain) (*certificate.Resource, error)) (*onDemandResolver, error) {
+	if config.Ask == "" && len(config.AllowedDomains) == 0 {
+		return nil, errors.New("on-demand certificates need an ask URL or allowed domains")
+	}
+
+	resolver := &onDemandResolver{
+		ctx:        ctx,
+		timeout:    time.Duration(config.Timeout),
+		limiter:    rate.NewLimiter(rate.Inf, 0),
+		askLimiter: rate.NewLimiter(askRate, askBurst),
+		client:     &http.Client{Timeout: askTimeout},
+		resolve:    resolve,
+		certs:      cache.New(onDemandCertsTTL, onDemandCertsTTL),
+		failures:   cache.New(onDemandFailureTTL, onDemandFailureTTL),
+		denials:    cache.New(onDemandDenialTTL, onDemandDenialTTL),
+		calls:      make(map[string]*onDemandCall),
+	}
+
+	if config.Ask != "" {
+		askURL, err := url.Parse(config.Ask)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ask URL %q: %w", config.Ask, err)
+		}
+		resolver.askURL = askURL
+	}
+
+	for _, expr := range config.AllowedDomains {
+		// The expressions match the whole domain,
+		// otherwise customers\.example\.com would also allow customers.example.com.attacker.net.
+		allowed, err := regexp.Compile("^(?:" + expr + ")$")
+		if err != nil {
+			return nil, fmt.Errorf("invalid allowed domains %q: %w", expr, err)
+		}
+		resolver.allowedDomains = append(resolver.allowedDomains, allowed)
+	}
+
+	if config.RateLimit != nil && config.RateLimit.Average > 0 {
+		period := time.Duration(config.RateLimit.Period)
+		if period <= 0 {
+			return nil, fmt.Errorf("invalid rate limit period: %s", period)
+		}
+
+		burst := int(config.RateLimit.Burst)
+		if burst < 1 {
+			burst = 1
+		}
+
+		resolver.limiter = rate.NewLimiter(rate.Every(period/time.Duration(config.RateLimit.Average)), burst)
+	}
+
+	return resolver, nil
+}
+
+// ResolveOnDemand returns the certificate of the domain, obtained if the domain is allowed.
+// When the certificate is not obtained before the timeout, a nil certificate is returned,
+// and the certificate is served by the next handshakes once obtained.
+func (r *onDemandResolver) ResolveOnDemand(domain string) (*tls.Certificate, error) {
+	if cert, ok := r.certs.Get(domain); ok {
+		return cert.(*tls.Certificate), nil
+	}
+
+	if _, ok := r.failures.Get(domain); ok {
+		return nil, fmt.Errorf("the on-demand resolution of %q failed recently", domain)
+	}
+
+	if _, ok := r.denials.Get(domain); ok {
+		return nil, nil
+	}
+
+	r.callsMu.Lock()
+	call, ok := r.calls[domain]
+	if !ok {
+		call = &onDemandCall{done: make(chan struct{})}
+		r.calls[domain] = call
+		go r.call(domain, call)
+	}
+	r.callsMu.Unlock()
+
+	timer := time.NewTimer(r.timeout)
+	defer timer.Stop()
+
+	select {
+	case <-call.done:
+		return call.cert, call.err
+	case <-timer.C:
+		log.FromContext(r.ctx).Debugf("The on-demand certificate for %q is not obtained yet, serving the default certificate", domain)
+		return nil, nil
+	}
+}
+
+func (r *onDemandResolver) call(domain string, call *onDemandCall) {
+	call.cert, call.err = r.obtain(domain)
+	if call.cert != nil {
+		r.certs.SetDefault(domain, call.cert)
+	}
+
+	r.callsMu.Lock()
+	delete(r.calls, domain)
+	r.callsMu.Unlock()
+
+	close(call.done)
+}
+
+func (r *onDemandResolver) obtain(domain string) (*tls.Certificate, error) {
+	allowed, err := r.isAllowed(domain)
+	if err != nil {
+		return nil, err
+	}
+	if !allowed {
+		log.FromContext(r.ctx).Debugf("No on-demand certificate allowed for %q", domain)
+		r.denials.SetDefault(domain, struct{}{})
+		return nil, nil
+	}
+
+	if !r.limiter.Allow() {
+		return nil, fmt.Errorf("on-demand certificates rate limit exceeded for %q", domain)
+	}
+
+	log.FromContext(r.ctx).Debugf("Obtaining an on-demand certificate for %q", domain)
+
+	resource, err := r.resolve(r.ctx, types.Domain{Main: domain})
+	if err != nil {
+		// The failures are not retried right away, to not exceed the rate limits of the CA.
+		r.failures.SetDefault(domain, struct{}{})
+		return nil, err
+	}
+	if resource == nil {
+		return nil, nil
+	}
+
+	cert, err := tls.X509KeyPair(resource.Certificate, resource.PrivateKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid on-demand certificate for %q: %w", domain, err)
+	}
+
+	return &cert, nil
+}
+
+// isAllowed checks whether the domain matches the allowed domains, and is accepted by the ask URL, when they are defined.
+// The domains whose ask request fails are not checked again before onDemandFailureTTL.
+func (r *onDemandResolver) isAllowed(domain string) (bool, error) {
+	if len(r.allowedDomains) > 0 && !r.matchAllowedDomains(domain) {
+		return false, nil
+	}
+
+	if r.askURL == nil {
+		return true, nil
+	}
+
+	if !r.askLimiter.Allow() {
+		return false, fmt.Errorf("on-demand ask rate limit exceeded for %q", domain)
+	}
+
+	askURL := *r.askURL
+	query := askURL.Query()
+	query.Set("domain", domain)
+	askURL.RawQuery = query.Encode()
+
+	resp, err := r.client.Get(askURL.String())
+	if err != nil {
+		r.failures.SetDefault(domain, struct{}{})
+		return false, fmt.Errorf("unable to ask whether %q is allowed: %w", domain, err)
+	}
+	_ = resp.Body.Close()
+
+	return resp.StatusCode == http.StatusOK, nil
+}
+
+func (r *onDemandResolver) matchAllowedDomains(domain string) bool {
+	for _, allowed := range r.allowedDomains {
+		if allowed.MatchString(domain) {
+			return true
+		}
+	}
+
+	return false
+}
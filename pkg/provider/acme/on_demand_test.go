@@ -0,0 +1,342 @@
+package acme
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-acme/lego/v4/certificate"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	ptypes "github.com/traefik/paerser/types"
+	"github.com/traefik/traefik/v2/pkg/tls/generate"
+	"github.com/traefik/traefik/v2/pkg/types"
+)
+
+func TestNewOnDemandResolver(t *testing.T) {
+	testCases := []struct {
+		desc          string
+		config        *OnDemand
+		expectedError bool
+	}{
+		{
+			desc:          "without allow-check",
+			config:        &OnDemand{},
+			expectedError: true,
+		},
+		{
+			desc:   "with allowed domains",
+			config: &OnDemand{AllowedDomains: []string{`.+\.example\.com`}},
+		},
+		{
+			desc:   "with ask URL",
+			config: &OnDemand{Ask: "http://localhost:8080/ask"},
+		},
+		{
+			desc:          "invalid allowed domains",
+			config:        &OnDemand{AllowedDomains: []string{`(`}},
+			expectedError: true,
+		},
+		{
+			desc:          "invalid ask URL",
+			config:        &OnDemand{Ask: "http://[::1"},
+			expectedError: true,
+		},
+		{
+			desc: "invalid rate limit period",
+			config: &OnDemand{
+				AllowedDomains: []string{`.*`},
+				RateLimit:      &OnDemandRateLimit{Average: 1},
+			},
+			expectedError: true,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := newOnDemandResolver(context.Background(), test.config, nil)
+			if test.expectedError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestOnDemandResolver_ResolveOnDemand_allowCheck(t *testing.T) {
+	askServer := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.URL.Query().Get("domain") == "allowed.com" {
+			return
+		}
+		rw.WriteHeader(http.StatusForbidden)
+	}))
+	defer askServer.Close()
+
+	testCases := []struct {
+		desc           string
+		config         *OnDemand
+		domain         string
+		expectObtained bool
+	}{
+		{
+			desc:           "matching allowed domains",
+			config:         &OnDemand{AllowedDomains: []string{`^[a-z]+\.example\.com$`}},
+			domain:         "foo.example.com",
+			expectObtained: true,
+		},
+		{
+			desc:   "not matching allowed domains",
+			config: &OnDemand{AllowedDomains: []string{`^[a-z]+\.example\.com$`}},
+			domain: "foo.example.org",
+		},
+		{
+			desc:   "matching only a part of the domain",
+			config: &OnDemand{AllowedDomains: []string{`customers\.example\.com`}},
+			domain: "customers.example.com.attacker.net",
+		},
+		{
+			desc:           "allowed by the ask URL",
+			config:         &OnDemand{Ask: askServer.URL + "/ask?token=secret"},
+			domain:         "allowed.com",
+			expectObtained: true,
+		},
+		{
+			desc:   "refused by the ask URL",
+			config: &OnDemand{Ask: askServer.URL + "/ask?token=secret"},
+			domain: "refused.com",
+		},
+		{
+			desc: "refused by the allowed domains before the ask URL",
+			config: &OnDemand{
+				Ask:            askServer.URL + "/ask",
+				AllowedDomains: []string{`.+\.example\.com`},
+			},
+			domain: "allowed.com",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			test.config.Timeout = ptypes.Duration(time.Second)
+
+			resolve := &fakeResolve{t: t}
+			resolver, err := newOnDemandResolver(context.Background(), test.config, resolve.resolve)
+			require.NoError(t, err)
+
+			cert, err := resolver.ResolveOnDemand(test.domain)
+			require.NoError(t, err)
+
+			if !test.expectObtained {
+				assert.Nil(t, cert)
+				assert.Zero(t, resolve.count())
+				return
+			}
+
+			assert.NotNil(t, cert)
+			assert.Equal(t, int32(1), resolve.count())
+		})
+	}
+}
+
+func TestOnDemandResolver_ResolveOnDemand_rateLimit(t *testing.T) {
+	config := &OnDemand{
+		AllowedDomains: []string{`.*`},
+		Timeout:        ptypes.Duration(time.Second),
+		RateLimit: &OnDemandRateLimit{
+			Average: 1,
+			Period:  ptypes.Duration(time.Hour),
+			Burst:   1,
+		},
+	}
+
+	resolve := &fakeResolve{t: t}
+	resolver, err := newOnDemandResolver(context.Background(), config, resolve.resolve)
+	require.NoError(t, err)
+
+	cert, err := resolver.ResolveOnDemand("foo.com")
+	require.NoError(t, err)
+	assert.NotNil(t, cert)
+
+	// The obtained certificate is served without being obtained again.
+	cert, err = resolver.ResolveOnDemand("foo.com")
+	require.NoError(t, err)
+	assert.NotNil(t, cert)
+
+	_, err = resolver.ResolveOnDemand("bar.com")
+	assert.Error(t, err)
+
+	assert.Equal(t, int32(1), resolve.count())
+}
+
+func TestOnDemandResolver_ResolveOnDemand_askCache(t *testing.T) {
+	var asks int32
+	askServer := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		atomic.AddInt32(&asks, 1)
+		rw.WriteHeader(http.StatusForbidden)
+	}))
+	defer askServer.Close()
+
+	config := &OnDemand{
+		Ask:     askServer.URL + "/ask",
+		Timeout: ptypes.Duration(time.Second),
+	}
+
+	resolve := &fakeResolve{t: t}
+	resolver, err := newOnDemandResolver(context.Background(), config, resolve.resolve)
+	require.NoError(t, err)
+
+	// The denied domain is not asked again right away.
+	for i := 0; i < 2; i++ {
+		cert, err := resolver.ResolveOnDemand("refused.com")
+		require.NoError(t, err)
+		assert.Nil(t, cert)
+	}
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&asks))
+	assert.Zero(t, resolve.count())
+}
+
+func TestOnDemandResolver_ResolveOnDemand_askFailure(t *testing.T) {
+	askServer := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {}))
+	askServer.Close()
+
+	config := &OnDemand{
+		Ask:     askServer.URL + "/ask",
+		Timeout: ptypes.Duration(time.Second),
+	}
+
+	resolver, err := newOnDemandResolver(context.Background(), config, nil)
+	require.NoError(t, err)
+
+	_, err = resolver.ResolveOnDemand("foo.com")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unable to ask")
+
+	// The domain whose ask request failed is not asked again right away.
+	_, err = resolver.ResolveOnDemand("foo.com")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed recently")
+}
+
+func TestOnDemandResolver_ResolveOnDemand_askRateLimit(t *testing.T) {
+	var asks int32
+	askServer := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		atomic.AddInt32(&asks, 1)
+		rw.WriteHeader(http.StatusForbidden)
+	}))
+	defer askServer.Close()
+
+	config := &OnDemand{
+		Ask:     askServer.URL + "/ask",
+		Timeout: ptypes.Duration(time.Second),
+	}
+
+	resolver, err := newOnDemandResolver(context.Background(), config, nil)
+	require.NoError(t, err)
+
+	var errs int
+	for i := 0; i < 2*askBurst; i++ {
+		if _, err := resolver.ResolveOnDemand(fmt.Sprintf("domain%d.com", i)); err != nil {
+			errs++
+		}
+	}
+
+	// The requests over the burst are rejected, without asking.
+	assert.LessOrEqual(t, atomic.LoadInt32(&asks), int32(askBurst+1))
+	assert.GreaterOrEqual(t, errs, askBurst-1)
+}
+
+func TestOnDemandResolver_ResolveOnDemand_failure(t *testing.T) {
+	config := &OnDemand{
+		AllowedDomains: []string{`.*`},
+		Timeout:        ptypes.Duration(time.Second),
+	}
+
+	resolve := &fakeResolve{t: t, err: errors.New("boom")}
+	resolver, err := newOnDemandResolver(context.Background(), config, resolve.resolve)
+	require.NoError(t, err)
+
+	_, err = resolver.ResolveOnDemand("foo.com")
+	assert.Error(t, err)
+
+	// The failed domain is not resolved again right away.
+	_, err = resolver.ResolveOnDemand("foo.com")
+	assert.Error(t, err)
+
+	assert.Equal(t, int32(1), resolve.count())
+}
+
+func TestOnDemandResolver_ResolveOnDemand_timeout(t *testing.T) {
+	config := &OnDemand{
+		AllowedDomains: []string{`.*`},
+		Timeout:        ptypes.Duration(50 * time.Millisecond),
+	}
+
+	release := make(chan struct{})
+	resolve := &fakeResolve{t: t, release: release}
+	resolver, err := newOnDemandResolver(context.Background(), config, resolve.resolve)
+	require.NoError(t, err)
+
+	// The concurrent handshakes share the same resolution, and get no certificate before the timeout.
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			cert, err := resolver.ResolveOnDemand("foo.com")
+			assert.NoError(t, err)
+			assert.Nil(t, cert)
+		}()
+	}
+	wg.Wait()
+
+	close(release)
+
+	// The certificate obtained after the timeout is served by the next handshakes.
+	assert.Eventually(t, func() bool {
+		cert, err := resolver.ResolveOnDemand("foo.com")
+		return err == nil && cert != nil
+	}, 5*time.Second, 10*time.Millisecond)
+
+	assert.Equal(t, int32(1), resolve.count())
+}
+
+type fakeResolve struct {
+	t       *testing.T
+	err     error
+	release chan struct{}
+	calls   int32
+}
+
+func (f *fakeResolve) resolve(_ context.Context, domain types.Domain) (*certificate.Resource, error) {
+	atomic.AddInt32(&f.calls, 1)
+
+	if f.release != nil {
+		<-f.release
+	}
+
+	if f.err != nil {
+		return nil, f.err
+	}
+
+	cert, key, err := generate.KeyPair(domain.Main, time.Now().Add(90*24*time.Hour))
+	require.NoError(f.t, err)
+
+	return &certificate.Resource{Domain: domain.Main, Certificate: cert, PrivateKey: key}, nil
+}
+
+func (f *fakeResolve) count() int32 {
+	return atomic.LoadInt32(&f.calls)
+}
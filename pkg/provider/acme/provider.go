@@ -12,6 +12,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/cenkalti/backoff/v4"
 	"github.com/go-acme/lego/v4/certificate"
 	"github.com/go-acme/lego/v4/challenge"
 	"github.com/go-acme/lego/v4/challenge/dns01"
@@ -20,6 +21,7 @@ import (
 	"github.com/go-acme/lego/v4/registration"
 	ptypes "github.com/traefik/paerser/types"
 	"github.com/traefik/traefik/v2/pkg/config/dynamic"
+	"github.com/traefik/traefik/v2/pkg/job"
 	"github.com/traefik/traefik/v2/pkg/log"
 	"github.com/traefik/traefik/v2/pkg/rules"
 	"github.com/traefik/traefik/v2/pkg/safe"
@@ -28,21 +30,22 @@ import (
 	"github.com/traefik/traefik/v2/pkg/version"
 )
 
-// oscpMustStaple enables OSCP stapling as from https://github.com/go-acme/lego/issues/270.
-var oscpMustStaple = false
-
 // Configuration holds ACME configuration provided by users.
 type Configuration struct {
-	Email          string `description:"Email address used for registration." json:"email,omitempty" toml:"email,omitempty" yaml:"email,omitempty"`
-	CAServer       string `description:"CA server to use." json:"caServer,omitempty" toml:"caServer,omitempty" yaml:"caServer,omitempty"`
-	PreferredChain string `description:"Preferred chain to use." json:"preferredChain,omitempty" toml:"preferredChain,omitempty" yaml:"preferredChain,omitempty" export:"true"`
-	Storage        string `description:"Storage to use." json:"storage,omitempty" toml:"storage,omitempty" yaml:"storage,omitempty" export:"true"`
-	KeyType        string `description:"KeyType used for generating certificate private key. Allow value 'EC256', 'EC384', 'RSA2048', 'RSA4096', 'RSA8192'." json:"keyType,omitempty" toml:"keyType,omitempty" yaml:"keyType,omitempty" export:"true"`
-	EAB            *EAB   `description:"External Account Binding to use." json:"eab,omitempty" toml:"eab,omitempty" yaml:"eab,omitempty"`
+	Email          string     `description:"Email address used for registration." json:"email,omitempty" toml:"email,omitempty" yaml:"email,omitempty"`
+	CAServer       string     `description:"CA server to use." json:"caServer,omitempty" toml:"caServer,omitempty" yaml:"caServer,omitempty"`
+	PreferredChain string     `description:"Preferred chain to use." json:"preferredChain,omitempty" toml:"preferredChain,omitempty" yaml:"preferredChain,omitempty" export:"true"`
+	Storage        string     `description:"Storage to use." json:"storage,omitempty" toml:"storage,omitempty" yaml:"storage,omitempty" export:"true"`
+	KVStorage      *KVStorage `description:"Stores the account and the certificates in a KV store shared by the Traefik instances, instead of the storage file." json:"kvStorage,omitempty" toml:"kvStorage,omitempty" yaml:"kvStorage,omitempty" export:"true"`
+	KeyType        string     `description:"KeyType used for generating certificate private key. Allow value 'EC256', 'EC384', 'RSA2048', 'RSA4096', 'RSA8192'." json:"keyType,omitempty" toml:"keyType,omitempty" yaml:"keyType,omitempty" export:"true"`
+	MustStaple     bool       `description:"Requests the certificates with the OCSP Must-Staple extension." json:"mustStaple,omitempty" toml:"mustStaple,omitempty" yaml:"mustStaple,omitempty" export:"true"`
+	EAB            *EAB       `description:"External Account Binding to use." json:"eab,omitempty" toml:"eab,omitempty" yaml:"eab,omitempty"`
 
 	DNSChallenge  *DNSChallenge  `description:"Activate DNS-01 Challenge." json:"dnsChallenge,omitempty" toml:"dnsChallenge,omitempty" yaml:"dnsChallenge,omitempty" label:"allowEmpty" file:"allowEmpty" export:"true"`
 	HTTPChallenge *HTTPChallenge `description:"Activate HTTP-01 Challenge." json:"httpChallenge,omitempty" toml:"httpChallenge,omitempty" yaml:"httpChallenge,omitempty" label:"allowEmpty" file:"allowEmpty" export:"true"`
 	TLSChallenge  *TLSChallenge  `description:"Activate TLS-ALPN-01 Challenge." json:"tlsChallenge,omitempty" toml:"tlsChallenge,omitempty" yaml:"tlsChallenge,omitempty" label:"allowEmpty" file:"allowEmpty" export:"true"`
+
+	OnDemand *OnDemand `description:"Obtains at handshake time the certificates of the allowed domains which are not known beforehand." json:"onDemand,omitempty" toml:"onDemand,omitempty" yaml:"onDemand,omitempty" export:"true"`
 }
 
 // SetDefaults sets the default values.
@@ -100,6 +103,7 @@ type Provider struct {
 	account                *Account
 	client                 *lego.Client
 	certsChan              chan *CertAndStore
+	sharedCertsChan        chan []*CertAndStore
 	configurationChan      chan<- dynamic.Message
 	tlsManager             *traefiktls.Manager
 	clientMutex            sync.Mutex
@@ -107,6 +111,7 @@ type Provider struct {
 	pool                   *safe.Pool
 	resolvingDomains       map[string]struct{}
 	resolvingDomainsMutex  sync.RWMutex
+	onDemand               *onDemandResolver
 }
 
 // SetTLSManager sets the tls manager to use.
@@ -129,7 +134,7 @@ func (p *Provider) Init() error {
 	ctx := log.With(context.Background(), log.Str(log.ProviderName, p.ResolverName+".acme"))
 	logger := log.FromContext(ctx)
 
-	if len(p.Configuration.Storage) == 0 {
+	if len(p.Configuration.Storage) == 0 && p.Configuration.KVStorage == nil {
 		return errors.New("unable to initialize ACME provider with no storage location for the certificates")
 	}
 
@@ -153,6 +158,17 @@ func (p *Provider) Init() error {
 	// Init the currently resolved domain map
 	p.resolvingDomains = make(map[string]struct{})
 
+	if p.OnDemand != nil {
+		if p.HTTPChallenge == nil && p.TLSChallenge == nil {
+			return errors.New("unable to initialize ACME on-demand certificates without an HTTP or TLS challenge")
+		}
+
+		p.onDemand, err = newOnDemandResolver(ctx, p.OnDemand, p.resolveOnDemandCertificate)
+		if err != nil {
+			return fmt.Errorf("unable to initialize ACME on-demand certificates: %w", err)
+		}
+	}
+
 	return nil
 }
 
@@ -187,6 +203,14 @@ func (p *Provider) Provide(configurationChan chan<- dynamic.Message, pool *safe.
 	p.configurationChan = configurationChan
 	p.refreshCertificates()
 
+	if shared, ok := p.Store.(SharedStore); ok {
+		p.watchSharedCertificates(ctx, shared)
+	}
+
+	if p.onDemand != nil {
+		p.tlsManager.AddOnDemandResolver(p.onDemand)
+	}
+
 	p.renewCertificates(ctx)
 
 	ticker := time.NewTicker(24 * time.Hour)
@@ -216,6 +240,18 @@ func (p *Provider) getClient() (*lego.Client, error) {
 		return p.client, nil
 	}
 
+	if _, ok := p.Store.(SharedStore); ok {
+		// The account may have been registered by another instance.
+		account, err := p.Store.GetAccount(p.ResolverName)
+		if err != nil {
+			return nil, fmt.Errorf("unable to get ACME account: %w", err)
+		}
+
+		if account != nil && account.Registration != nil && isAccountMatchingCaServer(ctx, account.Registration.URI, p.CAServer) {
+			p.account = account
+		}
+	}
+
 	account, err := p.initAccount(ctx)
 	if err != nil {
 		return nil, err
@@ -466,7 +502,25 @@ func (p *Provider) resolveCertificate(ctx context.Context, domain types.Domain,
 
 	defer p.removeResolvingDomains(uncheckedDomains)
 
+	unlock, err := p.lockStore(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
 	logger := log.FromContext(ctx)
+
+	// Another instance may have obtained the certificate while this one was waiting for the lock.
+	if sharedCert := p.getSharedCertificate(ctx, uncheckedDomains); sharedCert != nil {
+		logger.Debugf("Using the ACME certificate obtained by another instance for domains %+v", uncheckedDomains)
+		p.certsChan <- sharedCert
+		return &certificate.Resource{
+			Domain:      sharedCert.Domain.Main,
+			Certificate: sharedCert.Certificate.Certificate,
+			PrivateKey:  sharedCert.Key,
+		}, nil
+	}
+
 	logger.Debugf("Loading ACME certificates %+v...", uncheckedDomains)
 
 	client, err := p.getClient()
@@ -477,7 +531,7 @@ func (p *Provider) resolveCertificate(ctx context.Context, domain types.Domain,
 	request := certificate.ObtainRequest{
 		Domains:    domains,
 		Bundle:     true,
-		MustStaple: oscpMustStaple,
+		MustStaple: p.MustStaple,
 	}
 
 	cert, err := client.Certificate.Obtain(request)
@@ -503,6 +557,15 @@ func (p *Provider) resolveCertificate(ctx context.Context, domain types.Domain,
 	return cert, nil
 }
 
+// resolveOnDemandCertificate obtains the certificate of a domain requested at handshake time.
+func (p *Provider) resolveOnDemandCertificate(ctx context.Context, domain types.Domain) (*certificate.Resource, error) {
+	if p.certsChan == nil {
+		return nil, errors.New("the ACME provider is not started")
+	}
+
+	return p.resolveCertificate(ctx, domain, "default")
+}
+
 func (p *Provider) removeResolvingDomains(resolvingDomains []string) {
 	p.resolvingDomainsMutex.Lock()
 	defer p.resolvingDomainsMutex.Unlock()
@@ -513,7 +576,98 @@ func (p *Provider) removeResolvingDomains(resolvingDomains []string) {
 }
 
 func (p *Provider) addCertificateForDomain(domain types.Domain, certificate, key []byte, tlsStore string) {
-	p.certsChan <- &CertAndStore{Certificate: Certificate{Certificate: certificate, Key: key, Domain: domain}, Store: tlsStore}
+	cert := &CertAndStore{Certificate: Certificate{Certificate: certificate, Key: key, Domain: domain}, Store: tlsStore}
+
+	// The certificate is saved in the shared store before its lock is released,
+	// so that the other instances do not request it again.
+	if _, ok := p.Store.(SharedStore); ok {
+		if err := p.Store.SaveCertificates(p.ResolverName, []*CertAndStore{cert}); err != nil {
+			log.WithoutContext().WithField(log.ProviderName, p.ResolverName+".acme").
+				Errorf("Unable to save the ACME certificate for domains %q in the shared store: %v", strings.Join(domain.ToStrArray(), ","), err)
+		}
+	}
+
+	p.certsChan <- cert
+}
+
+// lockStore acquires the lock of the resolver when the store is shared by several instances.
+func (p *Provider) lockStore(ctx context.Context) (func(), error) {
+	shared, ok := p.Store.(SharedStore)
+	if !ok {
+		return func() {}, nil
+	}
+
+	return shared.Lock(ctx, p.ResolverName)
+}
+
+// getSharedCertificate returns the valid certificate of the shared store which checks all the domains, if any.
+func (p *Provider) getSharedCertificate(ctx context.Context, domains []string) *CertAndStore {
+	if _, ok := p.Store.(SharedStore); !ok {
+		return nil
+	}
+
+	certificates, err := p.Store.GetCertificates(p.ResolverName)
+	if err != nil {
+		log.FromContext(ctx).Errorf("Unable to get the ACME certificates from the shared store: %v", err)
+		return nil
+	}
+
+	for _, cert := range certificates {
+		checked := true
+		for _, domain := range domains {
+			if !isDomainAlreadyChecked(domain, []string{strings.Join(cert.Domain.ToStrArray(), ",")}) {
+				checked = false
+				break
+			}
+		}
+
+		if checked && !needsRenewal(ctx, cert) {
+			return cert
+		}
+	}
+
+	return nil
+}
+
+// watchSharedCertificates updates the certificates each time they are saved in the shared store by an instance.
+func (p *Provider) watchSharedCertificates(ctx context.Context, shared SharedStore) {
+	p.pool.GoCtx(func(ctxPool context.Context) {
+		logger := log.FromContext(ctx)
+
+		operation := func() error {
+			certificatesChan, err := shared.WatchCertificates(ctxPool, p.ResolverName)
+			if err != nil {
+				return fmt.Errorf("unable to watch the shared ACME certificates: %w", err)
+			}
+
+			for {
+				select {
+				case <-ctxPool.Done():
+					return nil
+				case certificates, ok := <-certificatesChan:
+					if !ok {
+						return errors.New("the shared ACME certificates watch channel is closed")
+					}
+
+					select {
+					case p.sharedCertsChan <- certificates:
+					case <-ctxPool.Done():
+						return nil
+					}
+				}
+			}
+		}
+
+		notify := func(err error, time time.Duration) {
+			logger.Errorf("Shared ACME store error: %v, retrying in %s", err, time)
+		}
+
+		err := backoff.RetryNotify(safe.OperationWithRecover(operation),
+			backoff.WithContext(job.NewBackOff(backoff.NewExponentialBackOff()), ctxPool), notify)
+		if err != nil {
+			logger.Errorf("Cannot watch the shared ACME certificates: %v", err)
+		}
+	})
 }
 
 // deleteUnnecessaryDomains deletes from the configuration :
@@ -575,6 +729,7 @@ func deleteUnnecessaryDomains(ctx context.Context, domains []types.Domain) []typ
 
 func (p *Provider) watchCertificate(ctx context.Context) {
 	p.certsChan = make(chan *CertAndStore)
+	p.sharedCertsChan = make(chan []*CertAndStore)
 
 	p.pool.GoCtx(func(ctxPool context.Context) {
 		for {
@@ -596,6 +751,9 @@ func (p *Provider) watchCertificate(ctx context.Context) {
 				if err != nil {
 					log.FromContext(ctx).Error(err)
 				}
+			case certificates := <-p.sharedCertsChan:
+				p.certificates = mergeCertificates(p.certificates, certificates)
+				p.refreshCertificates()
 			case <-ctxPool.Done():
 				return
 			}
@@ -604,7 +762,13 @@ func (p *Provider) watchCertificate(ctx context.Context) {
 }
 
 func (p *Provider) saveCertificates() error {
-	err := p.Store.SaveCertificates(p.ResolverName, p.certificates)
+	var err error
+
+	// The certificates of a shared store are saved one by one when they are obtained,
+	// so that the certificates updated by the other instances are not overwritten.
+	if _, ok := p.Store.(SharedStore); !ok {
+		err = p.Store.SaveCertificates(p.ResolverName, p.certificates)
+	}
 
 	p.refreshCertificates()
 
@@ -642,39 +806,67 @@ func (p *Provider) renewCertificates(ctx context.Context) {
 	logger := log.FromContext(ctx)
 
 	logger.Info("Testing certificate renew...")
+
+	var certificates []*CertAndStore
 	for _, cert := range p.certificates {
-		crt, err := getX509Certificate(ctx, &cert.Certificate)
-		// If there's an error, we assume the cert is broken, and needs update
-		// <= 30 days left, renew certificate
-		if err != nil || crt == nil || crt.NotAfter.Before(time.Now().Add(24*30*time.Hour)) {
-			client, err := p.getClient()
-			if err != nil {
-				logger.Infof("Error renewing certificate from LE : %+v, %v", cert.Domain, err)
-				continue
-			}
+		if needsRenewal(ctx, cert) {
+			certificates = append(certificates, cert)
+		}
+	}
 
-			logger.Infof("Renewing certificate from LE : %+v", cert.Domain)
+	if len(certificates) == 0 {
+		return
+	}
 
-			renewedCert, err := client.Certificate.Renew(certificate.Resource{
-				Domain:      cert.Domain.Main,
-				PrivateKey:  cert.Key,
-				Certificate: cert.Certificate.Certificate,
-			}, true, oscpMustStaple, p.PreferredChain)
-			if err != nil {
-				logger.Errorf("Error renewing certificate from LE: %v, %v", cert.Domain, err)
-				continue
-			}
+	unlock, err := p.lockStore(ctx)
+	if err != nil {
+		logger.Errorf("Unable to renew the certificates: %v", err)
+		return
+	}
+	defer unlock()
 
-			if len(renewedCert.Certificate) == 0 || len(renewedCert.PrivateKey) == 0 {
-				logger.Errorf("domains %v renew certificate with no value: %v", cert.Domain.ToStrArray(), cert)
-				continue
-			}
+	for _, cert := range certificates {
+		// Another instance may have renewed the certificate.
+		if sharedCert := p.getSharedCertificate(ctx, cert.Domain.ToStrArray()); sharedCert != nil {
+			logger.Infof("Using the certificate renewed by another instance: %+v", cert.Domain)
+			p.certsChan <- sharedCert
+			continue
+		}
+
+		client, err := p.getClient()
+		if err != nil {
+			logger.Infof("Error renewing certificate from LE : %+v, %v", cert.Domain, err)
+			continue
+		}
+
+		logger.Infof("Renewing certificate from LE : %+v", cert.Domain)
 
-			p.addCertificateForDomain(cert.Domain, renewedCert.Certificate, renewedCert.PrivateKey, cert.Store)
+		renewedCert, err := client.Certificate.Renew(certificate.Resource{
+			Domain:      cert.Domain.Main,
+			PrivateKey:  cert.Key,
+			Certificate: cert.Certificate.Certificate,
+		}, true, p.MustStaple, p.PreferredChain)
+		if err != nil {
+			logger.Errorf("Error renewing certificate from LE: %v, %v", cert.Domain, err)
+			continue
+		}
+
+		if len(renewedCert.Certificate) == 0 || len(renewedCert.PrivateKey) == 0 {
+			logger.Errorf("domains %v renew certificate with no value: %v", cert.Domain.ToStrArray(), cert)
+			continue
 		}
+
+		p.addCertificateForDomain(cert.Domain, renewedCert.Certificate, renewedCert.PrivateKey, cert.Store)
 	}
 }
 
+// needsRenewal reports whether the certificate expires in 30 days or less.
+// If there's an error, we assume the cert is broken, and needs update.
+func needsRenewal(ctx context.Context, cert *CertAndStore) bool {
+	crt, err := getX509Certificate(ctx, &cert.Certificate)
+	return err != nil || crt == nil || crt.NotAfter.Before(time.Now().Add(24*30*time.Hour))
+}
+
 // Get provided certificate which check a domains list (Main and SANs)
 // from static and dynamic provided certificates.
 func (p *Provider) getUncheckedDomains(ctx context.Context, domainsToCheck []string, tlsStore string) []string {
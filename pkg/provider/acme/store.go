@@ -1,5 +1,7 @@
 package acme
 
+import "context"
+
 // StoredData represents the data managed by Store.
 type StoredData struct {
 	Account      *Account
@@ -13,3 +15,16 @@ type Store interface {
 	GetCertificates(string) ([]*CertAndStore, error)
 	SaveCertificates(string, []*CertAndStore) error
 }
+
+// SharedStore is a Store shared by several Traefik instances.
+type SharedStore interface {
+	Store
+
+	// Lock acquires the lock of the resolver, so that only one instance at a time talks to the CA.
+	// The returned function releases the lock.
+	Lock(ctx context.Context, resolverName string) (func(), error)
+
+	// WatchCertificates sends the certificates of the resolver each time they are saved by an instance,
+	// until the context is done.
+	WatchCertificates(ctx context.Context, resolverName string) (<-chan []*CertAndStore, error)
+}
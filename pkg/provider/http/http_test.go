@@ -143,7 +143,8 @@ func TestProvider_decodeConfiguration(t *testing.T) {
 					Routers: map[string]*dynamic.TCPRouter{
 						"foo": {},
 					},
-					Services: make(map[string]*dynamic.TCPService),
+					Services:    make(map[string]*dynamic.TCPService),
+					Middlewares: make(map[string]*dynamic.TCPMiddleware),
 				},
 				TLS: &dynamic.TLSConfiguration{
 					Stores:  make(map[string]tls.Store),
@@ -199,8 +200,9 @@ func TestProvider_Provide(t *testing.T) {
 			ServersTransports: make(map[string]*dynamic.ServersTransport),
 		},
 		TCP: &dynamic.TCPConfiguration{
-			Routers:  make(map[string]*dynamic.TCPRouter),
-			Services: make(map[string]*dynamic.TCPService),
+			Routers:     make(map[string]*dynamic.TCPRouter),
+			Services:    make(map[string]*dynamic.TCPService),
+			Middlewares: make(map[string]*dynamic.TCPMiddleware),
 		},
 		TLS: &dynamic.TLSConfiguration{
 			Stores:  make(map[string]tls.Store),
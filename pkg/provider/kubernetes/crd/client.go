@@ -61,6 +61,7 @@ type Client interface {
 	GetService(namespace, name string) (*corev1.Service, bool, error)
 	GetSecret(namespace, name string) (*corev1.Secret, bool, error)
 	GetEndpoints(namespace, name string) (*corev1.Endpoints, bool, error)
+	GetPod(namespace, name string) (*corev1.Pod, bool, error)
 }
 
 // TODO: add tests for the clientWrapper (and its methods) itself.
@@ -71,6 +72,7 @@ type clientWrapper struct {
 	factoriesCrd    map[string]externalversions.SharedInformerFactory
 	factoriesKube   map[string]informers.SharedInformerFactory
 	factoriesSecret map[string]informers.SharedInformerFactory
+	factoriesPod    map[string]informers.SharedInformerFactory
 
 	labelSelector string
 
@@ -107,6 +109,7 @@ func newClientImpl(csKube kubernetes.Interface, csCrd versioned.Interface) *clie
 		factoriesCrd:    make(map[string]externalversions.SharedInformerFactory),
 		factoriesKube:   make(map[string]informers.SharedInformerFactory),
 		factoriesSecret: make(map[string]informers.SharedInformerFactory),
+		factoriesPod:    make(map[string]informers.SharedInformerFactory),
 	}
 }
 
@@ -174,6 +177,11 @@ func (c *clientWrapper) WatchAll(namespaces []string, stopCh <-chan struct{}) (<
 		opts.LabelSelector = "owner!=helm"
 	}
 
+	// Only the pods defining a server weight are watched, to keep the cache small.
+	hasServerWeight := func(opts *metav1.ListOptions) {
+		opts.LabelSelector = labelServerWeight
+	}
+
 	matchesLabelSelector := func(opts *metav1.ListOptions) {
 		opts.LabelSelector = c.labelSelector
 	}
@@ -196,15 +204,20 @@ func (c *clientWrapper) WatchAll(namespaces []string, stopCh <-chan struct{}) (<
 		factorySecret := informers.NewSharedInformerFactoryWithOptions(c.csKube, resyncPeriod, informers.WithNamespace(ns), informers.WithTweakListOptions(notOwnedByHelm))
 		factorySecret.Core().V1().Secrets().Informer().AddEventHandler(eventHandler)
 
+		factoryPod := informers.NewSharedInformerFactoryWithOptions(c.csKube, resyncPeriod, informers.WithNamespace(ns), informers.WithTweakListOptions(hasServerWeight))
+		factoryPod.Core().V1().Pods().Informer().AddEventHandler(eventHandler)
+
 		c.factoriesCrd[ns] = factoryCrd
 		c.factoriesKube[ns] = factoryKube
 		c.factoriesSecret[ns] = factorySecret
+		c.factoriesPod[ns] = factoryPod
 	}
 
 	for _, ns := range namespaces {
 		c.factoriesCrd[ns].Start(stopCh)
 		c.factoriesKube[ns].Start(stopCh)
 		c.factoriesSecret[ns].Start(stopCh)
+		c.factoriesPod[ns].Start(stopCh)
 	}
 
 	for _, ns := range namespaces {
@@ -225,6 +238,12 @@ func (c *clientWrapper) WatchAll(namespaces []string, stopCh <-chan struct{}) (<
 				return nil, fmt.Errorf("timed out waiting for controller caches to sync %s in namespace %q", t.String(), ns)
 			}
 		}
+
+		for t, ok := range c.factoriesPod[ns].WaitForCacheSync(stopCh) {
+			if !ok {
+				return nil, fmt.Errorf("timed out waiting for controller caches to sync %s in namespace %q", t.String(), ns)
+			}
+		}
 	}
 
 	return eventCh, nil
@@ -390,6 +409,18 @@ func (c *clientWrapper) GetSecret(namespace, name string) (*corev1.Secret, bool,
 	return secret, exist, err
 }
 
+// GetPod returns the named pod from the given namespace.
+// Only the pods with the server weight label are known.
+func (c *clientWrapper) GetPod(namespace, name string) (*corev1.Pod, bool, error) {
+	if !c.isWatchedNamespace(namespace) {
+		return nil, false, fmt.Errorf("failed to get pod %s/%s: namespace is not within watched namespaces", namespace, name)
+	}
+
+	pod, err := c.factoriesPod[c.lookupNamespace(namespace)].Core().V1().Pods().Lister().Pods(namespace).Get(name)
+	exist, err := translateNotFoundError(err)
+	return pod, exist, err
+}
+
 // lookupNamespace returns the lookup namespace key for the given namespace.
 // When listening on all namespaces, it returns the client-go identifier ("")
 // for all-namespaces. Otherwise, it returns the given namespace.
@@ -32,6 +32,9 @@ import (
 const (
 	annotationKubernetesIngressClass = "kubernetes.io/ingress.class"
 	traefikDefaultIngressClass       = "traefik"
+
+	// labelServerWeight is the pod label defining the weight of the servers of the pod.
+	labelServerWeight = "traefik.ingress.kubernetes.io/server-weight"
 )
 
 const (
@@ -227,7 +230,7 @@ func (p *Provider) loadConfigurationFromCRD(ctx context.Context, client Client)
 			continue
 		}
 
-		rateLimit, err := createRateLimitMiddleware(middleware.Spec.RateLimit)
+		rateLimit, err := createRateLimitMiddleware(client, middleware.Namespace, middleware.Spec.RateLimit)
 		if err != nil {
 			log.FromContext(ctxMid).Errorf("Error while reading rateLimit middleware: %v", err)
 			continue
@@ -239,6 +242,24 @@ func (p *Provider) loadConfigurationFromCRD(ctx context.Context, client Client)
 			continue
 		}
 
+		oidc, err := createOIDCMiddleware(client, middleware.Namespace, middleware.Spec.OIDC)
+		if err != nil {
+			log.FromContext(ctxMid).Errorf("Error while reading oidc middleware: %v", err)
+			continue
+		}
+
+		jwt, err := createJWTMiddleware(client, middleware.Namespace, middleware.Spec.JWT)
+		if err != nil {
+			log.FromContext(ctxMid).Errorf("Error while reading jwt middleware: %v", err)
+			continue
+		}
+
+		cache, err := createCacheMiddleware(middleware.Spec.Cache)
+		if err != nil {
+			log.FromContext(ctxMid).Errorf("Error while reading cache middleware: %v", err)
+			continue
+		}
+
 		conf.HTTP.Middlewares[id] = &dynamic.Middleware{
 			AddPrefix:         middleware.Spec.AddPrefix,
 			StripPrefix:       middleware.Spec.StripPrefix,
@@ -262,6 +283,11 @@ func (p *Provider) loadConfigurationFromCRD(ctx context.Context, client Client)
 			PassTLSClientCert: middleware.Spec.PassTLSClientCert,
 			Retry:             retry,
 			ContentType:       middleware.Spec.ContentType,
+			OIDC:              oidc,
+			JWT:               jwt,
+			Cache:             cache,
+			Limits:            middleware.Spec.Limits,
+			ClientCertAuth:    middleware.Spec.ClientCertAuth,
 			Plugin:            plugin,
 		}
 	}
@@ -395,7 +421,7 @@ func createPluginMiddleware(plugins map[string]apiextensionv1.JSON) (map[string]
 	return pc, nil
 }
 
-func createRateLimitMiddleware(rateLimit *v1alpha1.RateLimit) (*dynamic.RateLimit, error) {
+func createRateLimitMiddleware(k8sClient Client, namespace string, rateLimit *v1alpha1.RateLimit) (*dynamic.RateLimit, error) {
 	if rateLimit == nil {
 		return nil, nil
 	}
@@ -414,32 +440,202 @@ func createRateLimitMiddleware(rateLimit *v1alpha1.RateLimit) (*dynamic.RateLimi
 		}
 	}
 
+	if rateLimit.Storage != nil {
+		storage, err := createRateLimitStorage(k8sClient, namespace, rateLimit.Storage)
+		if err != nil {
+			return nil, err
+		}
+		rl.Storage = storage
+	}
+
 	return rl, nil
 }
 
+func createRateLimitStorage(k8sClient Client, namespace string, storage *v1alpha1.RateLimitStorage) (*dynamic.RateLimitStorage, error) {
+	s := &dynamic.RateLimitStorage{}
+	s.SetDefaults()
+
+	if storage.Fallback != "" {
+		s.Fallback = storage.Fallback
+	}
+
+	if storage.Redis == nil {
+		return s, nil
+	}
+
+	r := &dynamic.RedisStorage{DB: storage.Redis.DB}
+	r.SetDefaults()
+
+	if storage.Redis.Address != "" {
+		r.Address = storage.Redis.Address
+	}
+
+	if storage.Redis.Timeout != nil {
+		err := r.Timeout.Set(storage.Redis.Timeout.String())
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if storage.Redis.Secret != "" {
+		password, err := loadSecretValue(k8sClient, namespace, storage.Redis.Secret, "password")
+		if err != nil {
+			return nil, err
+		}
+		r.Password = password
+	}
+
+	s.Redis = r
+
+	return s, nil
+}
+
 func createRetryMiddleware(retry *v1alpha1.Retry) (*dynamic.Retry, error) {
 	if retry == nil {
 		return nil, nil
 	}
 
-	r := &dynamic.Retry{Attempts: retry.Attempts}
+	r := &dynamic.Retry{
+		Attempts:           retry.Attempts,
+		Status:             retry.Status,
+		RetryNonIdempotent: retry.RetryNonIdempotent,
+		BudgetPercent:      retry.BudgetPercent,
+	}
 
 	err := r.InitialInterval.Set(retry.InitialInterval.String())
 	if err != nil {
 		return nil, err
 	}
 
+	err = r.MaxInterval.Set(retry.MaxInterval.String())
+	if err != nil {
+		return nil, err
+	}
+
+	err = r.PerTryTimeout.Set(retry.PerTryTimeout.String())
+	if err != nil {
+		return nil, err
+	}
+
 	return r, nil
 }
 
+func createOIDCMiddleware(k8sClient Client, namespace string, oidc *v1alpha1.OIDC) (*dynamic.OIDC, error) {
+	if oidc == nil {
+		return nil, nil
+	}
+
+	if oidc.Secret == "" {
+		return nil, errors.New("oidc secret must be set")
+	}
+
+	o := &dynamic.OIDC{
+		Issuer:                oidc.Issuer,
+		ClientID:              oidc.ClientID,
+		Scopes:                oidc.Scopes,
+		CallbackPath:          oidc.CallbackPath,
+		LogoutPath:            oidc.LogoutPath,
+		PostLogoutRedirectURL: oidc.PostLogoutRedirectURL,
+		SessionCookieName:     oidc.SessionCookieName,
+		ClaimsHeaders:         oidc.ClaimsHeaders,
+		ForwardAccessToken:    oidc.ForwardAccessToken,
+	}
+
+	if oidc.SessionMaxAge != nil {
+		if err := o.SessionMaxAge.Set(oidc.SessionMaxAge.String()); err != nil {
+			return nil, err
+		}
+	}
+
+	clientSecret, err := loadSecretValue(k8sClient, namespace, oidc.Secret, "clientSecret")
+	if err != nil {
+		return nil, fmt.Errorf("failed to load oidc secret: %w", err)
+	}
+	o.ClientSecret = clientSecret
+
+	sessionSecret, err := loadSecretValue(k8sClient, namespace, oidc.Secret, "sessionSecret")
+	if err != nil {
+		return nil, fmt.Errorf("failed to load oidc secret: %w", err)
+	}
+	o.SessionSecret = sessionSecret
+
+	return o, nil
+}
+
+func createJWTMiddleware(k8sClient Client, namespace string, jwt *v1alpha1.JWT) (*dynamic.JWT, error) {
+	if jwt == nil {
+		return nil, nil
+	}
+
+	j := &dynamic.JWT{
+		Keys:          jwt.Keys,
+		JWKSURL:       jwt.JWKSURL,
+		Issuer:        jwt.Issuer,
+		Audience:      jwt.Audience,
+		ClaimsHeaders: jwt.ClaimsHeaders,
+		RemoveHeader:  jwt.RemoveHeader,
+	}
+	j.SetDefaults()
+
+	if jwt.JWKSRefreshInterval != nil {
+		if err := j.JWKSRefreshInterval.Set(jwt.JWKSRefreshInterval.String()); err != nil {
+			return nil, err
+		}
+	}
+
+	if jwt.Secret != "" {
+		secret, err := loadSecretValue(k8sClient, namespace, jwt.Secret, "secret")
+		if err != nil {
+			return nil, fmt.Errorf("failed to load jwt secret: %w", err)
+		}
+		j.Secret = secret
+	}
+
+	return j, nil
+}
+
+func createCacheMiddleware(cache *v1alpha1.Cache) (*dynamic.Cache, error) {
+	if cache == nil {
+		return nil, nil
+	}
+
+	c := &dynamic.Cache{DiskPath: cache.DiskPath}
+	c.SetDefaults()
+
+	if cache.MaxEntries != nil {
+		c.MaxEntries = *cache.MaxEntries
+	}
+
+	if cache.MaxResponseBodyBytes != nil {
+		c.MaxResponseBodyBytes = *cache.MaxResponseBodyBytes
+	}
+
+	if cache.DefaultTTL != nil {
+		if err := c.DefaultTTL.Set(cache.DefaultTTL.String()); err != nil {
+			return nil, err
+		}
+	}
+
+	return c, nil
+}
+
 func (p *Provider) createErrorPageMiddleware(client Client, namespace string, errorPage *v1alpha1.ErrorPage) (*dynamic.ErrorPage, *dynamic.Service, error) {
 	if errorPage == nil {
 		return nil, nil, nil
 	}
 
 	errorPageMiddleware := &dynamic.ErrorPage{
-		Status: errorPage.Status,
-		Query:  errorPage.Query,
+		Status:            errorPage.Status,
+		Query:             errorPage.Query,
+		Directory:         errorPage.Directory,
+		HTMLTemplate:      errorPage.HTMLTemplate,
+		JSONTemplate:      errorPage.JSONTemplate,
+		TraefikErrorsOnly: errorPage.TraefikErrorsOnly,
+	}
+
+	// The local error pages do not need a service.
+	if errorPage.Service.Name == "" {
+		return errorPageMiddleware, nil, nil
 	}
 
 	balancerServerHTTP, err := configBuilder{client, p.AllowCrossNamespace}.buildServersLB(namespace, errorPage.Service.LoadBalancerSpec)
@@ -534,6 +730,27 @@ func loadAuthTLSSecret(namespace, secretName string, k8sClient Client) (string,
 	return getCertificateBlocks(secret, namespace, secretName)
 }
 
+// loadSecretValue returns the value of the given key of the secret.
+func loadSecretValue(k8sClient Client, namespace, secretName, key string) (string, error) {
+	secret, ok, err := k8sClient.GetSecret(namespace, secretName)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch secret '%s/%s': %w", namespace, secretName, err)
+	}
+	if !ok {
+		return "", fmt.Errorf("secret '%s/%s' not found", namespace, secretName)
+	}
+	if secret == nil {
+		return "", fmt.Errorf("data for secret '%s/%s' must not be nil", namespace, secretName)
+	}
+
+	value, ok := secret.Data[key]
+	if !ok || len(value) == 0 {
+		return "", fmt.Errorf("the %s key is missing from secret '%s/%s'", key, namespace, secretName)
+	}
+
+	return string(value), nil
+}
+
 func createBasicAuthMiddleware(client Client, namespace string, basicAuth *v1alpha1.BasicAuth) (*dynamic.BasicAuth, error) {
 	if basicAuth == nil {
 		return nil, nil
@@ -288,16 +288,18 @@ func (c configBuilder) buildServersLB(namespace string, svc v1alpha1.LoadBalance
 	lb.Sticky = svc.Sticky
 	lb.ServersTransport = svc.ServersTransport
 
+	if svc.Strategy != roundRobinStrategy {
+		lb.Strategy = svc.Strategy
+	}
+
 	return &dynamic.Service{LoadBalancer: lb}, nil
 }
 
 func (c configBuilder) loadServers(parentNamespace string, svc v1alpha1.LoadBalancerSpec) ([]dynamic.Server, error) {
-	strategy := svc.Strategy
-	if strategy == "" {
-		strategy = roundRobinStrategy
-	}
-	if strategy != roundRobinStrategy {
-		return nil, fmt.Errorf("load balancing strategy %s is not supported", strategy)
+	switch svc.Strategy {
+	case "", roundRobinStrategy, dynamic.BalancerStrategyWRR, dynamic.BalancerStrategyLeastConn, dynamic.BalancerStrategyP2C, dynamic.BalancerStrategyRandom:
+	default:
+		return nil, fmt.Errorf("load balancing strategy %s is not supported", svc.Strategy)
 	}
 
 	namespace := namespaceOrFallback(svc, parentNamespace)
@@ -367,8 +369,14 @@ func (c configBuilder) loadServers(parentNamespace string, svc v1alpha1.LoadBala
 		for _, addr := range subset.Addresses {
 			hostPort := net.JoinHostPort(addr.IP, strconv.Itoa(int(port)))
 
+			weight, err := c.loadServerWeight(namespace, addr)
+			if err != nil {
+				return nil, err
+			}
+
 			servers = append(servers, dynamic.Server{
-				URL: fmt.Sprintf("%s://%s", protocol, hostPort),
+				URL:    fmt.Sprintf("%s://%s", protocol, hostPort),
+				Weight: weight,
 			})
 		}
 	}
@@ -376,6 +384,38 @@ func (c configBuilder) loadServers(parentNamespace string, svc v1alpha1.LoadBala
 	return servers, nil
 }
 
+// loadServerWeight returns the weight defined by the server weight label of the pod behind the endpoint address, if any.
+func (c configBuilder) loadServerWeight(namespace string, addr corev1.EndpointAddress) (*int, error) {
+	if addr.TargetRef == nil || addr.TargetRef.Kind != "Pod" {
+		return nil, nil
+	}
+
+	podNamespace := addr.TargetRef.Namespace
+	if podNamespace == "" {
+		podNamespace = namespace
+	}
+
+	pod, exists, err := c.client.GetPod(podNamespace, addr.TargetRef.Name)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, nil
+	}
+
+	value, ok := pod.Labels[labelServerWeight]
+	if !ok {
+		return nil, nil
+	}
+
+	weight, err := strconv.Atoi(value)
+	if err != nil || weight < 0 {
+		return nil, fmt.Errorf("invalid server weight %q for pod %s/%s", value, podNamespace, addr.TargetRef.Name)
+	}
+
+	return &weight, nil
+}
+
 // nameAndService returns the name that should be used for the svc service in the generated config.
 // In addition, if the service is a Kubernetes one,
 // it generates and returns the configuration part for such a service,
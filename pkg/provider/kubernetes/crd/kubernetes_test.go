@@ -1203,6 +1203,117 @@ func TestLoadIngressRoutes(t *testing.T) {
 				TLS: &dynamic.TLSConfiguration{},
 			},
 		},
+		{
+			desc:  "Simple Ingress Route, with leastConn strategy",
+			paths: []string{"services.yml", "with_strategy.yml"},
+			expected: &dynamic.Configuration{
+				UDP: &dynamic.UDPConfiguration{
+					Routers:  map[string]*dynamic.UDPRouter{},
+					Services: map[string]*dynamic.UDPService{},
+				},
+				TCP: &dynamic.TCPConfiguration{
+					Routers:  map[string]*dynamic.TCPRouter{},
+					Services: map[string]*dynamic.TCPService{},
+				},
+				HTTP: &dynamic.HTTPConfiguration{
+					ServersTransports: map[string]*dynamic.ServersTransport{},
+					Routers: map[string]*dynamic.Router{
+						"default-test-route-6b204d94623b3df4370c": {
+							EntryPoints: []string{"foo"},
+							Service:     "default-test-route-6b204d94623b3df4370c",
+							Rule:        "Host(`foo.com`) && PathPrefix(`/bar`)",
+							Priority:    12,
+						},
+					},
+					Middlewares: map[string]*dynamic.Middleware{},
+					Services: map[string]*dynamic.Service{
+						"default-test-route-6b204d94623b3df4370c": {
+							LoadBalancer: &dynamic.ServersLoadBalancer{
+								Strategy: "leastConn",
+								Servers: []dynamic.Server{
+									{
+										URL: "http://10.10.0.1:80",
+									},
+									{
+										URL: "http://10.10.0.2:80",
+									},
+								},
+								PassHostHeader: Bool(true),
+							},
+						},
+					},
+				},
+				TLS: &dynamic.TLSConfiguration{},
+			},
+		},
+		{
+			desc:  "Simple Ingress Route, with server weights from the pod labels",
+			paths: []string{"with_server_weight.yml"},
+			expected: &dynamic.Configuration{
+				UDP: &dynamic.UDPConfiguration{
+					Routers:  map[string]*dynamic.UDPRouter{},
+					Services: map[string]*dynamic.UDPService{},
+				},
+				TCP: &dynamic.TCPConfiguration{
+					Routers:  map[string]*dynamic.TCPRouter{},
+					Services: map[string]*dynamic.TCPService{},
+				},
+				HTTP: &dynamic.HTTPConfiguration{
+					ServersTransports: map[string]*dynamic.ServersTransport{},
+					Routers: map[string]*dynamic.Router{
+						"default-test-route-6b204d94623b3df4370c": {
+							EntryPoints: []string{"foo"},
+							Service:     "default-test-route-6b204d94623b3df4370c",
+							Rule:        "Host(`foo.com`) && PathPrefix(`/bar`)",
+							Priority:    12,
+						},
+					},
+					Middlewares: map[string]*dynamic.Middleware{},
+					Services: map[string]*dynamic.Service{
+						"default-test-route-6b204d94623b3df4370c": {
+							LoadBalancer: &dynamic.ServersLoadBalancer{
+								Servers: []dynamic.Server{
+									{
+										URL:    "http://10.10.0.11:80",
+										Weight: Int(3),
+									},
+									{
+										URL:    "http://10.10.0.12:80",
+										Weight: Int(1),
+									},
+									{
+										URL: "http://10.10.0.13:80",
+									},
+								},
+								PassHostHeader: Bool(true),
+							},
+						},
+					},
+				},
+				TLS: &dynamic.TLSConfiguration{},
+			},
+		},
+		{
+			desc:  "Simple Ingress Route, with unknown strategy",
+			paths: []string{"services.yml", "with_unknown_strategy.yml"},
+			expected: &dynamic.Configuration{
+				UDP: &dynamic.UDPConfiguration{
+					Routers:  map[string]*dynamic.UDPRouter{},
+					Services: map[string]*dynamic.UDPService{},
+				},
+				TCP: &dynamic.TCPConfiguration{
+					Routers:  map[string]*dynamic.TCPRouter{},
+					Services: map[string]*dynamic.TCPService{},
+				},
+				HTTP: &dynamic.HTTPConfiguration{
+					ServersTransports: map[string]*dynamic.ServersTransport{},
+					Routers:           map[string]*dynamic.Router{},
+					Middlewares:       map[string]*dynamic.Middleware{},
+					Services:          map[string]*dynamic.Service{},
+				},
+				TLS: &dynamic.TLSConfiguration{},
+			},
+		},
 		{
 			desc:  "Simple Ingress Route with middleware",
 			paths: []string{"services.yml", "with_middleware.yml"},
@@ -2892,6 +3003,173 @@ func TestLoadIngressRoutes(t *testing.T) {
 								},
 							},
 						},
+						"default-oidc": {
+							OIDC: &dynamic.OIDC{
+								Issuer:        "https://issuer.example.com",
+								ClientID:      "traefik",
+								ClientSecret:  "client-secret",
+								SessionSecret: "session-secret",
+								Scopes:        []string{"openid"},
+								SessionMaxAge: types.Duration(time.Hour),
+							},
+						},
+						"default-jwt": {
+							JWT: &dynamic.JWT{
+								Secret:              "hs-secret",
+								JWKSRefreshInterval: types.Duration(5 * time.Minute),
+								Issuer:              "https://issuer.example.com",
+								ClaimsHeaders:       map[string]string{"X-User": "sub"},
+							},
+						},
+						"default-clientcertauth": {
+							ClientCertAuth: &dynamic.ClientCertAuth{
+								AllowedCommonNames: []string{"client.example.com"},
+								AllowedURIs:        []string{"spiffe://example.com/client"},
+							},
+						},
+					},
+					Services: map[string]*dynamic.Service{},
+				},
+			},
+		},
+		{
+			desc:  "Simple Ingress Route, with cache middleware",
+			paths: []string{"services.yml", "with_cache.yml"},
+			expected: &dynamic.Configuration{
+				UDP: &dynamic.UDPConfiguration{
+					Routers:  map[string]*dynamic.UDPRouter{},
+					Services: map[string]*dynamic.UDPService{},
+				},
+				TLS: &dynamic.TLSConfiguration{},
+				TCP: &dynamic.TCPConfiguration{
+					Routers:  map[string]*dynamic.TCPRouter{},
+					Services: map[string]*dynamic.TCPService{},
+				},
+				HTTP: &dynamic.HTTPConfiguration{
+					ServersTransports: map[string]*dynamic.ServersTransport{},
+					Routers:           map[string]*dynamic.Router{},
+					Middlewares: map[string]*dynamic.Middleware{
+						"default-cache": {
+							Cache: &dynamic.Cache{
+								MaxEntries:           100,
+								MaxResponseBodyBytes: 1024 * 1024,
+								DefaultTTL:           types.Duration(30 * time.Second),
+							},
+						},
+					},
+					Services: map[string]*dynamic.Service{},
+				},
+			},
+		},
+		{
+			desc:  "Simple Ingress Route, with limits middleware",
+			paths: []string{"services.yml", "with_limits.yml"},
+			expected: &dynamic.Configuration{
+				UDP: &dynamic.UDPConfiguration{
+					Routers:  map[string]*dynamic.UDPRouter{},
+					Services: map[string]*dynamic.UDPService{},
+				},
+				TLS: &dynamic.TLSConfiguration{},
+				TCP: &dynamic.TCPConfiguration{
+					Routers:  map[string]*dynamic.TCPRouter{},
+					Services: map[string]*dynamic.TCPService{},
+				},
+				HTTP: &dynamic.HTTPConfiguration{
+					ServersTransports: map[string]*dynamic.ServersTransport{},
+					Routers:           map[string]*dynamic.Router{},
+					Middlewares: map[string]*dynamic.Middleware{
+						"default-limits": {
+							Limits: &dynamic.Limits{
+								MaxRequestBodyBytes: 1048576,
+								MaxHeaderCount:      50,
+								MaxURILength:        2048,
+							},
+						},
+					},
+					Services: map[string]*dynamic.Service{},
+				},
+			},
+		},
+		{
+			desc:  "Simple Ingress Route, with rate limit middlewares",
+			paths: []string{"services.yml", "with_ratelimit.yml"},
+			expected: &dynamic.Configuration{
+				UDP: &dynamic.UDPConfiguration{
+					Routers:  map[string]*dynamic.UDPRouter{},
+					Services: map[string]*dynamic.UDPService{},
+				},
+				TLS: &dynamic.TLSConfiguration{},
+				TCP: &dynamic.TCPConfiguration{
+					Routers:  map[string]*dynamic.TCPRouter{},
+					Services: map[string]*dynamic.TCPService{},
+				},
+				HTTP: &dynamic.HTTPConfiguration{
+					ServersTransports: map[string]*dynamic.ServersTransport{},
+					Routers:           map[string]*dynamic.Router{},
+					Middlewares: map[string]*dynamic.Middleware{
+						"default-ratelimit": {
+							RateLimit: &dynamic.RateLimit{
+								Average: 100,
+								Period:  types.Duration(time.Second),
+								Burst:   50,
+								Storage: &dynamic.RateLimitStorage{
+									Redis: &dynamic.RedisStorage{
+										Address:  "redis.default.svc:6379",
+										Password: "redis-password",
+										DB:       2,
+										Timeout:  types.Duration(200 * time.Millisecond),
+									},
+									Fallback: "deny",
+								},
+							},
+						},
+						"default-ratelimit-default-storage": {
+							RateLimit: &dynamic.RateLimit{
+								Average: 100,
+								Period:  types.Duration(time.Second),
+								Burst:   1,
+								Storage: &dynamic.RateLimitStorage{
+									Redis: &dynamic.RedisStorage{
+										Address: "127.0.0.1:6379",
+										Timeout: types.Duration(100 * time.Millisecond),
+									},
+									Fallback: "local",
+								},
+							},
+						},
+					},
+					Services: map[string]*dynamic.Service{},
+				},
+			},
+		},
+		{
+			desc:  "Simple Ingress Route, with retry middleware",
+			paths: []string{"services.yml", "with_retry.yml"},
+			expected: &dynamic.Configuration{
+				UDP: &dynamic.UDPConfiguration{
+					Routers:  map[string]*dynamic.UDPRouter{},
+					Services: map[string]*dynamic.UDPService{},
+				},
+				TLS: &dynamic.TLSConfiguration{},
+				TCP: &dynamic.TCPConfiguration{
+					Routers:  map[string]*dynamic.TCPRouter{},
+					Services: map[string]*dynamic.TCPService{},
+				},
+				HTTP: &dynamic.HTTPConfiguration{
+					ServersTransports: map[string]*dynamic.ServersTransport{},
+					Routers:           map[string]*dynamic.Router{},
+					Middlewares: map[string]*dynamic.Middleware{
+						"default-retry": {
+							Retry: &dynamic.Retry{
+								Attempts:           3,
+								InitialInterval:    types.Duration(100 * time.Millisecond),
+								MaxInterval:        types.Duration(2 * time.Second),
+								Status:             []string{"502-504"},
+								RetryNonIdempotent: true,
+								PerTryTimeout:      types.Duration(5 * time.Second),
+								BudgetPercent:      20,
+							},
+						},
 					},
 					Services: map[string]*dynamic.Service{},
 				},
@@ -2940,6 +3218,42 @@ func TestLoadIngressRoutes(t *testing.T) {
 				},
 			},
 		},
+		{
+			desc:  "Simple Ingress Route, with local error page middlewares",
+			paths: []string{"services.yml", "with_error_page_local.yml"},
+			expected: &dynamic.Configuration{
+				UDP: &dynamic.UDPConfiguration{
+					Routers:  map[string]*dynamic.UDPRouter{},
+					Services: map[string]*dynamic.UDPService{},
+				},
+				TLS: &dynamic.TLSConfiguration{},
+				TCP: &dynamic.TCPConfiguration{
+					Routers:  map[string]*dynamic.TCPRouter{},
+					Services: map[string]*dynamic.TCPService{},
+				},
+				HTTP: &dynamic.HTTPConfiguration{
+					ServersTransports: map[string]*dynamic.ServersTransport{},
+					Routers:           map[string]*dynamic.Router{},
+					Middlewares: map[string]*dynamic.Middleware{
+						"default-errorpage-directory": {
+							Errors: &dynamic.ErrorPage{
+								Status:            []string{"5xx"},
+								Directory:         "/etc/traefik/errors",
+								TraefikErrorsOnly: true,
+							},
+						},
+						"default-errorpage-templates": {
+							Errors: &dynamic.ErrorPage{
+								Status:       []string{"502-504"},
+								HTMLTemplate: "<h1>{{ .Status }}</h1>",
+								JSONTemplate: `{"status": {{ .Status }}}`,
+							},
+						},
+					},
+					Services: map[string]*dynamic.Service{},
+				},
+			},
+		},
 		{
 			desc:  "Simple Ingress Route, with options",
 			paths: []string{"services.yml", "with_options.yml"},
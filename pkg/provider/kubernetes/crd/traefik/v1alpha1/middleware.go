@@ -45,6 +45,11 @@ type MiddlewareSpec struct {
 	PassTLSClientCert *dynamic.PassTLSClientCert     `json:"passTLSClientCert,omitempty"`
 	Retry             *Retry                         `json:"retry,omitempty"`
 	ContentType       *dynamic.ContentType           `json:"contentType,omitempty"`
+	OIDC              *OIDC                          `json:"oidc,omitempty"`
+	JWT               *JWT                           `json:"jwt,omitempty"`
+	Cache             *Cache                         `json:"cache,omitempty"`
+	Limits            *dynamic.Limits                `json:"limits,omitempty"`
+	ClientCertAuth    *dynamic.ClientCertAuth        `json:"clientCertAuth,omitempty"`
 	Plugin            map[string]apiextensionv1.JSON `json:"plugin,omitempty"`
 }
 
@@ -52,9 +57,14 @@ type MiddlewareSpec struct {
 
 // ErrorPage holds the custom error page configuration.
 type ErrorPage struct {
-	Status  []string `json:"status,omitempty"`
-	Service Service  `json:"service,omitempty"`
-	Query   string   `json:"query,omitempty"`
+	Status []string `json:"status,omitempty"`
+	// Service is optional when the error pages are local, i.e. when Directory, HTMLTemplate or JSONTemplate is set.
+	Service           Service `json:"service,omitempty"`
+	Query             string  `json:"query,omitempty"`
+	Directory         string  `json:"directory,omitempty"`
+	HTMLTemplate      string  `json:"htmlTemplate,omitempty"`
+	JSONTemplate      string  `json:"jsonTemplate,omitempty"`
+	TraefikErrorsOnly bool    `json:"traefikErrorsOnly,omitempty"`
 }
 
 // +k8s:deepcopy-gen=true
@@ -96,6 +106,49 @@ type ForwardAuth struct {
 	TLS                      *ClientTLS `json:"tls,omitempty"`
 }
 
+// +k8s:deepcopy-gen=true
+
+// OIDC holds the OpenID Connect authentication configuration.
+type OIDC struct {
+	Issuer   string `json:"issuer,omitempty"`
+	ClientID string `json:"clientID,omitempty"`
+	// Secret is the name of the Kubernetes Secret holding the clientSecret and sessionSecret keys.
+	Secret                string              `json:"secret,omitempty"`
+	Scopes                []string            `json:"scopes,omitempty"`
+	CallbackPath          string              `json:"callbackPath,omitempty"`
+	LogoutPath            string              `json:"logoutPath,omitempty"`
+	PostLogoutRedirectURL string              `json:"postLogoutRedirectURL,omitempty"`
+	SessionCookieName     string              `json:"sessionCookieName,omitempty"`
+	SessionMaxAge         *intstr.IntOrString `json:"sessionMaxAge,omitempty"`
+	ClaimsHeaders         map[string]string   `json:"claimsHeaders,omitempty"`
+	ForwardAccessToken    bool                `json:"forwardAccessToken,omitempty"`
+}
+
+// +k8s:deepcopy-gen=true
+
+// JWT holds the JWT authentication configuration.
+type JWT struct {
+	Keys []string `json:"keys,omitempty"`
+	// Secret is the name of the Kubernetes Secret holding, in its secret key, the key used to verify the HS signatures.
+	Secret              string              `json:"secret,omitempty"`
+	JWKSURL             string              `json:"jwksURL,omitempty"`
+	JWKSRefreshInterval *intstr.IntOrString `json:"jwksRefreshInterval,omitempty"`
+	Issuer              string              `json:"issuer,omitempty"`
+	Audience            string              `json:"audience,omitempty"`
+	ClaimsHeaders       map[string]string   `json:"claimsHeaders,omitempty"`
+	RemoveHeader        bool                `json:"removeHeader,omitempty"`
+}
+
+// +k8s:deepcopy-gen=true
+
+// Cache holds the HTTP cache configuration.
+type Cache struct {
+	MaxEntries           *int                `json:"maxEntries,omitempty"`
+	MaxResponseBodyBytes *int64              `json:"maxResponseBodyBytes,omitempty"`
+	DefaultTTL           *intstr.IntOrString `json:"defaultTTL,omitempty"`
+	DiskPath             string              `json:"diskPath,omitempty"`
+}
+
 // ClientTLS holds TLS specific configurations as client.
 type ClientTLS struct {
 	CASecret           string `json:"caSecret,omitempty"`
@@ -122,12 +175,37 @@ type RateLimit struct {
 	Period          *intstr.IntOrString      `json:"period,omitempty"`
 	Burst           *int64                   `json:"burst,omitempty"`
 	SourceCriterion *dynamic.SourceCriterion `json:"sourceCriterion,omitempty"`
+	Storage         *RateLimitStorage        `json:"storage,omitempty"`
+}
+
+// +k8s:deepcopy-gen=true
+
+// RateLimitStorage holds the configuration of a shared storage for the rate limiter token buckets.
+type RateLimitStorage struct {
+	Redis    *RedisStorage `json:"redis,omitempty"`
+	Fallback string        `json:"fallback,omitempty"`
+}
+
+// +k8s:deepcopy-gen=true
+
+// RedisStorage holds the configuration of a Redis server.
+type RedisStorage struct {
+	Address string `json:"address,omitempty"`
+	// Secret is the name of the Kubernetes Secret holding, in its password key, the password of the Redis server.
+	Secret  string              `json:"secret,omitempty"`
+	DB      int                 `json:"db,omitempty"`
+	Timeout *intstr.IntOrString `json:"timeout,omitempty"`
 }
 
 // +k8s:deepcopy-gen=true
 
 // Retry holds the retry configuration.
 type Retry struct {
-	Attempts        int                `json:"attempts,omitempty"`
-	InitialInterval intstr.IntOrString `json:"initialInterval,omitempty"`
+	Attempts           int                `json:"attempts,omitempty"`
+	InitialInterval    intstr.IntOrString `json:"initialInterval,omitempty"`
+	MaxInterval        intstr.IntOrString `json:"maxInterval,omitempty"`
+	Status             []string           `json:"status,omitempty"`
+	RetryNonIdempotent bool               `json:"retryNonIdempotent,omitempty"`
+	PerTryTimeout      intstr.IntOrString `json:"perTryTimeout,omitempty"`
+	BudgetPercent      int                `json:"budgetPercent,omitempty"`
 }
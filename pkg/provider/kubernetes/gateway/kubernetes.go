@@ -182,8 +182,9 @@ func (p *Provider) loadConfigurationFromGateway(ctx context.Context, client Clie
 				Services: map[string]*dynamic.UDPService{},
 			},
 			TCP: &dynamic.TCPConfiguration{
-				Routers:  map[string]*dynamic.TCPRouter{},
-				Services: map[string]*dynamic.TCPService{},
+				Routers:     map[string]*dynamic.TCPRouter{},
+				Middlewares: map[string]*dynamic.TCPMiddleware{},
+				Services:    map[string]*dynamic.TCPService{},
 			},
 			HTTP: &dynamic.HTTPConfiguration{
 				Routers:     map[string]*dynamic.Router{},
@@ -269,8 +270,9 @@ func (p *Provider) createGatewayConf(client Client, gateway *v1alpha1.Gateway) (
 			Services: map[string]*dynamic.UDPService{},
 		},
 		TCP: &dynamic.TCPConfiguration{
-			Routers:  map[string]*dynamic.TCPRouter{},
-			Services: map[string]*dynamic.TCPService{},
+			Routers:     map[string]*dynamic.TCPRouter{},
+			Middlewares: map[string]*dynamic.TCPMiddleware{},
+			Services:    map[string]*dynamic.TCPService{},
 		},
 		HTTP: &dynamic.HTTPConfiguration{
 			Routers:     map[string]*dynamic.Router{},
@@ -7,13 +7,13 @@ import (
 	"path"
 	"time"
 
-	"github.com/abronan/valkeyrie"
-	"github.com/abronan/valkeyrie/store"
-	"github.com/abronan/valkeyrie/store/consul"
-	etcdv3 "github.com/abronan/valkeyrie/store/etcd/v3"
-	"github.com/abronan/valkeyrie/store/redis"
-	"github.com/abronan/valkeyrie/store/zookeeper"
 	"github.com/cenkalti/backoff/v4"
+	"github.com/kvtools/valkeyrie"
+	"github.com/kvtools/valkeyrie/store"
+	"github.com/kvtools/valkeyrie/store/consul"
+	etcdv3 "github.com/kvtools/valkeyrie/store/etcd/v3"
+	"github.com/kvtools/valkeyrie/store/redis"
+	"github.com/kvtools/valkeyrie/store/zookeeper"
 	"github.com/traefik/traefik/v2/pkg/config/dynamic"
 	"github.com/traefik/traefik/v2/pkg/config/kv"
 	"github.com/traefik/traefik/v2/pkg/job"
@@ -159,22 +159,32 @@ func (p *Provider) buildConfiguration() (*dynamic.Configuration, error) {
 }
 
 func (p *Provider) createKVClient(ctx context.Context) (store.Store, error) {
+	kvStore, err := NewStore(ctx, p.storeType, p.Endpoints, p.Username, p.Password, p.TLS)
+	if err != nil {
+		return nil, err
+	}
+
+	return &storeWrapper{Store: kvStore}, nil
+}
+
+// NewStore creates a client of the given KV store backend.
+func NewStore(ctx context.Context, storeType store.Backend, endpoints []string, username, password string, clientTLS *types.ClientTLS) (store.Store, error) {
 	storeConfig := &store.Config{
 		ConnectionTimeout: 3 * time.Second,
 		Bucket:            "traefik",
-		Username:          p.Username,
-		Password:          p.Password,
+		Username:          username,
+		Password:          password,
 	}
 
-	if p.TLS != nil {
+	if clientTLS != nil {
 		var err error
-		storeConfig.TLS, err = p.TLS.CreateTLSConfig(ctx)
+		storeConfig.TLS, err = clientTLS.CreateTLSConfig(ctx)
 		if err != nil {
 			return nil, err
 		}
 	}
 
-	switch p.storeType {
+	switch storeType {
 	case store.CONSUL:
 		consul.Register()
 	case store.ETCDV3:
@@ -185,10 +195,5 @@ func (p *Provider) createKVClient(ctx context.Context) (store.Store, error) {
 		redis.Register()
 	}
 
-	kvStore, err := valkeyrie.NewStore(p.storeType, p.Endpoints, storeConfig)
-	if err != nil {
-		return nil, err
-	}
-
-	return &storeWrapper{Store: kvStore}, nil
+	return valkeyrie.NewStore(storeType, endpoints, storeConfig)
 }
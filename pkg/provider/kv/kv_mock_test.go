@@ -4,7 +4,7 @@ import (
 	"errors"
 	"strings"
 
-	"github.com/abronan/valkeyrie/store"
+	"github.com/kvtools/valkeyrie/store"
 )
 
 func newProviderMock(kvPairs []*store.KVPair) *Provider {
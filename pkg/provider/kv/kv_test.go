@@ -6,7 +6,7 @@ import (
 	"testing"
 	"time"
 
-	"github.com/abronan/valkeyrie/store"
+	"github.com/kvtools/valkeyrie/store"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	ptypes "github.com/traefik/paerser/types"
@@ -56,6 +56,7 @@ func Test_buildConfiguration(t *testing.T) {
 		"traefik/http/services/Service01/loadBalancer/sticky/cookie/httpOnly":                        "true",
 		"traefik/http/services/Service01/loadBalancer/servers/0/url":                                 "foobar",
 		"traefik/http/services/Service01/loadBalancer/servers/1/url":                                 "foobar",
+		"traefik/http/services/Service01/loadBalancer/servers/1/weight":                              "42",
 		"traefik/http/services/Service02/mirroring/service":                                          "foobar",
 		"traefik/http/services/Service02/mirroring/maxBodySize":                                      "42",
 		"traefik/http/services/Service02/mirroring/mirrors/0/name":                                   "foobar",
@@ -625,6 +626,7 @@ func Test_buildConfiguration(t *testing.T) {
 							},
 							{
 								URL:    "foobar",
+								Weight: func(v int) *int { return &v }(42),
 								Scheme: "http",
 							},
 						},
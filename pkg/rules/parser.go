@@ -142,3 +142,31 @@ func newTCPParser() (predicate.Parser, error) {
 		Functions: parserFuncs,
 	})
 }
+
+func newUDPParser() (predicate.Parser, error) {
+	parserFuncs := make(map[string]interface{})
+
+	for matcherName := range udpFuncs {
+		matcherName := matcherName
+		fn := func(value ...string) treeBuilder {
+			return func() *tree {
+				return &tree{
+					matcher: matcherName,
+					value:   value,
+				}
+			}
+		}
+		parserFuncs[matcherName] = fn
+		parserFuncs[strings.ToLower(matcherName)] = fn
+		parserFuncs[strings.ToUpper(matcherName)] = fn
+		parserFuncs[strings.Title(strings.ToLower(matcherName))] = fn
+	}
+
+	return predicate.NewParser(predicate.Def{
+		Operators: predicate.Operators{
+			AND: andFunc,
+			OR:  orFunc,
+		},
+		Functions: parserFuncs,
+	})
+}
@@ -69,6 +69,28 @@ func (r *Router) AddRoute(rule string, priority int, handler http.Handler) error
 	return nil
 }
 
+// HTTPMatcher reports whether a request matches a rule.
+type HTTPMatcher func(req *http.Request) bool
+
+// NewHTTPMatcher parses the given rule, which uses the same matchers as the router rules,
+// and returns the corresponding matcher.
+func NewHTTPMatcher(rule string) (HTTPMatcher, error) {
+	router, err := NewRouter()
+	if err != nil {
+		return nil, err
+	}
+
+	err = router.AddRoute(rule, 0, http.NotFoundHandler())
+	if err != nil {
+		return nil, err
+	}
+
+	return func(req *http.Request) bool {
+		var match mux.RouteMatch
+		return router.Match(req, &match)
+	}, nil
+}
+
 type tree struct {
 	matcher   string
 	value     []string
@@ -3,6 +3,7 @@ package rules
 import (
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/gorilla/mux"
@@ -752,3 +753,69 @@ func TestParseDomains(t *testing.T) {
 		})
 	}
 }
+
+func TestNewHTTPMatcher(t *testing.T) {
+	testCases := []struct {
+		desc          string
+		rule          string
+		expectedError bool
+		expected      map[string]bool
+	}{
+		{
+			desc:          "Empty rule",
+			rule:          "",
+			expectedError: true,
+		},
+		{
+			desc:          "Unknown matcher",
+			rule:          "ClientIP(`10.0.0.1`)",
+			expectedError: true,
+		},
+		{
+			desc: "Method and PathPrefix",
+			rule: "Method(`GET`) && PathPrefix(`/api/v2`)",
+			expected: map[string]bool{
+				"GET http://localhost/api/v2/users":  true,
+				"POST http://localhost/api/v2/users": false,
+				"GET http://localhost/api/v1/users":  false,
+			},
+		},
+		{
+			desc: "Host or Path",
+			rule: "Host(`foo.bar`) || Path(`/health`)",
+			expected: map[string]bool{
+				"GET http://foo.bar/api":     true,
+				"POST http://bar.foo/health": true,
+				"GET http://bar.foo/api":     false,
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			matcher, err := NewHTTPMatcher(test.rule)
+			if test.expectedError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			// RequestDecorator is necessary for the host rule
+			reqHost := requestdecorator.New(nil)
+
+			results := make(map[string]bool)
+			for request := range test.expected {
+				parts := strings.SplitN(request, " ", 2)
+
+				req := testhelpers.MustNewRequest(parts[0], parts[1], nil)
+				reqHost.ServeHTTP(httptest.NewRecorder(), req, func(_ http.ResponseWriter, req *http.Request) {
+					results[request] = matcher(req)
+				})
+			}
+			assert.Equal(t, test.expected, results)
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package rules
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/traefik/traefik/v2/pkg/ip"
+)
+
+var udpFuncs = map[string]func(...string) (UDPMatcher, error){
+	"ClientIP": clientIP,
+}
+
+// UDPMatcher reports whether a UDP session, identified by the IP of its client, matches a rule.
+type UDPMatcher func(clientIP net.IP) bool
+
+// NewUDPMatcher parses the given UDP rule and returns the corresponding matcher.
+func NewUDPMatcher(rule string) (UDPMatcher, error) {
+	parser, err := newUDPParser()
+	if err != nil {
+		return nil, err
+	}
+
+	parse, err := parser.Parse(rule)
+	if err != nil {
+		return nil, fmt.Errorf("error while parsing rule %s: %w", rule, err)
+	}
+
+	buildTree, ok := parse.(treeBuilder)
+	if !ok {
+		return nil, fmt.Errorf("error while parsing rule %s", rule)
+	}
+
+	return buildUDPMatcher(buildTree())
+}
+
+func buildUDPMatcher(rule *tree) (UDPMatcher, error) {
+	switch rule.matcher {
+	case "and", "or":
+		left, err := buildUDPMatcher(rule.ruleLeft)
+		if err != nil {
+			return nil, err
+		}
+
+		right, err := buildUDPMatcher(rule.ruleRight)
+		if err != nil {
+			return nil, err
+		}
+
+		if rule.matcher == "and" {
+			return func(clientIP net.IP) bool {
+				return left(clientIP) && right(clientIP)
+			}, nil
+		}
+
+		return func(clientIP net.IP) bool {
+			return left(clientIP) || right(clientIP)
+		}, nil
+	default:
+		err := checkRule(rule)
+		if err != nil {
+			return nil, err
+		}
+
+		return udpFuncs[rule.matcher](rule.value...)
+	}
+}
+
+func clientIP(ranges ...string) (UDPMatcher, error) {
+	checker, err := ip.NewChecker(ranges)
+	if err != nil {
+		return nil, fmt.Errorf("invalid value for ClientIP matcher: %w", err)
+	}
+
+	return checker.ContainsIP, nil
+}
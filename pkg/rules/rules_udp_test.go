@@ -0,0 +1,100 @@
+package rules
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewUDPMatcher(t *testing.T) {
+	testCases := []struct {
+		desc          string
+		rule          string
+		expectedError bool
+		expected      map[string]bool
+	}{
+		{
+			desc:          "Empty rule",
+			rule:          "",
+			expectedError: true,
+		},
+		{
+			desc:          "Unknown matcher",
+			rule:          "Host(`foo.bar`)",
+			expectedError: true,
+		},
+		{
+			desc:          "ClientIP without value",
+			rule:          "ClientIP()",
+			expectedError: true,
+		},
+		{
+			desc:          "ClientIP with invalid range",
+			rule:          "ClientIP(`foobar`)",
+			expectedError: true,
+		},
+		{
+			desc: "ClientIP with an IP",
+			rule: "ClientIP(`10.0.0.1`)",
+			expected: map[string]bool{
+				"10.0.0.1": true,
+				"10.0.0.2": false,
+			},
+		},
+		{
+			desc: "ClientIP with several ranges",
+			rule: "ClientIP(`10.0.0.0/16`, `fe80::/10`)",
+			expected: map[string]bool{
+				"10.0.12.1":   true,
+				"10.1.0.1":    false,
+				"fe80::1":     true,
+				"2001:db8::1": false,
+			},
+		},
+		{
+			desc: "Lowercase ClientIP",
+			rule: "clientip(`192.168.0.0/24`)",
+			expected: map[string]bool{
+				"192.168.0.42": true,
+				"192.168.1.42": false,
+			},
+		},
+		{
+			desc: "ClientIP with OR operator",
+			rule: "ClientIP(`192.168.0.0/24`) || ClientIP(`10.0.0.0/8`)",
+			expected: map[string]bool{
+				"192.168.0.42": true,
+				"10.20.30.40":  true,
+				"172.16.0.1":   false,
+			},
+		},
+		{
+			desc: "ClientIP with AND operator",
+			rule: "ClientIP(`10.0.0.0/8`) && ClientIP(`10.10.0.0/16`)",
+			expected: map[string]bool{
+				"10.10.0.1": true,
+				"10.20.0.1": false,
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			matcher, err := NewUDPMatcher(test.rule)
+			if test.expectedError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			for clientIP, expected := range test.expected {
+				assert.Equal(t, expected, matcher(net.ParseIP(clientIP)), clientIP)
+			}
+		})
+	}
+}
@@ -8,10 +8,12 @@ import (
 	"strings"
 
 	"github.com/containous/alice"
+	"github.com/traefik/traefik/v2/pkg/config/dynamic"
 	"github.com/traefik/traefik/v2/pkg/config/runtime"
 	"github.com/traefik/traefik/v2/pkg/middlewares/addprefix"
 	"github.com/traefik/traefik/v2/pkg/middlewares/auth"
 	"github.com/traefik/traefik/v2/pkg/middlewares/buffering"
+	"github.com/traefik/traefik/v2/pkg/middlewares/cache"
 	"github.com/traefik/traefik/v2/pkg/middlewares/chain"
 	"github.com/traefik/traefik/v2/pkg/middlewares/circuitbreaker"
 	"github.com/traefik/traefik/v2/pkg/middlewares/compress"
@@ -19,6 +21,7 @@ import (
 	"github.com/traefik/traefik/v2/pkg/middlewares/headers"
 	"github.com/traefik/traefik/v2/pkg/middlewares/inflightreq"
 	"github.com/traefik/traefik/v2/pkg/middlewares/ipwhitelist"
+	"github.com/traefik/traefik/v2/pkg/middlewares/limits"
 	"github.com/traefik/traefik/v2/pkg/middlewares/passtlsclientcert"
 	"github.com/traefik/traefik/v2/pkg/middlewares/ratelimiter"
 	"github.com/traefik/traefik/v2/pkg/middlewares/redirect"
@@ -89,6 +92,30 @@ func (b *Builder) BuildChain(ctx context.Context, middlewares []string) *alice.C
 	return &chain
 }
 
+// PruneSharedResources releases the resources which the middlewares share across the configuration reloads,
+// i.e. the caches and the Redis clients, when they are not used by the given middleware configurations anymore.
+func PruneSharedResources(configs map[string]*runtime.MiddlewareInfo) {
+	cacheNames := make(map[string]struct{})
+	redisConfigs := make(map[dynamic.RedisStorage]struct{})
+
+	for name, config := range configs {
+		if config.Middleware == nil {
+			continue
+		}
+
+		if config.Cache != nil {
+			cacheNames[name] = struct{}{}
+		}
+
+		if config.RateLimit != nil && config.RateLimit.Storage != nil && config.RateLimit.Storage.Redis != nil {
+			redisConfigs[*config.RateLimit.Storage.Redis] = struct{}{}
+		}
+	}
+
+	cache.Prune(cacheNames)
+	ratelimiter.PruneRedisClients(redisConfigs)
+}
+
 func checkRecursion(ctx context.Context, middlewareName string) (context.Context, error) {
 	currentStack, ok := ctx.Value(middlewareStackKey).([]string)
 	if !ok {
@@ -173,6 +200,16 @@ func (b *Builder) buildConstructor(ctx context.Context, middlewareName string) (
 		}
 	}
 
+	// Cache
+	if config.Cache != nil {
+		if middleware != nil {
+			return nil, badConf
+		}
+		middleware = func(next http.Handler) (http.Handler, error) {
+			return cache.New(ctx, next, *config.Cache, middlewareName)
+		}
+	}
+
 	// ContentType
 	if config.ContentType != nil {
 		if middleware != nil {
@@ -218,6 +255,36 @@ func (b *Builder) buildConstructor(ctx context.Context, middlewareName string) (
 		}
 	}
 
+	// JWT
+	if config.JWT != nil {
+		if middleware != nil {
+			return nil, badConf
+		}
+		middleware = func(next http.Handler) (http.Handler, error) {
+			return auth.NewJWT(ctx, next, *config.JWT, middlewareName)
+		}
+	}
+
+	// OIDC
+	if config.OIDC != nil {
+		if middleware != nil {
+			return nil, badConf
+		}
+		middleware = func(next http.Handler) (http.Handler, error) {
+			return auth.NewOIDC(ctx, next, *config.OIDC, middlewareName)
+		}
+	}
+
+	// ClientCertAuth
+	if config.ClientCertAuth != nil {
+		if middleware != nil {
+			return nil, badConf
+		}
+		middleware = func(next http.Handler) (http.Handler, error) {
+			return auth.NewClientCertAuth(ctx, next, *config.ClientCertAuth, middlewareName)
+		}
+	}
+
 	// Headers
 	if config.Headers != nil {
 		if middleware != nil {
@@ -248,6 +315,16 @@ func (b *Builder) buildConstructor(ctx context.Context, middlewareName string) (
 		}
 	}
 
+	// Limits
+	if config.Limits != nil {
+		if middleware != nil {
+			return nil, badConf
+		}
+		middleware = func(next http.Handler) (http.Handler, error) {
+			return limits.New(ctx, next, *config.Limits, middlewareName)
+		}
+	}
+
 	// PassTLSClientCert
 	if config.PassTLSClientCert != nil {
 		if middleware != nil {
@@ -0,0 +1,115 @@
+package tcpmiddleware
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traefik/traefik/v2/pkg/config/dynamic"
+	"github.com/traefik/traefik/v2/pkg/config/runtime"
+	"github.com/traefik/traefik/v2/pkg/server/provider"
+	"github.com/traefik/traefik/v2/pkg/tcp"
+)
+
+func TestBuilder_BuildChainNilConfig(t *testing.T) {
+	testConfig := map[string]*runtime.TCPMiddlewareInfo{
+		"empty": {},
+	}
+	middlewaresBuilder := NewBuilder(testConfig)
+
+	chain := middlewaresBuilder.BuildChain(context.Background(), []string{"empty"})
+	_, err := chain.Then(tcp.HandlerFunc(func(conn tcp.WriteCloser) {}))
+	require.Error(t, err)
+}
+
+func TestBuilder_BuildChainNonExistentChain(t *testing.T) {
+	testConfig := map[string]*runtime.TCPMiddlewareInfo{
+		"foobar": {},
+	}
+	middlewaresBuilder := NewBuilder(testConfig)
+
+	chain := middlewaresBuilder.BuildChain(context.Background(), []string{"empty"})
+	_, err := chain.Then(tcp.HandlerFunc(func(conn tcp.WriteCloser) {}))
+	require.Error(t, err)
+}
+
+func TestBuilder_buildConstructor(t *testing.T) {
+	testConfig := map[string]*dynamic.TCPMiddleware{
+		"cidr-1@myprovider": {
+			IPWhiteList: &dynamic.TCPIPWhiteList{
+				SourceRange: []string{"10.0.0.0/8"},
+			},
+		},
+		"conn-1@myprovider": {
+			InFlightConn: &dynamic.TCPInFlightConn{
+				Amount: 1,
+			},
+		},
+		"invalid-cidr@myprovider": {
+			IPWhiteList: &dynamic.TCPIPWhiteList{
+				SourceRange: []string{"foo"},
+			},
+		},
+		"multi-types@myprovider": {
+			IPWhiteList: &dynamic.TCPIPWhiteList{
+				SourceRange: []string{"10.0.0.0/8"},
+			},
+			InFlightConn: &dynamic.TCPInFlightConn{
+				Amount: 1,
+			},
+		},
+	}
+
+	rtConf := runtime.NewConfig(dynamic.Configuration{
+		TCP: &dynamic.TCPConfiguration{
+			Middlewares: testConfig,
+		},
+	})
+	middlewaresBuilder := NewBuilder(rtConf.TCPMiddlewares)
+
+	testCases := []struct {
+		desc          string
+		middlewares   []string
+		expectedError bool
+	}{
+		{
+			desc:        "Should create the expected middlewares",
+			middlewares: []string{"cidr-1", "conn-1"},
+		},
+		{
+			desc:          "Should not create middleware with invalid configuration",
+			middlewares:   []string{"invalid-cidr"},
+			expectedError: true,
+		},
+		{
+			desc:          "Should not create multi-types middleware",
+			middlewares:   []string{"multi-types"},
+			expectedError: true,
+		},
+		{
+			desc:          "Should not create non existing middleware",
+			middlewares:   []string{"unknown"},
+			expectedError: true,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := provider.AddInContext(context.Background(), "foobar@myprovider")
+
+			chain := middlewaresBuilder.BuildChain(ctx, test.middlewares)
+			handler, err := chain.Then(tcp.HandlerFunc(func(conn tcp.WriteCloser) {}))
+			if test.expectedError {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.NotNil(t, handler)
+		})
+	}
+}
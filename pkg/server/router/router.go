@@ -142,8 +142,16 @@ func (m *Manager) buildRouterHandler(ctx context.Context, routerName string, rou
 		return nil, err
 	}
 
+	var accessLogOptions accesslog.FieldApply
+	if routerConfig.AccessLog != nil {
+		accessLogOptions, err = accesslog.NewRouterOptions(routerConfig.AccessLog)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	handlerWithAccessLog, err := alice.New(func(next http.Handler) (http.Handler, error) {
-		return accesslog.NewFieldHandler(next, accesslog.RouterName, routerName, nil), nil
+		return accesslog.NewFieldHandler(next, accesslog.RouterName, routerName, accessLogOptions), nil
 	}).Then(handler)
 	if err != nil {
 		log.FromContext(ctx).Error(err)
@@ -11,18 +11,20 @@ import (
 	"github.com/stretchr/testify/require"
 	"github.com/traefik/traefik/v2/pkg/config/dynamic"
 	"github.com/traefik/traefik/v2/pkg/config/runtime"
+	tcpmiddleware "github.com/traefik/traefik/v2/pkg/server/middleware/tcp"
 	"github.com/traefik/traefik/v2/pkg/server/service/tcp"
 	traefiktls "github.com/traefik/traefik/v2/pkg/tls"
 )
 
 func TestRuntimeConfiguration(t *testing.T) {
 	testCases := []struct {
-		desc              string
-		httpServiceConfig map[string]*runtime.ServiceInfo
-		httpRouterConfig  map[string]*runtime.RouterInfo
-		tcpServiceConfig  map[string]*runtime.TCPServiceInfo
-		tcpRouterConfig   map[string]*runtime.TCPRouterInfo
-		expectedError     int
+		desc                string
+		httpServiceConfig   map[string]*runtime.ServiceInfo
+		httpRouterConfig    map[string]*runtime.RouterInfo
+		tcpServiceConfig    map[string]*runtime.TCPServiceInfo
+		tcpRouterConfig     map[string]*runtime.TCPRouterInfo
+		tcpMiddlewareConfig map[string]*runtime.TCPMiddlewareInfo
+		expectedError       int
 	}{
 		{
 			desc: "No error",
@@ -237,6 +239,93 @@ func TestRuntimeConfiguration(t *testing.T) {
 			},
 			expectedError: 2,
 		},
+		{
+			desc: "Router with middlewares",
+			tcpServiceConfig: map[string]*runtime.TCPServiceInfo{
+				"foo-service": {
+					TCPService: &dynamic.TCPService{
+						LoadBalancer: &dynamic.TCPServersLoadBalancer{
+							Servers: []dynamic.TCPServer{
+								{
+									Address: "127.0.0.1:8085",
+								},
+							},
+						},
+					},
+				},
+			},
+			tcpMiddlewareConfig: map[string]*runtime.TCPMiddlewareInfo{
+				"ipwhitelist": {
+					TCPMiddleware: &dynamic.TCPMiddleware{
+						IPWhiteList: &dynamic.TCPIPWhiteList{
+							SourceRange: []string{"127.0.0.1/32"},
+						},
+					},
+				},
+				"inflightconn": {
+					TCPMiddleware: &dynamic.TCPMiddleware{
+						InFlightConn: &dynamic.TCPInFlightConn{
+							Amount: 10,
+						},
+					},
+				},
+			},
+			tcpRouterConfig: map[string]*runtime.TCPRouterInfo{
+				"bar": {
+					TCPRouter: &dynamic.TCPRouter{
+						EntryPoints: []string{"web"},
+						Middlewares: []string{"ipwhitelist", "inflightconn"},
+						Service:     "foo-service",
+						Rule:        "HostSNI(`*`)",
+					},
+				},
+			},
+			expectedError: 0,
+		},
+		{
+			desc: "Router with unknown and broken middlewares",
+			tcpServiceConfig: map[string]*runtime.TCPServiceInfo{
+				"foo-service": {
+					TCPService: &dynamic.TCPService{
+						LoadBalancer: &dynamic.TCPServersLoadBalancer{
+							Servers: []dynamic.TCPServer{
+								{
+									Address: "127.0.0.1:8085",
+								},
+							},
+						},
+					},
+				},
+			},
+			tcpMiddlewareConfig: map[string]*runtime.TCPMiddlewareInfo{
+				"ipwhitelist": {
+					TCPMiddleware: &dynamic.TCPMiddleware{
+						IPWhiteList: &dynamic.TCPIPWhiteList{
+							SourceRange: []string{"foo"},
+						},
+					},
+				},
+			},
+			tcpRouterConfig: map[string]*runtime.TCPRouterInfo{
+				"foo": {
+					TCPRouter: &dynamic.TCPRouter{
+						EntryPoints: []string{"web"},
+						Middlewares: []string{"unknown"},
+						Service:     "foo-service",
+						Rule:        "HostSNI(`*`)",
+					},
+				},
+				"bar": {
+					TCPRouter: &dynamic.TCPRouter{
+						EntryPoints: []string{"web"},
+						Middlewares: []string{"ipwhitelist"},
+						Service:     "foo-service",
+						Rule:        "HostSNI(`*`)",
+					},
+				},
+			},
+			expectedError: 3,
+		},
 	}
 
 	for _, test := range testCases {
@@ -248,12 +337,13 @@ func TestRuntimeConfiguration(t *testing.T) {
 			entryPoints := []string{"web"}
 
 			conf := &runtime.Configuration{
-				Services:    test.httpServiceConfig,
-				Routers:     test.httpRouterConfig,
-				TCPServices: test.tcpServiceConfig,
-				TCPRouters:  test.tcpRouterConfig,
+				Services:       test.httpServiceConfig,
+				Routers:        test.httpRouterConfig,
+				TCPServices:    test.tcpServiceConfig,
+				TCPRouters:     test.tcpRouterConfig,
+				TCPMiddlewares: test.tcpMiddlewareConfig,
 			}
-			serviceManager := tcp.NewManager(conf)
+			serviceManager := tcp.NewManager(conf, nil)
 			tlsManager := traefiktls.NewManager()
 			tlsManager.UpdateConfigs(
 				context.Background(),
@@ -271,7 +361,9 @@ func TestRuntimeConfiguration(t *testing.T) {
 				},
 				[]*traefiktls.CertAndStores{})
 
-			routerManager := NewManager(conf, serviceManager,
+			middlewaresBuilder := tcpmiddleware.NewBuilder(conf.TCPMiddlewares)
+
+			routerManager := NewManager(conf, serviceManager, middlewaresBuilder,
 				nil, nil, tlsManager)
 
 			_ = routerManager.BuildHandlers(context.Background(), entryPoints)
@@ -300,6 +392,11 @@ func TestRuntimeConfiguration(t *testing.T) {
 					allErrors++
 				}
 			}
+			for _, v := range conf.TCPMiddlewares {
+				if len(v.Err) > 0 {
+					allErrors++
+				}
+			}
 			assert.Equal(t, test.expectedError, allErrors)
 		})
 	}
@@ -492,7 +589,7 @@ func TestDomainFronting(t *testing.T) {
 				Routers: test.routers,
 			}
 
-			serviceManager := tcp.NewManager(conf)
+			serviceManager := tcp.NewManager(conf, nil)
 
 			tlsManager := traefiktls.NewManager()
 			tlsManager.UpdateConfigs(context.Background(), map[string]traefiktls.Store{}, tlsOptions, []*traefiktls.CertAndStores{})
@@ -501,7 +598,9 @@ func TestDomainFronting(t *testing.T) {
 				"web": http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {}),
 			}
 
-			routerManager := NewManager(conf, serviceManager, nil, httpsHandler, tlsManager)
+			middlewaresBuilder := tcpmiddleware.NewBuilder(conf.TCPMiddlewares)
+
+			routerManager := NewManager(conf, serviceManager, middlewaresBuilder, nil, httpsHandler, tlsManager)
 
 			routers := routerManager.BuildHandlers(context.Background(), entryPoints)
 
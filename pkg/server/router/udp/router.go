@@ -7,6 +7,7 @@ import (
 
 	"github.com/traefik/traefik/v2/pkg/config/runtime"
 	"github.com/traefik/traefik/v2/pkg/log"
+	"github.com/traefik/traefik/v2/pkg/rules"
 	"github.com/traefik/traefik/v2/pkg/server/provider"
 	udpservice "github.com/traefik/traefik/v2/pkg/server/service/udp"
 	"github.com/traefik/traefik/v2/pkg/udp"
@@ -48,25 +49,20 @@ func (m *Manager) BuildHandlers(rootCtx context.Context, entryPoints []string) m
 
 		ctx := log.With(rootCtx, log.Str(log.EntryPointName, entryPointName))
 
-		if len(routers) > 1 {
-			log.FromContext(ctx).Warn("Config has more than one udp router for a given entrypoint.")
-		}
-
-		handlers, err := m.buildEntryPointHandlers(ctx, routers)
+		handler, err := m.buildEntryPointHandler(ctx, routers)
 		if err != nil {
 			log.FromContext(ctx).Error(err)
 			continue
 		}
 
-		if len(handlers) > 0 {
-			// As UDP support only one router per entrypoint, we only take the first one.
-			entryPointHandlers[entryPointName] = handlers[0]
+		if handler != nil {
+			entryPointHandlers[entryPointName] = handler
 		}
 	}
 	return entryPointHandlers
 }
 
-func (m *Manager) buildEntryPointHandlers(ctx context.Context, configs map[string]*runtime.UDPRouterInfo) ([]udp.Handler, error) {
+func (m *Manager) buildEntryPointHandler(ctx context.Context, configs map[string]*runtime.UDPRouterInfo) (udp.Handler, error) {
 	var rtNames []string
 	for routerName := range configs {
 		rtNames = append(rtNames, routerName)
@@ -76,7 +72,10 @@ func (m *Manager) buildEntryPointHandlers(ctx context.Context, configs map[strin
 		return rtNames[i] > rtNames[j]
 	})
 
-	var handlers []udp.Handler
+	router := &udp.Router{}
+
+	var routesCount int
+	var catchAllRouter string
 
 	for _, routerName := range rtNames {
 		routerConfig := configs[routerName]
@@ -91,6 +90,17 @@ func (m *Manager) buildEntryPointHandlers(ctx context.Context, configs map[strin
 			continue
 		}
 
+		var matcher udp.Matcher
+		if routerConfig.Rule != "" {
+			udpMatcher, err := rules.NewUDPMatcher(routerConfig.Rule)
+			if err != nil {
+				routerConfig.AddError(err, true)
+				logger.Error(err)
+				continue
+			}
+			matcher = udp.Matcher(udpMatcher)
+		}
+
 		handler, err := m.serviceManager.BuildUDP(ctxRouter, routerConfig.Service)
 		if err != nil {
 			routerConfig.AddError(err, true)
@@ -98,8 +108,21 @@ func (m *Manager) buildEntryPointHandlers(ctx context.Context, configs map[strin
 			continue
 		}
 
-		handlers = append(handlers, handler)
+		if matcher == nil {
+			if catchAllRouter != "" {
+				logger.Warnf("The udp router without rule is shadowed by the udp router %q, which has no rule either", catchAllRouter)
+			} else {
+				catchAllRouter = routerName
+			}
+		}
+
+		router.AddRoute(matcher, len(routerConfig.Rule), handler)
+		routesCount++
+	}
+
+	if routesCount == 0 {
+		return nil, nil
 	}
 
-	return handlers, nil
+	return router, nil
 }
@@ -0,0 +1,299 @@
+package mirror
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	gokitmetrics "github.com/go-kit/kit/metrics"
+	"github.com/traefik/traefik/v2/pkg/config/dynamic"
+)
+
+var (
+	mismatchLogsMu sync.Mutex
+	// mismatchLogs are the mismatch logs of the mirroring services, keyed by service name,
+	// so that all the handlers of a service share the same log, which is kept across configuration reloads.
+	mismatchLogs = map[string]*mismatchLog{}
+)
+
+// ResponseSummary is what is compared between the response of the main service and the response of a mirror.
+type ResponseSummary struct {
+	StatusCode int               `json:"statusCode"`
+	Headers    map[string]string `json:"headers,omitempty"`
+	BodyHash   string            `json:"bodyHash"`
+}
+
+// Mismatch describes a mirror response differing from the response of the main service.
+type Mismatch struct {
+	Time     time.Time       `json:"time"`
+	Mirror   string          `json:"mirror"`
+	Method   string          `json:"method"`
+	URL      string          `json:"url"`
+	Reasons  []string        `json:"reasons"`
+	Main     ResponseSummary `json:"main"`
+	Mirrored ResponseSummary `json:"mirrored"`
+}
+
+// Mismatches returns the logged mismatches of the mirroring service with the given name, the most recent first.
+// The boolean is false when the responses of the service mirrors are not compared.
+func Mismatches(serviceName string) ([]Mismatch, bool) {
+	mismatchLogsMu.Lock()
+	l, ok := mismatchLogs[serviceName]
+	mismatchLogsMu.Unlock()
+
+	if !ok {
+		return nil, false
+	}
+
+	return l.list(), true
+}
+
+func getMismatchLog(serviceName string, size int) *mismatchLog {
+	mismatchLogsMu.Lock()
+	defer mismatchLogsMu.Unlock()
+
+	if l, ok := mismatchLogs[serviceName]; ok && len(l.entries) == size {
+		return l
+	}
+
+	l := &mismatchLog{entries: make([]Mismatch, size)}
+	mismatchLogs[serviceName] = l
+
+	return l
+}
+
+// mismatchLog keeps the most recent mismatches, in a ring buffer.
+type mismatchLog struct {
+	mu      sync.Mutex
+	entries []Mismatch
+	next    int
+	count   int
+}
+
+func (l *mismatchLog) add(m Mismatch) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if len(l.entries) == 0 {
+		return
+	}
+
+	l.entries[l.next] = m
+	l.next = (l.next + 1) % len(l.entries)
+	if l.count < len(l.entries) {
+		l.count++
+	}
+}
+
+func (l *mismatchLog) list() []Mismatch {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	result := make([]Mismatch, 0, l.count)
+	for i := 1; i <= l.count; i++ {
+		result = append(result, l.entries[(l.next-i+len(l.entries))%len(l.entries)])
+	}
+	return result
+}
+
+// comparison compares the responses of the mirrors with the response of the main service.
+type comparison struct {
+	serviceName string
+	headers     []string
+	mismatches  gokitmetrics.Counter
+	log         *mismatchLog
+	logPercent  int
+
+	lock   sync.Mutex
+	total  uint64
+	logged uint64
+}
+
+func (c *comparison) compare(req *http.Request, mirrorName string, main, mirrored ResponseSummary) {
+	var reasons []string
+	if main.StatusCode != mirrored.StatusCode {
+		reasons = append(reasons, "status")
+	}
+
+	for _, name := range c.headers {
+		if main.Headers[name] != mirrored.Headers[name] {
+			reasons = append(reasons, "header "+name)
+		}
+	}
+
+	if main.BodyHash != mirrored.BodyHash {
+		reasons = append(reasons, "body")
+	}
+
+	if len(reasons) == 0 {
+		return
+	}
+
+	if c.mismatches != nil {
+		c.mismatches.With("service", c.serviceName, "mirror", mirrorName).Add(1)
+	}
+
+	if !c.sample() {
+		return
+	}
+
+	c.log.add(Mismatch{
+		Time:     time.Now().UTC(),
+		Mirror:   mirrorName,
+		Method:   req.Method,
+		URL:      req.URL.RequestURI(),
+		Reasons:  reasons,
+		Main:     main,
+		Mirrored: mirrored,
+	})
+}
+
+// sample reports whether a mismatch should be logged, so that LogPercent of the mismatches are logged.
+func (c *comparison) sample() bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.total++
+	if c.logged*100 < c.total*uint64(c.logPercent) {
+		c.logged++
+		return true
+	}
+	return false
+}
+
+// EnableComparison enables the comparison of the responses of the mirrors with the response of the main service.
+// The mismatches are counted with the given counter, which can be nil,
+// and the logged ones can be retrieved with Mismatches(serviceName).
+func (m *Mirroring) EnableComparison(serviceName string, config dynamic.MirrorComparison, mismatches gokitmetrics.Counter) error {
+	if config.LogSize < 0 {
+		return fmt.Errorf("logSize cannot be negative, got %d", config.LogSize)
+	}
+
+	if config.LogPercent < 0 || config.LogPercent > 100 {
+		return fmt.Errorf("logPercent must be between 0 and 100, got %d", config.LogPercent)
+	}
+
+	headers := make([]string, 0, len(config.Headers))
+	for _, name := range config.Headers {
+		headers = append(headers, http.CanonicalHeaderKey(name))
+	}
+
+	m.comparison = &comparison{
+		serviceName: serviceName,
+		headers:     headers,
+		mismatches:  mismatches,
+		log:         getMismatchLog(serviceName, config.LogSize),
+		logPercent:  config.LogPercent,
+	}
+
+	return nil
+}
+
+// summaryRecorder is a http.ResponseWriter summarizing the response written to it, for the comparison.
+// When rw is nil, the response is discarded.
+type summaryRecorder struct {
+	rw      http.ResponseWriter
+	header  http.Header
+	headers []string
+
+	statusCode int
+	bodyHash   hash.Hash
+	hijacked   bool
+}
+
+func newSummaryRecorder(rw http.ResponseWriter, headers []string) *summaryRecorder {
+	recorder := &summaryRecorder{
+		rw:       rw,
+		headers:  headers,
+		bodyHash: sha256.New(),
+	}
+
+	if rw == nil {
+		recorder.header = make(http.Header)
+	}
+
+	return recorder
+}
+
+func (r *summaryRecorder) Header() http.Header {
+	if r.rw == nil {
+		return r.header
+	}
+	return r.rw.Header()
+}
+
+func (r *summaryRecorder) WriteHeader(statusCode int) {
+	if r.statusCode != 0 {
+		return
+	}
+
+	r.statusCode = statusCode
+	if r.rw != nil {
+		r.rw.WriteHeader(statusCode)
+	}
+}
+
+func (r *summaryRecorder) Write(b []byte) (int, error) {
+	if r.statusCode == 0 {
+		r.WriteHeader(http.StatusOK)
+	}
+
+	_, _ = r.bodyHash.Write(b)
+
+	if r.rw == nil {
+		return len(b), nil
+	}
+	return r.rw.Write(b)
+}
+
+// Flush sends any buffered data to the client.
+func (r *summaryRecorder) Flush() {
+	if r.statusCode == 0 {
+		r.WriteHeader(http.StatusOK)
+	}
+
+	if flusher, ok := r.rw.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Hijack hijacks the connection, in which case the response is not compared.
+func (r *summaryRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := r.rw.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("%T is not a http.Hijacker", r.rw)
+	}
+
+	r.hijacked = true
+	return hijacker.Hijack()
+}
+
+func (r *summaryRecorder) summary() ResponseSummary {
+	statusCode := r.statusCode
+	if statusCode == 0 {
+		statusCode = http.StatusOK
+	}
+
+	summary := ResponseSummary{
+		StatusCode: statusCode,
+		BodyHash:   hex.EncodeToString(r.bodyHash.Sum(nil)),
+	}
+
+	for _, name := range r.headers {
+		if values := r.Header().Values(name); len(values) > 0 {
+			if summary.Headers == nil {
+				summary.Headers = make(map[string]string)
+			}
+			summary.Headers[name] = strings.Join(values, ", ")
+		}
+	}
+
+	return summary
+}
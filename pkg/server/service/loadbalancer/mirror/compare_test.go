@@ -0,0 +1,136 @@
+package mirror
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/go-kit/kit/metrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traefik/traefik/v2/pkg/config/dynamic"
+	"github.com/traefik/traefik/v2/pkg/safe"
+)
+
+func TestMirroring_comparison(t *testing.T) {
+	t.Cleanup(func() { removeMismatchLog("comparison") })
+
+	respond := func(statusCode int, version, body string) http.Handler {
+		return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			rw.Header().Set("X-Version", version)
+			rw.Header().Set("X-Request-Id", req.URL.Path)
+			rw.WriteHeader(statusCode)
+			_, _ = rw.Write([]byte(body))
+		})
+	}
+
+	pool := safe.NewPool(context.Background())
+
+	mirror := New(respond(http.StatusOK, "1", "foo"), pool, defaultMaxBodySize)
+
+	counter := &counterMock{}
+	config := dynamic.MirrorComparison{Headers: []string{"x-version"}, LogSize: 10, LogPercent: 100}
+	require.NoError(t, mirror.EnableComparison("comparison", config, counter))
+
+	require.NoError(t, mirror.AddMirror(respond(http.StatusOK, "1", "foo"), 100, WithName("same")))
+	require.NoError(t, mirror.AddMirror(respond(http.StatusOK, "1", "bar"), 100, WithName("body")))
+	require.NoError(t, mirror.AddMirror(respond(http.StatusInternalServerError, "2", "foo"), 100, WithName("status")))
+
+	recorder := httptest.NewRecorder()
+	mirror.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/foo", nil))
+
+	pool.Stop()
+
+	// The main response is not altered by the comparison.
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "foo", recorder.Body.String())
+	assert.Equal(t, "1", recorder.Header().Get("X-Version"))
+
+	assert.Equal(t, float64(2), counter.value)
+	assert.Equal(t, []string{"service", "comparison", "mirror", "status"}, counter.labels)
+
+	mismatches, ok := Mismatches("comparison")
+	require.True(t, ok)
+	require.Len(t, mismatches, 2)
+
+	// The most recent first.
+	assert.Equal(t, "status", mismatches[0].Mirror)
+	assert.Equal(t, []string{"status", "header X-Version"}, mismatches[0].Reasons)
+	assert.Equal(t, http.StatusOK, mismatches[0].Main.StatusCode)
+	assert.Equal(t, http.StatusInternalServerError, mismatches[0].Mirrored.StatusCode)
+	assert.Equal(t, map[string]string{"X-Version": "2"}, mismatches[0].Mirrored.Headers)
+	assert.Equal(t, http.MethodGet, mismatches[0].Method)
+	assert.Equal(t, "/foo", mismatches[0].URL)
+
+	assert.Equal(t, "body", mismatches[1].Mirror)
+	assert.Equal(t, []string{"body"}, mismatches[1].Reasons)
+	assert.NotEqual(t, mismatches[1].Main.BodyHash, mismatches[1].Mirrored.BodyHash)
+}
+
+func TestMirroring_comparisonDisabled(t *testing.T) {
+	_, ok := Mismatches("unknown")
+	assert.False(t, ok)
+}
+
+func TestEnableComparison_invalid(t *testing.T) {
+	mirror := New(http.NotFoundHandler(), safe.NewPool(context.Background()), defaultMaxBodySize)
+
+	assert.Error(t, mirror.EnableComparison("invalid", dynamic.MirrorComparison{LogSize: -1}, nil))
+	assert.Error(t, mirror.EnableComparison("invalid", dynamic.MirrorComparison{LogPercent: 101}, nil))
+}
+
+func TestComparison_sampling(t *testing.T) {
+	t.Cleanup(func() { removeMismatchLog("sampling") })
+
+	c := &comparison{
+		serviceName: "sampling",
+		log:         getMismatchLog("sampling", 3),
+		logPercent:  50,
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	for i := 0; i < 10; i++ {
+		c.compare(req, fmt.Sprintf("mirror%d", i), ResponseSummary{StatusCode: http.StatusOK}, ResponseSummary{StatusCode: http.StatusNotFound})
+	}
+
+	mismatches, ok := Mismatches("sampling")
+	require.True(t, ok)
+
+	// Half of the mismatches are logged, and only the 3 most recent ones are kept.
+	var mirrors []string
+	for _, mismatch := range mismatches {
+		mirrors = append(mirrors, mismatch.Mirror)
+	}
+	assert.Equal(t, []string{"mirror8", "mirror6", "mirror4"}, mirrors)
+}
+
+type counterMock struct {
+	mu     sync.Mutex
+	value  float64
+	labels []string
+}
+
+func (c *counterMock) With(labelValues ...string) metrics.Counter {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.labels = labelValues
+	return c
+}
+
+func (c *counterMock) Add(delta float64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.value += delta
+}
+
+func removeMismatchLog(serviceName string) {
+	mismatchLogsMu.Lock()
+	defer mismatchLogsMu.Unlock()
+
+	delete(mismatchLogs, serviceName)
+}
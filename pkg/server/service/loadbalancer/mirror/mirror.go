@@ -14,6 +14,7 @@ import (
 
 	"github.com/traefik/traefik/v2/pkg/log"
 	"github.com/traefik/traefik/v2/pkg/middlewares/accesslog"
+	"github.com/traefik/traefik/v2/pkg/rules"
 	"github.com/traefik/traefik/v2/pkg/safe"
 )
 
@@ -25,9 +26,7 @@ type Mirroring struct {
 	routinePool    *safe.Pool
 
 	maxBodySize int64
-
-	lock  sync.RWMutex
-	total uint64
+	comparison  *comparison
 }
 
 // New returns a new instance of *Mirroring.
@@ -40,28 +39,54 @@ func New(handler http.Handler, pool *safe.Pool, maxBodySize int64) *Mirroring {
 	}
 }
 
-func (m *Mirroring) inc() uint64 {
-	m.lock.Lock()
-	defer m.lock.Unlock()
-	m.total++
-	return m.total
-}
-
 type mirrorHandler struct {
 	http.Handler
+	name    string
 	percent int
+	rule    rules.HTTPMatcher
+	headers map[string]string
 
 	lock  sync.RWMutex
+	total uint64
 	count uint64
 }
 
-func (m *Mirroring) getActiveMirrors() []http.Handler {
-	total := m.inc()
+// MirrorOption is an option of a mirror.
+type MirrorOption func(*mirrorHandler)
+
+// WithName sets the name of the mirror, which identifies it in the comparison results.
+func WithName(name string) MirrorOption {
+	return func(h *mirrorHandler) {
+		h.name = name
+	}
+}
+
+// WithRule restricts the mirroring to the requests matching the rule.
+// The percentage of mirrored requests is then a percentage of the matching requests.
+func WithRule(rule rules.HTTPMatcher) MirrorOption {
+	return func(h *mirrorHandler) {
+		h.rule = rule
+	}
+}
 
-	var mirrors []http.Handler
+// WithHeaders sets headers on the mirrored requests,
+// e.g. so that the mirror can tell the mirrored requests apart.
+func WithHeaders(headers map[string]string) MirrorOption {
+	return func(h *mirrorHandler) {
+		h.headers = headers
+	}
+}
+
+func (m *Mirroring) getActiveMirrors(req *http.Request) []*mirrorHandler {
+	var mirrors []*mirrorHandler
 	for _, handler := range m.mirrorHandlers {
+		if handler.rule != nil && !handler.rule(req) {
+			continue
+		}
+
 		handler.lock.Lock()
-		if handler.count*100 < total*uint64(handler.percent) {
+		handler.total++
+		if handler.count*100 < handler.total*uint64(handler.percent) {
 			handler.count++
 			handler.lock.Unlock()
 			mirrors = append(mirrors, handler)
@@ -73,7 +98,7 @@ func (m *Mirroring) getActiveMirrors() []http.Handler {
 }
 
 func (m *Mirroring) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
-	mirrors := m.getActiveMirrors()
+	mirrors := m.getActiveMirrors(req)
 	if len(mirrors) == 0 {
 		m.handler.ServeHTTP(rw, req)
 		return
@@ -94,6 +119,12 @@ func (m *Mirroring) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 		return
 	}
 
+	var recorder *summaryRecorder
+	if m.comparison != nil {
+		recorder = newSummaryRecorder(rw, m.comparison.headers)
+		rw = recorder
+	}
+
 	m.handler.ServeHTTP(rw, rr.clone(req.Context()))
 
 	select {
@@ -104,10 +135,21 @@ func (m *Mirroring) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 	default:
 	}
 
+	// The upgraded connections are not compared.
+	compare := recorder != nil && !recorder.hijacked
+
+	var mainSummary ResponseSummary
+	if compare {
+		mainSummary = recorder.summary()
+	}
+
 	m.routinePool.GoCtx(func(_ context.Context) {
 		for _, handler := range mirrors {
 			// prepare request, update body from buffer
 			r := rr.clone(req.Context())
+			for name, value := range handler.headers {
+				r.Header.Set(name, value)
+			}
 
 			// In ServeHTTP, we rely on the presence of the accessLog datatable found in the request's context
 			// to know whether we should mutate said datatable (and contribute some fields to the log).
@@ -121,17 +163,31 @@ func (m *Mirroring) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 			// which would trigger a cancellation of the ongoing mirrored requests.
 			// Therefore, we give a new, non-cancellable context  to each of the mirrored calls,
 			// so they can terminate by themselves.
-			handler.ServeHTTP(m.rw, r.WithContext(contextStopPropagation{ctx}))
+			if !compare {
+				handler.ServeHTTP(m.rw, r.WithContext(contextStopPropagation{ctx}))
+				continue
+			}
+
+			mirrorRecorder := newSummaryRecorder(nil, m.comparison.headers)
+			handler.ServeHTTP(mirrorRecorder, r.WithContext(contextStopPropagation{ctx}))
+
+			m.comparison.compare(r, handler.name, mainSummary, mirrorRecorder.summary())
 		}
 	})
 }
 
 // AddMirror adds an httpHandler to mirror to.
-func (m *Mirroring) AddMirror(handler http.Handler, percent int) error {
+func (m *Mirroring) AddMirror(handler http.Handler, percent int, options ...MirrorOption) error {
 	if percent < 0 || percent > 100 {
 		return errors.New("percent must be between 0 and 100")
 	}
-	m.mirrorHandlers = append(m.mirrorHandlers, &mirrorHandler{Handler: handler, percent: percent})
+
+	h := &mirrorHandler{Handler: handler, percent: percent}
+	for _, option := range options {
+		option(h)
+	}
+
+	m.mirrorHandlers = append(m.mirrorHandlers, h)
 	return nil
 }
 
@@ -10,6 +10,8 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traefik/traefik/v2/pkg/rules"
 	"github.com/traefik/traefik/v2/pkg/safe"
 )
 
@@ -73,6 +75,63 @@ func TestMirroringOn10(t *testing.T) {
 	assert.Equal(t, 5, int(val2))
 }
 
+func TestMirroringWithRule(t *testing.T) {
+	var countMirror int32
+	handler := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.WriteHeader(http.StatusOK)
+	})
+	pool := safe.NewPool(context.Background())
+	mirror := New(handler, pool, defaultMaxBodySize)
+
+	matcher, err := rules.NewHTTPMatcher("Method(`GET`) && PathPrefix(`/api`)")
+	require.NoError(t, err)
+
+	err = mirror.AddMirror(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, http.MethodGet, req.Method)
+		atomic.AddInt32(&countMirror, 1)
+	}), 50, WithRule(matcher))
+	assert.NoError(t, err)
+
+	for i := 0; i < 100; i++ {
+		mirror.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/foo", nil))
+		mirror.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/api/foo", nil))
+		mirror.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/foo", nil))
+	}
+
+	pool.Stop()
+
+	// The percentage applies to the matching requests.
+	val := atomic.LoadInt32(&countMirror)
+	assert.Equal(t, 50, int(val))
+}
+
+func TestMirroringWithHeaders(t *testing.T) {
+	var countMirror int32
+	handler := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		assert.Empty(t, req.Header.Get("X-Mirrored"))
+		rw.WriteHeader(http.StatusOK)
+	})
+	pool := safe.NewPool(context.Background())
+	mirror := New(handler, pool, defaultMaxBodySize)
+
+	err := mirror.AddMirror(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, "true", req.Header.Get("X-Mirrored"))
+		assert.Equal(t, "bar", req.Header.Get("X-Foo"))
+		atomic.AddInt32(&countMirror, 1)
+	}), 100, WithHeaders(map[string]string{"X-Mirrored": "true"}))
+	assert.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("X-Foo", "bar")
+
+	mirror.ServeHTTP(httptest.NewRecorder(), req)
+
+	pool.Stop()
+
+	val := atomic.LoadInt32(&countMirror)
+	assert.Equal(t, 1, int(val))
+}
+
 func TestInvalidPercent(t *testing.T) {
 	mirror := New(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {}), safe.NewPool(context.Background()), defaultMaxBodySize)
 	err := mirror.AddMirror(nil, -1)
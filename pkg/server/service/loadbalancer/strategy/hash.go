@@ -0,0 +1,149 @@
+package strategy
+
+import (
+	"hash/fnv"
+	"net/http"
+	"sort"
+	"strconv"
+
+	"github.com/traefik/traefik/v2/pkg/config/dynamic"
+	"github.com/traefik/traefik/v2/pkg/ip"
+)
+
+// replicasPerWeight is the number of points a server owns on the ring, per unit of weight.
+// The more points, the more even the distribution of the keys between the servers.
+const replicasPerWeight = 100
+
+// maxRingPoints is the maximum number of points on the ring, whatever the weights of the servers,
+// beyond which the points of each server are scaled down proportionally to its weight.
+const maxRingPoints = 100000
+
+type ringPoint struct {
+	hash   uint64
+	server *server
+}
+
+// hash selects the server owning the first point following the hash of the request key on a ring.
+// When a server is added or removed, only the keys of the ring sections it gains or loses are remapped.
+type hash struct {
+	policy     dynamic.HashPolicy
+	ipStrategy ip.Strategy
+
+	ring []ringPoint
+}
+
+func newHash(policy *dynamic.HashPolicy) (*hash, error) {
+	if policy == nil {
+		policy = &dynamic.HashPolicy{}
+	}
+
+	ipStrategy, err := policy.IPStrategy.Get()
+	if err != nil {
+		return nil, err
+	}
+
+	return &hash{policy: *policy, ipStrategy: ipStrategy}, nil
+}
+
+func (h *hash) updateServers(servers []*server) {
+	var totalWeight int64
+	for _, srv := range servers {
+		if srv.weight > 0 {
+			totalWeight += int64(srv.weight)
+		}
+	}
+
+	replicas := float64(replicasPerWeight)
+	if totalWeight*replicasPerWeight > maxRingPoints {
+		replicas = float64(maxRingPoints) / float64(totalWeight)
+	}
+
+	var ring []ringPoint
+	for _, srv := range servers {
+		if srv.weight <= 0 {
+			continue
+		}
+
+		// Each server owns at least one point, so that it is never left out of the ring.
+		points := int(float64(srv.weight) * replicas)
+		if points < 1 {
+			points = 1
+		}
+
+		name := srv.url.String()
+		for i := 0; i < points; i++ {
+			ring = append(ring, ringPoint{hash: hashString(name + "-" + strconv.Itoa(i)), server: srv})
+		}
+	}
+
+	sort.Slice(ring, func(i, j int) bool {
+		return ring[i].hash < ring[j].hash
+	})
+
+	h.ring = ring
+}
+
+func (h *hash) pick(req *http.Request, servers []*server) *server {
+	if len(h.ring) == 0 {
+		return servers[0]
+	}
+
+	keyHash := hashString(h.key(req))
+
+	i := sort.Search(len(h.ring), func(i int) bool {
+		return h.ring[i].hash >= keyHash
+	})
+
+	// The ring is walked clockwise until a server of the given ones is found.
+	for j := 0; j < len(h.ring); j++ {
+		candidate := h.ring[(i+j)%len(h.ring)].server
+		for _, srv := range servers {
+			if srv == candidate {
+				return srv
+			}
+		}
+	}
+
+	return servers[0]
+}
+
+// key returns the first non-empty value among the configured header, cookie and query parameter,
+// and the client IP otherwise.
+func (h *hash) key(req *http.Request) string {
+	if h.policy.Header != "" {
+		if value := req.Header.Get(h.policy.Header); value != "" {
+			return value
+		}
+	}
+
+	if h.policy.Cookie != "" {
+		if cookie, err := req.Cookie(h.policy.Cookie); err == nil && cookie.Value != "" {
+			return cookie.Value
+		}
+	}
+
+	if h.policy.Query != "" {
+		if value := req.URL.Query().Get(h.policy.Query); value != "" {
+			return value
+		}
+	}
+
+	return h.ipStrategy.GetIP(req)
+}
+
+// hashString hashes the given string with FNV-1a,
+// and mixes the result so that close inputs are spread over the whole ring.
+func hashString(s string) uint64 {
+	hasher := fnv.New64a()
+	_, _ = hasher.Write([]byte(s))
+
+	// Finalizer of MurmurHash3.
+	k := hasher.Sum64()
+	k ^= k >> 33
+	k *= 0xff51afd7ed558ccd
+	k ^= k >> 33
+	k *= 0xc4ceb9fe1a85ec53
+	k ^= k >> 33
+
+	return k
+}
@@ -0,0 +1,194 @@
+package strategy
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traefik/traefik/v2/pkg/config/dynamic"
+	"github.com/traefik/traefik/v2/pkg/testhelpers"
+	"github.com/vulcand/oxy/roundrobin"
+)
+
+func TestHash_key(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		policy   *dynamic.HashPolicy
+		header   string
+		cookie   string
+		query    string
+		xff      string
+		expected string
+	}{
+		{
+			desc:     "no policy",
+			expected: "10.0.0.1",
+		},
+		{
+			desc:     "header",
+			policy:   &dynamic.HashPolicy{Header: "X-User", Cookie: "user", Query: "user"},
+			header:   "header-value",
+			cookie:   "cookie-value",
+			query:    "query-value",
+			expected: "header-value",
+		},
+		{
+			desc:     "cookie",
+			policy:   &dynamic.HashPolicy{Header: "X-User", Cookie: "user", Query: "user"},
+			cookie:   "cookie-value",
+			query:    "query-value",
+			expected: "cookie-value",
+		},
+		{
+			desc:     "query",
+			policy:   &dynamic.HashPolicy{Header: "X-User", Cookie: "user", Query: "user"},
+			query:    "query-value",
+			expected: "query-value",
+		},
+		{
+			desc:     "fallback on the client IP",
+			policy:   &dynamic.HashPolicy{Header: "X-User", Cookie: "user", Query: "user"},
+			expected: "10.0.0.1",
+		},
+		{
+			desc:     "client IP with depth",
+			policy:   &dynamic.HashPolicy{IPStrategy: &dynamic.IPStrategy{Depth: 1}},
+			xff:      "10.0.0.2, 10.0.0.3",
+			expected: "10.0.0.3",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			h, err := newHash(test.policy)
+			require.NoError(t, err)
+
+			req := httptest.NewRequest(http.MethodGet, "/?user="+test.query, nil)
+			req.RemoteAddr = "10.0.0.1:1234"
+			if test.header != "" {
+				req.Header.Set("X-User", test.header)
+			}
+			if test.cookie != "" {
+				req.AddCookie(&http.Cookie{Name: "user", Value: test.cookie})
+			}
+			if test.xff != "" {
+				req.Header.Set("X-Forwarded-For", test.xff)
+			}
+
+			assert.Equal(t, test.expected, h.key(req))
+		})
+	}
+}
+
+func TestNewHash_invalidIPStrategy(t *testing.T) {
+	_, err := NewHash(http.NotFoundHandler(), &dynamic.HashPolicy{IPStrategy: &dynamic.IPStrategy{ExcludedIPs: []string{"foo"}}}, nil)
+	assert.Error(t, err)
+}
+
+func TestBalancer_hash(t *testing.T) {
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("server", req.URL.Host)
+	})
+
+	balancer, err := NewHash(next, &dynamic.HashPolicy{Header: "X-User"}, nil)
+	require.NoError(t, err)
+
+	for i := 0; i < 4; i++ {
+		require.NoError(t, balancer.UpsertServer(testhelpers.MustParseURL("http://server"+strconv.Itoa(i))))
+	}
+
+	route := func() map[string]string {
+		servers := make(map[string]string)
+		for i := 0; i < 1000; i++ {
+			user := strconv.Itoa(i)
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set("X-User", user)
+
+			recorder := httptest.NewRecorder()
+			balancer.ServeHTTP(recorder, req)
+
+			servers[user] = recorder.Header().Get("server")
+		}
+		return servers
+	}
+
+	before := route()
+
+	// The same key is always sent to the same server.
+	assert.Equal(t, before, route())
+
+	// The keys are spread between all the servers.
+	counts := make(map[string]int)
+	for _, srv := range before {
+		counts[srv]++
+	}
+	require.Len(t, counts, 4)
+	for srv, count := range counts {
+		assert.InDelta(t, 250, count, 100, srv)
+	}
+
+	// Only the keys of the removed server are remapped.
+	require.NoError(t, balancer.RemoveServer(testhelpers.MustParseURL("http://server0")))
+
+	after := route()
+	for user, srv := range before {
+		if srv == "server0" {
+			assert.NotEqual(t, "server0", after[user])
+			continue
+		}
+		assert.Equal(t, srv, after[user], user)
+	}
+
+	// When the server comes back, the keys are mapped to it again.
+	require.NoError(t, balancer.UpsertServer(testhelpers.MustParseURL("http://server0")))
+	assert.Equal(t, before, route())
+}
+
+func TestBalancer_hashWeight(t *testing.T) {
+	balancer, err := NewHash(http.NotFoundHandler(), nil, nil)
+	require.NoError(t, err)
+
+	require.NoError(t, balancer.UpsertServer(testhelpers.MustParseURL("http://light")))
+	require.NoError(t, balancer.UpsertServer(testhelpers.MustParseURL("http://heavy"), roundrobin.Weight(3)))
+
+	h := balancer.picker.(*hash)
+	assert.Len(t, h.ring, 4*replicasPerWeight)
+
+	counts := make(map[string]int)
+	for i := 0; i < 1000; i++ {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.RemoteAddr = "10.0." + strconv.Itoa(i/256) + "." + strconv.Itoa(i%256) + ":1234"
+
+		counts[h.pick(req, balancer.servers).url.Host]++
+	}
+
+	assert.InDelta(t, 750, counts["heavy"], 100)
+	assert.InDelta(t, 250, counts["light"], 100)
+}
+
+func TestBalancer_hashMaxRingPoints(t *testing.T) {
+	balancer, err := NewHash(http.NotFoundHandler(), nil, nil)
+	require.NoError(t, err)
+
+	require.NoError(t, balancer.UpsertServer(testhelpers.MustParseURL("http://light")))
+	require.NoError(t, balancer.UpsertServer(testhelpers.MustParseURL("http://heavy"), roundrobin.Weight(9999)))
+
+	h := balancer.picker.(*hash)
+	assert.Len(t, h.ring, maxRingPoints)
+
+	counts := make(map[string]int)
+	for _, point := range h.ring {
+		counts[point.server.url.Host]++
+	}
+
+	// The points are scaled down, keeping the proportion of the weights.
+	assert.Equal(t, 10, counts["light"])
+	assert.Equal(t, 99990, counts["heavy"])
+}
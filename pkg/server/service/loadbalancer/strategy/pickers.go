@@ -0,0 +1,73 @@
+package strategy
+
+import (
+	"math/rand"
+	"net/http"
+)
+
+// leastConn selects the server with the fewest in-flight requests relatively to its weight.
+// Ties are broken in a round-robin fashion.
+type leastConn struct {
+	index int
+}
+
+func (l *leastConn) pick(_ *http.Request, servers []*server) *server {
+	best := -1
+	for i := 1; i <= len(servers); i++ {
+		candidate := (l.index + i) % len(servers)
+		if best == -1 || servers[candidate].lessLoadedThan(servers[best]) {
+			best = candidate
+		}
+	}
+
+	l.index = best
+	return servers[best]
+}
+
+// p2c selects two servers at random, and keeps the least loaded one (power of two random choices).
+type p2c struct {
+	rand *rand.Rand
+}
+
+func (p *p2c) pick(_ *http.Request, servers []*server) *server {
+	if len(servers) == 1 {
+		return servers[0]
+	}
+
+	first := p.rand.Intn(len(servers))
+	second := p.rand.Intn(len(servers) - 1)
+	if second >= first {
+		second++
+	}
+
+	if servers[second].lessLoadedThan(servers[first]) {
+		return servers[second]
+	}
+	return servers[first]
+}
+
+// random selects a server at random, proportionally to its weight.
+type random struct {
+	rand *rand.Rand
+}
+
+func (r *random) pick(_ *http.Request, servers []*server) *server {
+	var total int
+	for _, srv := range servers {
+		total += srv.weight
+	}
+
+	if total <= 0 {
+		return servers[r.rand.Intn(len(servers))]
+	}
+
+	n := r.rand.Intn(total)
+	for _, srv := range servers {
+		n -= srv.weight
+		if n < 0 {
+			return srv
+		}
+	}
+
+	return servers[len(servers)-1]
+}
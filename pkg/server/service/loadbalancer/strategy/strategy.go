@@ -0,0 +1,268 @@
+package strategy
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/traefik/traefik/v2/pkg/config/dynamic"
+	"github.com/traefik/traefik/v2/pkg/log"
+	"github.com/traefik/traefik/v2/pkg/middlewares/retry"
+	"github.com/vulcand/oxy/roundrobin"
+	"github.com/vulcand/oxy/utils"
+)
+
+type server struct {
+	url    *url.URL
+	weight int
+	// inFlight is the number of requests currently handled by the server.
+	// It must be accessed atomically.
+	inFlight int64
+}
+
+// lessLoadedThan compares the number of in-flight requests of the servers, relatively to their weight.
+// It is done by cross-multiplication, to avoid rounding issues.
+// A server without a positive weight is never less loaded than another one,
+// so that it does not win the ties with its null load.
+func (s *server) lessLoadedThan(other *server) bool {
+	if s.weight <= 0 {
+		return false
+	}
+	if other.weight <= 0 {
+		return true
+	}
+
+	return atomic.LoadInt64(&s.inFlight)*int64(other.weight) < atomic.LoadInt64(&other.inFlight)*int64(s.weight)
+}
+
+// picker selects the server which should handle the next request.
+// It is always called with at least one server, and under the Balancer lock.
+// The servers can be a subset of the servers of the Balancer, when a request is retried.
+type picker interface {
+	pick(req *http.Request, servers []*server) *server
+}
+
+// serversObserver is implemented by the pickers which need to know when the list of servers changes.
+// It is called under the Balancer lock.
+type serversObserver interface {
+	updateServers(servers []*server)
+}
+
+// Balancer is a load balancer which forwards each request to the server selected by a strategy.
+type Balancer struct {
+	next          http.Handler
+	picker        picker
+	stickySession *roundrobin.StickySession
+
+	// registry keeps track of the servers and their options,
+	// as the roundrobin.ServerOption can only be applied on a roundrobin.RoundRobin.
+	registry *roundrobin.RoundRobin
+
+	mutex   sync.Mutex
+	servers []*server
+}
+
+// New creates a new Balancer using the given strategy.
+// The stickySession is optional.
+// The hash strategy must be created with NewHash.
+func New(next http.Handler, strategy string, stickySession *roundrobin.StickySession) (*Balancer, error) {
+	rnd := rand.New(rand.NewSource(time.Now().UnixNano()))
+
+	var p picker
+	switch strategy {
+	case dynamic.BalancerStrategyLeastConn:
+		p = &leastConn{index: -1}
+	case dynamic.BalancerStrategyP2C:
+		p = &p2c{rand: rnd}
+	case dynamic.BalancerStrategyRandom:
+		p = &random{rand: rnd}
+	default:
+		return nil, fmt.Errorf("unknown load-balancing strategy: %q", strategy)
+	}
+
+	return newBalancer(next, p, stickySession)
+}
+
+// NewHash creates a new Balancer which selects the servers with a consistent hash
+// of the request key defined by the given policy.
+// The stickySession is optional.
+func NewHash(next http.Handler, policy *dynamic.HashPolicy, stickySession *roundrobin.StickySession) (*Balancer, error) {
+	p, err := newHash(policy)
+	if err != nil {
+		return nil, err
+	}
+
+	return newBalancer(next, p, stickySession)
+}
+
+func newBalancer(next http.Handler, p picker, stickySession *roundrobin.StickySession) (*Balancer, error) {
+	registry, err := roundrobin.New(next)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Balancer{
+		next:          next,
+		picker:        p,
+		stickySession: stickySession,
+		registry:      registry,
+	}, nil
+}
+
+// ServeHTTP forwards the request to the selected server.
+func (b *Balancer) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	// make shallow copy of request before changing anything to avoid side effects
+	newReq := *req
+
+	// When the request is retried, the servers which handled the previous attempts are avoided.
+	attempts := retry.AttemptsFromContext(req.Context())
+
+	var srv *server
+	if b.stickySession != nil {
+		cookieURL, present, err := b.stickySession.GetBackend(&newReq, b.Servers())
+		if err != nil {
+			log.WithoutContext().Warnf("Error while using server from cookie: %v", err)
+		}
+
+		if present && !attempts.Tried(cookieURL) {
+			srv = b.findServer(cookieURL)
+		}
+	}
+
+	if srv == nil {
+		var err error
+		srv, err = b.nextServer(&newReq, attempts)
+		if err != nil {
+			utils.DefaultHandler.ServeHTTP(w, req, err)
+			return
+		}
+
+		if b.stickySession != nil {
+			b.stickySession.StickBackend(srv.url, &w)
+		}
+	}
+
+	newReq.URL = utils.CopyURL(srv.url)
+
+	atomic.AddInt64(&srv.inFlight, 1)
+	defer atomic.AddInt64(&srv.inFlight, -1)
+
+	b.next.ServeHTTP(w, &newReq)
+}
+
+func (b *Balancer) nextServer(req *http.Request, attempts *retry.Attempts) (*server, error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if len(b.servers) == 0 {
+		return nil, errors.New("no servers in the pool")
+	}
+
+	servers := b.servers
+	if attempts != nil {
+		servers = make([]*server, 0, len(b.servers))
+		for _, srv := range b.servers {
+			if !attempts.Tried(srv.url) {
+				servers = append(servers, srv)
+			}
+		}
+
+		// All the servers have been tried, any of them can be selected again.
+		if len(servers) == 0 {
+			servers = b.servers
+		}
+	}
+
+	return b.picker.pick(req, servers), nil
+}
+
+func (b *Balancer) findServer(u *url.URL) *server {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	_, srv := b.findServerByURL(u)
+	return srv
+}
+
+// findServerByURL must be called under the Balancer lock.
+func (b *Balancer) findServerByURL(u *url.URL) (int, *server) {
+	for i, srv := range b.servers {
+		if sameURL(srv.url, u) {
+			return i, srv
+		}
+	}
+	return -1, nil
+}
+
+// Servers returns the URLs of the servers of the Balancer.
+func (b *Balancer) Servers() []*url.URL {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	urls := make([]*url.URL, len(b.servers))
+	for i, srv := range b.servers {
+		urls[i] = srv.url
+	}
+	return urls
+}
+
+// RemoveServer removes the given server from the Balancer.
+func (b *Balancer) RemoveServer(u *url.URL) error {
+	if err := b.registry.RemoveServer(u); err != nil {
+		return err
+	}
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if i, _ := b.findServerByURL(u); i >= 0 {
+		b.servers = append(b.servers[:i], b.servers[i+1:]...)
+		b.notifyPicker()
+	}
+	return nil
+}
+
+// UpsertServer adds the given server to the Balancer, or updates its options if it is already present.
+func (b *Balancer) UpsertServer(u *url.URL, options ...roundrobin.ServerOption) error {
+	if err := b.registry.UpsertServer(u, options...); err != nil {
+		return err
+	}
+
+	weight, _ := b.registry.ServerWeight(u)
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if _, srv := b.findServerByURL(u); srv != nil {
+		if srv.weight != weight {
+			srv.weight = weight
+			b.notifyPicker()
+		}
+		return nil
+	}
+
+	b.servers = append(b.servers, &server{url: utils.CopyURL(u), weight: weight})
+	b.notifyPicker()
+	return nil
+}
+
+// notifyPicker must be called under the Balancer lock.
+func (b *Balancer) notifyPicker() {
+	if observer, ok := b.picker.(serversObserver); ok {
+		observer.updateServers(b.servers)
+	}
+}
+
+// ServerWeight returns the weight of the given server.
+func (b *Balancer) ServerWeight(u *url.URL) (int, bool) {
+	return b.registry.ServerWeight(u)
+}
+
+func sameURL(a, b *url.URL) bool {
+	return a.Path == b.Path && a.Host == b.Host && a.Scheme == b.Scheme
+}
@@ -0,0 +1,259 @@
+package strategy
+
+import (
+	"context"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httptrace"
+	"net/url"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traefik/traefik/v2/pkg/config/dynamic"
+	"github.com/traefik/traefik/v2/pkg/middlewares/retry"
+	"github.com/traefik/traefik/v2/pkg/testhelpers"
+	"github.com/vulcand/oxy/roundrobin"
+)
+
+func TestNew(t *testing.T) {
+	for _, strategy := range []string{dynamic.BalancerStrategyLeastConn, dynamic.BalancerStrategyP2C, dynamic.BalancerStrategyRandom} {
+		balancer, err := New(http.NotFoundHandler(), strategy, nil)
+		require.NoError(t, err, strategy)
+		assert.NotNil(t, balancer, strategy)
+	}
+
+	_, err := New(http.NotFoundHandler(), "foo", nil)
+	assert.Error(t, err)
+}
+
+func TestBalancer_UpsertRemoveServer(t *testing.T) {
+	balancer, err := New(http.NotFoundHandler(), dynamic.BalancerStrategyLeastConn, nil)
+	require.NoError(t, err)
+
+	first := testhelpers.MustParseURL("http://first")
+	second := testhelpers.MustParseURL("http://second")
+
+	require.NoError(t, balancer.UpsertServer(first))
+	require.NoError(t, balancer.UpsertServer(second, roundrobin.Weight(3)))
+	require.NoError(t, balancer.UpsertServer(second, roundrobin.Weight(2)))
+
+	assert.Equal(t, []*url.URL{first, second}, balancer.Servers())
+
+	weight, ok := balancer.ServerWeight(second)
+	require.True(t, ok)
+	assert.Equal(t, 2, weight)
+
+	require.NoError(t, balancer.RemoveServer(first))
+	assert.Equal(t, []*url.URL{second}, balancer.Servers())
+
+	assert.Error(t, balancer.RemoveServer(first))
+}
+
+func TestBalancer_noServer(t *testing.T) {
+	balancer, err := New(http.NotFoundHandler(), dynamic.BalancerStrategyP2C, nil)
+	require.NoError(t, err)
+
+	recorder := httptest.NewRecorder()
+	balancer.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+}
+
+func TestBalancer_leastConn(t *testing.T) {
+	// The slow server holds its requests until released.
+	release := make(chan struct{})
+	var wg sync.WaitGroup
+
+	calls := map[string]int{}
+	var mu sync.Mutex
+
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		mu.Lock()
+		calls[req.URL.Host]++
+		mu.Unlock()
+
+		if req.URL.Host == "slow" {
+			wg.Done()
+			<-release
+		}
+	})
+
+	balancer, err := New(next, dynamic.BalancerStrategyLeastConn, nil)
+	require.NoError(t, err)
+
+	require.NoError(t, balancer.UpsertServer(testhelpers.MustParseURL("http://slow")))
+	require.NoError(t, balancer.UpsertServer(testhelpers.MustParseURL("http://fast")))
+
+	// The first request goes to the slow server, and stays in flight.
+	wg.Add(1)
+	go balancer.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	wg.Wait()
+
+	for i := 0; i < 10; i++ {
+		balancer.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	}
+
+	close(release)
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	assert.Equal(t, map[string]int{"slow": 1, "fast": 10}, calls)
+}
+
+func TestLeastConn_pick(t *testing.T) {
+	servers := []*server{
+		{url: testhelpers.MustParseURL("http://a"), weight: 1},
+		{url: testhelpers.MustParseURL("http://b"), weight: 1},
+		{url: testhelpers.MustParseURL("http://c"), weight: 2, inFlight: 1},
+	}
+
+	picker := &leastConn{index: -1}
+
+	// Ties are broken in a round-robin fashion.
+	assert.Equal(t, "a", picker.pick(nil, servers).url.Host)
+	assert.Equal(t, "b", picker.pick(nil, servers).url.Host)
+	assert.Equal(t, "a", picker.pick(nil, servers).url.Host)
+
+	servers[0].inFlight = 1
+	servers[1].inFlight = 1
+
+	// c has the lowest load relatively to its weight.
+	assert.Equal(t, "c", picker.pick(nil, servers).url.Host)
+}
+
+func TestLeastConn_pick_zeroWeight(t *testing.T) {
+	servers := []*server{
+		{url: testhelpers.MustParseURL("http://a"), weight: 0},
+		{url: testhelpers.MustParseURL("http://b"), weight: 1, inFlight: 5},
+	}
+
+	picker := &leastConn{index: -1}
+
+	// A server without a positive weight does not win the ties with its null load.
+	assert.Equal(t, "b", picker.pick(nil, servers).url.Host)
+	assert.Equal(t, "b", picker.pick(nil, servers).url.Host)
+
+	// It is only selected when it is the only server left.
+	assert.Equal(t, "a", picker.pick(nil, servers[:1]).url.Host)
+}
+
+func TestP2C_pick(t *testing.T) {
+	servers := []*server{
+		{url: testhelpers.MustParseURL("http://a"), weight: 1, inFlight: 10},
+		{url: testhelpers.MustParseURL("http://b"), weight: 1, inFlight: 10},
+		{url: testhelpers.MustParseURL("http://c"), weight: 1},
+	}
+
+	picker := &p2c{rand: rand.New(rand.NewSource(1))}
+
+	calls := map[string]int{}
+	for i := 0; i < 300; i++ {
+		calls[picker.pick(nil, servers).url.Host]++
+	}
+
+	// The least loaded server is selected every time it is one of the two choices,
+	// that is to say two times out of three.
+	assert.InDelta(t, 200, calls["c"], 30)
+	assert.Equal(t, 300, calls["a"]+calls["b"]+calls["c"])
+
+	assert.Equal(t, "a", picker.pick(nil, servers[:1]).url.Host)
+}
+
+func TestRandom_pick(t *testing.T) {
+	servers := []*server{
+		{url: testhelpers.MustParseURL("http://a"), weight: 1},
+		{url: testhelpers.MustParseURL("http://b"), weight: 3},
+	}
+
+	picker := &random{rand: rand.New(rand.NewSource(1))}
+
+	calls := map[string]int{}
+	for i := 0; i < 400; i++ {
+		calls[picker.pick(nil, servers).url.Host]++
+	}
+
+	assert.InDelta(t, 100, calls["a"], 30)
+	assert.InDelta(t, 300, calls["b"], 30)
+}
+
+func TestBalancer_sticky(t *testing.T) {
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("server", req.URL.Host)
+	})
+
+	balancer, err := New(next, dynamic.BalancerStrategyRandom, roundrobin.NewStickySession("test"))
+	require.NoError(t, err)
+
+	require.NoError(t, balancer.UpsertServer(testhelpers.MustParseURL("http://first")))
+	require.NoError(t, balancer.UpsertServer(testhelpers.MustParseURL("http://second")))
+
+	recorder := httptest.NewRecorder()
+	balancer.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	stuck := recorder.Header().Get("server")
+	cookie := recorder.Header().Get("Set-Cookie")
+	require.NotEmpty(t, cookie)
+
+	for i := 0; i < 10; i++ {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("Cookie", cookie)
+
+		recorder = httptest.NewRecorder()
+		balancer.ServeHTTP(recorder, req)
+
+		assert.Equal(t, stuck, recorder.Header().Get("server"))
+		assert.Empty(t, recorder.Header().Get("Set-Cookie"))
+	}
+
+	// Once the server is removed, the cookie is ignored.
+	require.NoError(t, balancer.RemoveServer(testhelpers.MustParseURL("http://"+stuck)))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Cookie", cookie)
+
+	recorder = httptest.NewRecorder()
+	balancer.ServeHTTP(recorder, req)
+
+	assert.NotEqual(t, stuck, recorder.Header().Get("server"))
+	assert.NotEmpty(t, recorder.Header().Get("Set-Cookie"))
+}
+
+func TestBalancer_retry(t *testing.T) {
+	var hosts []string
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		hosts = append(hosts, req.URL.Host)
+
+		// The request is sent to the server.
+		httptrace.ContextClientTrace(req.Context()).WroteHeaders()
+
+		if len(hosts) == 1 {
+			rw.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		rw.WriteHeader(http.StatusOK)
+	})
+
+	// The hash strategy always selects the same server for the same request, unless it is retried.
+	balancer, err := NewHash(retry.TrackServers(next), &dynamic.HashPolicy{Header: "X-Key"}, nil)
+	require.NoError(t, err)
+
+	require.NoError(t, balancer.UpsertServer(testhelpers.MustParseURL("http://first")))
+	require.NoError(t, balancer.UpsertServer(testhelpers.MustParseURL("http://second")))
+
+	handler, err := retry.New(context.Background(), balancer, dynamic.Retry{Attempts: 2, Status: []string{"502"}}, retry.Listeners{}, "retry")
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("X-Key", "foo")
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, req)
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	require.Len(t, hosts, 2)
+	assert.NotEqual(t, hosts[0], hosts[1])
+}
@@ -14,6 +14,7 @@ import (
 	ptypes "github.com/traefik/paerser/types"
 	"github.com/traefik/traefik/v2/pkg/config/dynamic"
 	"github.com/traefik/traefik/v2/pkg/log"
+	"github.com/traefik/traefik/v2/pkg/middlewares/customerrors"
 )
 
 // StatusClientClosedRequest non-standard HTTP status code for client disconnection.
@@ -100,6 +101,7 @@ func buildProxy(passHostHeader *bool, responseForwarding *dynamic.ResponseForwar
 			}
 
 			log.Debugf("'%d %s' caused by: %v", statusCode, statusText(statusCode), err)
+			customerrors.SetGenerated(request, statusCode)
 			w.WriteHeader(statusCode)
 			_, werr := w.Write([]byte(statusText(statusCode)))
 			if werr != nil {
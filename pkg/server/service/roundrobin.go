@@ -0,0 +1,47 @@
+package service
+
+import (
+	"net/http"
+
+	"github.com/traefik/traefik/v2/pkg/middlewares/retry"
+	"github.com/vulcand/oxy/roundrobin"
+)
+
+// retryRoundRobin is a roundrobin.RoundRobin which avoids the servers that handled the previous attempts of a retried request.
+type retryRoundRobin struct {
+	*roundrobin.RoundRobin
+	next          http.Handler
+	stickySession *roundrobin.StickySession
+}
+
+func (r *retryRoundRobin) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	attempts := retry.AttemptsFromContext(req.Context())
+
+	var serverCount int
+	if attempts.Len() > 0 {
+		serverCount = len(r.Servers())
+	}
+
+	for i := 0; i < serverCount; i++ {
+		u, err := r.NextServer()
+		if err != nil {
+			break
+		}
+
+		if attempts.Tried(u) {
+			continue
+		}
+
+		if r.stickySession != nil {
+			r.stickySession.StickBackend(u, &w)
+		}
+
+		// make shallow copy of request before changing anything to avoid side effects
+		newReq := *req
+		newReq.URL = u
+		r.next.ServeHTTP(w, &newReq)
+		return
+	}
+
+	r.RoundRobin.ServeHTTP(w, req)
+}
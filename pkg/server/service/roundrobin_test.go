@@ -0,0 +1,51 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httptrace"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traefik/traefik/v2/pkg/config/dynamic"
+	"github.com/traefik/traefik/v2/pkg/middlewares/retry"
+	"github.com/traefik/traefik/v2/pkg/testhelpers"
+	"github.com/vulcand/oxy/roundrobin"
+)
+
+func TestRetryRoundRobin(t *testing.T) {
+	var hosts []string
+	next := retry.TrackServers(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		hosts = append(hosts, req.URL.Host)
+
+		// The request is sent to the server.
+		httptrace.ContextClientTrace(req.Context()).WroteHeaders()
+
+		if req.URL.Host == "first" {
+			rw.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		rw.WriteHeader(http.StatusOK)
+	}))
+
+	rr, err := roundrobin.New(next)
+	require.NoError(t, err)
+
+	// The first server gets three times more requests than the second one,
+	// so it would be selected again by the round robin.
+	require.NoError(t, rr.UpsertServer(testhelpers.MustParseURL("http://first"), roundrobin.Weight(3)))
+	require.NoError(t, rr.UpsertServer(testhelpers.MustParseURL("http://second")))
+
+	balancer := &retryRoundRobin{RoundRobin: rr, next: next}
+
+	handler, err := retry.New(context.Background(), balancer, dynamic.Retry{Attempts: 3, Status: []string{"502"}}, retry.Listeners{}, "retry")
+	require.NoError(t, err)
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, []string{"first", "second"}, hosts)
+}
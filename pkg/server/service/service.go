@@ -11,6 +11,7 @@ import (
 	"time"
 
 	"github.com/containous/alice"
+	gokitmetrics "github.com/go-kit/kit/metrics"
 	"github.com/traefik/traefik/v2/pkg/config/dynamic"
 	"github.com/traefik/traefik/v2/pkg/config/runtime"
 	"github.com/traefik/traefik/v2/pkg/healthcheck"
@@ -20,10 +21,13 @@ import (
 	"github.com/traefik/traefik/v2/pkg/middlewares/emptybackendhandler"
 	metricsMiddle "github.com/traefik/traefik/v2/pkg/middlewares/metrics"
 	"github.com/traefik/traefik/v2/pkg/middlewares/pipelining"
+	"github.com/traefik/traefik/v2/pkg/middlewares/retry"
+	"github.com/traefik/traefik/v2/pkg/rules"
 	"github.com/traefik/traefik/v2/pkg/safe"
 	"github.com/traefik/traefik/v2/pkg/server/cookie"
 	"github.com/traefik/traefik/v2/pkg/server/provider"
 	"github.com/traefik/traefik/v2/pkg/server/service/loadbalancer/mirror"
+	"github.com/traefik/traefik/v2/pkg/server/service/loadbalancer/strategy"
 	"github.com/traefik/traefik/v2/pkg/server/service/loadbalancer/wrr"
 	"github.com/vulcand/oxy/roundrobin"
 )
@@ -110,7 +114,7 @@ func (m *Manager) BuildHTTP(rootCtx context.Context, serviceName string) (http.H
 		}
 	case conf.Mirroring != nil:
 		var err error
-		lb, err = m.getMirrorServiceHandler(ctx, conf.Mirroring)
+		lb, err = m.getMirrorServiceHandler(ctx, serviceName, conf.Mirroring)
 		if err != nil {
 			conf.AddError(err, true)
 			return nil, err
@@ -124,7 +128,7 @@ func (m *Manager) BuildHTTP(rootCtx context.Context, serviceName string) (http.H
 	return lb, nil
 }
 
-func (m *Manager) getMirrorServiceHandler(ctx context.Context, config *dynamic.Mirroring) (http.Handler, error) {
+func (m *Manager) getMirrorServiceHandler(ctx context.Context, serviceName string, config *dynamic.Mirroring) (http.Handler, error) {
 	serviceHandler, err := m.BuildHTTP(ctx, config.Service)
 	if err != nil {
 		return nil, err
@@ -135,13 +139,33 @@ func (m *Manager) getMirrorServiceHandler(ctx context.Context, config *dynamic.M
 		maxBodySize = *config.MaxBodySize
 	}
 	handler := mirror.New(serviceHandler, m.routinePool, maxBodySize)
+	if config.Comparison != nil {
+		var mismatches gokitmetrics.Counter
+		if m.metricsRegistry != nil && m.metricsRegistry.IsSvcEnabled() {
+			mismatches = m.metricsRegistry.ServiceMirrorMismatchesCounter()
+		}
+
+		if err := handler.EnableComparison(serviceName, *config.Comparison, mismatches); err != nil {
+			return nil, err
+		}
+	}
+
 	for _, mirrorConfig := range config.Mirrors {
 		mirrorHandler, err := m.BuildHTTP(ctx, mirrorConfig.Name)
 		if err != nil {
 			return nil, err
 		}
 
-		err = handler.AddMirror(mirrorHandler, mirrorConfig.Percent)
+		options := []mirror.MirrorOption{mirror.WithName(mirrorConfig.Name), mirror.WithHeaders(mirrorConfig.Headers)}
+		if mirrorConfig.Rule != "" {
+			matcher, err := rules.NewHTTPMatcher(mirrorConfig.Rule)
+			if err != nil {
+				return nil, fmt.Errorf("invalid rule for mirror %s: %w", mirrorConfig.Name, err)
+			}
+			options = append(options, mirror.WithRule(matcher))
+		}
+
+		err = handler.AddMirror(mirrorHandler, mirrorConfig.Percent, options...)
 		if err != nil {
 			return nil, err
 		}
@@ -200,11 +224,28 @@ func (m *Manager) getLoadBalancerServiceHandler(ctx context.Context, serviceName
 		return nil, err
 	}
 
-	balancer, err := m.getLoadBalancer(ctx, serviceName, service, handler)
+	var passiveHealthCheck *healthcheck.PassiveHealthCheck
+	if phc := service.PassiveHealthCheck; phc != nil {
+		opts := healthcheck.PassiveOptions{
+			MaxFailures:     phc.MaxFailures,
+			EjectionTime:    time.Duration(phc.EjectionTime),
+			MaxEjectionTime: time.Duration(phc.MaxEjectionTime),
+		}
+		log.FromContext(ctx).Debugf("Setting up passive healthcheck for service %s with %s", serviceName, opts)
+
+		passiveHealthCheck = healthcheck.NewPassiveHealthCheck(handler, opts, serviceName, m.configs[serviceName])
+		handler = passiveHealthCheck
+	}
+
+	balancer, err := m.getLoadBalancer(ctx, serviceName, service, retry.TrackServers(handler))
 	if err != nil {
 		return nil, err
 	}
 
+	if passiveHealthCheck != nil {
+		passiveHealthCheck.SetBalancer(balancer)
+	}
+
 	// TODO rename and checks
 	m.balancers[serviceName] = append(m.balancers[serviceName], balancer)
 
@@ -298,11 +339,9 @@ func (m *Manager) getLoadBalancer(ctx context.Context, serviceName string, servi
 	logger := log.FromContext(ctx)
 	logger.Debug("Creating load-balancer")
 
-	var options []roundrobin.LBOption
-
-	var cookieName string
+	var stickySession *roundrobin.StickySession
 	if service.Sticky != nil && service.Sticky.Cookie != nil {
-		cookieName = cookie.GetName(service.Sticky.Cookie.Name, serviceName)
+		cookieName := cookie.GetName(service.Sticky.Cookie.Name, serviceName)
 
 		opts := roundrobin.CookieOptions{
 			HTTPOnly: service.Sticky.Cookie.HTTPOnly,
@@ -310,14 +349,40 @@ func (m *Manager) getLoadBalancer(ctx context.Context, serviceName string, servi
 			SameSite: convertSameSite(service.Sticky.Cookie.SameSite),
 		}
 
-		options = append(options, roundrobin.EnableStickySession(roundrobin.NewStickySessionWithOptions(cookieName, opts)))
+		stickySession = roundrobin.NewStickySessionWithOptions(cookieName, opts)
 
 		logger.Debugf("Sticky session cookie name: %v", cookieName)
 	}
 
-	lb, err := roundrobin.New(fwd, options...)
-	if err != nil {
-		return nil, err
+	var lb healthcheck.BalancerHandler
+	switch service.Strategy {
+	case "", dynamic.BalancerStrategyWRR:
+		var options []roundrobin.LBOption
+		if stickySession != nil {
+			options = append(options, roundrobin.EnableStickySession(stickySession))
+		}
+
+		rr, err := roundrobin.New(fwd, options...)
+		if err != nil {
+			return nil, err
+		}
+		lb = &retryRoundRobin{RoundRobin: rr, next: fwd, stickySession: stickySession}
+	case dynamic.BalancerStrategyHash:
+		logger.Debugf("Load-balancing strategy: %s", service.Strategy)
+
+		balancer, err := strategy.NewHash(fwd, service.Hash, stickySession)
+		if err != nil {
+			return nil, err
+		}
+		lb = balancer
+	default:
+		logger.Debugf("Load-balancing strategy: %s", service.Strategy)
+
+		balancer, err := strategy.New(fwd, service.Strategy, stickySession)
+		if err != nil {
+			return nil, err
+		}
+		lb = balancer
 	}
 
 	lbsu := healthcheck.NewLBStatusUpdater(lb, m.configs[serviceName])
@@ -337,9 +402,19 @@ func (m *Manager) upsertServers(ctx context.Context, lb healthcheck.BalancerHand
 			return fmt.Errorf("error parsing server URL %s: %w", srv.URL, err)
 		}
 
-		logger.WithField(log.ServerName, name).Debugf("Creating server %d %s", name, u)
+		weight := 1
+		if srv.Weight != nil {
+			weight = *srv.Weight
+		}
+
+		if weight <= 0 {
+			logger.WithField(log.ServerName, name).Debugf("Ignoring server %d %s with non-positive weight %d", name, u, weight)
+			continue
+		}
+
+		logger.WithField(log.ServerName, name).Debugf("Creating server %d %s with weight %d", name, u, weight)
 
-		if err := lb.UpsertServer(u, roundrobin.Weight(1)); err != nil {
+		if err := lb.UpsertServer(u, roundrobin.Weight(weight)); err != nil {
 			return fmt.Errorf("error adding server %s to load balancer: %w", srv.URL, err)
 		}
 
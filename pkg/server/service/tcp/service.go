@@ -7,21 +7,36 @@ import (
 	"net"
 	"time"
 
+	"github.com/traefik/traefik/v2/pkg/config/dynamic"
 	"github.com/traefik/traefik/v2/pkg/config/runtime"
+	"github.com/traefik/traefik/v2/pkg/healthcheck"
 	"github.com/traefik/traefik/v2/pkg/log"
+	"github.com/traefik/traefik/v2/pkg/metrics"
 	"github.com/traefik/traefik/v2/pkg/server/provider"
 	"github.com/traefik/traefik/v2/pkg/tcp"
 )
 
+const (
+	defaultHealthCheckInterval = 30 * time.Second
+	defaultHealthCheckTimeout  = 5 * time.Second
+)
+
 // Manager is the TCPHandlers factory.
 type Manager struct {
-	configs map[string]*runtime.TCPServiceInfo
+	configs         map[string]*runtime.TCPServiceInfo
+	metricsRegistry metrics.Registry
+	// balancers is the map of all TCPBalancers, keyed by service name.
+	// There is one TCPBalancer per service handler, and there is one service handler per reference to a service,
+	// which is why there is not just one TCPBalancer per service name.
+	balancers map[string]healthcheck.TCPBalancers
 }
 
 // NewManager creates a new manager.
-func NewManager(conf *runtime.Configuration) *Manager {
+func NewManager(conf *runtime.Configuration, metricsRegistry metrics.Registry) *Manager {
 	return &Manager{
-		configs: conf.TCPServices,
+		configs:         conf.TCPServices,
+		metricsRegistry: metricsRegistry,
+		balancers:       make(map[string]healthcheck.TCPBalancers),
 	}
 }
 
@@ -46,6 +61,7 @@ func (m *Manager) BuildTCP(rootCtx context.Context, serviceName string) (tcp.Han
 	switch {
 	case conf.LoadBalancer != nil:
 		loadBalancer := tcp.NewWRRLoadBalancer()
+		lbsu := healthcheck.NewTCPLBStatusUpdater(loadBalancer, conf)
 
 		if conf.LoadBalancer.TerminationDelay == nil {
 			defaultTerminationDelay := 100
@@ -65,10 +81,19 @@ func (m *Manager) BuildTCP(rootCtx context.Context, serviceName string) (tcp.Han
 				continue
 			}
 
-			loadBalancer.AddServer(handler)
+			loadBalancer.AddAddressServer(server.Address, handler)
+			if err := lbsu.UpsertServer(server.Address); err != nil {
+				logger.Errorf("In service %q server %q: %v", serviceQualifiedName, server.Address, err)
+				continue
+			}
 			logger.WithField(log.ServerName, name).Debugf("Creating TCP server %d at %s", name, server.Address)
 		}
-		return loadBalancer, nil
+
+		if conf.LoadBalancer.HealthCheck != nil {
+			m.balancers[serviceQualifiedName] = append(m.balancers[serviceQualifiedName], lbsu)
+		}
+
+		return lbsu, nil
 	case conf.Weighted != nil:
 		loadBalancer := tcp.NewWRRLoadBalancer()
 		for _, service := range conf.Weighted.Services {
@@ -86,3 +111,51 @@ func (m *Manager) BuildTCP(rootCtx context.Context, serviceName string) (tcp.Han
 		return nil, err
 	}
 }
+
+// LaunchHealthCheck Launches the health checks.
+func (m *Manager) LaunchHealthCheck() {
+	backendConfigs := make(map[string]*healthcheck.TCPBackendConfig)
+
+	for serviceName, balancers := range m.balancers {
+		ctx := log.With(context.Background(), log.Str(log.ServiceName, serviceName))
+
+		hcOpts := buildHealthCheckOptions(ctx, balancers, serviceName, m.configs[serviceName].LoadBalancer.HealthCheck)
+		log.FromContext(ctx).Debugf("Setting up healthcheck for TCP service %s with %s", serviceName, hcOpts)
+
+		backendConfigs[serviceName] = healthcheck.NewTCPBackendConfig(hcOpts, serviceName)
+	}
+
+	healthcheck.GetHealthCheck(m.metricsRegistry).SetTCPBackendsConfiguration(context.Background(), backendConfigs)
+}
+
+func buildHealthCheckOptions(ctx context.Context, lb healthcheck.TCPBalancer, backend string, hc *dynamic.TCPHealthCheck) healthcheck.TCPOptions {
+	logger := log.FromContext(ctx)
+
+	interval := time.Duration(hc.Interval)
+	if interval <= 0 {
+		if interval < 0 {
+			logger.Errorf("Health check interval smaller than zero for TCP service '%s'", backend)
+		}
+		interval = defaultHealthCheckInterval
+	}
+
+	timeout := time.Duration(hc.Timeout)
+	if timeout <= 0 {
+		if timeout < 0 {
+			logger.Errorf("Health check timeout smaller than zero for TCP service '%s'", backend)
+		}
+		timeout = defaultHealthCheckTimeout
+	}
+
+	if timeout >= interval {
+		logger.Warnf("Health check timeout for TCP service '%s' should be lower than the health check interval (%s).", backend, interval)
+	}
+
+	return healthcheck.TCPOptions{
+		Send:     hc.Send,
+		Expect:   hc.Expect,
+		Interval: interval,
+		Timeout:  timeout,
+		LB:       lb,
+	}
+}
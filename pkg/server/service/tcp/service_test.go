@@ -180,7 +180,7 @@ func TestManager_BuildTCP(t *testing.T) {
 
 			manager := NewManager(&runtime.Configuration{
 				TCPServices: test.configs,
-			})
+			}, nil)
 
 			ctx := context.Background()
 			if len(test.providerName) > 0 {
@@ -199,3 +199,38 @@ func TestManager_BuildTCP(t *testing.T) {
 		})
 	}
 }
+
+func TestManager_BuildTCP_healthCheck(t *testing.T) {
+	serviceInfo := &runtime.TCPServiceInfo{
+		TCPService: &dynamic.TCPService{
+			LoadBalancer: &dynamic.TCPServersLoadBalancer{
+				Servers: []dynamic.TCPServer{
+					{Address: "127.0.0.1:8080"},
+					{Address: "127.0.0.2:8080"},
+				},
+				HealthCheck: &dynamic.TCPHealthCheck{},
+			},
+		},
+	}
+
+	manager := NewManager(&runtime.Configuration{
+		TCPServices: map[string]*runtime.TCPServiceInfo{
+			"serviceName@provider-1": serviceInfo,
+		},
+	}, nil)
+
+	ctx := provider.AddInContext(context.Background(), "foobar@provider-1")
+
+	handler, err := manager.BuildTCP(ctx, "serviceName")
+	require.NoError(t, err)
+	require.NotNil(t, handler)
+
+	expectedStatus := map[string]string{
+		"127.0.0.1:8080": "UP",
+		"127.0.0.2:8080": "UP",
+	}
+	assert.Equal(t, expectedStatus, serviceInfo.GetAllStatus())
+
+	require.Len(t, manager.balancers["serviceName@provider-1"], 1)
+	assert.Equal(t, []string{"127.0.0.1:8080", "127.0.0.2:8080"}, manager.balancers["serviceName@provider-1"].Servers())
+}
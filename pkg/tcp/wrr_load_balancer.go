@@ -9,7 +9,9 @@ import (
 
 type server struct {
 	Handler
-	weight int
+	address string
+	weight  int
+	down    bool
 }
 
 // WRRLoadBalancer is a naive RoundRobin load balancer for TCP services.
@@ -38,6 +40,7 @@ func (b *WRRLoadBalancer) ServeTCP(conn WriteCloser) {
 	if err != nil {
 		log.WithoutContext().Errorf("Error during load balancing: %v", err)
 		conn.Close()
+		return
 	}
 	next.ServeTCP(conn)
 }
@@ -57,9 +60,61 @@ func (b *WRRLoadBalancer) AddWeightServer(serverHandler Handler, weight *int) {
 	b.servers = append(b.servers, server{Handler: serverHandler, weight: w})
 }
 
+// AddAddressServer appends a server identified by its address to the existing list.
+// Only servers added this way can be managed through Servers, RemoveServer and UpsertServer.
+func (b *WRRLoadBalancer) AddAddressServer(address string, serverHandler Handler) {
+	b.servers = append(b.servers, server{Handler: serverHandler, address: address, weight: 1})
+}
+
+// Servers returns the addresses of the servers which are currently enabled.
+func (b *WRRLoadBalancer) Servers() []string {
+	b.lock.RLock()
+	defer b.lock.RUnlock()
+
+	var addresses []string
+	for _, s := range b.servers {
+		if s.address != "" && !s.down {
+			addresses = append(addresses, s.address)
+		}
+	}
+	return addresses
+}
+
+// RemoveServer disables the server with the given address,
+// so that it does not receive connections anymore.
+func (b *WRRLoadBalancer) RemoveServer(address string) error {
+	return b.setServerDown(address, true)
+}
+
+// UpsertServer enables again the server with the given address.
+func (b *WRRLoadBalancer) UpsertServer(address string) error {
+	return b.setServerDown(address, false)
+}
+
+func (b *WRRLoadBalancer) setServerDown(address string, down bool) error {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	found := false
+	for i := range b.servers {
+		if b.servers[i].address == address {
+			b.servers[i].down = down
+			found = true
+		}
+	}
+
+	if !found {
+		return fmt.Errorf("server %q not found", address)
+	}
+	return nil
+}
+
 func (b *WRRLoadBalancer) maxWeight() int {
 	max := -1
 	for _, s := range b.servers {
+		if s.down {
+			continue
+		}
 		if s.weight > max {
 			max = s.weight
 		}
@@ -70,6 +125,9 @@ func (b *WRRLoadBalancer) maxWeight() int {
 func (b *WRRLoadBalancer) weightGcd() int {
 	divisor := -1
 	for _, s := range b.servers {
+		if s.down {
+			continue
+		}
 		if divisor == -1 {
 			divisor = s.weight
 		} else {
@@ -94,14 +152,18 @@ func (b *WRRLoadBalancer) next() (Handler, error) {
 		return nil, fmt.Errorf("no servers in the pool")
 	}
 
+	// Maximum weight across all enabled servers
+	max := b.maxWeight()
+	if max == -1 {
+		return nil, fmt.Errorf("no healthy servers in the pool")
+	}
+
 	// The algo below may look messy, but is actually very simple
 	// it calculates the GCD  and subtracts it on every iteration, what interleaves servers
 	// and allows us not to build an iterator every time we readjust weights
 
 	// GCD across all enabled servers
 	gcd := b.weightGcd()
-	// Maximum weight across all enabled servers
-	max := b.maxWeight()
 
 	for {
 		b.index = (b.index + 1) % len(b.servers)
@@ -115,7 +177,7 @@ func (b *WRRLoadBalancer) next() (Handler, error) {
 			}
 		}
 		srv := b.servers[b.index]
-		if srv.weight >= b.currentWeight {
+		if !srv.down && srv.weight >= b.currentWeight {
 			return srv, nil
 		}
 	}
@@ -129,3 +129,37 @@ func TestLoadBalancing(t *testing.T) {
 		})
 	}
 }
+
+func TestLoadBalancingWithDownServers(t *testing.T) {
+	balancer := NewWRRLoadBalancer()
+	for _, server := range []string{"h1", "h2", "h3"} {
+		server := server
+		balancer.AddAddressServer(server, HandlerFunc(func(conn WriteCloser) {
+			_, err := conn.Write([]byte(server))
+			require.NoError(t, err)
+		}))
+	}
+
+	require.NoError(t, balancer.RemoveServer("h2"))
+	require.Error(t, balancer.RemoveServer("unknown"))
+
+	assert.Equal(t, []string{"h1", "h3"}, balancer.Servers())
+
+	conn := &fakeConn{call: make(map[string]int)}
+	for i := 0; i < 4; i++ {
+		balancer.ServeTCP(conn)
+	}
+
+	assert.Equal(t, map[string]int{"h1": 2, "h3": 2}, conn.call)
+
+	require.NoError(t, balancer.UpsertServer("h2"))
+
+	assert.Equal(t, []string{"h1", "h2", "h3"}, balancer.Servers())
+
+	conn = &fakeConn{call: make(map[string]int)}
+	for i := 0; i < 3; i++ {
+		balancer.ServeTCP(conn)
+	}
+
+	assert.Equal(t, map[string]int{"h1": 1, "h2": 1, "h3": 1}, conn.call)
+}
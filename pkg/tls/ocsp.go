@@ -0,0 +1,394 @@
+package tls
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/traefik/traefik/v2/pkg/log"
+	"github.com/traefik/traefik/v2/pkg/safe"
+	"golang.org/x/crypto/ocsp"
+)
+
+const (
+	// ocspRetryInterval is the interval between the attempts to fetch an OCSP response after a failure.
+	ocspRetryInterval = 10 * time.Minute
+
+	// ocspDefaultRefreshInterval is the refresh interval of the OCSP responses without a next update.
+	ocspDefaultRefreshInterval = time.Hour
+
+	// ocspMaxResponseSize is the maximum size of the OCSP responses and issuer certificates.
+	ocspMaxResponseSize = 1 << 20
+)
+
+// OCSPConfig holds the OCSP stapling configuration.
+type OCSPConfig struct {
+	CacheDir string `description:"Directory where the OCSP responses are cached across restarts." json:"cacheDir,omitempty" toml:"cacheDir,omitempty" yaml:"cacheDir,omitempty" export:"true"`
+}
+
+// SetDefaults sets the default values.
+func (o *OCSPConfig) SetDefaults() {
+	o.CacheDir = "ocsp"
+}
+
+// ocspStaple is the OCSP response of a certificate.
+type ocspStaple struct {
+	raw        []byte
+	nextUpdate time.Time
+	refreshAt  time.Time
+}
+
+func (s *ocspStaple) isValid(now time.Time) bool {
+	return s.nextUpdate.IsZero() || now.Before(s.nextUpdate)
+}
+
+// ocspCertificate is a certificate with an OCSP server.
+type ocspCertificate struct {
+	leaf    *x509.Certificate
+	issuer  *x509.Certificate
+	retryAt time.Time
+}
+
+// ocspStapler fetches, refreshes and caches the OCSP responses of the loaded certificates,
+// which are stapled in the handshakes.
+type ocspStapler struct {
+	cacheDir string
+	client   *http.Client
+	updateCh chan struct{}
+
+	mu sync.RWMutex
+	// fingerprints of the loaded certificates.
+	fingerprints map[*tls.Certificate]string
+	certs        map[string]*ocspCertificate
+	staples      map[string]*ocspStaple
+}
+
+func newOCSPStapler(config *OCSPConfig) *ocspStapler {
+	return &ocspStapler{
+		cacheDir:     config.CacheDir,
+		client:       &http.Client{Timeout: 10 * time.Second},
+		updateCh:     make(chan struct{}, 1),
+		fingerprints: make(map[*tls.Certificate]string),
+		certs:        make(map[string]*ocspCertificate),
+		staples:      make(map[string]*ocspStaple),
+	}
+}
+
+// staple returns a copy of the certificate with its OCSP response, if any.
+// It returns the certificate as is when the OCSP stapling is disabled.
+func (s *ocspStapler) staple(cert *tls.Certificate) *tls.Certificate {
+	if s == nil || cert == nil {
+		return cert
+	}
+
+	s.mu.RLock()
+	staple := s.staples[s.fingerprints[cert]]
+	s.mu.RUnlock()
+
+	if staple == nil || !staple.isValid(time.Now()) {
+		return cert
+	}
+
+	stapled := *cert
+	stapled.OCSPStaple = staple.raw
+	return &stapled
+}
+
+// update sets the loaded certificates, whose OCSP responses are fetched in the background.
+func (s *ocspStapler) update(certs []*tls.Certificate) {
+	fingerprints := make(map[*tls.Certificate]string)
+	for _, cert := range certs {
+		if cert == nil || len(cert.Certificate) == 0 {
+			continue
+		}
+
+		sum := sha256.Sum256(cert.Certificate[0])
+		fingerprints[cert] = hex.EncodeToString(sum[:])
+	}
+
+	s.mu.Lock()
+	s.fingerprints = fingerprints
+	s.mu.Unlock()
+
+	select {
+	case s.updateCh <- struct{}{}:
+	default:
+	}
+}
+
+// run refreshes the OCSP responses when the certificates are updated, and before their next update.
+func (s *ocspStapler) run(ctx context.Context) {
+	timer := time.NewTimer(ocspDefaultRefreshInterval)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.updateCh:
+		case <-timer.C:
+		}
+
+		next := s.refresh(ctx)
+
+		if !timer.Stop() {
+			select {
+			case <-timer.C:
+			default:
+			}
+		}
+		timer.Reset(time.Until(next))
+	}
+}
+
+// refresh fetches the missing and expiring OCSP responses of the loaded certificates,
+// and returns the time of the next refresh.
+func (s *ocspStapler) refresh(ctx context.Context) time.Time {
+	logger := log.FromContext(ctx)
+
+	s.mu.RLock()
+	loaded := make(map[string]*tls.Certificate)
+	for cert, fingerprint := range s.fingerprints {
+		loaded[fingerprint] = cert
+	}
+	s.mu.RUnlock()
+
+	now := time.Now()
+	next := now.Add(ocspDefaultRefreshInterval)
+
+	for fingerprint, tlsCert := range loaded {
+		cert, err := s.getCertificate(fingerprint, tlsCert)
+		if err != nil {
+			logger.Debugf("No OCSP stapling for the certificate %s: %v", fingerprint, err)
+			continue
+		}
+		if cert == nil {
+			continue
+		}
+
+		s.mu.RLock()
+		staple := s.staples[fingerprint]
+		s.mu.RUnlock()
+
+		if staple == nil {
+			staple = s.loadStaple(ctx, fingerprint, cert)
+		}
+
+		if staple == nil || !now.Before(staple.refreshAt) {
+			if now.Before(cert.retryAt) {
+				next = minTime(next, cert.retryAt)
+				continue
+			}
+
+			fetched, err := s.fetchStaple(cert)
+			if err != nil {
+				logger.Errorf("Unable to fetch the OCSP response of the certificate for %q: %v", cert.leaf.Subject.CommonName, err)
+				cert.retryAt = now.Add(ocspRetryInterval)
+				next = minTime(next, cert.retryAt)
+			} else {
+				staple = fetched
+				s.saveStaple(ctx, fingerprint, staple)
+			}
+		}
+
+		if staple != nil {
+			s.mu.Lock()
+			s.staples[fingerprint] = staple
+			s.mu.Unlock()
+
+			if staple.refreshAt.After(now) {
+				next = minTime(next, staple.refreshAt)
+			}
+		}
+	}
+
+	// Forget the certificates which are not loaded anymore.
+	s.mu.Lock()
+	for fingerprint := range s.certs {
+		if _, ok := loaded[fingerprint]; !ok {
+			delete(s.certs, fingerprint)
+			delete(s.staples, fingerprint)
+		}
+	}
+	s.mu.Unlock()
+
+	return next
+}
+
+// getCertificate returns the certificate and its issuer, or nil if the certificate has no OCSP server.
+func (s *ocspStapler) getCertificate(fingerprint string, tlsCert *tls.Certificate) (*ocspCertificate, error) {
+	s.mu.RLock()
+	cert, ok := s.certs[fingerprint]
+	s.mu.RUnlock()
+	if ok {
+		return cert, nil
+	}
+
+	leaf := tlsCert.Leaf
+	if leaf == nil {
+		var err error
+		leaf, err = x509.ParseCertificate(tlsCert.Certificate[0])
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if len(leaf.OCSPServer) > 0 {
+		var issuer *x509.Certificate
+		var err error
+		if len(tlsCert.Certificate) > 1 {
+			issuer, err = x509.ParseCertificate(tlsCert.Certificate[1])
+		} else {
+			issuer, err = s.fetchIssuer(leaf)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		cert = &ocspCertificate{leaf: leaf, issuer: issuer}
+	}
+
+	s.mu.Lock()
+	s.certs[fingerprint] = cert
+	s.mu.Unlock()
+
+	return cert, nil
+}
+
+// fetchIssuer fetches the issuer of a certificate without a chain, from its issuing certificate URL.
+func (s *ocspStapler) fetchIssuer(leaf *x509.Certificate) (*x509.Certificate, error) {
+	if len(leaf.IssuingCertificateURL) == 0 {
+		return nil, errors.New("no issuer certificate in the chain")
+	}
+
+	resp, err := s.client.Get(leaf.IssuingCertificateURL[0])
+	if err != nil {
+		return nil, fmt.Errorf("unable to fetch the issuer certificate: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	data, err := ioutil.ReadAll(http.MaxBytesReader(nil, resp.Body, ocspMaxResponseSize))
+	if err != nil {
+		return nil, fmt.Errorf("unable to fetch the issuer certificate: %w", err)
+	}
+
+	return x509.ParseCertificate(data)
+}
+
+func (s *ocspStapler) fetchStaple(cert *ocspCertificate) (*ocspStaple, error) {
+	request, err := ocsp.CreateRequest(cert.leaf, cert.issuer, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := s.client.Post(cert.leaf.OCSPServer[0], "application/ocsp-request", bytes.NewReader(request))
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected OCSP responder status code: %d", resp.StatusCode)
+	}
+
+	raw, err := ioutil.ReadAll(http.MaxBytesReader(nil, resp.Body, ocspMaxResponseSize))
+	if err != nil {
+		return nil, err
+	}
+
+	return parseStaple(raw, cert)
+}
+
+func parseStaple(raw []byte, cert *ocspCertificate) (*ocspStaple, error) {
+	response, err := ocsp.ParseResponseForCert(raw, cert.leaf, cert.issuer)
+	if err != nil {
+		return nil, err
+	}
+
+	if response.Status == ocsp.Revoked {
+		log.WithoutContext().Warnf("The certificate for %q is revoked", cert.leaf.Subject.CommonName)
+	}
+
+	staple := &ocspStaple{
+		raw:        raw,
+		nextUpdate: response.NextUpdate,
+		refreshAt:  time.Now().Add(ocspDefaultRefreshInterval),
+	}
+
+	// The response is refreshed halfway through its validity period.
+	if !response.NextUpdate.IsZero() {
+		staple.refreshAt = response.ThisUpdate.Add(response.NextUpdate.Sub(response.ThisUpdate) / 2)
+	}
+
+	return staple, nil
+}
+
+func (s *ocspStapler) cachePath(fingerprint string) string {
+	return filepath.Join(s.cacheDir, fingerprint+".ocsp")
+}
+
+// loadStaple loads the still valid OCSP response of the certificate from the cache directory.
+func (s *ocspStapler) loadStaple(ctx context.Context, fingerprint string, cert *ocspCertificate) *ocspStaple {
+	if s.cacheDir == "" {
+		return nil
+	}
+
+	raw, err := ioutil.ReadFile(s.cachePath(fingerprint))
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.FromContext(ctx).Errorf("Unable to read the cached OCSP response: %v", err)
+		}
+		return nil
+	}
+
+	staple, err := parseStaple(raw, cert)
+	if err != nil || !staple.isValid(time.Now()) {
+		return nil
+	}
+
+	return staple
+}
+
+func (s *ocspStapler) saveStaple(ctx context.Context, fingerprint string, staple *ocspStaple) {
+	if s.cacheDir == "" {
+		return
+	}
+
+	if err := os.MkdirAll(s.cacheDir, 0o700); err != nil {
+		log.FromContext(ctx).Errorf("Unable to create the OCSP cache directory: %v", err)
+		return
+	}
+
+	if err := ioutil.WriteFile(s.cachePath(fingerprint), staple.raw, 0o600); err != nil {
+		log.FromContext(ctx).Errorf("Unable to cache the OCSP response: %v", err)
+	}
+}
+
+func minTime(a, b time.Time) time.Time {
+	if b.Before(a) {
+		return b
+	}
+	return a
+}
+
+// EnableOCSPStapling staples the OCSP responses of the loaded certificates in the handshakes.
+func (m *Manager) EnableOCSPStapling(config *OCSPConfig, pool *safe.Pool) {
+	stapler := newOCSPStapler(config)
+
+	m.lock.Lock()
+	m.ocsp = stapler
+	m.lock.Unlock()
+
+	pool.GoCtx(stapler.run)
+}
@@ -0,0 +1,221 @@
+package tls
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ocsp"
+)
+
+func TestManager_Get_ocspStapling(t *testing.T) {
+	ca := newTestCA(t)
+
+	var requests int32
+	responder := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		atomic.AddInt32(&requests, 1)
+
+		body, err := ioutil.ReadAll(req.Body)
+		require.NoError(t, err)
+
+		ocspReq, err := ocsp.ParseRequest(body)
+		require.NoError(t, err)
+
+		now := time.Now()
+		resp, err := ocsp.CreateResponse(ca.cert, ca.cert, ocsp.Response{
+			Status:       ocsp.Good,
+			SerialNumber: ocspReq.SerialNumber,
+			ThisUpdate:   now.Add(-time.Hour),
+			NextUpdate:   now.Add(2 * time.Hour),
+		}, ca.key)
+		require.NoError(t, err)
+
+		_, _ = rw.Write(resp)
+	}))
+	defer responder.Close()
+
+	certPEM, keyPEM := ca.issue(t, "stapled.com", responder.URL)
+	certs := []*CertAndStores{{
+		Certificate: Certificate{CertFile: FileOrContent(certPEM), KeyFile: FileOrContent(keyPEM)},
+	}}
+
+	cacheDir := filepath.Join(t.TempDir(), "ocsp")
+
+	tlsManager := NewManager()
+	tlsManager.ocsp = newOCSPStapler(&OCSPConfig{CacheDir: cacheDir})
+	tlsManager.UpdateConfigs(context.Background(), nil, map[string]Options{"default": {}}, certs)
+
+	next := tlsManager.ocsp.refresh(context.Background())
+	assert.Equal(t, int32(1), atomic.LoadInt32(&requests))
+
+	// The response is refreshed halfway through its validity period.
+	assert.WithinDuration(t, time.Now().Add(30*time.Minute), next, time.Minute)
+
+	assertStapled(t, tlsManager, "stapled.com")
+
+	// The response is not fetched again before its refresh.
+	tlsManager.ocsp.refresh(context.Background())
+	assert.Equal(t, int32(1), atomic.LoadInt32(&requests))
+
+	// The response is cached on disk across restarts.
+	responder.Close()
+
+	restarted := NewManager()
+	restarted.ocsp = newOCSPStapler(&OCSPConfig{CacheDir: cacheDir})
+	restarted.UpdateConfigs(context.Background(), nil, map[string]Options{"default": {}}, certs)
+	restarted.ocsp.refresh(context.Background())
+
+	assertStapled(t, restarted, "stapled.com")
+}
+
+func TestManager_Get_ocspStapling_noOCSPServer(t *testing.T) {
+	certs := []*CertAndStores{{
+		Certificate: Certificate{CertFile: localhostCert, KeyFile: localhostKey},
+	}}
+
+	tlsManager := NewManager()
+	tlsManager.ocsp = newOCSPStapler(&OCSPConfig{CacheDir: t.TempDir()})
+	tlsManager.UpdateConfigs(context.Background(), nil, map[string]Options{"default": {}}, certs)
+	tlsManager.ocsp.refresh(context.Background())
+
+	config, err := tlsManager.Get("default", "default")
+	require.NoError(t, err)
+
+	cert, err := config.GetCertificate(&tls.ClientHelloInfo{ServerName: "example.com"})
+	require.NoError(t, err)
+	assert.Empty(t, cert.OCSPStaple)
+}
+
+func assertStapled(t *testing.T, tlsManager *Manager, serverName string) {
+	t.Helper()
+
+	config, err := tlsManager.Get("default", "default")
+	require.NoError(t, err)
+
+	cert, err := config.GetCertificate(&tls.ClientHelloInfo{ServerName: serverName})
+	require.NoError(t, err)
+	require.NotEmpty(t, cert.OCSPStaple)
+
+	resp, err := ocsp.ParseResponse(cert.OCSPStaple, nil)
+	require.NoError(t, err)
+	assert.Equal(t, ocsp.Good, resp.Status)
+}
+
+type testCA struct {
+	cert   *x509.Certificate
+	key    crypto.Signer
+	serial int64
+}
+
+func newTestCA(t *testing.T) *testCA {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(24 * time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+	require.NoError(t, err)
+
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	return &testCA{cert: cert, key: key, serial: 1}
+}
+
+// issue returns the PEM encoded certificate, followed by the CA certificate, and key for the domain.
+func (c *testCA) issue(t *testing.T, domain, ocspServer string) (certPEM, keyPEM []byte) {
+	t.Helper()
+
+	cert, key := c.issueCert(t, domain, ocspServer)
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	certPEM = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw})
+	certPEM = append(certPEM, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: c.cert.Raw})...)
+	keyPEM = pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+
+	return certPEM, keyPEM
+}
+
+// issueCert issues a certificate, with a new serial number, for the servers and the clients.
+func (c *testCA) issueCert(t *testing.T, domain, ocspServer string) (*x509.Certificate, *ecdsa.PrivateKey) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	c.serial++
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(c.serial),
+		Subject:      pkix.Name{CommonName: domain},
+		DNSNames:     []string{domain},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(24 * time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	if ocspServer != "" {
+		template.OCSPServer = []string{ocspServer}
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, c.cert, key.Public(), c.key)
+	require.NoError(t, err)
+
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	return cert, key
+}
+
+// ocspResponder returns an OCSP responder of the CA, answering with the status of the certificates by serial number.
+func (c *testCA) ocspResponder(t *testing.T, statuses map[int64]int) *httptest.Server {
+	t.Helper()
+
+	return httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		body, err := ioutil.ReadAll(req.Body)
+		require.NoError(t, err)
+
+		ocspReq, err := ocsp.ParseRequest(body)
+		require.NoError(t, err)
+
+		now := time.Now()
+		resp, err := ocsp.CreateResponse(c.cert, c.cert, ocsp.Response{
+			Status:       statuses[ocspReq.SerialNumber.Int64()],
+			SerialNumber: ocspReq.SerialNumber,
+			ThisUpdate:   now.Add(-time.Hour),
+			NextUpdate:   now.Add(2 * time.Hour),
+			RevokedAt:    now.Add(-time.Hour),
+		}, c.key)
+		require.NoError(t, err)
+
+		_, _ = rw.Write(resp)
+	}))
+}
@@ -0,0 +1,334 @@
+package tls
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/patrickmn/go-cache"
+	"github.com/traefik/traefik/v2/pkg/log"
+	"golang.org/x/crypto/ocsp"
+)
+
+const (
+	// crlReloadInterval is the minimum interval between the checks of the modification of the CRL files.
+	crlReloadInterval = 10 * time.Second
+
+	// ocspClientTimeout is the timeout of the OCSP requests for the client certificates.
+	ocspClientTimeout = 5 * time.Second
+
+	// ocspFailureTTL is the duration during which an OCSP server is not asked again about a certificate,
+	// after it failed to give its status, so that the handshakes do not all wait for the timeout.
+	ocspFailureTTL = time.Minute
+)
+
+type revocationStatus int
+
+const (
+	revocationUnknown revocationStatus = iota
+	revocationGood
+	revocationRevoked
+)
+
+// crl is a parsed certificate revocation list.
+type crl struct {
+	list    *pkix.CertificateList
+	issuer  []byte
+	revoked map[string]struct{}
+	// verified holds the result of the signature checking by the issuer certificates.
+	verified map[string]error
+}
+
+// revocationChecker checks the revocation of the verified client certificates,
+// with the certificate revocation lists and the OCSP servers.
+type revocationChecker struct {
+	crlFiles []FileOrContent
+	ocsp     bool
+	hardFail bool
+	client   *http.Client
+
+	mu        sync.Mutex
+	crls      []*crl
+	modTimes  map[string]time.Time
+	lastCheck time.Time
+
+	ocspCache *cache.Cache
+}
+
+func newRevocationChecker(config *Revocation) (*revocationChecker, error) {
+	checker := &revocationChecker{
+		crlFiles:  config.CRLFiles,
+		ocsp:      config.OCSP,
+		hardFail:  config.HardFail,
+		client:    &http.Client{Timeout: ocspClientTimeout},
+		ocspCache: cache.New(time.Hour, 10*time.Minute),
+	}
+
+	crls, modTimes, err := loadCRLs(checker.crlFiles)
+	if err != nil {
+		return nil, err
+	}
+
+	checker.crls = crls
+	checker.modTimes = modTimes
+	checker.lastCheck = time.Now()
+
+	return checker, nil
+}
+
+// VerifyPeerCertificate rejects the revoked client certificates,
+// and the client certificates whose revocation status cannot be determined in hard-fail mode.
+// Every certificate of a chain is checked, but the trusted root, so that a revoked intermediate CA is rejected too.
+func (c *revocationChecker) VerifyPeerCertificate(_ [][]byte, verifiedChains [][]*x509.Certificate) error {
+	if len(verifiedChains) == 0 {
+		return nil
+	}
+
+	status := revocationUnknown
+	for _, chain := range verifiedChains {
+		// The revocation of a certificate directly trusted cannot be checked.
+		if len(chain) < 2 {
+			continue
+		}
+
+		switch c.checkChain(chain) {
+		case revocationRevoked:
+			status = revocationRevoked
+		case revocationGood:
+			if status == revocationUnknown {
+				status = revocationGood
+			}
+		}
+	}
+
+	leaf := verifiedChains[0][0]
+
+	switch status {
+	case revocationRevoked:
+		log.WithoutContext().Errorf("Rejecting the revoked client certificate %q (serial number %s)", leaf.Subject.CommonName, leaf.SerialNumber)
+		return fmt.Errorf("client certificate %q is revoked", leaf.Subject.CommonName)
+	case revocationUnknown:
+		if c.hardFail {
+			log.WithoutContext().Errorf("Rejecting the client certificate %q (serial number %s): unable to determine its revocation status", leaf.Subject.CommonName, leaf.SerialNumber)
+			return fmt.Errorf("unable to determine the revocation status of the client certificate %q", leaf.Subject.CommonName)
+		}
+
+		log.WithoutContext().Debugf("Unable to determine the revocation status of the client certificate %q (serial number %s)", leaf.Subject.CommonName, leaf.SerialNumber)
+	}
+
+	return nil
+}
+
+// checkChain returns the status of the chain: revoked if one of its certificates is revoked,
+// and good only if the status of all its certificates is good.
+func (c *revocationChecker) checkChain(chain []*x509.Certificate) revocationStatus {
+	status := revocationGood
+	for i := 0; i < len(chain)-1; i++ {
+		switch c.check(chain[i], chain[i+1]) {
+		case revocationRevoked:
+			return revocationRevoked
+		case revocationUnknown:
+			status = revocationUnknown
+		}
+	}
+
+	return status
+}
+
+func (c *revocationChecker) check(cert, issuer *x509.Certificate) revocationStatus {
+	if status := c.checkCRLs(cert, issuer); status != revocationUnknown {
+		return status
+	}
+
+	if c.ocsp && len(cert.OCSPServer) > 0 {
+		return c.checkOCSP(cert, issuer)
+	}
+
+	return revocationUnknown
+}
+
+// checkCRLs checks the certificate with the valid revocation lists of its issuer.
+func (c *revocationChecker) checkCRLs(cert, issuer *x509.Certificate) revocationStatus {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.reloadCRLs()
+
+	status := revocationUnknown
+	for _, list := range c.crls {
+		if !bytes.Equal(list.issuer, issuer.RawSubject) || list.list.HasExpired(time.Now()) {
+			continue
+		}
+
+		err, ok := list.verified[string(issuer.Raw)]
+		if !ok {
+			err = issuer.CheckCRLSignature(list.list)
+			list.verified[string(issuer.Raw)] = err
+		}
+		if err != nil {
+			continue
+		}
+
+		if _, revoked := list.revoked[cert.SerialNumber.String()]; revoked {
+			return revocationRevoked
+		}
+		status = revocationGood
+	}
+
+	return status
+}
+
+// reloadCRLs reloads the CRL files when they are modified, it must be called under the lock.
+func (c *revocationChecker) reloadCRLs() {
+	if time.Since(c.lastCheck) < crlReloadInterval {
+		return
+	}
+	c.lastCheck = time.Now()
+
+	modified := false
+	for _, file := range c.crlFiles {
+		if !file.IsPath() {
+			continue
+		}
+
+		info, err := os.Stat(file.String())
+		if err != nil || !info.ModTime().Equal(c.modTimes[file.String()]) {
+			modified = true
+			break
+		}
+	}
+
+	if !modified {
+		return
+	}
+
+	crls, modTimes, err := loadCRLs(c.crlFiles)
+	if err != nil {
+		log.WithoutContext().Errorf("Unable to reload the certificate revocation lists, keeping the previous ones: %v", err)
+		return
+	}
+
+	c.crls = crls
+	c.modTimes = modTimes
+}
+
+func loadCRLs(files []FileOrContent) ([]*crl, map[string]time.Time, error) {
+	var crls []*crl
+	modTimes := make(map[string]time.Time)
+
+	for _, file := range files {
+		if file.IsPath() {
+			info, err := os.Stat(file.String())
+			if err != nil {
+				return nil, nil, err
+			}
+			modTimes[file.String()] = info.ModTime()
+		}
+
+		data, err := file.Read()
+		if err != nil {
+			return nil, nil, err
+		}
+
+		list, err := x509.ParseCRL(data)
+		if err != nil {
+			if file.IsPath() {
+				return nil, nil, fmt.Errorf("invalid certificate revocation list in %s: %w", file, err)
+			}
+			return nil, nil, fmt.Errorf("invalid certificate revocation list content: %w", err)
+		}
+
+		issuer, err := asn1.Marshal(list.TBSCertList.Issuer)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		revoked := make(map[string]struct{})
+		for _, cert := range list.TBSCertList.RevokedCertificates {
+			revoked[cert.SerialNumber.String()] = struct{}{}
+		}
+
+		crls = append(crls, &crl{list: list, issuer: issuer, revoked: revoked, verified: make(map[string]error)})
+	}
+
+	return crls, modTimes, nil
+}
+
+// checkOCSP checks the certificate with its OCSP server, the responses being cached until their next update,
+// and the failures during ocspFailureTTL.
+func (c *revocationChecker) checkOCSP(cert, issuer *x509.Certificate) revocationStatus {
+	sum := sha256.Sum256(cert.Raw)
+	key := hex.EncodeToString(sum[:])
+
+	if status, ok := c.ocspCache.Get(key); ok {
+		return status.(revocationStatus)
+	}
+
+	response, err := c.requestOCSP(cert, issuer)
+	if err != nil {
+		log.WithoutContext().Debugf("Unable to check the client certificate %q with its OCSP server: %v", cert.Subject.CommonName, err)
+		c.ocspCache.Set(key, revocationUnknown, ocspFailureTTL)
+		return revocationUnknown
+	}
+
+	var status revocationStatus
+	switch response.Status {
+	case ocsp.Good:
+		status = revocationGood
+	case ocsp.Revoked:
+		status = revocationRevoked
+	default:
+		c.ocspCache.Set(key, revocationUnknown, ocspFailureTTL)
+		return revocationUnknown
+	}
+
+	ttl := cache.DefaultExpiration
+	if !response.NextUpdate.IsZero() {
+		ttl = time.Until(response.NextUpdate)
+		if ttl <= 0 {
+			return status
+		}
+	}
+
+	c.ocspCache.Set(key, status, ttl)
+
+	return status
+}
+
+func (c *revocationChecker) requestOCSP(cert, issuer *x509.Certificate) (*ocsp.Response, error) {
+	request, err := ocsp.CreateRequest(cert, issuer, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.client.Post(cert.OCSPServer[0], "application/ocsp-request", bytes.NewReader(request))
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected OCSP responder status code: %d", resp.StatusCode)
+	}
+
+	raw, err := ioutil.ReadAll(http.MaxBytesReader(nil, resp.Body, ocspMaxResponseSize))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(raw) == 0 {
+		return nil, errors.New("empty OCSP response")
+	}
+
+	return ocsp.ParseResponseForCert(raw, cert, issuer)
+}
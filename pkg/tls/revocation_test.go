@@ -0,0 +1,343 @@
+package tls
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ocsp"
+)
+
+func TestRevocationChecker_VerifyPeerCertificate(t *testing.T) {
+	ca := newTestCA(t)
+	otherCA := newTestCA(t)
+
+	revoked, _ := ca.issueCert(t, "revoked", "")
+	good, _ := ca.issueCert(t, "good", "")
+
+	crl := ca.createCRL(t, time.Now().Add(time.Hour), revoked)
+	expiredCRL := ca.createCRL(t, time.Now().Add(-time.Minute), revoked)
+	otherCRL := otherCA.createCRL(t, time.Now().Add(time.Hour))
+
+	statuses := map[int64]int{}
+	responder := ca.ocspResponder(t, statuses)
+	defer responder.Close()
+
+	ocspRevoked, _ := ca.issueCert(t, "ocsp-revoked", responder.URL)
+	statuses[ocspRevoked.SerialNumber.Int64()] = ocsp.Revoked
+	ocspGood, _ := ca.issueCert(t, "ocsp-good", responder.URL)
+	statuses[ocspGood.SerialNumber.Int64()] = ocsp.Good
+	ocspUnreachable, _ := ca.issueCert(t, "ocsp-unreachable", "http://127.0.0.1:1")
+
+	testCases := []struct {
+		desc          string
+		revocation    *Revocation
+		cert          *x509.Certificate
+		expectedError bool
+	}{
+		{
+			desc:          "revoked by the CRL",
+			revocation:    &Revocation{CRLFiles: []FileOrContent{crl}},
+			cert:          revoked,
+			expectedError: true,
+		},
+		{
+			desc:       "not revoked by the CRL",
+			revocation: &Revocation{CRLFiles: []FileOrContent{crl}, HardFail: true},
+			cert:       good,
+		},
+		{
+			desc:       "expired CRL in soft-fail mode",
+			revocation: &Revocation{CRLFiles: []FileOrContent{expiredCRL}},
+			cert:       revoked,
+		},
+		{
+			desc:          "expired CRL in hard-fail mode",
+			revocation:    &Revocation{CRLFiles: []FileOrContent{expiredCRL}, HardFail: true},
+			cert:          good,
+			expectedError: true,
+		},
+		{
+			desc:       "CRL of another issuer in soft-fail mode",
+			revocation: &Revocation{CRLFiles: []FileOrContent{otherCRL}},
+			cert:       good,
+		},
+		{
+			desc:          "CRL of another issuer in hard-fail mode",
+			revocation:    &Revocation{CRLFiles: []FileOrContent{otherCRL}, HardFail: true},
+			cert:          good,
+			expectedError: true,
+		},
+		{
+			desc:          "revoked by the OCSP server",
+			revocation:    &Revocation{OCSP: true},
+			cert:          ocspRevoked,
+			expectedError: true,
+		},
+		{
+			desc:       "not revoked by the OCSP server",
+			revocation: &Revocation{OCSP: true, HardFail: true},
+			cert:       ocspGood,
+		},
+		{
+			desc:       "OCSP server not checked",
+			revocation: &Revocation{},
+			cert:       ocspRevoked,
+		},
+		{
+			desc:       "unreachable OCSP server in soft-fail mode",
+			revocation: &Revocation{OCSP: true},
+			cert:       ocspUnreachable,
+		},
+		{
+			desc:          "unreachable OCSP server in hard-fail mode",
+			revocation:    &Revocation{OCSP: true, HardFail: true},
+			cert:          ocspUnreachable,
+			expectedError: true,
+		},
+		{
+			desc:       "CRL checked before the OCSP server",
+			revocation: &Revocation{CRLFiles: []FileOrContent{crl}, OCSP: true},
+			cert:       ocspRevoked,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			checker, err := newRevocationChecker(test.revocation)
+			require.NoError(t, err)
+
+			err = checker.VerifyPeerCertificate(nil, [][]*x509.Certificate{{test.cert, ca.cert}})
+			if test.expectedError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestRevocationChecker_VerifyPeerCertificate_intermediate(t *testing.T) {
+	root := newTestCA(t)
+	intermediate := root.issueIntermediate(t)
+	cert, _ := intermediate.issueCert(t, "client", "")
+
+	chains := [][]*x509.Certificate{{cert, intermediate.cert, root.cert}}
+
+	testCases := []struct {
+		desc          string
+		revocation    *Revocation
+		expectedError bool
+	}{
+		{
+			desc: "intermediate revoked by the root CRL",
+			revocation: &Revocation{CRLFiles: []FileOrContent{
+				root.createCRL(t, time.Now().Add(time.Hour), intermediate.cert),
+				intermediate.createCRL(t, time.Now().Add(time.Hour)),
+			}},
+			expectedError: true,
+		},
+		{
+			desc: "no certificate revoked in hard-fail mode",
+			revocation: &Revocation{CRLFiles: []FileOrContent{
+				root.createCRL(t, time.Now().Add(time.Hour)),
+				intermediate.createCRL(t, time.Now().Add(time.Hour)),
+			}, HardFail: true},
+		},
+		{
+			desc: "unknown status of the intermediate in hard-fail mode",
+			revocation: &Revocation{CRLFiles: []FileOrContent{
+				intermediate.createCRL(t, time.Now().Add(time.Hour)),
+			}, HardFail: true},
+			expectedError: true,
+		},
+		{
+			desc: "unknown status of the intermediate in soft-fail mode",
+			revocation: &Revocation{CRLFiles: []FileOrContent{
+				intermediate.createCRL(t, time.Now().Add(time.Hour)),
+			}},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			checker, err := newRevocationChecker(test.revocation)
+			require.NoError(t, err)
+
+			err = checker.VerifyPeerCertificate(nil, chains)
+			if test.expectedError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestRevocationChecker_checkOCSP_failure(t *testing.T) {
+	ca := newTestCA(t)
+
+	var requests int32
+	responder := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		rw.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer responder.Close()
+
+	cert, _ := ca.issueCert(t, "client", responder.URL)
+
+	checker, err := newRevocationChecker(&Revocation{OCSP: true})
+	require.NoError(t, err)
+
+	// The failure is cached, so that the next handshakes do not wait for the OCSP server.
+	for i := 0; i < 3; i++ {
+		require.NoError(t, checker.VerifyPeerCertificate(nil, [][]*x509.Certificate{{cert, ca.cert}}))
+	}
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&requests))
+}
+
+func TestRevocationChecker_reloadCRLs(t *testing.T) {
+	ca := newTestCA(t)
+	cert, _ := ca.issueCert(t, "client", "")
+
+	crlFile := filepath.Join(t.TempDir(), "ca.crl")
+	err := ioutil.WriteFile(crlFile, []byte(ca.createCRL(t, time.Now().Add(time.Hour))), 0o600)
+	require.NoError(t, err)
+
+	checker, err := newRevocationChecker(&Revocation{CRLFiles: []FileOrContent{FileOrContent(crlFile)}, HardFail: true})
+	require.NoError(t, err)
+
+	chains := [][]*x509.Certificate{{cert, ca.cert}}
+	require.NoError(t, checker.VerifyPeerCertificate(nil, chains))
+
+	err = ioutil.WriteFile(crlFile, []byte(ca.createCRL(t, time.Now().Add(time.Hour), cert)), 0o600)
+	require.NoError(t, err)
+	modTime := time.Now().Add(time.Minute)
+	require.NoError(t, os.Chtimes(crlFile, modTime, modTime))
+
+	// The CRL files are not checked again before the reload interval.
+	require.NoError(t, checker.VerifyPeerCertificate(nil, chains))
+
+	checker.lastCheck = time.Now().Add(-crlReloadInterval)
+	assert.Error(t, checker.VerifyPeerCertificate(nil, chains))
+
+	// An invalid CRL file does not replace the previous CRLs.
+	err = ioutil.WriteFile(crlFile, []byte("invalid"), 0o600)
+	require.NoError(t, err)
+	modTime = modTime.Add(time.Minute)
+	require.NoError(t, os.Chtimes(crlFile, modTime, modTime))
+
+	checker.lastCheck = time.Now().Add(-crlReloadInterval)
+	assert.Error(t, checker.VerifyPeerCertificate(nil, chains))
+}
+
+func TestBuildTLSConfig_revocation(t *testing.T) {
+	ca := newTestCA(t)
+	caPEM := FileOrContent(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ca.cert.Raw}))
+
+	testCases := []struct {
+		desc          string
+		clientAuth    ClientAuth
+		expectedError bool
+	}{
+		{
+			desc: "revocation checking",
+			clientAuth: ClientAuth{
+				CAFiles:    []FileOrContent{caPEM},
+				Revocation: &Revocation{CRLFiles: []FileOrContent{ca.createCRL(t, time.Now().Add(time.Hour))}},
+			},
+		},
+		{
+			desc: "revocation checking without CA",
+			clientAuth: ClientAuth{
+				ClientAuthType: "RequireAnyClientCert",
+				Revocation:     &Revocation{OCSP: true},
+			},
+			expectedError: true,
+		},
+		{
+			desc: "invalid CRL",
+			clientAuth: ClientAuth{
+				CAFiles:    []FileOrContent{caPEM},
+				Revocation: &Revocation{CRLFiles: []FileOrContent{"invalid"}},
+			},
+			expectedError: true,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			conf, err := buildTLSConfig(Options{ClientAuth: test.clientAuth})
+			if test.expectedError {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.NotNil(t, conf.VerifyPeerCertificate)
+		})
+	}
+}
+
+// createCRL returns the PEM encoded CRL of the CA, revoking the given certificates.
+func (c *testCA) createCRL(t *testing.T, nextUpdate time.Time, revoked ...*x509.Certificate) FileOrContent {
+	t.Helper()
+
+	var revokedCerts []pkix.RevokedCertificate
+	for _, cert := range revoked {
+		revokedCerts = append(revokedCerts, pkix.RevokedCertificate{SerialNumber: cert.SerialNumber, RevocationTime: time.Now()})
+	}
+
+	der, err := c.cert.CreateCRL(rand.Reader, c.key, revokedCerts, time.Now().Add(-time.Hour), nextUpdate)
+	require.NoError(t, err)
+
+	return FileOrContent(pem.EncodeToMemory(&pem.Block{Type: "X509 CRL", Bytes: der}))
+}
+
+// issueIntermediate issues an intermediate CA certificate.
+func (c *testCA) issueIntermediate(t *testing.T) *testCA {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	c.serial++
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(c.serial),
+		Subject:               pkix.Name{CommonName: "Test Intermediate CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(24 * time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, c.cert, key.Public(), c.key)
+	require.NoError(t, err)
+
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	return &testCA{cert: cert, key: key, serial: 1}
+}
@@ -10,6 +10,22 @@ type ClientAuth struct {
 	// ClientAuthType defines the client authentication type to apply.
 	// The available values are: "NoClientCert", "RequestClientCert", "VerifyClientCertIfGiven" and "RequireAndVerifyClientCert".
 	ClientAuthType string `json:"clientAuthType,omitempty" toml:"clientAuthType,omitempty" yaml:"clientAuthType,omitempty" export:"true"`
+	// Revocation defines the revocation checking of the verified client certificates.
+	Revocation *Revocation `json:"revocation,omitempty" toml:"revocation,omitempty" yaml:"revocation,omitempty" export:"true"`
+}
+
+// +k8s:deepcopy-gen=true
+
+// Revocation defines the revocation checking of the client certificates.
+type Revocation struct {
+	// CRLFiles are the certificate revocation lists, in PEM or DER format, reloaded when they change.
+	CRLFiles []FileOrContent `json:"crlFiles,omitempty" toml:"crlFiles,omitempty" yaml:"crlFiles,omitempty"`
+	// OCSP enables the checking with the OCSP server of the client certificates,
+	// when their status is not given by the revocation lists.
+	OCSP bool `json:"ocsp,omitempty" toml:"ocsp,omitempty" yaml:"ocsp,omitempty" export:"true"`
+	// HardFail rejects the client certificates whose revocation status cannot be determined.
+	// By default, they are accepted.
+	HardFail bool `json:"hardFail,omitempty" toml:"hardFail,omitempty" yaml:"hardFail,omitempty" export:"true"`
 }
 
 // +k8s:deepcopy-gen=true
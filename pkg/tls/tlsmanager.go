@@ -6,6 +6,7 @@ import (
 	"crypto/x509"
 	"errors"
 	"fmt"
+	"net"
 	"sync"
 
 	"github.com/go-acme/lego/v4/challenge/tlsalpn01"
@@ -18,13 +19,21 @@ import (
 // DefaultTLSOptions the default TLS options.
 var DefaultTLSOptions = Options{}
 
+// OnDemandResolver obtains, at handshake time, the certificate of a domain which is not in the certificate stores.
+// It returns a nil certificate when no certificate is obtained for the domain.
+type OnDemandResolver interface {
+	ResolveOnDemand(domain string) (*tls.Certificate, error)
+}
+
 // Manager is the TLS option/store/configuration factory.
 type Manager struct {
-	storesConfig map[string]Store
-	stores       map[string]*CertificateStore
-	configs      map[string]Options
-	certs        []*CertAndStores
-	lock         sync.RWMutex
+	storesConfig      map[string]Store
+	stores            map[string]*CertificateStore
+	configs           map[string]Options
+	certs             []*CertAndStores
+	onDemandResolvers []OnDemandResolver
+	ocsp              *ocspStapler
+	lock              sync.RWMutex
 }
 
 // NewManager creates a new Manager.
@@ -77,6 +86,34 @@ func (m *Manager) UpdateConfigs(ctx context.Context, stores map[string]Store, co
 	for storeName, certs := range storesCertificates {
 		m.getStore(storeName).DynamicCerts.Set(certs)
 	}
+
+	if m.ocsp != nil {
+		m.ocsp.update(m.getAllCertificates())
+	}
+}
+
+// getAllCertificates returns the certificates and the default certificates of the stores.
+func (m *Manager) getAllCertificates() []*tls.Certificate {
+	var certificates []*tls.Certificate
+	for _, store := range m.stores {
+		certificates = append(certificates, store.DefaultCertificate)
+
+		if store.DynamicCerts != nil && store.DynamicCerts.Get() != nil {
+			for _, cert := range store.DynamicCerts.Get().(map[string]*tls.Certificate) {
+				certificates = append(certificates, cert)
+			}
+		}
+	}
+
+	return certificates
+}
+
+// AddOnDemandResolver adds a resolver asked for the certificates of the domains unknown to the certificate stores.
+func (m *Manager) AddOnDemandResolver(resolver OnDemandResolver) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.onDemandResolvers = append(m.onDemandResolvers, resolver)
 }
 
 // Get gets the TLS configuration to use for a given store / configuration.
@@ -95,6 +132,7 @@ func (m *Manager) Get(storeName, configName string) (*tls.Config, error) {
 
 	store := m.getStore(storeName)
 	acmeTLSStore := m.getStore(tlsalpn01.ACMETLS1Protocol)
+	stapler := m.ocsp
 
 	if err == nil {
 		tlsConfig, err = buildTLSConfig(config)
@@ -117,7 +155,11 @@ func (m *Manager) Get(storeName, configName string) (*tls.Config, error) {
 
 		bestCertificate := store.GetBestCertificate(clientHello)
 		if bestCertificate != nil {
-			return bestCertificate, nil
+			return stapler.staple(bestCertificate), nil
+		}
+
+		if certificate := m.resolveOnDemand(domainToCheck); certificate != nil {
+			return certificate, nil
 		}
 
 		if m.configs[configName].SniStrict {
@@ -125,12 +167,38 @@ func (m *Manager) Get(storeName, configName string) (*tls.Config, error) {
 		}
 
 		log.WithoutContext().Debugf("Serving default certificate for request: %q", domainToCheck)
-		return store.DefaultCertificate, nil
+		return stapler.staple(store.DefaultCertificate), nil
 	}
 
 	return tlsConfig, err
 }
 
+// resolveOnDemand asks the on-demand resolvers for the certificate of the domain, the first obtained certificate being used.
+func (m *Manager) resolveOnDemand(domain string) *tls.Certificate {
+	// ACME certificates cannot be obtained for IP addresses.
+	if domain == "" || net.ParseIP(domain) != nil {
+		return nil
+	}
+
+	m.lock.RLock()
+	resolvers := m.onDemandResolvers
+	m.lock.RUnlock()
+
+	for _, resolver := range resolvers {
+		certificate, err := resolver.ResolveOnDemand(domain)
+		if err != nil {
+			log.WithoutContext().Debugf("Unable to obtain an on-demand certificate for %q: %v", domain, err)
+			continue
+		}
+
+		if certificate != nil {
+			return certificate
+		}
+	}
+
+	return nil
+}
+
 // GetCertificates returns all stored certificates.
 func (m *Manager) GetCertificates() []*x509.Certificate {
 	var certificates []*x509.Certificate
@@ -238,6 +306,18 @@ func buildTLSConfig(tlsOption Options) (*tls.Config, error) {
 		}
 	}
 
+	if tlsOption.ClientAuth.Revocation != nil {
+		if conf.ClientCAs == nil {
+			return nil, errors.New("invalid revocation checking: CAFiles is required")
+		}
+
+		checker, err := newRevocationChecker(tlsOption.ClientAuth.Revocation)
+		if err != nil {
+			return nil, err
+		}
+		conf.VerifyPeerCertificate = checker.VerifyPeerCertificate
+	}
+
 	// Set PreferServerCipherSuites.
 	conf.PreferServerCipherSuites = tlsOption.PreferServerCipherSuites
 
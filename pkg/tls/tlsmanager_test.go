@@ -5,10 +5,12 @@ import (
 	"crypto/tls"
 	"crypto/x509"
 	"encoding/pem"
+	"errors"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/traefik/traefik/v2/pkg/tls/generate"
 )
 
 // LocalhostCert is a PEM-encoded TLS cert with SAN IPs
@@ -298,3 +300,92 @@ func TestClientAuth(t *testing.T) {
 		})
 	}
 }
+
+func TestManager_Get_onDemand(t *testing.T) {
+	dynamicConfigs := []*CertAndStores{{
+		Certificate: Certificate{
+			CertFile: localhostCert,
+			KeyFile:  localhostKey,
+		},
+	}}
+
+	onDemandCert, err := generate.DefaultCertificate()
+	require.NoError(t, err)
+
+	testCases := []struct {
+		desc           string
+		serverName     string
+		resolvers      []OnDemandResolver
+		expectedCalls  []string
+		expectOnDemand bool
+	}{
+		{
+			desc:       "domain in the store",
+			serverName: "example.com",
+			resolvers:  []OnDemandResolver{&fakeOnDemandResolver{cert: onDemandCert}},
+		},
+		{
+			desc:           "unknown domain",
+			serverName:     "unknown.com",
+			resolvers:      []OnDemandResolver{&fakeOnDemandResolver{cert: onDemandCert}},
+			expectedCalls:  []string{"unknown.com"},
+			expectOnDemand: true,
+		},
+		{
+			desc:          "unknown domain without on-demand certificate",
+			serverName:    "unknown.com",
+			resolvers:     []OnDemandResolver{&fakeOnDemandResolver{}},
+			expectedCalls: []string{"unknown.com"},
+		},
+		{
+			desc:       "unknown domain with a failing resolver",
+			serverName: "Unknown.com",
+			resolvers: []OnDemandResolver{
+				&fakeOnDemandResolver{err: errors.New("boom")},
+				&fakeOnDemandResolver{cert: onDemandCert},
+			},
+			expectedCalls:  []string{"unknown.com"},
+			expectOnDemand: true,
+		},
+		{
+			desc:       "IP address",
+			serverName: "10.0.0.1",
+			resolvers:  []OnDemandResolver{&fakeOnDemandResolver{cert: onDemandCert}},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			tlsManager := NewManager()
+			tlsManager.UpdateConfigs(context.Background(), nil, map[string]Options{"default": {}}, dynamicConfigs)
+			for _, resolver := range test.resolvers {
+				tlsManager.AddOnDemandResolver(resolver)
+			}
+
+			config, err := tlsManager.Get("default", "default")
+			require.NoError(t, err)
+
+			cert, err := config.GetCertificate(&tls.ClientHelloInfo{ServerName: test.serverName})
+			require.NoError(t, err)
+
+			assert.Equal(t, test.expectOnDemand, cert == onDemandCert)
+
+			last := test.resolvers[len(test.resolvers)-1].(*fakeOnDemandResolver)
+			assert.Equal(t, test.expectedCalls, last.calls)
+		})
+	}
+}
+
+type fakeOnDemandResolver struct {
+	cert  *tls.Certificate
+	err   error
+	calls []string
+}
+
+func (f *fakeOnDemandResolver) ResolveOnDemand(domain string) (*tls.Certificate, error) {
+	f.calls = append(f.calls, domain)
+	return f.cert, f.err
+}
@@ -0,0 +1,223 @@
+package opentelemetry
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net/url"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/ext"
+	"github.com/traefik/traefik/v2/pkg/log"
+	"github.com/traefik/traefik/v2/pkg/types"
+	"github.com/traefik/traefik/v2/pkg/version"
+	"go.opentelemetry.io/otel/attribute"
+	oteltracer "go.opentelemetry.io/otel/bridge/opentracing"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.4.0"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc/credentials"
+)
+
+// Name sets the name of this tracer.
+const Name = "opentelemetry"
+
+// Config provides configuration settings for an OpenTelemetry tracer.
+type Config struct {
+	GRPC               *GRPC             `description:"gRPC specific configuration for the OpenTelemetry collector." json:"grpc,omitempty" toml:"grpc,omitempty" yaml:"grpc,omitempty" export:"true" label:"allowEmpty" file:"allowEmpty"`
+	HTTP               *HTTP             `description:"HTTP specific configuration for the OpenTelemetry collector." json:"http,omitempty" toml:"http,omitempty" yaml:"http,omitempty" export:"true" label:"allowEmpty" file:"allowEmpty"`
+	Headers            map[string]string `description:"Defines additional headers to be sent with the payloads." json:"headers,omitempty" toml:"headers,omitempty" yaml:"headers,omitempty"`
+	ResourceAttributes map[string]string `description:"Defines additional resource attributes (key:value)." json:"resourceAttributes,omitempty" toml:"resourceAttributes,omitempty" yaml:"resourceAttributes,omitempty" export:"true"`
+	SampleRate         float64           `description:"The rate between 0.0 and 1.0 of requests to trace." json:"sampleRate,omitempty" toml:"sampleRate,omitempty" yaml:"sampleRate,omitempty" export:"true"`
+}
+
+// SetDefaults sets the default values.
+func (c *Config) SetDefaults() {
+	c.SampleRate = 1.0
+}
+
+// GRPC provides configuration settings for the OTLP/gRPC exporter.
+type GRPC struct {
+	Endpoint string           `description:"Sets the gRPC endpoint (host:port) of the collector." json:"endpoint,omitempty" toml:"endpoint,omitempty" yaml:"endpoint,omitempty"`
+	Insecure bool             `description:"Disables client transport security for the exporter." json:"insecure,omitempty" toml:"insecure,omitempty" yaml:"insecure,omitempty" export:"true"`
+	TLS      *types.ClientTLS `description:"Defines client transport security parameters." json:"tls,omitempty" toml:"tls,omitempty" yaml:"tls,omitempty" export:"true"`
+}
+
+// SetDefaults sets the default values.
+func (c *GRPC) SetDefaults() {
+	c.Endpoint = "localhost:4317"
+}
+
+// HTTP provides configuration settings for the OTLP/HTTP exporter.
+type HTTP struct {
+	Endpoint string           `description:"Sets the HTTP endpoint (scheme://host:port/path) of the collector." json:"endpoint,omitempty" toml:"endpoint,omitempty" yaml:"endpoint,omitempty"`
+	TLS      *types.ClientTLS `description:"Defines client transport security parameters." json:"tls,omitempty" toml:"tls,omitempty" yaml:"tls,omitempty" export:"true"`
+}
+
+// SetDefaults sets the default values.
+func (c *HTTP) SetDefaults() {
+	c.Endpoint = "http://localhost:4318/v1/traces"
+}
+
+// Setup sets up the tracer.
+func (c *Config) Setup(componentName string) (opentracing.Tracer, io.Closer, error) {
+	if c.SampleRate < 0 || c.SampleRate > 1 {
+		return nil, nil, fmt.Errorf("invalid sample rate %v: must be between 0.0 and 1.0", c.SampleRate)
+	}
+
+	exporter, err := c.setupExporter()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	bt := oteltracer.NewBridgeTracer()
+	bt.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	bt.SetWarningHandler(func(msg string) {
+		log.WithoutContext().Debugf("OpenTelemetry bridge: %s", msg)
+	})
+
+	attr := []attribute.KeyValue{
+		semconv.ServiceNameKey.String(componentName),
+		semconv.ServiceVersionKey.String(version.Version),
+	}
+	for k, v := range c.ResourceAttributes {
+		attr = append(attr, attribute.String(k, v))
+	}
+
+	res, err := resource.New(context.Background(), resource.WithAttributes(attr...))
+	if err != nil {
+		return nil, nil, fmt.Errorf("building resource: %w", err)
+	}
+
+	tracerProvider := sdktrace.NewTracerProvider(
+		// The sampling decision of the parent span, e.g. the one received with the traceparent header, is kept.
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(c.SampleRate))),
+		sdktrace.WithResource(res),
+		sdktrace.WithBatcher(exporter),
+	)
+
+	bt.SetOpenTelemetryTracer(tracerProvider.Tracer("github.com/traefik/traefik", trace.WithInstrumentationVersion(version.Version)))
+
+	tracer := bridgeTracer{BridgeTracer: bt}
+
+	// Without this, child spans are getting the NOOP tracer
+	opentracing.SetGlobalTracer(tracer)
+
+	log.WithoutContext().Debug("OpenTelemetry tracer configured")
+
+	return tracer, tpCloser{provider: tracerProvider}, nil
+}
+
+func (c *Config) setupExporter() (*otlptrace.Exporter, error) {
+	if c.GRPC != nil && c.HTTP != nil {
+		return nil, fmt.Errorf("cannot use both gRPC and HTTP exporters")
+	}
+
+	if c.GRPC != nil {
+		return c.setupGRPCExporter()
+	}
+
+	// The HTTP exporter is used by default.
+	return c.setupHTTPExporter()
+}
+
+func (c *Config) setupHTTPExporter() (*otlptrace.Exporter, error) {
+	config := c.HTTP
+	if config == nil {
+		config = &HTTP{}
+		config.SetDefaults()
+	}
+
+	endpoint, err := url.Parse(config.Endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("invalid collector endpoint %q: %w", config.Endpoint, err)
+	}
+
+	opts := []otlptracehttp.Option{
+		otlptracehttp.WithEndpoint(endpoint.Host),
+		otlptracehttp.WithHeaders(c.Headers),
+	}
+
+	if endpoint.Scheme == "http" {
+		opts = append(opts, otlptracehttp.WithInsecure())
+	}
+
+	if endpoint.Path != "" {
+		opts = append(opts, otlptracehttp.WithURLPath(endpoint.Path))
+	}
+
+	if config.TLS != nil {
+		tlsConfig, err := config.TLS.CreateTLSConfig(context.Background())
+		if err != nil {
+			return nil, fmt.Errorf("creating HTTP TLS configuration: %w", err)
+		}
+
+		opts = append(opts, otlptracehttp.WithTLSClientConfig(tlsConfig))
+	}
+
+	return otlptrace.New(context.Background(), otlptracehttp.NewClient(opts...))
+}
+
+func (c *Config) setupGRPCExporter() (*otlptrace.Exporter, error) {
+	opts := []otlptracegrpc.Option{
+		otlptracegrpc.WithEndpoint(c.GRPC.Endpoint),
+		otlptracegrpc.WithHeaders(c.Headers),
+	}
+
+	if c.GRPC.Insecure {
+		opts = append(opts, otlptracegrpc.WithInsecure())
+	} else {
+		tlsConfig, err := createTLSConfig(c.GRPC.TLS)
+		if err != nil {
+			return nil, fmt.Errorf("creating gRPC TLS configuration: %w", err)
+		}
+
+		opts = append(opts, otlptracegrpc.WithTLSCredentials(credentials.NewTLS(tlsConfig)))
+	}
+
+	return otlptrace.New(context.Background(), otlptracegrpc.NewClient(opts...))
+}
+
+// createTLSConfig creates the TLS configuration of the gRPC exporter, which uses the system roots when no client TLS is configured.
+func createTLSConfig(config *types.ClientTLS) (*tls.Config, error) {
+	if config == nil {
+		return &tls.Config{}, nil
+	}
+
+	return config.CreateTLSConfig(context.Background())
+}
+
+// bridgeTracer is the OpenTracing bridge tracer,
+// which gives the span kind as a string, the only type supported by the bridge.
+type bridgeTracer struct {
+	*oteltracer.BridgeTracer
+}
+
+// StartSpan belongs to the Tracer interface.
+func (t bridgeTracer) StartSpan(operationName string, opts ...opentracing.StartSpanOption) opentracing.Span {
+	return t.BridgeTracer.StartSpan(operationName, append(opts, spanKindOption{})...)
+}
+
+// spanKindOption converts the span kind tag given with ext.SpanKindEnum into a string.
+type spanKindOption struct{}
+
+func (spanKindOption) Apply(options *opentracing.StartSpanOptions) {
+	if kind, ok := options.Tags[string(ext.SpanKind)].(ext.SpanKindEnum); ok {
+		options.Tags[string(ext.SpanKind)] = string(kind)
+	}
+}
+
+// tpCloser converts a TracerProvider into an io.Closer, which exports the remaining spans.
+type tpCloser struct {
+	provider *sdktrace.TracerProvider
+}
+
+func (t tpCloser) Close() error {
+	return t.provider.Shutdown(context.Background())
+}
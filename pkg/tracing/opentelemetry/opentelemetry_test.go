@@ -0,0 +1,254 @@
+package opentelemetry
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	mtracing "github.com/traefik/traefik/v2/pkg/middlewares/tracing"
+	"github.com/traefik/traefik/v2/pkg/tracing"
+	"github.com/traefik/traefik/v2/pkg/version"
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestConfig_Setup_invalidSampleRate(t *testing.T) {
+	config := &Config{}
+	config.SetDefaults()
+	config.SampleRate = 2
+
+	_, _, err := config.Setup("traefik")
+	assert.Error(t, err)
+}
+
+func TestConfig_Setup_bothExporters(t *testing.T) {
+	config := &Config{GRPC: &GRPC{}, HTTP: &HTTP{}}
+	config.SetDefaults()
+
+	_, _, err := config.Setup("traefik")
+	assert.Error(t, err)
+}
+
+func TestTracing_HTTP(t *testing.T) {
+	collector := newHTTPCollector(t)
+
+	config := &Config{HTTP: &HTTP{}}
+	config.SetDefaults()
+	config.HTTP.SetDefaults()
+	config.HTTP.Endpoint = collector.server.URL + "/v1/traces"
+	config.Headers = map[string]string{"Authorization": "Bearer foobar"}
+	config.ResourceAttributes = map[string]string{"deployment.environment": "test"}
+
+	backendHeaders := serveRequest(t, config, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+
+	collector.mu.Lock()
+	defer collector.mu.Unlock()
+
+	require.Len(t, collector.requests, 1)
+	assert.Equal(t, "application/x-protobuf", collector.headers[0].Get("Content-Type"))
+	assert.Equal(t, "Bearer foobar", collector.headers[0].Get("Authorization"))
+
+	assertExportRequest(t, collector.requests[0], backendHeaders)
+}
+
+func TestTracing_GRPC(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	collector := &grpcCollector{}
+
+	server := grpc.NewServer()
+	coltracepb.RegisterTraceServiceServer(server, collector)
+
+	go func() { _ = server.Serve(listener) }()
+	t.Cleanup(server.Stop)
+
+	config := &Config{GRPC: &GRPC{}}
+	config.SetDefaults()
+	config.GRPC.SetDefaults()
+	config.GRPC.Endpoint = listener.Addr().String()
+	config.GRPC.Insecure = true
+	config.Headers = map[string]string{"Authorization": "Bearer foobar"}
+	config.ResourceAttributes = map[string]string{"deployment.environment": "test"}
+
+	backendHeaders := serveRequest(t, config, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+
+	collector.mu.Lock()
+	defer collector.mu.Unlock()
+
+	require.Len(t, collector.requests, 1)
+	assert.Equal(t, []string{"Bearer foobar"}, collector.md[0].Get("authorization"))
+
+	assertExportRequest(t, collector.requests[0], backendHeaders)
+}
+
+func TestTracing_notSampled(t *testing.T) {
+	collector := newHTTPCollector(t)
+
+	config := &Config{HTTP: &HTTP{}}
+	config.SetDefaults()
+	config.SampleRate = 0
+	config.HTTP.SetDefaults()
+	config.HTTP.Endpoint = collector.server.URL + "/v1/traces"
+
+	backendHeaders := serveRequest(t, config, "")
+
+	collector.mu.Lock()
+	defer collector.mu.Unlock()
+
+	assert.Empty(t, collector.requests)
+	assert.Regexp(t, "^00-[0-9a-f]{32}-[0-9a-f]{16}-00$", backendHeaders.Get("Traceparent"))
+}
+
+// serveRequest sends a traced request through the entry point and forwarder middlewares,
+// closes the tracer to export the spans, and returns the headers received by the backend.
+func serveRequest(t *testing.T, config *Config, traceParent string) http.Header {
+	t.Helper()
+
+	tr, err := tracing.NewTracing("traefik", 0, config)
+	require.NoError(t, err)
+
+	var backendHeaders http.Header
+	backend := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		backendHeaders = req.Header
+		rw.WriteHeader(http.StatusNotFound)
+	})
+
+	ctx := context.Background()
+	handler := mtracing.NewEntryPoint(ctx, tr, "web", mtracing.NewForwarder(ctx, "router@file", "service@file", backend))
+
+	req := httptest.NewRequest(http.MethodGet, "http://www.test.com/foo", nil)
+	if traceParent != "" {
+		req.Header.Set("Traceparent", traceParent)
+	}
+	req.Header.Set("Baggage", "userId=alice")
+
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	tr.Close()
+
+	return backendHeaders
+}
+
+func assertExportRequest(t *testing.T, request *coltracepb.ExportTraceServiceRequest, backendHeaders http.Header) {
+	t.Helper()
+
+	require.Len(t, request.ResourceSpans, 1)
+	resourceSpans := request.ResourceSpans[0]
+
+	assert.Equal(t, map[string]string{
+		"service.name":           "traefik",
+		"service.version":        version.Version,
+		"deployment.environment": "test",
+	}, attributes(resourceSpans.Resource.Attributes))
+
+	require.Len(t, resourceSpans.InstrumentationLibrarySpans, 1)
+	spans := resourceSpans.InstrumentationLibrarySpans[0].Spans
+	require.Len(t, spans, 2)
+
+	// The forwarder span is finished first.
+	forwarder, entryPoint := spans[0], spans[1]
+
+	assert.Equal(t, "EntryPoint web www.test.com", entryPoint.Name)
+	assert.Equal(t, tracepb.Span_SPAN_KIND_SERVER, entryPoint.Kind)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", hex.EncodeToString(entryPoint.TraceId))
+	assert.Equal(t, "00f067aa0ba902b7", hex.EncodeToString(entryPoint.ParentSpanId))
+	assert.Equal(t, "GET", attributes(entryPoint.Attributes)["http.method"])
+	assert.Equal(t, "404", attributes(entryPoint.Attributes)["http.status_code"])
+	assert.Equal(t, tracepb.Status_STATUS_CODE_ERROR, entryPoint.Status.GetCode())
+
+	assert.Equal(t, "forward service@file/router@file", forwarder.Name)
+	assert.Equal(t, tracepb.Span_SPAN_KIND_CLIENT, forwarder.Kind)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", hex.EncodeToString(forwarder.TraceId))
+	assert.Equal(t, entryPoint.SpanId, forwarder.ParentSpanId)
+	assert.Equal(t, "service@file", attributes(forwarder.Attributes)["service.name"])
+	assert.Equal(t, "router@file", attributes(forwarder.Attributes)["router.name"])
+
+	assert.Equal(t, fmt.Sprintf("00-4bf92f3577b34da6a3ce929d0e0e4736-%x-01", forwarder.SpanId), backendHeaders.Get("Traceparent"))
+	// The OpenTracing bridge canonicalizes the baggage keys, which are case-insensitive in OpenTracing.
+	assert.Equal(t, "Userid=alice", backendHeaders.Get("Baggage"))
+}
+
+// attributes formats the values of the given attributes as strings.
+func attributes(keyValues []*commonpb.KeyValue) map[string]string {
+	result := make(map[string]string, len(keyValues))
+	for _, kv := range keyValues {
+		switch v := kv.Value.GetValue().(type) {
+		case *commonpb.AnyValue_StringValue:
+			result[kv.Key] = v.StringValue
+		case *commonpb.AnyValue_IntValue:
+			result[kv.Key] = fmt.Sprint(v.IntValue)
+		case *commonpb.AnyValue_BoolValue:
+			result[kv.Key] = fmt.Sprint(v.BoolValue)
+		}
+	}
+	return result
+}
+
+// httpCollector is an OTLP/HTTP collector stand-in, recording the export requests.
+type httpCollector struct {
+	server *httptest.Server
+
+	mu       sync.Mutex
+	requests []*coltracepb.ExportTraceServiceRequest
+	headers  []http.Header
+}
+
+func newHTTPCollector(t *testing.T) *httpCollector {
+	t.Helper()
+
+	c := &httpCollector{}
+	c.server = httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		body, err := ioutil.ReadAll(req.Body)
+		if err != nil {
+			http.Error(rw, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		request := &coltracepb.ExportTraceServiceRequest{}
+		if err := proto.Unmarshal(body, request); err != nil {
+			http.Error(rw, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		c.mu.Lock()
+		c.requests = append(c.requests, request)
+		c.headers = append(c.headers, req.Header)
+		c.mu.Unlock()
+	}))
+	t.Cleanup(c.server.Close)
+
+	return c
+}
+
+// grpcCollector is an OTLP/gRPC collector stand-in, recording the export requests.
+type grpcCollector struct {
+	coltracepb.UnimplementedTraceServiceServer
+
+	mu       sync.Mutex
+	requests []*coltracepb.ExportTraceServiceRequest
+	md       []metadata.MD
+}
+
+func (c *grpcCollector) Export(ctx context.Context, request *coltracepb.ExportTraceServiceRequest) (*coltracepb.ExportTraceServiceResponse, error) {
+	incoming, _ := metadata.FromIncomingContext(ctx)
+
+	c.mu.Lock()
+	c.requests = append(c.requests, request)
+	c.md = append(c.md, incoming)
+	c.mu.Unlock()
+
+	return &coltracepb.ExportTraceServiceResponse{}, nil
+}
@@ -150,21 +150,22 @@ func LogEventf(r *http.Request, format string, args ...interface{}) {
 
 // StartSpan starts a new span from the one in the request context.
 func StartSpan(r *http.Request, operationName string, spanKind ext.SpanKindEnum, opts ...opentracing.StartSpanOption) (opentracing.Span, *http.Request, func()) {
-	span, ctx := opentracing.StartSpanFromContext(r.Context(), operationName, opts...)
-
+	// The span kind is given when starting the span, as some tracers, e.g. the OpenTelemetry bridge, ignore it afterwards.
 	switch spanKind {
 	case ext.SpanKindRPCClientEnum:
-		ext.SpanKindRPCClient.Set(span)
+		opts = append(opts, ext.SpanKindRPCClient)
 	case ext.SpanKindRPCServerEnum:
-		ext.SpanKindRPCServer.Set(span)
+		opts = append(opts, ext.SpanKindRPCServer)
 	case ext.SpanKindProducerEnum:
-		ext.SpanKindProducer.Set(span)
+		opts = append(opts, ext.SpanKindProducer)
 	case ext.SpanKindConsumerEnum:
-		ext.SpanKindConsumer.Set(span)
+		opts = append(opts, ext.SpanKindConsumer)
 	default:
 		// noop
 	}
 
+	span, ctx := opentracing.StartSpanFromContext(r.Context(), operationName, opts...)
+
 	r = r.WithContext(ctx)
 	return span, r, func() { span.Finish() }
 }
@@ -17,6 +17,15 @@ const (
 
 	// JSONFormat is the JSON logging format.
 	JSONFormat string = "json"
+
+	// CombinedFormat is the combined logging format, i.e. the common logging format with the referer and the user agent.
+	CombinedFormat string = "combined"
+
+	// LogfmtFormat is the logfmt logging format.
+	LogfmtFormat string = "logfmt"
+
+	// TemplateFormat is the logging format defined by a template.
+	TemplateFormat string = "template"
 )
 
 // TraefikLog holds the configuration settings for the traefik logger.
@@ -34,11 +43,15 @@ func (l *TraefikLog) SetDefaults() {
 
 // AccessLog holds the configuration settings for the access logger (middlewares/accesslog).
 type AccessLog struct {
-	FilePath      string            `description:"Access log file path. Stdout is used when omitted or empty." json:"filePath,omitempty" toml:"filePath,omitempty" yaml:"filePath,omitempty"`
-	Format        string            `description:"Access log format: json | common" json:"format,omitempty" toml:"format,omitempty" yaml:"format,omitempty" export:"true"`
-	Filters       *AccessLogFilters `description:"Access log filters, used to keep only specific access logs." json:"filters,omitempty" toml:"filters,omitempty" yaml:"filters,omitempty" export:"true"`
-	Fields        *AccessLogFields  `description:"AccessLogFields." json:"fields,omitempty" toml:"fields,omitempty" yaml:"fields,omitempty" export:"true"`
-	BufferingSize int64             `description:"Number of access log lines to process in a buffered way." json:"bufferingSize,omitempty" toml:"bufferingSize,omitempty" yaml:"bufferingSize,omitempty" export:"true"`
+	FilePath      string             `description:"Access log file path. Stdout is used when omitted or empty." json:"filePath,omitempty" toml:"filePath,omitempty" yaml:"filePath,omitempty"`
+	Format        string             `description:"Access log format: json | common | combined | logfmt | template" json:"format,omitempty" toml:"format,omitempty" yaml:"format,omitempty" export:"true"`
+	Template      string             `description:"Access log template, used by the template format." json:"template,omitempty" toml:"template,omitempty" yaml:"template,omitempty" export:"true"`
+	Filters       *AccessLogFilters  `description:"Access log filters, used to keep only specific access logs." json:"filters,omitempty" toml:"filters,omitempty" yaml:"filters,omitempty" export:"true"`
+	Fields        *AccessLogFields   `description:"AccessLogFields." json:"fields,omitempty" toml:"fields,omitempty" yaml:"fields,omitempty" export:"true"`
+	BufferingSize int64              `description:"Number of access log lines to process in a buffered way." json:"bufferingSize,omitempty" toml:"bufferingSize,omitempty" yaml:"bufferingSize,omitempty" export:"true"`
+	Rotation      *AccessLogRotation `description:"Rotation of the access log file." json:"rotation,omitempty" toml:"rotation,omitempty" yaml:"rotation,omitempty" label:"allowEmpty" file:"allowEmpty" export:"true"`
+	Syslog        *AccessLogSyslog   `description:"Send the access logs to a syslog server instead of a file." json:"syslog,omitempty" toml:"syslog,omitempty" yaml:"syslog,omitempty" label:"allowEmpty" file:"allowEmpty" export:"true"`
+	Sampling      *AccessLogSampling `description:"Access log sampling, used to keep only a share of the access logs." json:"sampling,omitempty" toml:"sampling,omitempty" yaml:"sampling,omitempty" label:"allowEmpty" file:"allowEmpty" export:"true"`
 }
 
 // SetDefaults sets the default values.
@@ -50,11 +63,52 @@ func (l *AccessLog) SetDefaults() {
 	l.Fields.SetDefaults()
 }
 
+// AccessLogRotation holds the rotation configuration of the access log file.
+type AccessLogRotation struct {
+	MaxSize    int            `description:"Maximum size in megabytes of the access log file before it is rotated." json:"maxSize,omitempty" toml:"maxSize,omitempty" yaml:"maxSize,omitempty" export:"true"`
+	MaxAge     types.Duration `description:"Maximum age of the rotated access log files before they are removed." json:"maxAge,omitempty" toml:"maxAge,omitempty" yaml:"maxAge,omitempty" export:"true"`
+	MaxBackups int            `description:"Maximum number of rotated access log files to keep." json:"maxBackups,omitempty" toml:"maxBackups,omitempty" yaml:"maxBackups,omitempty" export:"true"`
+}
+
+// SetDefaults sets the default values.
+func (r *AccessLogRotation) SetDefaults() {
+	r.MaxSize = 100
+}
+
+// AccessLogSyslog holds the syslog configuration of the access logs.
+type AccessLogSyslog struct {
+	Network  string `description:"Network of the syslog server: udp | tcp | unix | unixgram" json:"network,omitempty" toml:"network,omitempty" yaml:"network,omitempty" export:"true"`
+	Address  string `description:"Address of the syslog server, or path of its socket." json:"address,omitempty" toml:"address,omitempty" yaml:"address,omitempty"`
+	Tag      string `description:"Tag of the syslog messages." json:"tag,omitempty" toml:"tag,omitempty" yaml:"tag,omitempty" export:"true"`
+	Facility string `description:"Facility of the syslog messages." json:"facility,omitempty" toml:"facility,omitempty" yaml:"facility,omitempty" export:"true"`
+}
+
+// SetDefaults sets the default values.
+func (s *AccessLogSyslog) SetDefaults() {
+	s.Network = "udp"
+	s.Address = "localhost:514"
+	s.Tag = "traefik"
+	s.Facility = "local0"
+}
+
+// AccessLogSampling holds the sampling configuration of the access logs.
+type AccessLogSampling struct {
+	Rate       float64 `description:"Share of the access logs to keep, between 0 and 1." json:"rate,omitempty" toml:"rate,omitempty" yaml:"rate,omitempty" export:"true"`
+	KeepErrors bool    `description:"Keep the access logs of the requests with a 5xx status code regardless of the rate." json:"keepErrors,omitempty" toml:"keepErrors,omitempty" yaml:"keepErrors,omitempty" export:"true"`
+}
+
+// SetDefaults sets the default values.
+func (s *AccessLogSampling) SetDefaults() {
+	s.Rate = 1
+}
+
 // AccessLogFilters holds filters configuration.
 type AccessLogFilters struct {
 	StatusCodes   []string       `description:"Keep access logs with status codes in the specified range." json:"statusCodes,omitempty" toml:"statusCodes,omitempty" yaml:"statusCodes,omitempty" export:"true"`
 	RetryAttempts bool           `description:"Keep access logs when at least one retry happened." json:"retryAttempts,omitempty" toml:"retryAttempts,omitempty" yaml:"retryAttempts,omitempty" export:"true"`
 	MinDuration   types.Duration `description:"Keep access logs when request took longer than the specified duration." json:"minDuration,omitempty" toml:"minDuration,omitempty" yaml:"minDuration,omitempty" export:"true"`
+	Include       string         `description:"Keep access logs of the requests matching the rule." json:"include,omitempty" toml:"include,omitempty" yaml:"include,omitempty"`
+	Exclude       string         `description:"Drop access logs of the requests matching the rule, regardless of the other filters." json:"exclude,omitempty" toml:"exclude,omitempty" yaml:"exclude,omitempty"`
 }
 
 // FieldHeaders holds configuration for access log headers.
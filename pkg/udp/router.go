@@ -0,0 +1,51 @@
+package udp
+
+import (
+	"net"
+	"sort"
+
+	"github.com/traefik/traefik/v2/pkg/log"
+)
+
+// Matcher reports whether a session, identified by the IP of its client, should be handled by a route.
+type Matcher func(clientIP net.IP) bool
+
+type route struct {
+	matcher  Matcher
+	priority int
+	handler  Handler
+}
+
+// Router is a UDP router which dispatches sessions to handlers according to the client IP.
+type Router struct {
+	routes []route
+}
+
+// AddRoute adds a route to the router.
+// A nil matcher matches all the sessions.
+// Routes are tried by decreasing priority, and in insertion order for routes with the same priority.
+func (r *Router) AddRoute(matcher Matcher, priority int, handler Handler) {
+	r.routes = append(r.routes, route{matcher: matcher, priority: priority, handler: handler})
+
+	sort.SliceStable(r.routes, func(i, j int) bool {
+		return r.routes[i].priority > r.routes[j].priority
+	})
+}
+
+// ServeUDP forwards the session to the handler of the first matching route.
+func (r *Router) ServeUDP(conn *Conn) {
+	var clientIP net.IP
+	if addr, ok := conn.RemoteAddr().(*net.UDPAddr); ok {
+		clientIP = addr.IP
+	}
+
+	for _, rt := range r.routes {
+		if rt.matcher == nil || rt.matcher(clientIP) {
+			rt.handler.ServeUDP(conn)
+			return
+		}
+	}
+
+	log.WithoutContext().Debugf("No UDP route matching client %s", conn.RemoteAddr())
+	conn.Close()
+}
@@ -0,0 +1,69 @@
+package udp
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRouter_ServeUDP(t *testing.T) {
+	internal := func(clientIP net.IP) bool {
+		_, ipNet, _ := net.ParseCIDR("10.0.0.0/8")
+		return ipNet.Contains(clientIP)
+	}
+
+	testCases := []struct {
+		desc     string
+		clientIP string
+		fallback bool
+		expected string
+	}{
+		{
+			desc:     "matching route",
+			clientIP: "10.0.0.1",
+			expected: "internal",
+		},
+		{
+			desc:     "not matching route, with fallback",
+			clientIP: "192.168.0.1",
+			fallback: true,
+			expected: "fallback",
+		},
+		{
+			desc:     "not matching route, without fallback",
+			clientIP: "192.168.0.1",
+			expected: "",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			var called string
+			newHandler := func(name string) Handler {
+				return HandlerFunc(func(conn *Conn) {
+					called = name
+				})
+			}
+
+			router := &Router{}
+			if test.fallback {
+				router.AddRoute(nil, 0, newHandler("fallback"))
+			}
+			router.AddRoute(internal, 10, newHandler("internal"))
+
+			conn := &Conn{
+				listener: &Listener{conns: make(map[string]*Conn)},
+				rAddr:    &net.UDPAddr{IP: net.ParseIP(test.clientIP), Port: 42},
+				doneCh:   make(chan struct{}),
+			}
+
+			router.ServeUDP(conn)
+
+			assert.Equal(t, test.expected, called)
+		})
+	}
+}